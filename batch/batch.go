@@ -0,0 +1,102 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+type Status string
+
+const (
+	Passed       Status = "PASS"
+	Failed       Status = "FAIL"
+	Skipped      Status = "SKIP"
+	NotRequested Status = "-"
+)
+
+// StepRunner runs one step for a target, writing everything it prints to
+// out, and reports whether the step succeeded.
+type StepRunner func(target Target, out io.Writer) bool
+
+type Result struct {
+	Name      string
+	Construct Status
+	Package   Status
+	Duration  time.Duration
+}
+
+func (r Result) Passed() bool {
+	return r.Construct != Failed && r.Package != Failed
+}
+
+// Run builds the targets with at most parallelism of them running at once.
+// The output of each target is written to output line by line, prefixed with
+// the target name. Package is skipped for a target whose construct failed.
+func Run(output io.Writer, targets []Target, parallelism int, construct, pkg StepRunner) []Result {
+	shared := &lockedWriter{w: output}
+	results := make([]Result, len(targets))
+	slots := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			out := newPrefixWriter(shared, fmt.Sprintf("[%s] ", target.Name))
+			results[i] = build(target, out, construct, pkg)
+			out.Flush()
+		}(i, target)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func build(target Target, out io.Writer, construct, pkg StepRunner) Result {
+	start := time.Now()
+	result := Result{Name: target.Name, Construct: NotRequested, Package: NotRequested}
+
+	if target.runs(StepConstruct) {
+		result.Construct = runStep(target, out, construct)
+	}
+	if target.runs(StepPackage) {
+		result.Package = Skipped
+		if result.Construct != Failed {
+			result.Package = runStep(target, out, pkg)
+		}
+	}
+
+	result.Duration = time.Since(start).Round(time.Second)
+	return result
+}
+
+func runStep(target Target, out io.Writer, step StepRunner) Status {
+	if step(target, out) {
+		return Passed
+	}
+	return Failed
+}
+
+func AllPassed(results []Result) bool {
+	for _, result := range results {
+		if !result.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+func PrintResults(out io.Writer, results []Result) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tCONSTRUCT\tPACKAGE\tDURATION")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, result.Construct, result.Package, result.Duration)
+	}
+	w.Flush() //nolint:errcheck
+}
@@ -0,0 +1,142 @@
+package batch_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/batch"
+)
+
+var _ = Describe("Batch", func() {
+	passing := func(batch.Target, io.Writer) bool { return true }
+	failing := func(batch.Target, io.Writer) bool { return false }
+
+	both := []string{batch.StepConstruct, batch.StepPackage}
+
+	Describe("Run", func() {
+		It("returns the results in the order of the targets", func() {
+			targets := []batch.Target{
+				{Name: "first", Steps: both},
+				{Name: "second", Steps: []string{batch.StepConstruct}},
+				{Name: "third", Steps: []string{batch.StepPackage}},
+			}
+
+			results := batch.Run(io.Discard, targets, 3, passing, passing)
+
+			Expect(results).To(HaveLen(3))
+			Expect(results[0]).To(haveResult(batch.Passed, batch.Passed, "first"))
+			Expect(results[1]).To(haveResult(batch.Passed, batch.NotRequested, "second"))
+			Expect(results[2]).To(haveResult(batch.NotRequested, batch.Passed, "third"))
+			Expect(batch.AllPassed(results)).To(BeTrue())
+		})
+
+		It("skips package when construct fails", func() {
+			packaged := false
+			pkg := func(batch.Target, io.Writer) bool {
+				packaged = true
+				return true
+			}
+
+			results := batch.Run(io.Discard, []batch.Target{{Name: "first", Steps: both}}, 1, failing, pkg)
+
+			Expect(packaged).To(BeFalse())
+			Expect(results[0]).To(haveResult(batch.Failed, batch.Skipped, "first"))
+			Expect(batch.AllPassed(results)).To(BeFalse())
+		})
+
+		It("runs no more than parallelism targets at once", func() {
+			var mu sync.Mutex
+			running, maxRunning := 0, 0
+			construct := func(batch.Target, io.Writer) bool {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return true
+			}
+
+			var targets []batch.Target
+			for i := 0; i < 6; i++ {
+				targets = append(targets, batch.Target{Name: fmt.Sprintf("target-%d", i), Steps: []string{batch.StepConstruct}})
+			}
+
+			batch.Run(io.Discard, targets, 2, construct, passing)
+
+			Expect(maxRunning).To(Equal(2))
+		})
+
+		It("prefixes every line of a target's output with its name", func() {
+			output := new(bytes.Buffer)
+			construct := func(target batch.Target, out io.Writer) bool {
+				_, _ = io.WriteString(out, "first line\nsecond ")
+				_, _ = io.WriteString(out, "line\nunterminated")
+				return true
+			}
+
+			batch.Run(output, []batch.Target{{Name: "vm", Steps: []string{batch.StepConstruct}}}, 1, construct, passing)
+
+			Expect(output.String()).To(Equal("[vm] first line\n[vm] second line\n[vm] unterminated\n"))
+		})
+
+		It("does not interleave lines of concurrent targets", func() {
+			output := new(bytes.Buffer)
+			construct := func(target batch.Target, out io.Writer) bool {
+				for i := 0; i < 100; i++ {
+					_, _ = io.WriteString(out, "some ")
+					_, _ = io.WriteString(out, "output\n")
+				}
+				return true
+			}
+
+			var targets []batch.Target
+			for i := 0; i < 4; i++ {
+				targets = append(targets, batch.Target{Name: fmt.Sprintf("target-%d", i), Steps: []string{batch.StepConstruct}})
+			}
+
+			batch.Run(output, targets, 4, construct, passing)
+
+			lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(400))
+			for _, line := range lines {
+				Expect(line).To(MatchRegexp(`^\[target-\d\] some output$`))
+			}
+		})
+	})
+
+	Describe("PrintResults", func() {
+		It("prints a table with the result of every target", func() {
+			output := new(bytes.Buffer)
+
+			batch.PrintResults(output, []batch.Result{
+				{Name: "first", Construct: batch.Passed, Package: batch.Passed, Duration: 90 * time.Second},
+				{Name: "second-target", Construct: batch.Failed, Package: batch.Skipped, Duration: time.Second},
+			})
+
+			Expect(output.String()).To(Equal(
+				"TARGET         CONSTRUCT  PACKAGE  DURATION\n" +
+					"first          PASS       PASS     1m30s\n" +
+					"second-target  FAIL       SKIP     1s\n"))
+		})
+	})
+})
+
+func haveResult(construct, pkg batch.Status, name string) OmegaMatcher {
+	return WithTransform(func(r batch.Result) []interface{} {
+		return []interface{}{r.Name, r.Construct, r.Package}
+	}, Equal([]interface{}{name, construct, pkg}))
+}
@@ -0,0 +1,45 @@
+package batch_factory
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/subcommands"
+
+	"github.com/cloudfoundry/stembuild/commandparser"
+	vmconstructfactory "github.com/cloudfoundry/stembuild/construct/factory"
+	vcenterclientfactory "github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/factory"
+	packagerfactory "github.com/cloudfoundry/stembuild/package_stemcell/factory"
+	"github.com/cloudfoundry/stembuild/version"
+)
+
+// CommandFactory creates the construct and package commands for a single
+// batch target. Every command gets its own factories, so no state is shared
+// between targets, and writes everything it prints to output.
+type CommandFactory struct{}
+
+func (f *CommandFactory) ConstructCmd(ctx context.Context, globalFlags *commandparser.GlobalFlags, output io.Writer) subcommands.Command {
+	cmd := commandparser.NewConstructCmd(
+		ctx,
+		&vmconstructfactory.VMConstructFactory{Output: output},
+		&vcenterclientfactory.ManagerFactory{},
+		&commandparser.ConstructValidator{},
+		&commandparser.ConstructCmdMessenger{OutputChannel: output},
+	)
+	cmd.GlobalFlags = globalFlags
+	// targets run concurrently, so none of them may read or prompt on stdin
+	cmd.Stdin = nil
+	return cmd
+}
+
+func (f *CommandFactory) PackageCmd(globalFlags *commandparser.GlobalFlags, output io.Writer) subcommands.Command {
+	cmd := commandparser.NewPackageCommand(
+		version.NewVersionGetter(),
+		&packagerfactory.PackagerFactory{Output: output},
+		&commandparser.PackageMessenger{Output: output},
+	)
+	cmd.GlobalFlags = globalFlags
+	cmd.LogOutput = output
+	cmd.Stdin = nil
+	return cmd
+}
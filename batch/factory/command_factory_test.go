@@ -0,0 +1,51 @@
+package batch_factory_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchfactory "github.com/cloudfoundry/stembuild/batch/factory"
+	"github.com/cloudfoundry/stembuild/commandparser"
+)
+
+var _ = Describe("CommandFactory", func() {
+	var (
+		factory     *batchfactory.CommandFactory
+		globalFlags *commandparser.GlobalFlags
+		output      *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		factory = &batchfactory.CommandFactory{}
+		globalFlags = &commandparser.GlobalFlags{Debug: true}
+		output = new(bytes.Buffer)
+	})
+
+	It("returns a construct command with the global flags", func() {
+		cmd := factory.ConstructCmd(context.Background(), globalFlags, output)
+
+		Expect(cmd).To(BeAssignableToTypeOf(&commandparser.ConstructCmd{}))
+		Expect(cmd.(*commandparser.ConstructCmd).GlobalFlags).To(BeIdenticalTo(globalFlags))
+	})
+
+	It("returns commands that do not read passwords from stdin", func() {
+		Expect(factory.ConstructCmd(context.Background(), globalFlags, output).(*commandparser.ConstructCmd).Stdin).To(BeNil())
+		Expect(factory.PackageCmd(globalFlags, output).(*commandparser.PackageCmd).Stdin).To(BeNil())
+	})
+
+	It("returns a package command that logs to the output", func() {
+		cmd := factory.PackageCmd(globalFlags, output)
+
+		Expect(cmd).To(BeAssignableToTypeOf(&commandparser.PackageCmd{}))
+		Expect(cmd.(*commandparser.PackageCmd).GlobalFlags).To(BeIdenticalTo(globalFlags))
+		Expect(cmd.(*commandparser.PackageCmd).LogOutput).To(BeIdenticalTo(output))
+	})
+
+	It("returns new commands for every call", func() {
+		Expect(factory.ConstructCmd(context.Background(), globalFlags, output)).NotTo(BeIdenticalTo(factory.ConstructCmd(context.Background(), globalFlags, output)))
+		Expect(factory.PackageCmd(globalFlags, output)).NotTo(BeIdenticalTo(factory.PackageCmd(globalFlags, output)))
+	})
+})
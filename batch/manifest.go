@@ -0,0 +1,159 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StepConstruct = "construct"
+	StepPackage   = "package"
+)
+
+// Manifest lists the VMs to build and the credentials used to reach them.
+type Manifest struct {
+	Credentials map[string]Credentials `yaml:"credentials"`
+	Targets     []Target               `yaml:"targets"`
+}
+
+// Credentials are shared by the targets that reference them by name.
+// Values may refer to environment variables as $NAME or ${NAME} so secrets
+// do not have to be written to the manifest.
+type Credentials struct {
+	VCenterUsername string `yaml:"vcenter-username"`
+	VCenterPassword string `yaml:"vcenter-password"`
+	VMUsername      string `yaml:"vm-username"`
+	VMPassword      string `yaml:"vm-password"`
+}
+
+// Target is a single VM to construct and/or package. ConstructArgs and
+// PackageArgs are passed through to the respective commands as extra flags.
+type Target struct {
+	Name            string   `yaml:"name"`
+	VCenterUrl      string   `yaml:"vcenter-url"`
+	CaCertFile      string   `yaml:"vcenter-ca-certs"`
+	VmInventoryPath string   `yaml:"vm-inventory-path"`
+	GuestVmIp       string   `yaml:"vm-ip"`
+	Credentials     string   `yaml:"credentials"`
+	OutputDir       string   `yaml:"output-dir"`
+	PatchVersion    string   `yaml:"patch-version"`
+	Steps           []string `yaml:"steps"`
+	ConstructArgs   []string `yaml:"construct-args"`
+	PackageArgs     []string `yaml:"package-args"`
+
+	credentials Credentials
+}
+
+// LoadManifest reads and validates the manifest at manifestPath, resolving
+// the credentials of every target.
+func LoadManifest(manifestPath string) ([]Target, error) {
+	contents, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read batch manifest: %s", err)
+	}
+
+	var manifest Manifest
+	err = yaml.Unmarshal(contents, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("batch manifest %s is invalid: %s", manifestPath, err)
+	}
+
+	return manifest.resolveTargets()
+}
+
+func (m Manifest) resolveTargets() ([]Target, error) {
+	if len(m.Targets) == 0 {
+		return nil, errors.New("batch manifest has no targets")
+	}
+
+	names := map[string]bool{}
+	targets := make([]Target, 0, len(m.Targets))
+	for i, target := range m.Targets {
+		if target.VmInventoryPath == "" {
+			return nil, fmt.Errorf("target %d has no vm-inventory-path", i+1)
+		}
+		if target.Name == "" {
+			target.Name = path.Base(target.VmInventoryPath)
+		}
+		if names[target.Name] {
+			return nil, fmt.Errorf("target name %s is used more than once", target.Name)
+		}
+		names[target.Name] = true
+
+		credentials, ok := m.Credentials[target.Credentials]
+		if !ok {
+			return nil, fmt.Errorf("target %s references unknown credentials '%s'", target.Name, target.Credentials)
+		}
+		target.credentials = Credentials{
+			VCenterUsername: os.ExpandEnv(credentials.VCenterUsername),
+			VCenterPassword: os.ExpandEnv(credentials.VCenterPassword),
+			VMUsername:      os.ExpandEnv(credentials.VMUsername),
+			VMPassword:      os.ExpandEnv(credentials.VMPassword),
+		}
+
+		if len(target.Steps) == 0 {
+			target.Steps = []string{StepConstruct, StepPackage}
+		}
+		for _, step := range target.Steps {
+			if step != StepConstruct && step != StepPackage {
+				return nil, fmt.Errorf("target %s has unknown step '%s', steps must be %s or %s", target.Name, step, StepConstruct, StepPackage)
+			}
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+func (t Target) runs(step string) bool {
+	for _, s := range t.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstructFlags returns the flags for running construct against the target.
+func (t Target) ConstructFlags() []string {
+	args := []string{
+		"-vcenter-url", t.VCenterUrl,
+		"-vcenter-username", t.credentials.VCenterUsername,
+		"-vcenter-password", t.credentials.VCenterPassword,
+		"-vm-inventory-path", t.VmInventoryPath,
+		"-vm-username", t.credentials.VMUsername,
+		"-vm-password", t.credentials.VMPassword,
+	}
+	if t.GuestVmIp != "" {
+		args = append(args, "-vm-ip", t.GuestVmIp)
+	}
+	if t.CaCertFile != "" {
+		args = append(args, "-vcenter-ca-certs", t.CaCertFile)
+	}
+	return append(args, t.ConstructArgs...)
+}
+
+// PackageFlags returns the flags for packaging the target into a stemcell.
+func (t Target) PackageFlags() []string {
+	args := []string{
+		"-vcenter-url", t.VCenterUrl,
+		"-vcenter-username", t.credentials.VCenterUsername,
+		"-vcenter-password", t.credentials.VCenterPassword,
+		"-vm-inventory-path", t.VmInventoryPath,
+	}
+	if t.CaCertFile != "" {
+		args = append(args, "-vcenter-ca-certs", t.CaCertFile)
+	}
+	if t.OutputDir != "" {
+		args = append(args, "-outputDir", t.OutputDir)
+	}
+	if t.PatchVersion != "" {
+		args = append(args, "-patch-version", t.PatchVersion)
+	}
+	return append(args, t.PackageArgs...)
+}
@@ -0,0 +1,125 @@
+package batch_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/batch"
+)
+
+var _ = Describe("LoadManifest", func() {
+	var manifestPath string
+
+	loadManifest := func(contents string) ([]batch.Target, error) {
+		Expect(os.WriteFile(manifestPath, []byte(contents), 0600)).To(Succeed())
+		return batch.LoadManifest(manifestPath)
+	}
+
+	BeforeEach(func() {
+		manifestPath = filepath.Join(GinkgoT().TempDir(), "builds.yml")
+	})
+
+	It("resolves the credentials of every target and expands environment variables in them", func() {
+		GinkgoT().Setenv("BATCH_TEST_PASSWORD", "from-env")
+
+		targets, err := loadManifest(`
+credentials:
+  lab:
+    vcenter-username: admin
+    vcenter-password: $BATCH_TEST_PASSWORD
+    vm-username: Administrator
+    vm-password: vm-secret
+targets:
+- name: 2019-patch-3
+  vcenter-url: vcenter.example.com
+  vcenter-ca-certs: ca.pem
+  vm-inventory-path: /dc/vm/first
+  vm-ip: 10.0.0.5
+  credentials: lab
+  output-dir: out
+  patch-version: "3"
+  construct-args: [-skip-os-check]
+  package-args: [-vmdk, ""]
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targets).To(HaveLen(1))
+
+		Expect(targets[0].ConstructFlags()).To(Equal([]string{
+			"-vcenter-url", "vcenter.example.com",
+			"-vcenter-username", "admin",
+			"-vcenter-password", "from-env",
+			"-vm-inventory-path", "/dc/vm/first",
+			"-vm-username", "Administrator",
+			"-vm-password", "vm-secret",
+			"-vm-ip", "10.0.0.5",
+			"-vcenter-ca-certs", "ca.pem",
+			"-skip-os-check",
+		}))
+		Expect(targets[0].PackageFlags()).To(Equal([]string{
+			"-vcenter-url", "vcenter.example.com",
+			"-vcenter-username", "admin",
+			"-vcenter-password", "from-env",
+			"-vm-inventory-path", "/dc/vm/first",
+			"-vcenter-ca-certs", "ca.pem",
+			"-outputDir", "out",
+			"-patch-version", "3",
+			"-vmdk", "",
+		}))
+	})
+
+	It("names targets after their VM and runs both steps by default", func() {
+		targets, err := loadManifest(`
+credentials:
+  lab: {}
+targets:
+- vm-inventory-path: /dc/vm/folder/my-vm
+  credentials: lab
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targets[0].Name).To(Equal("my-vm"))
+		Expect(targets[0].Steps).To(Equal([]string{batch.StepConstruct, batch.StepPackage}))
+	})
+
+	DescribeTable("rejects invalid manifests",
+		func(contents, expectedError string) {
+			_, err := loadManifest(contents)
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("without targets", "credentials: {}", "batch manifest has no targets"),
+		Entry("that is not yaml", "targets: [", "is invalid"),
+		Entry("with a target without an inventory path", `
+targets:
+- name: first
+`, "target 1 has no vm-inventory-path"),
+		Entry("with duplicate target names", `
+credentials:
+  lab: {}
+targets:
+- vm-inventory-path: /dc/vm/a/my-vm
+  credentials: lab
+- vm-inventory-path: /dc/vm/b/my-vm
+  credentials: lab
+`, "target name my-vm is used more than once"),
+		Entry("with unknown credentials", `
+targets:
+- vm-inventory-path: /dc/vm/my-vm
+  credentials: missing
+`, "target my-vm references unknown credentials 'missing'"),
+		Entry("with an unknown step", `
+credentials:
+  lab: {}
+targets:
+- vm-inventory-path: /dc/vm/my-vm
+  credentials: lab
+  steps: [construct, upload]
+`, "target my-vm has unknown step 'upload'"),
+	)
+
+	It("returns an error when the manifest cannot be read", func() {
+		_, err := batch.LoadManifest(filepath.Join(GinkgoT().TempDir(), "missing.yml"))
+		Expect(err).To(MatchError(HavePrefix("could not read batch manifest: ")))
+	})
+})
@@ -0,0 +1,131 @@
+package commandparser
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/subcommands"
+
+	"github.com/cloudfoundry/stembuild/batch"
+)
+
+//counterfeiter:generate . BatchCommandFactory
+type BatchCommandFactory interface {
+	ConstructCmd(ctx context.Context, globalFlags *GlobalFlags, output io.Writer) subcommands.Command
+	PackageCmd(globalFlags *GlobalFlags, output io.Writer) subcommands.Command
+}
+
+type BatchCmd struct {
+	ctx            context.Context
+	manifestPath   string
+	parallelism    int
+	commandFactory BatchCommandFactory
+	output         io.Writer
+	GlobalFlags    *GlobalFlags
+}
+
+func NewBatchCmd(ctx context.Context, commandFactory BatchCommandFactory, output io.Writer) *BatchCmd {
+	return &BatchCmd{ctx: ctx, commandFactory: commandFactory, output: output}
+}
+
+func (*BatchCmd) Name() string { return "batch" }
+func (*BatchCmd) Synopsis() string {
+	return "Constructs and packages several VMs concurrently from a manifest"
+}
+
+func (*BatchCmd) Usage() string {
+	return fmt.Sprintf(`%[1]s batch -f <manifest> [-parallelism <n>]
+
+Runs construct and then package for every target in the manifest, with up to [parallelism] targets at once.
+Each line of output is prefixed with the name of its target, and a table with the result of every target is printed at the end.
+Package is skipped for a target whose construct failed. Exits with a nonzero code when any target fails.
+
+Manifest:
+	credentials:
+	  lab:
+	    vcenter-username: administrator@vsphere.local
+	    vcenter-password: ${VCENTER_PASSWORD}
+	    vm-username: Administrator
+	    vm-password: ${VM_PASSWORD}
+	targets:
+	- name: 2019-patch-3                              # default is the VM name
+	  vcenter-url: vcenter.example.com
+	  vcenter-ca-certs: /path/to/ca.pem               # optional
+	  vm-inventory-path: /datacenter/vm/folder/vm-name
+	  vm-ip: 10.0.0.5
+	  credentials: lab
+	  output-dir: ./stemcells/2019                    # optional
+	  patch-version: "3"                              # optional
+	  steps: [construct, package]                     # default is both
+	  construct-args: [-setup-arg, "-Foo bar"]        # optional extra construct flags
+	  package-args: []                                # optional extra package flags
+
+	Credentials may refer to environment variables as $NAME or ${NAME}.
+
+Example:
+	%[1]s batch -f builds.yml -parallelism 3
+
+Flags:
+`, filepath.Base(os.Args[0]))
+}
+
+func (b *BatchCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&b.manifestPath, "f", "", "Manifest listing the targets to build")
+	f.IntVar(&b.parallelism, "parallelism", 2, "Maximum number of targets to build at once")
+}
+
+func (b *BatchCmd) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if b.manifestPath == "" {
+		fmt.Fprintln(b.output, "Not all required parameters were provided. See stembuild --help for more details")
+		return subcommands.ExitFailure
+	}
+	if b.parallelism < 1 {
+		fmt.Fprintln(b.output, "parallelism must be at least 1")
+		return subcommands.ExitFailure
+	}
+	// the output of targets is interleaved line by line, which would mix up their events
+	if b.GlobalFlags.JSONOutput() {
+		fmt.Fprintln(b.output, "-output json is not supported by batch")
+		return subcommands.ExitFailure
+	}
+
+	targets, err := batch.LoadManifest(b.manifestPath)
+	if err != nil {
+		fmt.Fprintln(b.output, err)
+		return subcommands.ExitFailure
+	}
+
+	construct := func(target batch.Target, out io.Writer) bool {
+		return b.runCommand(b.commandFactory.ConstructCmd(b.ctx, b.GlobalFlags, out), target.ConstructFlags(), out)
+	}
+	pkg := func(target batch.Target, out io.Writer) bool {
+		return b.runCommand(b.commandFactory.PackageCmd(b.GlobalFlags, out), target.PackageFlags(), out)
+	}
+
+	results := batch.Run(b.output, targets, b.parallelism, construct, pkg)
+	fmt.Fprintln(b.output)
+	batch.PrintResults(b.output, results)
+
+	if !batch.AllPassed(results) {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+// runCommand runs cmd as if it had been invoked with args on the command line
+func (b *BatchCmd) runCommand(cmd subcommands.Command, args []string, out io.Writer) bool {
+	f := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	f.SetOutput(out)
+	cmd.SetFlags(f)
+
+	err := f.Parse(args)
+	if err != nil {
+		return false
+	}
+
+	return cmd.Execute(b.ctx, f) == subcommands.ExitSuccess
+}
@@ -0,0 +1,206 @@
+package commandparser_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/subcommands"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/commandparser/commandparserfakes"
+)
+
+var _ = Describe("batch", func() {
+	var (
+		batchCmd            *commandparser.BatchCmd
+		fakeCommandFactory  *commandparserfakes.FakeBatchCommandFactory
+		fakeVmConstruct     *commandparserfakes.FakeVmConstruct
+		fakePreparerFactory *commandparserfakes.FakeVMPreparerFactory
+		fakeValidator       *commandparserfakes.FakeConstructCmdValidator
+		fakePackager        *commandparserfakes.FakePackager
+		fakePackagerFactory *commandparserfakes.FakePackagerFactory
+		fakeVersionGetter   *commandparserfakes.FakeOSAndVersionGetter
+		output              *Buffer
+		manifestPath        string
+		stemcellOutputDir   string
+	)
+
+	writeManifest := func(contents string) {
+		Expect(os.WriteFile(manifestPath, []byte(contents), 0600)).To(Succeed())
+	}
+
+	execute := func(args ...string) subcommands.ExitStatus {
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		batchCmd.SetFlags(f)
+		Expect(f.Parse(args)).To(Succeed())
+		return batchCmd.Execute(context.Background(), f)
+	}
+
+	BeforeEach(func() {
+		tmpDir := GinkgoT().TempDir()
+		manifestPath = filepath.Join(tmpDir, "builds.yml")
+		stemcellOutputDir = filepath.Join(tmpDir, "stemcells")
+
+		fakeVmConstruct = &commandparserfakes.FakeVmConstruct{}
+		fakePreparerFactory = &commandparserfakes.FakeVMPreparerFactory{}
+		fakePreparerFactory.VMPreparerReturns(fakeVmConstruct, nil)
+		fakeValidator = &commandparserfakes.FakeConstructCmdValidator{}
+		fakeValidator.PopulatedArgsReturns(true)
+		fakeValidator.LGPOInDirectoryReturns(true)
+
+		fakePackager = &commandparserfakes.FakePackager{}
+		fakePackagerFactory = &commandparserfakes.FakePackagerFactory{}
+		fakePackagerFactory.PackagerReturns(fakePackager, nil)
+		fakeVersionGetter = &commandparserfakes.FakeOSAndVersionGetter{}
+		fakeVersionGetter.GetOsReturns("2019")
+		fakeVersionGetter.GetVersionWithPatchNumberCalls(func(patch string) string { return "2019.2." + patch })
+
+		fakeCommandFactory = &commandparserfakes.FakeBatchCommandFactory{}
+		fakeCommandFactory.ConstructCmdCalls(func(ctx context.Context, gf *commandparser.GlobalFlags, out io.Writer) subcommands.Command {
+			_, _ = io.WriteString(out, "provisioning\n")
+			messenger := &commandparser.ConstructCmdMessenger{OutputChannel: out}
+			cmd := commandparser.NewConstructCmd(ctx, fakePreparerFactory, &commandparserfakes.FakeManagerFactory{}, fakeValidator, messenger)
+			cmd.GlobalFlags = gf
+			return cmd
+		})
+		fakeCommandFactory.PackageCmdCalls(func(gf *commandparser.GlobalFlags, out io.Writer) subcommands.Command {
+			cmd := commandparser.NewPackageCommand(fakeVersionGetter, fakePackagerFactory, &commandparser.PackageMessenger{Output: out})
+			cmd.GlobalFlags = gf
+			cmd.LogOutput = out
+			return cmd
+		})
+
+		output = NewBuffer()
+		batchCmd = commandparser.NewBatchCmd(context.Background(), fakeCommandFactory, output)
+		batchCmd.GlobalFlags = &commandparser.GlobalFlags{}
+
+		GinkgoT().Setenv("BATCH_TEST_VCENTER_PASSWORD", "vcenter-secret")
+		writeManifest(`
+credentials:
+  lab:
+    vcenter-username: admin
+    vcenter-password: ${BATCH_TEST_VCENTER_PASSWORD}
+    vm-username: Administrator
+    vm-password: vm-secret
+targets:
+- name: first
+  vcenter-url: vcenter.example.com
+  vm-inventory-path: /dc/vm/first
+  vm-ip: 10.0.0.5
+  credentials: lab
+  output-dir: ` + stemcellOutputDir + `
+  patch-version: "3"
+  construct-args: [-setup-arg, "-Foo bar"]
+- vcenter-url: vcenter.example.com
+  vm-inventory-path: /dc/vm/second
+  vm-ip: 10.0.0.6
+  credentials: lab
+  steps: [construct]
+`)
+	})
+
+	It("constructs every target and packages the ones that ask for it", func() {
+		Expect(execute("-f", manifestPath)).To(Equal(subcommands.ExitSuccess))
+
+		Expect(fakePreparerFactory.VMPreparerCallCount()).To(Equal(2))
+		Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(2))
+		Expect(fakePackager.PackageCallCount()).To(Equal(1))
+
+		var inventoryPaths []string
+		for i := 0; i < 2; i++ {
+			_, sourceConfig, _ := fakePreparerFactory.VMPreparerArgsForCall(i)
+			inventoryPaths = append(inventoryPaths, sourceConfig.VmInventoryPath)
+			Expect(sourceConfig.VCenterUrl).To(Equal("vcenter.example.com"))
+			Expect(sourceConfig.VCenterUsername).To(Equal("admin"))
+			Expect(sourceConfig.VCenterPassword).To(Equal("vcenter-secret"))
+			Expect(sourceConfig.GuestVMUsername).To(Equal("Administrator"))
+			Expect(sourceConfig.GuestVMPassword).To(Equal("vm-secret"))
+			if sourceConfig.VmInventoryPath == "/dc/vm/first" {
+				Expect(sourceConfig.GuestVmIp).To(Equal("10.0.0.5"))
+				Expect(sourceConfig.SetupFlags).To(Equal([]string{"-Foo bar"}))
+			}
+		}
+		Expect(inventoryPaths).To(ConsistOf("/dc/vm/first", "/dc/vm/second"))
+
+		_, sourceConfig, outputConfig, _ := fakePackagerFactory.PackagerArgsForCall(0)
+		Expect(sourceConfig.VmInventoryPath).To(Equal("/dc/vm/first"))
+		Expect(sourceConfig.Password).To(Equal("vcenter-secret"))
+		Expect(outputConfig.OutputDir).To(Equal(stemcellOutputDir))
+		Expect(outputConfig.StemcellVersion).To(Equal("2019.2.3"))
+	})
+
+	It("prefixes the output of each target with its name and prints a result table", func() {
+		Expect(execute("-f", manifestPath)).To(Equal(subcommands.ExitSuccess))
+
+		Expect(output).To(Say(`\[(first|second)\] provisioning`))
+		Expect(string(output.Contents())).To(ContainSubstring("[first] provisioning\n"))
+		Expect(string(output.Contents())).To(ContainSubstring("[second] provisioning\n"))
+		Expect(output).To(Say(`TARGET\s+CONSTRUCT\s+PACKAGE\s+DURATION`))
+		Expect(output).To(Say(`first\s+PASS\s+PASS\s+`))
+		Expect(output).To(Say(`second\s+PASS\s+-\s+`))
+	})
+
+	It("skips packaging and fails when construct fails", func() {
+		fakeVmConstruct.PrepareVMReturns(errors.New("test error"))
+
+		Expect(execute("-f", manifestPath, "-parallelism", "1")).To(Equal(subcommands.ExitFailure))
+
+		Expect(fakePackager.PackageCallCount()).To(Equal(0))
+		Expect(output).To(Say(`\[first\] Could not prepare VM: test error`))
+		Expect(output).To(Say(`first\s+FAIL\s+SKIP\s+`))
+		Expect(output).To(Say(`second\s+FAIL\s+-\s+`))
+	})
+
+	It("fails the target when its extra flags are invalid", func() {
+		writeManifest(`
+credentials:
+  lab: {}
+targets:
+- vm-inventory-path: /dc/vm/first
+  credentials: lab
+  steps: [package]
+  package-args: [-not-a-flag]
+`)
+
+		Expect(execute("-f", manifestPath)).To(Equal(subcommands.ExitFailure))
+
+		Expect(fakePackagerFactory.PackagerCallCount()).To(Equal(0))
+		Expect(output).To(Say(`\[first\] flag provided but not defined: -not-a-flag`))
+		Expect(output).To(Say(`first\s+-\s+FAIL\s+`))
+	})
+
+	It("fails when no manifest is given", func() {
+		Expect(execute()).To(Equal(subcommands.ExitFailure))
+		Expect(output).To(Say("Not all required parameters were provided"))
+		Expect(fakeCommandFactory.ConstructCmdCallCount()).To(Equal(0))
+	})
+
+	It("fails when the parallelism is less than one", func() {
+		Expect(execute("-f", manifestPath, "-parallelism", "0")).To(Equal(subcommands.ExitFailure))
+		Expect(output).To(Say("parallelism must be at least 1"))
+	})
+
+	It("fails with JSON output", func() {
+		batchCmd.GlobalFlags.Output = "json"
+		writeManifest("targets: []")
+
+		Expect(execute("-f", manifestPath)).To(Equal(subcommands.ExitFailure))
+		Expect(output).To(Say("-output json is not supported by batch"))
+		Expect(fakeCommandFactory.ConstructCmdCallCount()).To(Equal(0))
+	})
+
+	It("fails when the manifest is invalid", func() {
+		writeManifest("targets: []")
+
+		Expect(execute("-f", manifestPath)).To(Equal(subcommands.ExitFailure))
+		Expect(output).To(Say("batch manifest has no targets"))
+		Expect(fakeCommandFactory.ConstructCmdCallCount()).To(Equal(0))
+	})
+})
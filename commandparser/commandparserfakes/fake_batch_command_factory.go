@@ -0,0 +1,194 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package commandparserfakes
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/google/subcommands"
+)
+
+type FakeBatchCommandFactory struct {
+	ConstructCmdStub        func(context.Context, *commandparser.GlobalFlags, io.Writer) subcommands.Command
+	constructCmdMutex       sync.RWMutex
+	constructCmdArgsForCall []struct {
+		arg1 context.Context
+		arg2 *commandparser.GlobalFlags
+		arg3 io.Writer
+	}
+	constructCmdReturns struct {
+		result1 subcommands.Command
+	}
+	constructCmdReturnsOnCall map[int]struct {
+		result1 subcommands.Command
+	}
+	PackageCmdStub        func(*commandparser.GlobalFlags, io.Writer) subcommands.Command
+	packageCmdMutex       sync.RWMutex
+	packageCmdArgsForCall []struct {
+		arg1 *commandparser.GlobalFlags
+		arg2 io.Writer
+	}
+	packageCmdReturns struct {
+		result1 subcommands.Command
+	}
+	packageCmdReturnsOnCall map[int]struct {
+		result1 subcommands.Command
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBatchCommandFactory) ConstructCmd(arg1 context.Context, arg2 *commandparser.GlobalFlags, arg3 io.Writer) subcommands.Command {
+	fake.constructCmdMutex.Lock()
+	ret, specificReturn := fake.constructCmdReturnsOnCall[len(fake.constructCmdArgsForCall)]
+	fake.constructCmdArgsForCall = append(fake.constructCmdArgsForCall, struct {
+		arg1 context.Context
+		arg2 *commandparser.GlobalFlags
+		arg3 io.Writer
+	}{arg1, arg2, arg3})
+	stub := fake.ConstructCmdStub
+	fakeReturns := fake.constructCmdReturns
+	fake.recordInvocation("ConstructCmd", []interface{}{arg1, arg2, arg3})
+	fake.constructCmdMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBatchCommandFactory) ConstructCmdCallCount() int {
+	fake.constructCmdMutex.RLock()
+	defer fake.constructCmdMutex.RUnlock()
+	return len(fake.constructCmdArgsForCall)
+}
+
+func (fake *FakeBatchCommandFactory) ConstructCmdCalls(stub func(context.Context, *commandparser.GlobalFlags, io.Writer) subcommands.Command) {
+	fake.constructCmdMutex.Lock()
+	defer fake.constructCmdMutex.Unlock()
+	fake.ConstructCmdStub = stub
+}
+
+func (fake *FakeBatchCommandFactory) ConstructCmdArgsForCall(i int) (context.Context, *commandparser.GlobalFlags, io.Writer) {
+	fake.constructCmdMutex.RLock()
+	defer fake.constructCmdMutex.RUnlock()
+	argsForCall := fake.constructCmdArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeBatchCommandFactory) ConstructCmdReturns(result1 subcommands.Command) {
+	fake.constructCmdMutex.Lock()
+	defer fake.constructCmdMutex.Unlock()
+	fake.ConstructCmdStub = nil
+	fake.constructCmdReturns = struct {
+		result1 subcommands.Command
+	}{result1}
+}
+
+func (fake *FakeBatchCommandFactory) ConstructCmdReturnsOnCall(i int, result1 subcommands.Command) {
+	fake.constructCmdMutex.Lock()
+	defer fake.constructCmdMutex.Unlock()
+	fake.ConstructCmdStub = nil
+	if fake.constructCmdReturnsOnCall == nil {
+		fake.constructCmdReturnsOnCall = make(map[int]struct {
+			result1 subcommands.Command
+		})
+	}
+	fake.constructCmdReturnsOnCall[i] = struct {
+		result1 subcommands.Command
+	}{result1}
+}
+
+func (fake *FakeBatchCommandFactory) PackageCmd(arg1 *commandparser.GlobalFlags, arg2 io.Writer) subcommands.Command {
+	fake.packageCmdMutex.Lock()
+	ret, specificReturn := fake.packageCmdReturnsOnCall[len(fake.packageCmdArgsForCall)]
+	fake.packageCmdArgsForCall = append(fake.packageCmdArgsForCall, struct {
+		arg1 *commandparser.GlobalFlags
+		arg2 io.Writer
+	}{arg1, arg2})
+	stub := fake.PackageCmdStub
+	fakeReturns := fake.packageCmdReturns
+	fake.recordInvocation("PackageCmd", []interface{}{arg1, arg2})
+	fake.packageCmdMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBatchCommandFactory) PackageCmdCallCount() int {
+	fake.packageCmdMutex.RLock()
+	defer fake.packageCmdMutex.RUnlock()
+	return len(fake.packageCmdArgsForCall)
+}
+
+func (fake *FakeBatchCommandFactory) PackageCmdCalls(stub func(*commandparser.GlobalFlags, io.Writer) subcommands.Command) {
+	fake.packageCmdMutex.Lock()
+	defer fake.packageCmdMutex.Unlock()
+	fake.PackageCmdStub = stub
+}
+
+func (fake *FakeBatchCommandFactory) PackageCmdArgsForCall(i int) (*commandparser.GlobalFlags, io.Writer) {
+	fake.packageCmdMutex.RLock()
+	defer fake.packageCmdMutex.RUnlock()
+	argsForCall := fake.packageCmdArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBatchCommandFactory) PackageCmdReturns(result1 subcommands.Command) {
+	fake.packageCmdMutex.Lock()
+	defer fake.packageCmdMutex.Unlock()
+	fake.PackageCmdStub = nil
+	fake.packageCmdReturns = struct {
+		result1 subcommands.Command
+	}{result1}
+}
+
+func (fake *FakeBatchCommandFactory) PackageCmdReturnsOnCall(i int, result1 subcommands.Command) {
+	fake.packageCmdMutex.Lock()
+	defer fake.packageCmdMutex.Unlock()
+	fake.PackageCmdStub = nil
+	if fake.packageCmdReturnsOnCall == nil {
+		fake.packageCmdReturnsOnCall = make(map[int]struct {
+			result1 subcommands.Command
+		})
+	}
+	fake.packageCmdReturnsOnCall[i] = struct {
+		result1 subcommands.Command
+	}{result1}
+}
+
+func (fake *FakeBatchCommandFactory) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.constructCmdMutex.RLock()
+	defer fake.constructCmdMutex.RUnlock()
+	fake.packageCmdMutex.RLock()
+	defer fake.packageCmdMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeBatchCommandFactory) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commandparser.BatchCommandFactory = new(FakeBatchCommandFactory)
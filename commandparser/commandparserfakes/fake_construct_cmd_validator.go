@@ -5,9 +5,21 @@ import (
 	"sync"
 
 	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
 )
 
 type FakeConstructCmdValidator struct {
+	AutomationZipStub        func(string) error
+	automationZipMutex       sync.RWMutex
+	automationZipArgsForCall []struct {
+		arg1 string
+	}
+	automationZipReturns struct {
+		result1 error
+	}
+	automationZipReturnsOnCall map[int]struct {
+		result1 error
+	}
 	LGPOInDirectoryStub        func() bool
 	lGPOInDirectoryMutex       sync.RWMutex
 	lGPOInDirectoryArgsForCall []struct {
@@ -29,10 +41,96 @@ type FakeConstructCmdValidator struct {
 	populatedArgsReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	PostRebootArgsStub        func(string, []string) error
+	postRebootArgsMutex       sync.RWMutex
+	postRebootArgsArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	postRebootArgsReturns struct {
+		result1 error
+	}
+	postRebootArgsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ResolveLGPOStub        func(lgpo.Source) (string, error)
+	resolveLGPOMutex       sync.RWMutex
+	resolveLGPOArgsForCall []struct {
+		arg1 lgpo.Source
+	}
+	resolveLGPOReturns struct {
+		result1 string
+		result2 error
+	}
+	resolveLGPOReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeConstructCmdValidator) AutomationZip(arg1 string) error {
+	fake.automationZipMutex.Lock()
+	ret, specificReturn := fake.automationZipReturnsOnCall[len(fake.automationZipArgsForCall)]
+	fake.automationZipArgsForCall = append(fake.automationZipArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AutomationZipStub
+	fakeReturns := fake.automationZipReturns
+	fake.recordInvocation("AutomationZip", []interface{}{arg1})
+	fake.automationZipMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeConstructCmdValidator) AutomationZipCallCount() int {
+	fake.automationZipMutex.RLock()
+	defer fake.automationZipMutex.RUnlock()
+	return len(fake.automationZipArgsForCall)
+}
+
+func (fake *FakeConstructCmdValidator) AutomationZipCalls(stub func(string) error) {
+	fake.automationZipMutex.Lock()
+	defer fake.automationZipMutex.Unlock()
+	fake.AutomationZipStub = stub
+}
+
+func (fake *FakeConstructCmdValidator) AutomationZipArgsForCall(i int) string {
+	fake.automationZipMutex.RLock()
+	defer fake.automationZipMutex.RUnlock()
+	argsForCall := fake.automationZipArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructCmdValidator) AutomationZipReturns(result1 error) {
+	fake.automationZipMutex.Lock()
+	defer fake.automationZipMutex.Unlock()
+	fake.AutomationZipStub = nil
+	fake.automationZipReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeConstructCmdValidator) AutomationZipReturnsOnCall(i int, result1 error) {
+	fake.automationZipMutex.Lock()
+	defer fake.automationZipMutex.Unlock()
+	fake.AutomationZipStub = nil
+	if fake.automationZipReturnsOnCall == nil {
+		fake.automationZipReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.automationZipReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeConstructCmdValidator) LGPOInDirectory() bool {
 	fake.lGPOInDirectoryMutex.Lock()
 	ret, specificReturn := fake.lGPOInDirectoryReturnsOnCall[len(fake.lGPOInDirectoryArgsForCall)]
@@ -147,13 +245,150 @@ func (fake *FakeConstructCmdValidator) PopulatedArgsReturnsOnCall(i int, result1
 	}{result1}
 }
 
+func (fake *FakeConstructCmdValidator) PostRebootArgs(arg1 string, arg2 []string) error {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.postRebootArgsMutex.Lock()
+	ret, specificReturn := fake.postRebootArgsReturnsOnCall[len(fake.postRebootArgsArgsForCall)]
+	fake.postRebootArgsArgsForCall = append(fake.postRebootArgsArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.PostRebootArgsStub
+	fakeReturns := fake.postRebootArgsReturns
+	fake.recordInvocation("PostRebootArgs", []interface{}{arg1, arg2Copy})
+	fake.postRebootArgsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeConstructCmdValidator) PostRebootArgsCallCount() int {
+	fake.postRebootArgsMutex.RLock()
+	defer fake.postRebootArgsMutex.RUnlock()
+	return len(fake.postRebootArgsArgsForCall)
+}
+
+func (fake *FakeConstructCmdValidator) PostRebootArgsCalls(stub func(string, []string) error) {
+	fake.postRebootArgsMutex.Lock()
+	defer fake.postRebootArgsMutex.Unlock()
+	fake.PostRebootArgsStub = stub
+}
+
+func (fake *FakeConstructCmdValidator) PostRebootArgsArgsForCall(i int) (string, []string) {
+	fake.postRebootArgsMutex.RLock()
+	defer fake.postRebootArgsMutex.RUnlock()
+	argsForCall := fake.postRebootArgsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeConstructCmdValidator) PostRebootArgsReturns(result1 error) {
+	fake.postRebootArgsMutex.Lock()
+	defer fake.postRebootArgsMutex.Unlock()
+	fake.PostRebootArgsStub = nil
+	fake.postRebootArgsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeConstructCmdValidator) PostRebootArgsReturnsOnCall(i int, result1 error) {
+	fake.postRebootArgsMutex.Lock()
+	defer fake.postRebootArgsMutex.Unlock()
+	fake.PostRebootArgsStub = nil
+	if fake.postRebootArgsReturnsOnCall == nil {
+		fake.postRebootArgsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.postRebootArgsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeConstructCmdValidator) ResolveLGPO(arg1 lgpo.Source) (string, error) {
+	fake.resolveLGPOMutex.Lock()
+	ret, specificReturn := fake.resolveLGPOReturnsOnCall[len(fake.resolveLGPOArgsForCall)]
+	fake.resolveLGPOArgsForCall = append(fake.resolveLGPOArgsForCall, struct {
+		arg1 lgpo.Source
+	}{arg1})
+	stub := fake.ResolveLGPOStub
+	fakeReturns := fake.resolveLGPOReturns
+	fake.recordInvocation("ResolveLGPO", []interface{}{arg1})
+	fake.resolveLGPOMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeConstructCmdValidator) ResolveLGPOCallCount() int {
+	fake.resolveLGPOMutex.RLock()
+	defer fake.resolveLGPOMutex.RUnlock()
+	return len(fake.resolveLGPOArgsForCall)
+}
+
+func (fake *FakeConstructCmdValidator) ResolveLGPOCalls(stub func(lgpo.Source) (string, error)) {
+	fake.resolveLGPOMutex.Lock()
+	defer fake.resolveLGPOMutex.Unlock()
+	fake.ResolveLGPOStub = stub
+}
+
+func (fake *FakeConstructCmdValidator) ResolveLGPOArgsForCall(i int) lgpo.Source {
+	fake.resolveLGPOMutex.RLock()
+	defer fake.resolveLGPOMutex.RUnlock()
+	argsForCall := fake.resolveLGPOArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructCmdValidator) ResolveLGPOReturns(result1 string, result2 error) {
+	fake.resolveLGPOMutex.Lock()
+	defer fake.resolveLGPOMutex.Unlock()
+	fake.ResolveLGPOStub = nil
+	fake.resolveLGPOReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeConstructCmdValidator) ResolveLGPOReturnsOnCall(i int, result1 string, result2 error) {
+	fake.resolveLGPOMutex.Lock()
+	defer fake.resolveLGPOMutex.Unlock()
+	fake.ResolveLGPOStub = nil
+	if fake.resolveLGPOReturnsOnCall == nil {
+		fake.resolveLGPOReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.resolveLGPOReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeConstructCmdValidator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.automationZipMutex.RLock()
+	defer fake.automationZipMutex.RUnlock()
 	fake.lGPOInDirectoryMutex.RLock()
 	defer fake.lGPOInDirectoryMutex.RUnlock()
 	fake.populatedArgsMutex.RLock()
 	defer fake.populatedArgsMutex.RUnlock()
+	fake.postRebootArgsMutex.RLock()
+	defer fake.postRebootArgsMutex.RUnlock()
+	fake.resolveLGPOMutex.RLock()
+	defer fake.resolveLGPOMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -22,6 +22,40 @@ type FakeConstructMessenger struct {
 	cannotPrepareVMArgsForCall []struct {
 		arg1 error
 	}
+	CannotReadPasswordStub        func(error)
+	cannotReadPasswordMutex       sync.RWMutex
+	cannotReadPasswordArgsForCall []struct {
+		arg1 error
+	}
+	InvalidAutomationZipStub        func(error)
+	invalidAutomationZipMutex       sync.RWMutex
+	invalidAutomationZipArgsForCall []struct {
+		arg1 error
+	}
+	InvalidCloneNetworkStub        func()
+	invalidCloneNetworkMutex       sync.RWMutex
+	invalidCloneNetworkArgsForCall []struct {
+	}
+	InvalidConfigStub        func(error)
+	invalidConfigMutex       sync.RWMutex
+	invalidConfigArgsForCall []struct {
+		arg1 error
+	}
+	InvalidLGPOStub        func(error)
+	invalidLGPOMutex       sync.RWMutex
+	invalidLGPOArgsForCall []struct {
+		arg1 error
+	}
+	InvalidPostRebootArgStub        func(error)
+	invalidPostRebootArgMutex       sync.RWMutex
+	invalidPostRebootArgArgsForCall []struct {
+		arg1 error
+	}
+	InvalidWinRMOptionsStub        func(error)
+	invalidWinRMOptionsMutex       sync.RWMutex
+	invalidWinRMOptionsArgsForCall []struct {
+		arg1 error
+	}
 	LGPONotFoundStub        func()
 	lGPONotFoundMutex       sync.RWMutex
 	lGPONotFoundArgsForCall []struct {
@@ -118,6 +152,222 @@ func (fake *FakeConstructMessenger) CannotPrepareVMArgsForCall(i int) error {
 	return argsForCall.arg1
 }
 
+func (fake *FakeConstructMessenger) CannotReadPassword(arg1 error) {
+	fake.cannotReadPasswordMutex.Lock()
+	fake.cannotReadPasswordArgsForCall = append(fake.cannotReadPasswordArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.CannotReadPasswordStub
+	fake.recordInvocation("CannotReadPassword", []interface{}{arg1})
+	fake.cannotReadPasswordMutex.Unlock()
+	if stub != nil {
+		fake.CannotReadPasswordStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) CannotReadPasswordCallCount() int {
+	fake.cannotReadPasswordMutex.RLock()
+	defer fake.cannotReadPasswordMutex.RUnlock()
+	return len(fake.cannotReadPasswordArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CannotReadPasswordCalls(stub func(error)) {
+	fake.cannotReadPasswordMutex.Lock()
+	defer fake.cannotReadPasswordMutex.Unlock()
+	fake.CannotReadPasswordStub = stub
+}
+
+func (fake *FakeConstructMessenger) CannotReadPasswordArgsForCall(i int) error {
+	fake.cannotReadPasswordMutex.RLock()
+	defer fake.cannotReadPasswordMutex.RUnlock()
+	argsForCall := fake.cannotReadPasswordArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) InvalidAutomationZip(arg1 error) {
+	fake.invalidAutomationZipMutex.Lock()
+	fake.invalidAutomationZipArgsForCall = append(fake.invalidAutomationZipArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.InvalidAutomationZipStub
+	fake.recordInvocation("InvalidAutomationZip", []interface{}{arg1})
+	fake.invalidAutomationZipMutex.Unlock()
+	if stub != nil {
+		fake.InvalidAutomationZipStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) InvalidAutomationZipCallCount() int {
+	fake.invalidAutomationZipMutex.RLock()
+	defer fake.invalidAutomationZipMutex.RUnlock()
+	return len(fake.invalidAutomationZipArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) InvalidAutomationZipCalls(stub func(error)) {
+	fake.invalidAutomationZipMutex.Lock()
+	defer fake.invalidAutomationZipMutex.Unlock()
+	fake.InvalidAutomationZipStub = stub
+}
+
+func (fake *FakeConstructMessenger) InvalidAutomationZipArgsForCall(i int) error {
+	fake.invalidAutomationZipMutex.RLock()
+	defer fake.invalidAutomationZipMutex.RUnlock()
+	argsForCall := fake.invalidAutomationZipArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) InvalidCloneNetwork() {
+	fake.invalidCloneNetworkMutex.Lock()
+	fake.invalidCloneNetworkArgsForCall = append(fake.invalidCloneNetworkArgsForCall, struct {
+	}{})
+	stub := fake.InvalidCloneNetworkStub
+	fake.recordInvocation("InvalidCloneNetwork", []interface{}{})
+	fake.invalidCloneNetworkMutex.Unlock()
+	if stub != nil {
+		fake.InvalidCloneNetworkStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) InvalidCloneNetworkCallCount() int {
+	fake.invalidCloneNetworkMutex.RLock()
+	defer fake.invalidCloneNetworkMutex.RUnlock()
+	return len(fake.invalidCloneNetworkArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) InvalidCloneNetworkCalls(stub func()) {
+	fake.invalidCloneNetworkMutex.Lock()
+	defer fake.invalidCloneNetworkMutex.Unlock()
+	fake.InvalidCloneNetworkStub = stub
+}
+
+func (fake *FakeConstructMessenger) InvalidConfig(arg1 error) {
+	fake.invalidConfigMutex.Lock()
+	fake.invalidConfigArgsForCall = append(fake.invalidConfigArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.InvalidConfigStub
+	fake.recordInvocation("InvalidConfig", []interface{}{arg1})
+	fake.invalidConfigMutex.Unlock()
+	if stub != nil {
+		fake.InvalidConfigStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) InvalidConfigCallCount() int {
+	fake.invalidConfigMutex.RLock()
+	defer fake.invalidConfigMutex.RUnlock()
+	return len(fake.invalidConfigArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) InvalidConfigCalls(stub func(error)) {
+	fake.invalidConfigMutex.Lock()
+	defer fake.invalidConfigMutex.Unlock()
+	fake.InvalidConfigStub = stub
+}
+
+func (fake *FakeConstructMessenger) InvalidConfigArgsForCall(i int) error {
+	fake.invalidConfigMutex.RLock()
+	defer fake.invalidConfigMutex.RUnlock()
+	argsForCall := fake.invalidConfigArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) InvalidLGPO(arg1 error) {
+	fake.invalidLGPOMutex.Lock()
+	fake.invalidLGPOArgsForCall = append(fake.invalidLGPOArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.InvalidLGPOStub
+	fake.recordInvocation("InvalidLGPO", []interface{}{arg1})
+	fake.invalidLGPOMutex.Unlock()
+	if stub != nil {
+		fake.InvalidLGPOStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) InvalidLGPOCallCount() int {
+	fake.invalidLGPOMutex.RLock()
+	defer fake.invalidLGPOMutex.RUnlock()
+	return len(fake.invalidLGPOArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) InvalidLGPOCalls(stub func(error)) {
+	fake.invalidLGPOMutex.Lock()
+	defer fake.invalidLGPOMutex.Unlock()
+	fake.InvalidLGPOStub = stub
+}
+
+func (fake *FakeConstructMessenger) InvalidLGPOArgsForCall(i int) error {
+	fake.invalidLGPOMutex.RLock()
+	defer fake.invalidLGPOMutex.RUnlock()
+	argsForCall := fake.invalidLGPOArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) InvalidPostRebootArg(arg1 error) {
+	fake.invalidPostRebootArgMutex.Lock()
+	fake.invalidPostRebootArgArgsForCall = append(fake.invalidPostRebootArgArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.InvalidPostRebootArgStub
+	fake.recordInvocation("InvalidPostRebootArg", []interface{}{arg1})
+	fake.invalidPostRebootArgMutex.Unlock()
+	if stub != nil {
+		fake.InvalidPostRebootArgStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) InvalidPostRebootArgCallCount() int {
+	fake.invalidPostRebootArgMutex.RLock()
+	defer fake.invalidPostRebootArgMutex.RUnlock()
+	return len(fake.invalidPostRebootArgArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) InvalidPostRebootArgCalls(stub func(error)) {
+	fake.invalidPostRebootArgMutex.Lock()
+	defer fake.invalidPostRebootArgMutex.Unlock()
+	fake.InvalidPostRebootArgStub = stub
+}
+
+func (fake *FakeConstructMessenger) InvalidPostRebootArgArgsForCall(i int) error {
+	fake.invalidPostRebootArgMutex.RLock()
+	defer fake.invalidPostRebootArgMutex.RUnlock()
+	argsForCall := fake.invalidPostRebootArgArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) InvalidWinRMOptions(arg1 error) {
+	fake.invalidWinRMOptionsMutex.Lock()
+	fake.invalidWinRMOptionsArgsForCall = append(fake.invalidWinRMOptionsArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.InvalidWinRMOptionsStub
+	fake.recordInvocation("InvalidWinRMOptions", []interface{}{arg1})
+	fake.invalidWinRMOptionsMutex.Unlock()
+	if stub != nil {
+		fake.InvalidWinRMOptionsStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) InvalidWinRMOptionsCallCount() int {
+	fake.invalidWinRMOptionsMutex.RLock()
+	defer fake.invalidWinRMOptionsMutex.RUnlock()
+	return len(fake.invalidWinRMOptionsArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) InvalidWinRMOptionsCalls(stub func(error)) {
+	fake.invalidWinRMOptionsMutex.Lock()
+	defer fake.invalidWinRMOptionsMutex.Unlock()
+	fake.InvalidWinRMOptionsStub = stub
+}
+
+func (fake *FakeConstructMessenger) InvalidWinRMOptionsArgsForCall(i int) error {
+	fake.invalidWinRMOptionsMutex.RLock()
+	defer fake.invalidWinRMOptionsMutex.RUnlock()
+	argsForCall := fake.invalidWinRMOptionsArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) LGPONotFound() {
 	fake.lGPONotFoundMutex.Lock()
 	fake.lGPONotFoundArgsForCall = append(fake.lGPONotFoundArgsForCall, struct {
@@ -151,6 +401,20 @@ func (fake *FakeConstructMessenger) Invocations() map[string][][]interface{} {
 	defer fake.cannotConnectToVMMutex.RUnlock()
 	fake.cannotPrepareVMMutex.RLock()
 	defer fake.cannotPrepareVMMutex.RUnlock()
+	fake.cannotReadPasswordMutex.RLock()
+	defer fake.cannotReadPasswordMutex.RUnlock()
+	fake.invalidAutomationZipMutex.RLock()
+	defer fake.invalidAutomationZipMutex.RUnlock()
+	fake.invalidCloneNetworkMutex.RLock()
+	defer fake.invalidCloneNetworkMutex.RUnlock()
+	fake.invalidConfigMutex.RLock()
+	defer fake.invalidConfigMutex.RUnlock()
+	fake.invalidLGPOMutex.RLock()
+	defer fake.invalidLGPOMutex.RUnlock()
+	fake.invalidPostRebootArgMutex.RLock()
+	defer fake.invalidPostRebootArgMutex.RUnlock()
+	fake.invalidWinRMOptionsMutex.RLock()
+	defer fake.invalidWinRMOptionsMutex.RUnlock()
 	fake.lGPONotFoundMutex.RLock()
 	defer fake.lGPONotFoundMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
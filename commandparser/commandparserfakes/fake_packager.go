@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry/stembuild/commandparser"
 	"github.com/cloudfoundry/stembuild/filesystem"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 type FakePackager struct {
@@ -19,6 +20,16 @@ type FakePackager struct {
 	packageReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StepTimingsStub        func() []report.Step
+	stepTimingsMutex       sync.RWMutex
+	stepTimingsArgsForCall []struct {
+	}
+	stepTimingsReturns struct {
+		result1 []report.Step
+	}
+	stepTimingsReturnsOnCall map[int]struct {
+		result1 []report.Step
+	}
 	ValidateFreeSpaceForPackageStub        func(filesystem.FileSystem) error
 	validateFreeSpaceForPackageMutex       sync.RWMutex
 	validateFreeSpaceForPackageArgsForCall []struct {
@@ -97,6 +108,59 @@ func (fake *FakePackager) PackageReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakePackager) StepTimings() []report.Step {
+	fake.stepTimingsMutex.Lock()
+	ret, specificReturn := fake.stepTimingsReturnsOnCall[len(fake.stepTimingsArgsForCall)]
+	fake.stepTimingsArgsForCall = append(fake.stepTimingsArgsForCall, struct {
+	}{})
+	stub := fake.StepTimingsStub
+	fakeReturns := fake.stepTimingsReturns
+	fake.recordInvocation("StepTimings", []interface{}{})
+	fake.stepTimingsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakePackager) StepTimingsCallCount() int {
+	fake.stepTimingsMutex.RLock()
+	defer fake.stepTimingsMutex.RUnlock()
+	return len(fake.stepTimingsArgsForCall)
+}
+
+func (fake *FakePackager) StepTimingsCalls(stub func() []report.Step) {
+	fake.stepTimingsMutex.Lock()
+	defer fake.stepTimingsMutex.Unlock()
+	fake.StepTimingsStub = stub
+}
+
+func (fake *FakePackager) StepTimingsReturns(result1 []report.Step) {
+	fake.stepTimingsMutex.Lock()
+	defer fake.stepTimingsMutex.Unlock()
+	fake.StepTimingsStub = nil
+	fake.stepTimingsReturns = struct {
+		result1 []report.Step
+	}{result1}
+}
+
+func (fake *FakePackager) StepTimingsReturnsOnCall(i int, result1 []report.Step) {
+	fake.stepTimingsMutex.Lock()
+	defer fake.stepTimingsMutex.Unlock()
+	fake.StepTimingsStub = nil
+	if fake.stepTimingsReturnsOnCall == nil {
+		fake.stepTimingsReturnsOnCall = make(map[int]struct {
+			result1 []report.Step
+		})
+	}
+	fake.stepTimingsReturnsOnCall[i] = struct {
+		result1 []report.Step
+	}{result1}
+}
+
 func (fake *FakePackager) ValidateFreeSpaceForPackage(arg1 filesystem.FileSystem) error {
 	fake.validateFreeSpaceForPackageMutex.Lock()
 	ret, specificReturn := fake.validateFreeSpaceForPackageReturnsOnCall[len(fake.validateFreeSpaceForPackageArgsForCall)]
@@ -216,6 +280,8 @@ func (fake *FakePackager) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.packageMutex.RLock()
 	defer fake.packageMutex.RUnlock()
+	fake.stepTimingsMutex.RLock()
+	defer fake.stepTimingsMutex.RUnlock()
 	fake.validateFreeSpaceForPackageMutex.RLock()
 	defer fake.validateFreeSpaceForPackageMutex.RUnlock()
 	fake.validateSourceParametersMutex.RLock()
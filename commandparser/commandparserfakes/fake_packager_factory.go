@@ -2,6 +2,7 @@
 package commandparserfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cloudfoundry/stembuild/colorlogger"
@@ -10,12 +11,13 @@ import (
 )
 
 type FakePackagerFactory struct {
-	PackagerStub        func(config.SourceConfig, config.OutputConfig, colorlogger.Logger) (commandparser.Packager, error)
+	PackagerStub        func(context.Context, config.SourceConfig, config.OutputConfig, colorlogger.Logger) (commandparser.Packager, error)
 	packagerMutex       sync.RWMutex
 	packagerArgsForCall []struct {
-		arg1 config.SourceConfig
-		arg2 config.OutputConfig
-		arg3 colorlogger.Logger
+		arg1 context.Context
+		arg2 config.SourceConfig
+		arg3 config.OutputConfig
+		arg4 colorlogger.Logger
 	}
 	packagerReturns struct {
 		result1 commandparser.Packager
@@ -29,20 +31,21 @@ type FakePackagerFactory struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakePackagerFactory) Packager(arg1 config.SourceConfig, arg2 config.OutputConfig, arg3 colorlogger.Logger) (commandparser.Packager, error) {
+func (fake *FakePackagerFactory) Packager(arg1 context.Context, arg2 config.SourceConfig, arg3 config.OutputConfig, arg4 colorlogger.Logger) (commandparser.Packager, error) {
 	fake.packagerMutex.Lock()
 	ret, specificReturn := fake.packagerReturnsOnCall[len(fake.packagerArgsForCall)]
 	fake.packagerArgsForCall = append(fake.packagerArgsForCall, struct {
-		arg1 config.SourceConfig
-		arg2 config.OutputConfig
-		arg3 colorlogger.Logger
-	}{arg1, arg2, arg3})
+		arg1 context.Context
+		arg2 config.SourceConfig
+		arg3 config.OutputConfig
+		arg4 colorlogger.Logger
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.PackagerStub
 	fakeReturns := fake.packagerReturns
-	fake.recordInvocation("Packager", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("Packager", []interface{}{arg1, arg2, arg3, arg4})
 	fake.packagerMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -56,17 +59,17 @@ func (fake *FakePackagerFactory) PackagerCallCount() int {
 	return len(fake.packagerArgsForCall)
 }
 
-func (fake *FakePackagerFactory) PackagerCalls(stub func(config.SourceConfig, config.OutputConfig, colorlogger.Logger) (commandparser.Packager, error)) {
+func (fake *FakePackagerFactory) PackagerCalls(stub func(context.Context, config.SourceConfig, config.OutputConfig, colorlogger.Logger) (commandparser.Packager, error)) {
 	fake.packagerMutex.Lock()
 	defer fake.packagerMutex.Unlock()
 	fake.PackagerStub = stub
 }
 
-func (fake *FakePackagerFactory) PackagerArgsForCall(i int) (config.SourceConfig, config.OutputConfig, colorlogger.Logger) {
+func (fake *FakePackagerFactory) PackagerArgsForCall(i int) (context.Context, config.SourceConfig, config.OutputConfig, colorlogger.Logger) {
 	fake.packagerMutex.RLock()
 	defer fake.packagerMutex.RUnlock()
 	argsForCall := fake.packagerArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakePackagerFactory) PackagerReturns(result1 commandparser.Packager, result2 error) {
@@ -5,19 +5,41 @@ import (
 	"sync"
 
 	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 type FakePackagerMessenger struct {
+	BuildReportNotWrittenStub        func(error)
+	buildReportNotWrittenMutex       sync.RWMutex
+	buildReportNotWrittenArgsForCall []struct {
+		arg1 error
+	}
+	BuildReportWrittenStub        func(string, report.Report)
+	buildReportWrittenMutex       sync.RWMutex
+	buildReportWrittenArgsForCall []struct {
+		arg1 string
+		arg2 report.Report
+	}
 	CannotCreatePackagerStub        func(error)
 	cannotCreatePackagerMutex       sync.RWMutex
 	cannotCreatePackagerArgsForCall []struct {
 		arg1 error
 	}
+	CannotReadPasswordStub        func(error)
+	cannotReadPasswordMutex       sync.RWMutex
+	cannotReadPasswordArgsForCall []struct {
+		arg1 error
+	}
 	DoesNotHaveEnoughSpaceStub        func(error)
 	doesNotHaveEnoughSpaceMutex       sync.RWMutex
 	doesNotHaveEnoughSpaceArgsForCall []struct {
 		arg1 error
 	}
+	InvalidConfigStub        func(error)
+	invalidConfigMutex       sync.RWMutex
+	invalidConfigArgsForCall []struct {
+		arg1 error
+	}
 	InvalidOutputConfigStub        func(error)
 	invalidOutputConfigMutex       sync.RWMutex
 	invalidOutputConfigArgsForCall []struct {
@@ -37,6 +59,71 @@ type FakePackagerMessenger struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakePackagerMessenger) BuildReportNotWritten(arg1 error) {
+	fake.buildReportNotWrittenMutex.Lock()
+	fake.buildReportNotWrittenArgsForCall = append(fake.buildReportNotWrittenArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.BuildReportNotWrittenStub
+	fake.recordInvocation("BuildReportNotWritten", []interface{}{arg1})
+	fake.buildReportNotWrittenMutex.Unlock()
+	if stub != nil {
+		fake.BuildReportNotWrittenStub(arg1)
+	}
+}
+
+func (fake *FakePackagerMessenger) BuildReportNotWrittenCallCount() int {
+	fake.buildReportNotWrittenMutex.RLock()
+	defer fake.buildReportNotWrittenMutex.RUnlock()
+	return len(fake.buildReportNotWrittenArgsForCall)
+}
+
+func (fake *FakePackagerMessenger) BuildReportNotWrittenCalls(stub func(error)) {
+	fake.buildReportNotWrittenMutex.Lock()
+	defer fake.buildReportNotWrittenMutex.Unlock()
+	fake.BuildReportNotWrittenStub = stub
+}
+
+func (fake *FakePackagerMessenger) BuildReportNotWrittenArgsForCall(i int) error {
+	fake.buildReportNotWrittenMutex.RLock()
+	defer fake.buildReportNotWrittenMutex.RUnlock()
+	argsForCall := fake.buildReportNotWrittenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakePackagerMessenger) BuildReportWritten(arg1 string, arg2 report.Report) {
+	fake.buildReportWrittenMutex.Lock()
+	fake.buildReportWrittenArgsForCall = append(fake.buildReportWrittenArgsForCall, struct {
+		arg1 string
+		arg2 report.Report
+	}{arg1, arg2})
+	stub := fake.BuildReportWrittenStub
+	fake.recordInvocation("BuildReportWritten", []interface{}{arg1, arg2})
+	fake.buildReportWrittenMutex.Unlock()
+	if stub != nil {
+		fake.BuildReportWrittenStub(arg1, arg2)
+	}
+}
+
+func (fake *FakePackagerMessenger) BuildReportWrittenCallCount() int {
+	fake.buildReportWrittenMutex.RLock()
+	defer fake.buildReportWrittenMutex.RUnlock()
+	return len(fake.buildReportWrittenArgsForCall)
+}
+
+func (fake *FakePackagerMessenger) BuildReportWrittenCalls(stub func(string, report.Report)) {
+	fake.buildReportWrittenMutex.Lock()
+	defer fake.buildReportWrittenMutex.Unlock()
+	fake.BuildReportWrittenStub = stub
+}
+
+func (fake *FakePackagerMessenger) BuildReportWrittenArgsForCall(i int) (string, report.Report) {
+	fake.buildReportWrittenMutex.RLock()
+	defer fake.buildReportWrittenMutex.RUnlock()
+	argsForCall := fake.buildReportWrittenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakePackagerMessenger) CannotCreatePackager(arg1 error) {
 	fake.cannotCreatePackagerMutex.Lock()
 	fake.cannotCreatePackagerArgsForCall = append(fake.cannotCreatePackagerArgsForCall, struct {
@@ -69,6 +156,38 @@ func (fake *FakePackagerMessenger) CannotCreatePackagerArgsForCall(i int) error
 	return argsForCall.arg1
 }
 
+func (fake *FakePackagerMessenger) CannotReadPassword(arg1 error) {
+	fake.cannotReadPasswordMutex.Lock()
+	fake.cannotReadPasswordArgsForCall = append(fake.cannotReadPasswordArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.CannotReadPasswordStub
+	fake.recordInvocation("CannotReadPassword", []interface{}{arg1})
+	fake.cannotReadPasswordMutex.Unlock()
+	if stub != nil {
+		fake.CannotReadPasswordStub(arg1)
+	}
+}
+
+func (fake *FakePackagerMessenger) CannotReadPasswordCallCount() int {
+	fake.cannotReadPasswordMutex.RLock()
+	defer fake.cannotReadPasswordMutex.RUnlock()
+	return len(fake.cannotReadPasswordArgsForCall)
+}
+
+func (fake *FakePackagerMessenger) CannotReadPasswordCalls(stub func(error)) {
+	fake.cannotReadPasswordMutex.Lock()
+	defer fake.cannotReadPasswordMutex.Unlock()
+	fake.CannotReadPasswordStub = stub
+}
+
+func (fake *FakePackagerMessenger) CannotReadPasswordArgsForCall(i int) error {
+	fake.cannotReadPasswordMutex.RLock()
+	defer fake.cannotReadPasswordMutex.RUnlock()
+	argsForCall := fake.cannotReadPasswordArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakePackagerMessenger) DoesNotHaveEnoughSpace(arg1 error) {
 	fake.doesNotHaveEnoughSpaceMutex.Lock()
 	fake.doesNotHaveEnoughSpaceArgsForCall = append(fake.doesNotHaveEnoughSpaceArgsForCall, struct {
@@ -101,6 +220,38 @@ func (fake *FakePackagerMessenger) DoesNotHaveEnoughSpaceArgsForCall(i int) erro
 	return argsForCall.arg1
 }
 
+func (fake *FakePackagerMessenger) InvalidConfig(arg1 error) {
+	fake.invalidConfigMutex.Lock()
+	fake.invalidConfigArgsForCall = append(fake.invalidConfigArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.InvalidConfigStub
+	fake.recordInvocation("InvalidConfig", []interface{}{arg1})
+	fake.invalidConfigMutex.Unlock()
+	if stub != nil {
+		fake.InvalidConfigStub(arg1)
+	}
+}
+
+func (fake *FakePackagerMessenger) InvalidConfigCallCount() int {
+	fake.invalidConfigMutex.RLock()
+	defer fake.invalidConfigMutex.RUnlock()
+	return len(fake.invalidConfigArgsForCall)
+}
+
+func (fake *FakePackagerMessenger) InvalidConfigCalls(stub func(error)) {
+	fake.invalidConfigMutex.Lock()
+	defer fake.invalidConfigMutex.Unlock()
+	fake.InvalidConfigStub = stub
+}
+
+func (fake *FakePackagerMessenger) InvalidConfigArgsForCall(i int) error {
+	fake.invalidConfigMutex.RLock()
+	defer fake.invalidConfigMutex.RUnlock()
+	argsForCall := fake.invalidConfigArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakePackagerMessenger) InvalidOutputConfig(arg1 error) {
 	fake.invalidOutputConfigMutex.Lock()
 	fake.invalidOutputConfigArgsForCall = append(fake.invalidOutputConfigArgsForCall, struct {
@@ -200,10 +351,18 @@ func (fake *FakePackagerMessenger) SourceParametersAreInvalidArgsForCall(i int)
 func (fake *FakePackagerMessenger) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.buildReportNotWrittenMutex.RLock()
+	defer fake.buildReportNotWrittenMutex.RUnlock()
+	fake.buildReportWrittenMutex.RLock()
+	defer fake.buildReportWrittenMutex.RUnlock()
 	fake.cannotCreatePackagerMutex.RLock()
 	defer fake.cannotCreatePackagerMutex.RUnlock()
+	fake.cannotReadPasswordMutex.RLock()
+	defer fake.cannotReadPasswordMutex.RUnlock()
 	fake.doesNotHaveEnoughSpaceMutex.RLock()
 	defer fake.doesNotHaveEnoughSpaceMutex.RUnlock()
+	fake.invalidConfigMutex.RLock()
+	defer fake.invalidConfigMutex.RUnlock()
 	fake.invalidOutputConfigMutex.RLock()
 	defer fake.invalidOutputConfigMutex.RUnlock()
 	fake.packageFailedMutex.RLock()
@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package commandparserfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/preflight"
+)
+
+type FakePreflightCheckFactory struct {
+	ChecksStub        func(context.Context, preflight.Config) []preflight.Check
+	checksMutex       sync.RWMutex
+	checksArgsForCall []struct {
+		arg1 context.Context
+		arg2 preflight.Config
+	}
+	checksReturns struct {
+		result1 []preflight.Check
+	}
+	checksReturnsOnCall map[int]struct {
+		result1 []preflight.Check
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePreflightCheckFactory) Checks(arg1 context.Context, arg2 preflight.Config) []preflight.Check {
+	fake.checksMutex.Lock()
+	ret, specificReturn := fake.checksReturnsOnCall[len(fake.checksArgsForCall)]
+	fake.checksArgsForCall = append(fake.checksArgsForCall, struct {
+		arg1 context.Context
+		arg2 preflight.Config
+	}{arg1, arg2})
+	stub := fake.ChecksStub
+	fakeReturns := fake.checksReturns
+	fake.recordInvocation("Checks", []interface{}{arg1, arg2})
+	fake.checksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakePreflightCheckFactory) ChecksCallCount() int {
+	fake.checksMutex.RLock()
+	defer fake.checksMutex.RUnlock()
+	return len(fake.checksArgsForCall)
+}
+
+func (fake *FakePreflightCheckFactory) ChecksCalls(stub func(context.Context, preflight.Config) []preflight.Check) {
+	fake.checksMutex.Lock()
+	defer fake.checksMutex.Unlock()
+	fake.ChecksStub = stub
+}
+
+func (fake *FakePreflightCheckFactory) ChecksArgsForCall(i int) (context.Context, preflight.Config) {
+	fake.checksMutex.RLock()
+	defer fake.checksMutex.RUnlock()
+	argsForCall := fake.checksArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakePreflightCheckFactory) ChecksReturns(result1 []preflight.Check) {
+	fake.checksMutex.Lock()
+	defer fake.checksMutex.Unlock()
+	fake.ChecksStub = nil
+	fake.checksReturns = struct {
+		result1 []preflight.Check
+	}{result1}
+}
+
+func (fake *FakePreflightCheckFactory) ChecksReturnsOnCall(i int, result1 []preflight.Check) {
+	fake.checksMutex.Lock()
+	defer fake.checksMutex.Unlock()
+	fake.ChecksStub = nil
+	if fake.checksReturnsOnCall == nil {
+		fake.checksReturnsOnCall = make(map[int]struct {
+			result1 []preflight.Check
+		})
+	}
+	fake.checksReturnsOnCall[i] = struct {
+		result1 []preflight.Check
+	}{result1}
+}
+
+func (fake *FakePreflightCheckFactory) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checksMutex.RLock()
+	defer fake.checksMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakePreflightCheckFactory) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commandparser.PreflightCheckFactory = new(FakePreflightCheckFactory)
@@ -10,9 +10,52 @@ import (
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/vcenter_manager"
 	"github.com/vmware/govmomi/guest"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type FakeVCenterManager struct {
+	CloneVMWithCustomizationStub        func(context.Context, *object.VirtualMachine, string, types.CustomizationSpec) error
+	cloneVMWithCustomizationMutex       sync.RWMutex
+	cloneVMWithCustomizationArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+		arg4 types.CustomizationSpec
+	}
+	cloneVMWithCustomizationReturns struct {
+		result1 error
+	}
+	cloneVMWithCustomizationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateSnapshotStub        func(context.Context, *object.VirtualMachine, string, string) error
+	createSnapshotMutex       sync.RWMutex
+	createSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+		arg4 string
+	}
+	createSnapshotReturns struct {
+		result1 error
+	}
+	createSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSnapshotStub        func(context.Context, *object.VirtualMachine, string) error
+	deleteSnapshotMutex       sync.RWMutex
+	deleteSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+	}
+	deleteSnapshotReturns struct {
+		result1 error
+	}
+	deleteSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FindVMStub        func(context.Context, string) (*object.VirtualMachine, error)
 	findVMMutex       sync.RWMutex
 	findVMArgsForCall []struct {
@@ -27,6 +70,20 @@ type FakeVCenterManager struct {
 		result1 *object.VirtualMachine
 		result2 error
 	}
+	GuestIPAddressesStub        func(context.Context, *object.VirtualMachine) ([]string, error)
+	guestIPAddressesMutex       sync.RWMutex
+	guestIPAddressesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}
+	guestIPAddressesReturns struct {
+		result1 []string
+		result2 error
+	}
+	guestIPAddressesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	GuestManagerStub        func(context.Context, vcenter_manager.OpsManager, string, string) (*guest_manager.GuestManager, error)
 	guestManagerMutex       sync.RWMutex
 	guestManagerArgsForCall []struct {
@@ -43,6 +100,21 @@ type FakeVCenterManager struct {
 		result1 *guest_manager.GuestManager
 		result2 error
 	}
+	HasSnapshotStub        func(context.Context, *object.VirtualMachine, string) (bool, error)
+	hasSnapshotMutex       sync.RWMutex
+	hasSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+	}
+	hasSnapshotReturns struct {
+		result1 bool
+		result2 error
+	}
+	hasSnapshotReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	LoginStub        func(context.Context) error
 	loginMutex       sync.RWMutex
 	loginArgsForCall []struct {
@@ -66,10 +138,266 @@ type FakeVCenterManager struct {
 	operationsManagerReturnsOnCall map[int]struct {
 		result1 *guest.OperationsManager
 	}
+	PowerOnVMStub        func(context.Context, *object.VirtualMachine) error
+	powerOnVMMutex       sync.RWMutex
+	powerOnVMArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}
+	powerOnVMReturns struct {
+		result1 error
+	}
+	powerOnVMReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RebootStatusStub        func(context.Context, *object.VirtualMachine) (mo.VirtualMachine, error)
+	rebootStatusMutex       sync.RWMutex
+	rebootStatusArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}
+	rebootStatusReturns struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}
+	rebootStatusReturnsOnCall map[int]struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}
+	RevertToSnapshotStub        func(context.Context, *object.VirtualMachine, string) error
+	revertToSnapshotMutex       sync.RWMutex
+	revertToSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+	}
+	revertToSnapshotReturns struct {
+		result1 error
+	}
+	revertToSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForCustomizationStub        func(context.Context, *object.VirtualMachine) error
+	waitForCustomizationMutex       sync.RWMutex
+	waitForCustomizationArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}
+	waitForCustomizationReturns struct {
+		result1 error
+	}
+	waitForCustomizationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForIPStub        func(context.Context, *object.VirtualMachine) (string, error)
+	waitForIPMutex       sync.RWMutex
+	waitForIPArgsForCall []struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}
+	waitForIPReturns struct {
+		result1 string
+		result2 error
+	}
+	waitForIPReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeVCenterManager) CloneVMWithCustomization(arg1 context.Context, arg2 *object.VirtualMachine, arg3 string, arg4 types.CustomizationSpec) error {
+	fake.cloneVMWithCustomizationMutex.Lock()
+	ret, specificReturn := fake.cloneVMWithCustomizationReturnsOnCall[len(fake.cloneVMWithCustomizationArgsForCall)]
+	fake.cloneVMWithCustomizationArgsForCall = append(fake.cloneVMWithCustomizationArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+		arg4 types.CustomizationSpec
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.CloneVMWithCustomizationStub
+	fakeReturns := fake.cloneVMWithCustomizationReturns
+	fake.recordInvocation("CloneVMWithCustomization", []interface{}{arg1, arg2, arg3, arg4})
+	fake.cloneVMWithCustomizationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVCenterManager) CloneVMWithCustomizationCallCount() int {
+	fake.cloneVMWithCustomizationMutex.RLock()
+	defer fake.cloneVMWithCustomizationMutex.RUnlock()
+	return len(fake.cloneVMWithCustomizationArgsForCall)
+}
+
+func (fake *FakeVCenterManager) CloneVMWithCustomizationCalls(stub func(context.Context, *object.VirtualMachine, string, types.CustomizationSpec) error) {
+	fake.cloneVMWithCustomizationMutex.Lock()
+	defer fake.cloneVMWithCustomizationMutex.Unlock()
+	fake.CloneVMWithCustomizationStub = stub
+}
+
+func (fake *FakeVCenterManager) CloneVMWithCustomizationArgsForCall(i int) (context.Context, *object.VirtualMachine, string, types.CustomizationSpec) {
+	fake.cloneVMWithCustomizationMutex.RLock()
+	defer fake.cloneVMWithCustomizationMutex.RUnlock()
+	argsForCall := fake.cloneVMWithCustomizationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeVCenterManager) CloneVMWithCustomizationReturns(result1 error) {
+	fake.cloneVMWithCustomizationMutex.Lock()
+	defer fake.cloneVMWithCustomizationMutex.Unlock()
+	fake.CloneVMWithCustomizationStub = nil
+	fake.cloneVMWithCustomizationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) CloneVMWithCustomizationReturnsOnCall(i int, result1 error) {
+	fake.cloneVMWithCustomizationMutex.Lock()
+	defer fake.cloneVMWithCustomizationMutex.Unlock()
+	fake.CloneVMWithCustomizationStub = nil
+	if fake.cloneVMWithCustomizationReturnsOnCall == nil {
+		fake.cloneVMWithCustomizationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cloneVMWithCustomizationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) CreateSnapshot(arg1 context.Context, arg2 *object.VirtualMachine, arg3 string, arg4 string) error {
+	fake.createSnapshotMutex.Lock()
+	ret, specificReturn := fake.createSnapshotReturnsOnCall[len(fake.createSnapshotArgsForCall)]
+	fake.createSnapshotArgsForCall = append(fake.createSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.CreateSnapshotStub
+	fakeReturns := fake.createSnapshotReturns
+	fake.recordInvocation("CreateSnapshot", []interface{}{arg1, arg2, arg3, arg4})
+	fake.createSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVCenterManager) CreateSnapshotCallCount() int {
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	return len(fake.createSnapshotArgsForCall)
+}
+
+func (fake *FakeVCenterManager) CreateSnapshotCalls(stub func(context.Context, *object.VirtualMachine, string, string) error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = stub
+}
+
+func (fake *FakeVCenterManager) CreateSnapshotArgsForCall(i int) (context.Context, *object.VirtualMachine, string, string) {
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	argsForCall := fake.createSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeVCenterManager) CreateSnapshotReturns(result1 error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = nil
+	fake.createSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) CreateSnapshotReturnsOnCall(i int, result1 error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = nil
+	if fake.createSnapshotReturnsOnCall == nil {
+		fake.createSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) DeleteSnapshot(arg1 context.Context, arg2 *object.VirtualMachine, arg3 string) error {
+	fake.deleteSnapshotMutex.Lock()
+	ret, specificReturn := fake.deleteSnapshotReturnsOnCall[len(fake.deleteSnapshotArgsForCall)]
+	fake.deleteSnapshotArgsForCall = append(fake.deleteSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteSnapshotStub
+	fakeReturns := fake.deleteSnapshotReturns
+	fake.recordInvocation("DeleteSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.deleteSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVCenterManager) DeleteSnapshotCallCount() int {
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	return len(fake.deleteSnapshotArgsForCall)
+}
+
+func (fake *FakeVCenterManager) DeleteSnapshotCalls(stub func(context.Context, *object.VirtualMachine, string) error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = stub
+}
+
+func (fake *FakeVCenterManager) DeleteSnapshotArgsForCall(i int) (context.Context, *object.VirtualMachine, string) {
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	argsForCall := fake.deleteSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeVCenterManager) DeleteSnapshotReturns(result1 error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = nil
+	fake.deleteSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) DeleteSnapshotReturnsOnCall(i int, result1 error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = nil
+	if fake.deleteSnapshotReturnsOnCall == nil {
+		fake.deleteSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeVCenterManager) FindVM(arg1 context.Context, arg2 string) (*object.VirtualMachine, error) {
 	fake.findVMMutex.Lock()
 	ret, specificReturn := fake.findVMReturnsOnCall[len(fake.findVMArgsForCall)]
@@ -135,6 +463,71 @@ func (fake *FakeVCenterManager) FindVMReturnsOnCall(i int, result1 *object.Virtu
 	}{result1, result2}
 }
 
+func (fake *FakeVCenterManager) GuestIPAddresses(arg1 context.Context, arg2 *object.VirtualMachine) ([]string, error) {
+	fake.guestIPAddressesMutex.Lock()
+	ret, specificReturn := fake.guestIPAddressesReturnsOnCall[len(fake.guestIPAddressesArgsForCall)]
+	fake.guestIPAddressesArgsForCall = append(fake.guestIPAddressesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}{arg1, arg2})
+	stub := fake.GuestIPAddressesStub
+	fakeReturns := fake.guestIPAddressesReturns
+	fake.recordInvocation("GuestIPAddresses", []interface{}{arg1, arg2})
+	fake.guestIPAddressesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVCenterManager) GuestIPAddressesCallCount() int {
+	fake.guestIPAddressesMutex.RLock()
+	defer fake.guestIPAddressesMutex.RUnlock()
+	return len(fake.guestIPAddressesArgsForCall)
+}
+
+func (fake *FakeVCenterManager) GuestIPAddressesCalls(stub func(context.Context, *object.VirtualMachine) ([]string, error)) {
+	fake.guestIPAddressesMutex.Lock()
+	defer fake.guestIPAddressesMutex.Unlock()
+	fake.GuestIPAddressesStub = stub
+}
+
+func (fake *FakeVCenterManager) GuestIPAddressesArgsForCall(i int) (context.Context, *object.VirtualMachine) {
+	fake.guestIPAddressesMutex.RLock()
+	defer fake.guestIPAddressesMutex.RUnlock()
+	argsForCall := fake.guestIPAddressesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVCenterManager) GuestIPAddressesReturns(result1 []string, result2 error) {
+	fake.guestIPAddressesMutex.Lock()
+	defer fake.guestIPAddressesMutex.Unlock()
+	fake.GuestIPAddressesStub = nil
+	fake.guestIPAddressesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVCenterManager) GuestIPAddressesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.guestIPAddressesMutex.Lock()
+	defer fake.guestIPAddressesMutex.Unlock()
+	fake.GuestIPAddressesStub = nil
+	if fake.guestIPAddressesReturnsOnCall == nil {
+		fake.guestIPAddressesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.guestIPAddressesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeVCenterManager) GuestManager(arg1 context.Context, arg2 vcenter_manager.OpsManager, arg3 string, arg4 string) (*guest_manager.GuestManager, error) {
 	fake.guestManagerMutex.Lock()
 	ret, specificReturn := fake.guestManagerReturnsOnCall[len(fake.guestManagerArgsForCall)]
@@ -202,6 +595,72 @@ func (fake *FakeVCenterManager) GuestManagerReturnsOnCall(i int, result1 *guest_
 	}{result1, result2}
 }
 
+func (fake *FakeVCenterManager) HasSnapshot(arg1 context.Context, arg2 *object.VirtualMachine, arg3 string) (bool, error) {
+	fake.hasSnapshotMutex.Lock()
+	ret, specificReturn := fake.hasSnapshotReturnsOnCall[len(fake.hasSnapshotArgsForCall)]
+	fake.hasSnapshotArgsForCall = append(fake.hasSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.HasSnapshotStub
+	fakeReturns := fake.hasSnapshotReturns
+	fake.recordInvocation("HasSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.hasSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVCenterManager) HasSnapshotCallCount() int {
+	fake.hasSnapshotMutex.RLock()
+	defer fake.hasSnapshotMutex.RUnlock()
+	return len(fake.hasSnapshotArgsForCall)
+}
+
+func (fake *FakeVCenterManager) HasSnapshotCalls(stub func(context.Context, *object.VirtualMachine, string) (bool, error)) {
+	fake.hasSnapshotMutex.Lock()
+	defer fake.hasSnapshotMutex.Unlock()
+	fake.HasSnapshotStub = stub
+}
+
+func (fake *FakeVCenterManager) HasSnapshotArgsForCall(i int) (context.Context, *object.VirtualMachine, string) {
+	fake.hasSnapshotMutex.RLock()
+	defer fake.hasSnapshotMutex.RUnlock()
+	argsForCall := fake.hasSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeVCenterManager) HasSnapshotReturns(result1 bool, result2 error) {
+	fake.hasSnapshotMutex.Lock()
+	defer fake.hasSnapshotMutex.Unlock()
+	fake.HasSnapshotStub = nil
+	fake.hasSnapshotReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVCenterManager) HasSnapshotReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.hasSnapshotMutex.Lock()
+	defer fake.hasSnapshotMutex.Unlock()
+	fake.HasSnapshotStub = nil
+	if fake.hasSnapshotReturnsOnCall == nil {
+		fake.hasSnapshotReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.hasSnapshotReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeVCenterManager) Login(arg1 context.Context) error {
 	fake.loginMutex.Lock()
 	ret, specificReturn := fake.loginReturnsOnCall[len(fake.loginArgsForCall)]
@@ -325,17 +784,354 @@ func (fake *FakeVCenterManager) OperationsManagerReturnsOnCall(i int, result1 *g
 	}{result1}
 }
 
+func (fake *FakeVCenterManager) PowerOnVM(arg1 context.Context, arg2 *object.VirtualMachine) error {
+	fake.powerOnVMMutex.Lock()
+	ret, specificReturn := fake.powerOnVMReturnsOnCall[len(fake.powerOnVMArgsForCall)]
+	fake.powerOnVMArgsForCall = append(fake.powerOnVMArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}{arg1, arg2})
+	stub := fake.PowerOnVMStub
+	fakeReturns := fake.powerOnVMReturns
+	fake.recordInvocation("PowerOnVM", []interface{}{arg1, arg2})
+	fake.powerOnVMMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVCenterManager) PowerOnVMCallCount() int {
+	fake.powerOnVMMutex.RLock()
+	defer fake.powerOnVMMutex.RUnlock()
+	return len(fake.powerOnVMArgsForCall)
+}
+
+func (fake *FakeVCenterManager) PowerOnVMCalls(stub func(context.Context, *object.VirtualMachine) error) {
+	fake.powerOnVMMutex.Lock()
+	defer fake.powerOnVMMutex.Unlock()
+	fake.PowerOnVMStub = stub
+}
+
+func (fake *FakeVCenterManager) PowerOnVMArgsForCall(i int) (context.Context, *object.VirtualMachine) {
+	fake.powerOnVMMutex.RLock()
+	defer fake.powerOnVMMutex.RUnlock()
+	argsForCall := fake.powerOnVMArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVCenterManager) PowerOnVMReturns(result1 error) {
+	fake.powerOnVMMutex.Lock()
+	defer fake.powerOnVMMutex.Unlock()
+	fake.PowerOnVMStub = nil
+	fake.powerOnVMReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) PowerOnVMReturnsOnCall(i int, result1 error) {
+	fake.powerOnVMMutex.Lock()
+	defer fake.powerOnVMMutex.Unlock()
+	fake.PowerOnVMStub = nil
+	if fake.powerOnVMReturnsOnCall == nil {
+		fake.powerOnVMReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.powerOnVMReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) RebootStatus(arg1 context.Context, arg2 *object.VirtualMachine) (mo.VirtualMachine, error) {
+	fake.rebootStatusMutex.Lock()
+	ret, specificReturn := fake.rebootStatusReturnsOnCall[len(fake.rebootStatusArgsForCall)]
+	fake.rebootStatusArgsForCall = append(fake.rebootStatusArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}{arg1, arg2})
+	stub := fake.RebootStatusStub
+	fakeReturns := fake.rebootStatusReturns
+	fake.recordInvocation("RebootStatus", []interface{}{arg1, arg2})
+	fake.rebootStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVCenterManager) RebootStatusCallCount() int {
+	fake.rebootStatusMutex.RLock()
+	defer fake.rebootStatusMutex.RUnlock()
+	return len(fake.rebootStatusArgsForCall)
+}
+
+func (fake *FakeVCenterManager) RebootStatusCalls(stub func(context.Context, *object.VirtualMachine) (mo.VirtualMachine, error)) {
+	fake.rebootStatusMutex.Lock()
+	defer fake.rebootStatusMutex.Unlock()
+	fake.RebootStatusStub = stub
+}
+
+func (fake *FakeVCenterManager) RebootStatusArgsForCall(i int) (context.Context, *object.VirtualMachine) {
+	fake.rebootStatusMutex.RLock()
+	defer fake.rebootStatusMutex.RUnlock()
+	argsForCall := fake.rebootStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVCenterManager) RebootStatusReturns(result1 mo.VirtualMachine, result2 error) {
+	fake.rebootStatusMutex.Lock()
+	defer fake.rebootStatusMutex.Unlock()
+	fake.RebootStatusStub = nil
+	fake.rebootStatusReturns = struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVCenterManager) RebootStatusReturnsOnCall(i int, result1 mo.VirtualMachine, result2 error) {
+	fake.rebootStatusMutex.Lock()
+	defer fake.rebootStatusMutex.Unlock()
+	fake.RebootStatusStub = nil
+	if fake.rebootStatusReturnsOnCall == nil {
+		fake.rebootStatusReturnsOnCall = make(map[int]struct {
+			result1 mo.VirtualMachine
+			result2 error
+		})
+	}
+	fake.rebootStatusReturnsOnCall[i] = struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVCenterManager) RevertToSnapshot(arg1 context.Context, arg2 *object.VirtualMachine, arg3 string) error {
+	fake.revertToSnapshotMutex.Lock()
+	ret, specificReturn := fake.revertToSnapshotReturnsOnCall[len(fake.revertToSnapshotArgsForCall)]
+	fake.revertToSnapshotArgsForCall = append(fake.revertToSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.RevertToSnapshotStub
+	fakeReturns := fake.revertToSnapshotReturns
+	fake.recordInvocation("RevertToSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.revertToSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVCenterManager) RevertToSnapshotCallCount() int {
+	fake.revertToSnapshotMutex.RLock()
+	defer fake.revertToSnapshotMutex.RUnlock()
+	return len(fake.revertToSnapshotArgsForCall)
+}
+
+func (fake *FakeVCenterManager) RevertToSnapshotCalls(stub func(context.Context, *object.VirtualMachine, string) error) {
+	fake.revertToSnapshotMutex.Lock()
+	defer fake.revertToSnapshotMutex.Unlock()
+	fake.RevertToSnapshotStub = stub
+}
+
+func (fake *FakeVCenterManager) RevertToSnapshotArgsForCall(i int) (context.Context, *object.VirtualMachine, string) {
+	fake.revertToSnapshotMutex.RLock()
+	defer fake.revertToSnapshotMutex.RUnlock()
+	argsForCall := fake.revertToSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeVCenterManager) RevertToSnapshotReturns(result1 error) {
+	fake.revertToSnapshotMutex.Lock()
+	defer fake.revertToSnapshotMutex.Unlock()
+	fake.RevertToSnapshotStub = nil
+	fake.revertToSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) RevertToSnapshotReturnsOnCall(i int, result1 error) {
+	fake.revertToSnapshotMutex.Lock()
+	defer fake.revertToSnapshotMutex.Unlock()
+	fake.RevertToSnapshotStub = nil
+	if fake.revertToSnapshotReturnsOnCall == nil {
+		fake.revertToSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.revertToSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) WaitForCustomization(arg1 context.Context, arg2 *object.VirtualMachine) error {
+	fake.waitForCustomizationMutex.Lock()
+	ret, specificReturn := fake.waitForCustomizationReturnsOnCall[len(fake.waitForCustomizationArgsForCall)]
+	fake.waitForCustomizationArgsForCall = append(fake.waitForCustomizationArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}{arg1, arg2})
+	stub := fake.WaitForCustomizationStub
+	fakeReturns := fake.waitForCustomizationReturns
+	fake.recordInvocation("WaitForCustomization", []interface{}{arg1, arg2})
+	fake.waitForCustomizationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVCenterManager) WaitForCustomizationCallCount() int {
+	fake.waitForCustomizationMutex.RLock()
+	defer fake.waitForCustomizationMutex.RUnlock()
+	return len(fake.waitForCustomizationArgsForCall)
+}
+
+func (fake *FakeVCenterManager) WaitForCustomizationCalls(stub func(context.Context, *object.VirtualMachine) error) {
+	fake.waitForCustomizationMutex.Lock()
+	defer fake.waitForCustomizationMutex.Unlock()
+	fake.WaitForCustomizationStub = stub
+}
+
+func (fake *FakeVCenterManager) WaitForCustomizationArgsForCall(i int) (context.Context, *object.VirtualMachine) {
+	fake.waitForCustomizationMutex.RLock()
+	defer fake.waitForCustomizationMutex.RUnlock()
+	argsForCall := fake.waitForCustomizationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVCenterManager) WaitForCustomizationReturns(result1 error) {
+	fake.waitForCustomizationMutex.Lock()
+	defer fake.waitForCustomizationMutex.Unlock()
+	fake.WaitForCustomizationStub = nil
+	fake.waitForCustomizationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) WaitForCustomizationReturnsOnCall(i int, result1 error) {
+	fake.waitForCustomizationMutex.Lock()
+	defer fake.waitForCustomizationMutex.Unlock()
+	fake.WaitForCustomizationStub = nil
+	if fake.waitForCustomizationReturnsOnCall == nil {
+		fake.waitForCustomizationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForCustomizationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVCenterManager) WaitForIP(arg1 context.Context, arg2 *object.VirtualMachine) (string, error) {
+	fake.waitForIPMutex.Lock()
+	ret, specificReturn := fake.waitForIPReturnsOnCall[len(fake.waitForIPArgsForCall)]
+	fake.waitForIPArgsForCall = append(fake.waitForIPArgsForCall, struct {
+		arg1 context.Context
+		arg2 *object.VirtualMachine
+	}{arg1, arg2})
+	stub := fake.WaitForIPStub
+	fakeReturns := fake.waitForIPReturns
+	fake.recordInvocation("WaitForIP", []interface{}{arg1, arg2})
+	fake.waitForIPMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVCenterManager) WaitForIPCallCount() int {
+	fake.waitForIPMutex.RLock()
+	defer fake.waitForIPMutex.RUnlock()
+	return len(fake.waitForIPArgsForCall)
+}
+
+func (fake *FakeVCenterManager) WaitForIPCalls(stub func(context.Context, *object.VirtualMachine) (string, error)) {
+	fake.waitForIPMutex.Lock()
+	defer fake.waitForIPMutex.Unlock()
+	fake.WaitForIPStub = stub
+}
+
+func (fake *FakeVCenterManager) WaitForIPArgsForCall(i int) (context.Context, *object.VirtualMachine) {
+	fake.waitForIPMutex.RLock()
+	defer fake.waitForIPMutex.RUnlock()
+	argsForCall := fake.waitForIPArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVCenterManager) WaitForIPReturns(result1 string, result2 error) {
+	fake.waitForIPMutex.Lock()
+	defer fake.waitForIPMutex.Unlock()
+	fake.WaitForIPStub = nil
+	fake.waitForIPReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVCenterManager) WaitForIPReturnsOnCall(i int, result1 string, result2 error) {
+	fake.waitForIPMutex.Lock()
+	defer fake.waitForIPMutex.Unlock()
+	fake.WaitForIPStub = nil
+	if fake.waitForIPReturnsOnCall == nil {
+		fake.waitForIPReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.waitForIPReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeVCenterManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.cloneVMWithCustomizationMutex.RLock()
+	defer fake.cloneVMWithCustomizationMutex.RUnlock()
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
 	fake.findVMMutex.RLock()
 	defer fake.findVMMutex.RUnlock()
+	fake.guestIPAddressesMutex.RLock()
+	defer fake.guestIPAddressesMutex.RUnlock()
 	fake.guestManagerMutex.RLock()
 	defer fake.guestManagerMutex.RUnlock()
+	fake.hasSnapshotMutex.RLock()
+	defer fake.hasSnapshotMutex.RUnlock()
 	fake.loginMutex.RLock()
 	defer fake.loginMutex.RUnlock()
 	fake.operationsManagerMutex.RLock()
 	defer fake.operationsManagerMutex.RUnlock()
+	fake.powerOnVMMutex.RLock()
+	defer fake.powerOnVMMutex.RUnlock()
+	fake.rebootStatusMutex.RLock()
+	defer fake.rebootStatusMutex.RUnlock()
+	fake.revertToSnapshotMutex.RLock()
+	defer fake.revertToSnapshotMutex.RUnlock()
+	fake.waitForCustomizationMutex.RLock()
+	defer fake.waitForCustomizationMutex.RUnlock()
+	fake.waitForIPMutex.RLock()
+	defer fake.waitForIPMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
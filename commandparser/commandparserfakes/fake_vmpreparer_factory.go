@@ -2,6 +2,7 @@
 package commandparserfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cloudfoundry/stembuild/commandparser"
@@ -9,11 +10,12 @@ import (
 )
 
 type FakeVMPreparerFactory struct {
-	VMPreparerStub        func(config.SourceConfig, commandparser.VCenterManager) (commandparser.VmConstruct, error)
+	VMPreparerStub        func(context.Context, config.SourceConfig, commandparser.VCenterManager) (commandparser.VmConstruct, error)
 	vMPreparerMutex       sync.RWMutex
 	vMPreparerArgsForCall []struct {
-		arg1 config.SourceConfig
-		arg2 commandparser.VCenterManager
+		arg1 context.Context
+		arg2 config.SourceConfig
+		arg3 commandparser.VCenterManager
 	}
 	vMPreparerReturns struct {
 		result1 commandparser.VmConstruct
@@ -27,19 +29,20 @@ type FakeVMPreparerFactory struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeVMPreparerFactory) VMPreparer(arg1 config.SourceConfig, arg2 commandparser.VCenterManager) (commandparser.VmConstruct, error) {
+func (fake *FakeVMPreparerFactory) VMPreparer(arg1 context.Context, arg2 config.SourceConfig, arg3 commandparser.VCenterManager) (commandparser.VmConstruct, error) {
 	fake.vMPreparerMutex.Lock()
 	ret, specificReturn := fake.vMPreparerReturnsOnCall[len(fake.vMPreparerArgsForCall)]
 	fake.vMPreparerArgsForCall = append(fake.vMPreparerArgsForCall, struct {
-		arg1 config.SourceConfig
-		arg2 commandparser.VCenterManager
-	}{arg1, arg2})
+		arg1 context.Context
+		arg2 config.SourceConfig
+		arg3 commandparser.VCenterManager
+	}{arg1, arg2, arg3})
 	stub := fake.VMPreparerStub
 	fakeReturns := fake.vMPreparerReturns
-	fake.recordInvocation("VMPreparer", []interface{}{arg1, arg2})
+	fake.recordInvocation("VMPreparer", []interface{}{arg1, arg2, arg3})
 	fake.vMPreparerMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -53,17 +56,17 @@ func (fake *FakeVMPreparerFactory) VMPreparerCallCount() int {
 	return len(fake.vMPreparerArgsForCall)
 }
 
-func (fake *FakeVMPreparerFactory) VMPreparerCalls(stub func(config.SourceConfig, commandparser.VCenterManager) (commandparser.VmConstruct, error)) {
+func (fake *FakeVMPreparerFactory) VMPreparerCalls(stub func(context.Context, config.SourceConfig, commandparser.VCenterManager) (commandparser.VmConstruct, error)) {
 	fake.vMPreparerMutex.Lock()
 	defer fake.vMPreparerMutex.Unlock()
 	fake.VMPreparerStub = stub
 }
 
-func (fake *FakeVMPreparerFactory) VMPreparerArgsForCall(i int) (config.SourceConfig, commandparser.VCenterManager) {
+func (fake *FakeVMPreparerFactory) VMPreparerArgsForCall(i int) (context.Context, config.SourceConfig, commandparser.VCenterManager) {
 	fake.vMPreparerMutex.RLock()
 	defer fake.vMPreparerMutex.RUnlock()
 	argsForCall := fake.vMPreparerArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeVMPreparerFactory) VMPreparerReturns(result1 commandparser.VmConstruct, result2 error) {
@@ -0,0 +1,131 @@
+package commandparser
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configFlag = "config"
+
+// setConfigFlag registers the flag for a YAML file of flag values, applied by applyConfig
+func setConfigFlag(f *flag.FlagSet, configFile *string) {
+	f.StringVar(configFile, configFlag, "", "filepath of a YAML file of flag values, e.g. 'vcenter-password: secret', default is $STEMBUILD_CONFIG; flags and STEMBUILD_* environment variables take precedence")
+}
+
+// envVar is the environment variable a flag can be set with, e.g. STEMBUILD_VCENTER_PASSWORD for -vcenter-password
+func envVar(flagName string) string {
+	return "STEMBUILD_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyConfig gives every flag not set on the command line the value of its STEMBUILD_* environment variable,
+// or else its value in the config file. Aliases map a shorthand flag to the flag it stands for.
+// A password flag and its -<name>-from flag are one setting: setting either one hides both from the sources below it.
+func applyConfig(f *flag.FlagSet, configFile string, aliases map[string]string) error {
+	set := map[string]bool{}
+	markSet := func(name string) {
+		set[name] = true
+		if base, ok := strings.CutSuffix(name, "-from"); ok && f.Lookup(base) != nil {
+			set[base] = true
+		} else if f.Lookup(name+"-from") != nil {
+			set[name+"-from"] = true
+		}
+	}
+	f.Visit(func(fl *flag.Flag) {
+		name := fl.Name
+		if aliased, ok := aliases[name]; ok {
+			name = aliased
+		}
+		markSet(name)
+	})
+
+	if configFile == "" {
+		configFile = os.Getenv(envVar(configFlag))
+	}
+
+	fileValues := map[string][]string{}
+	if configFile != "" {
+		var err error
+		fileValues, err = loadConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		for name := range fileValues {
+			_, isAlias := aliases[name]
+			if f.Lookup(name) == nil || name == configFlag || isAlias {
+				return fmt.Errorf("config file %s sets unknown flag %s", configFile, name)
+			}
+		}
+	}
+
+	skip := func(fl *flag.Flag) bool {
+		_, isAlias := aliases[fl.Name]
+		return fl.Name == configFlag || isAlias || set[fl.Name]
+	}
+
+	var err error
+	var setByEnv []string
+	f.VisitAll(func(fl *flag.Flag) {
+		if err != nil || skip(fl) {
+			return
+		}
+		if value := os.Getenv(envVar(fl.Name)); value != "" {
+			if setErr := f.Set(fl.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %s", value, envVar(fl.Name), setErr)
+			}
+			setByEnv = append(setByEnv, fl.Name)
+		}
+	})
+	for _, name := range setByEnv {
+		markSet(name)
+	}
+
+	f.VisitAll(func(fl *flag.Flag) {
+		if err != nil || skip(fl) {
+			return
+		}
+		for _, value := range fileValues[fl.Name] {
+			if setErr := f.Set(fl.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s in %s: %s", value, fl.Name, configFile, setErr)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// loadConfigFile reads a YAML mapping of flag names to a value, or to a list of values for flags that can be set multiple times
+func loadConfigFile(configFile string) (map[string][]string, error) {
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %s", err)
+	}
+
+	var nodes map[string]yaml.Node
+	err = yaml.Unmarshal(contents, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s is invalid: %s", configFile, err)
+	}
+
+	values := map[string][]string{}
+	for name, node := range nodes {
+		items := []*yaml.Node{&node}
+		if node.Kind == yaml.SequenceNode {
+			items = node.Content
+		}
+
+		for _, item := range items {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("config file %s is invalid: %s must be a value or a list of values", configFile, name)
+			}
+			if item.Tag == "!!null" {
+				continue
+			}
+			values[name] = append(values[name], item.Value)
+		}
+	}
+	return values, nil
+}
@@ -4,20 +4,31 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/google/subcommands"
 	"github.com/vmware/govmomi/guest"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 
+	"github.com/cloudfoundry/stembuild/construct"
 	"github.com/cloudfoundry/stembuild/construct/config"
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+	"github.com/cloudfoundry/stembuild/events"
 	vcenterclientfactory "github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/factory"
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/guest_manager"
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/vcenter_manager"
+	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/cloudfoundry/stembuild/version"
 )
 
+const defaultMaxUpdateRounds = 5
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 
 //counterfeiter:generate . VmConstruct
@@ -31,11 +42,21 @@ type VCenterManager interface {
 	GuestManager(ctx context.Context, opsManager vcenter_manager.OpsManager, username, password string) (*guest_manager.GuestManager, error)
 	FindVM(ctx context.Context, inventoryPath string) (*object.VirtualMachine, error)
 	Login(ctx context.Context) error
+	CreateSnapshot(ctx context.Context, vm *object.VirtualMachine, name, description string) error
+	RevertToSnapshot(ctx context.Context, vm *object.VirtualMachine, name string) error
+	PowerOnVM(ctx context.Context, vm *object.VirtualMachine) error
+	DeleteSnapshot(ctx context.Context, vm *object.VirtualMachine, name string) error
+	HasSnapshot(ctx context.Context, vm *object.VirtualMachine, name string) (bool, error)
+	RebootStatus(ctx context.Context, vm *object.VirtualMachine) (mo.VirtualMachine, error)
+	GuestIPAddresses(ctx context.Context, vm *object.VirtualMachine) ([]string, error)
+	CloneVMWithCustomization(ctx context.Context, vm *object.VirtualMachine, clonePath string, customization types.CustomizationSpec) error
+	WaitForCustomization(ctx context.Context, vm *object.VirtualMachine) error
+	WaitForIP(ctx context.Context, vm *object.VirtualMachine) (string, error)
 }
 
 //counterfeiter:generate . VMPreparerFactory
 type VMPreparerFactory interface {
-	VMPreparer(config config.SourceConfig, vCenterManager VCenterManager) (VmConstruct, error)
+	VMPreparer(ctx context.Context, config config.SourceConfig, vCenterManager VCenterManager) (VmConstruct, error)
 }
 
 //counterfeiter:generate . ManagerFactory
@@ -48,24 +69,41 @@ type ManagerFactory interface {
 type ConstructCmdValidator interface {
 	PopulatedArgs(...string) bool
 	LGPOInDirectory() bool
+	ResolveLGPO(source lgpo.Source) (string, error)
+	AutomationZip(path string) error
+	PostRebootArgs(automationZip string, args []string) error
 }
 
 //counterfeiter:generate . ConstructMessenger
 type ConstructMessenger interface {
 	ArgumentsNotProvided()
 	LGPONotFound()
+	InvalidLGPO(err error)
+	InvalidAutomationZip(err error)
 	CannotConnectToVM(err error)
 	CannotPrepareVM(err error)
+	InvalidCloneNetwork()
+	InvalidPostRebootArg(err error)
+	InvalidWinRMOptions(err error)
+	InvalidConfig(err error)
+	CannotReadPassword(err error)
 }
 
 type ConstructCmd struct {
-	ctx            context.Context
-	sourceConfig   config.SourceConfig
-	prepFactory    VMPreparerFactory
-	managerFactory ManagerFactory
-	validator      ConstructCmdValidator
-	messenger      ConstructMessenger
-	GlobalFlags    *GlobalFlags
+	ctx             context.Context
+	sourceConfig    config.SourceConfig
+	configFile      string
+	vmPassword      passwordFlag
+	vCenterPassword passwordFlag
+	prepFactory     VMPreparerFactory
+	managerFactory  ManagerFactory
+	validator       ConstructCmdValidator
+	messenger       ConstructMessenger
+	GlobalFlags     *GlobalFlags
+	// Stdin is read for passwords given as -*-password-from stdin, and prompted for when missing. Nil disables both.
+	Stdin *os.File
+	// EventOutput receives the JSON events of -output json, default is os.Stdout
+	EventOutput io.Writer
 }
 
 type setupFlagsValue struct {
@@ -85,8 +123,104 @@ func (v setupFlagsValue) Set(s string) error {
 	return nil
 }
 
+type dnsServersValue struct {
+	sourceConfig *config.SourceConfig
+}
+
+func (v dnsServersValue) String() string {
+	if v.sourceConfig == nil {
+		return ""
+	}
+	return strings.Join(v.sourceConfig.CloneDNSServers, ",")
+}
+
+func (v dnsServersValue) Set(s string) error {
+	for _, server := range strings.Split(s, ",") {
+		if server = strings.TrimSpace(server); server != "" {
+			v.sourceConfig.CloneDNSServers = append(v.sourceConfig.CloneDNSServers, server)
+		}
+	}
+	return nil
+}
+
+type postRebootFlagsValue struct {
+	sourceConfig *config.SourceConfig
+}
+
+func (v postRebootFlagsValue) String() string {
+	if v.sourceConfig == nil {
+		return ""
+	}
+	return strings.Join(v.sourceConfig.PostRebootFlags, "; ")
+}
+
+func (v postRebootFlagsValue) Set(s string) error {
+	v.sourceConfig.PostRebootFlags = append(v.sourceConfig.PostRebootFlags, s)
+	return nil
+}
+
+type transportValue struct {
+	sourceConfig *config.SourceConfig
+}
+
+func (v transportValue) String() string {
+	if v.sourceConfig == nil {
+		return ""
+	}
+	return v.sourceConfig.Transport
+}
+
+func (v transportValue) Set(s string) error {
+	switch s {
+	case config.TransportWinRM, config.TransportGuestOps:
+		v.sourceConfig.Transport = s
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s", config.TransportWinRM, config.TransportGuestOps)
+}
+
+type ifConstructedValue struct {
+	sourceConfig *config.SourceConfig
+}
+
+func (v ifConstructedValue) String() string {
+	if v.sourceConfig == nil {
+		return ""
+	}
+	return v.sourceConfig.IfConstructed
+}
+
+func (v ifConstructedValue) Set(s string) error {
+	switch s {
+	case construct.IfConstructedRefuse, construct.IfConstructedContinue:
+		v.sourceConfig.IfConstructed = s
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s", construct.IfConstructedRefuse, construct.IfConstructedContinue)
+}
+
+type updateRoundsValue struct {
+	sourceConfig *config.SourceConfig
+}
+
+func (v updateRoundsValue) String() string {
+	if v.sourceConfig == nil {
+		return ""
+	}
+	return strconv.Itoa(v.sourceConfig.MaxUpdateRounds)
+}
+
+func (v updateRoundsValue) Set(s string) error {
+	rounds, err := strconv.Atoi(s)
+	if err != nil || rounds < 1 {
+		return fmt.Errorf("must be a number of at least 1")
+	}
+	v.sourceConfig.MaxUpdateRounds = rounds
+	return nil
+}
+
 func NewConstructCmd(ctx context.Context, prepFactory VMPreparerFactory, managerFactory ManagerFactory, validator ConstructCmdValidator, messenger ConstructMessenger) *ConstructCmd {
-	return &ConstructCmd{ctx: ctx, prepFactory: prepFactory, managerFactory: managerFactory, validator: validator, messenger: messenger}
+	return &ConstructCmd{ctx: ctx, prepFactory: prepFactory, managerFactory: managerFactory, validator: validator, messenger: messenger, Stdin: os.Stdin, EventOutput: os.Stdout}
 }
 
 func (*ConstructCmd) Name() string { return "construct" }
@@ -100,7 +234,7 @@ func (*ConstructCmd) Usage() string {
 Prepares a VM to be used by stembuild package. It leverages stemcell automation scripts to provision a VM to be used as a stemcell.
 
 Requirements:
-	LGPO.zip in current working directory
+	LGPO.zip in current working directory, or given with -lgpo-path or -lgpo-url
 	Running Windows VM with:
 		- Up to date Operating System
 		- Reachable by IP
@@ -112,6 +246,70 @@ Requirements:
 Example:
 	%[1]s construct -vm-ip '10.0.0.5' -vm-username Admin -vm-password 'password' -vcenter-url vcenter.example.com -vcenter-username root -vcenter-password 'password' -vm-inventory-path '/datacenter/vm/folder/vm-name'
 
+Passwords:
+	Passwords given as flags are visible to other users of this machine. Instead, -vm-password-from and -vcenter-password-from read them from
+	file:<path>, env:<name> or stdin. A password that is still missing is prompted for when stdin is a terminal:
+	echo "$VM_PASSWORD" | %[1]s construct -vm-password-from stdin -vcenter-password-from file:/run/secrets/vcenter ...
+
+Configuration:
+	Any flag can instead be given in a YAML file passed with -config, or $STEMBUILD_CONFIG, as the flag name and its value:
+		vcenter-url: vcenter.example.com
+		vcenter-password: secret
+		post-reboot-arg: [Organization MyOrg, SkipRandomPassword]
+	or in an environment variable named STEMBUILD_ and the flag name in upper case with dashes replaced by underscores, e.g. STEMBUILD_VCENTER_PASSWORD.
+	Flags on the command line take precedence over environment variables, which take precedence over the config file.
+
+Resuming:
+	Each completed step is recorded in a local state file keyed by the VM inventory path.
+	Rerun the same command with -resume to continue a failed run from the first step whose results are missing on the VM.
+	Without -resume, construct refuses to run against a VM that an earlier construct has left its marker or stemcell version file on,
+	or that is powered off. Pass -if-constructed continue to run only the steps whose results are missing instead.
+
+Cloning:
+	With -clone-from, the VM at that inventory path is cloned to -vm-inventory-path and construct runs against the clone, leaving the original untouched.
+	The clone is customized with sysprep, which names it after the clone and sets its Administrator password to -vm-password.
+	Its first network adapter gets -vm-ip as a static address when -clone-netmask is set, otherwise it waits for a DHCP address.
+	Example:
+	%[1]s construct -clone-from '/datacenter/vm/folder/base-vm' -vm-ip '10.0.0.5' -clone-netmask 255.255.255.0 -clone-gateway 10.0.0.1 -clone-dns 10.0.0.2 ...
+
+Snapshots:
+	A snapshot named stembuild-pre-construct is taken before the VM is changed and deleted once construct succeeds.
+	When construct fails the snapshot is kept, or the VM is reverted to it and it is deleted when -rollback-on-failure is set.
+	No snapshot is taken of a clone.
+
+Timeouts:
+	Every wait has a default suited to a typical vCenter. Durations are given like 90s, 45m or 2h.
+	-winrm-timeout and -winrm-dial-timeout bound WinRM connections and commands.
+	-reboot-wait, -reboot-poll-interval and -reboot-timeout control waiting for a reboot, -post-reboot-timeout bounds PostReboot.ps1,
+	-shutdown-poll-interval and -shutdown-timeout control waiting for sysprep to power off the VM, and -update-timeout bounds each round of -install-updates.
+	Construct fails with a timeout error when a reboot or the power off takes longer than its timeout.
+	-timeout is a deadline for the whole construct. When it passes, or on Ctrl-C, the command running in the guest is stopped,
+	construct reports the step it interrupted and rolls back with -rollback-on-failure. A second Ctrl-C exits immediately.
+
+Windows updates:
+	With -install-updates, available Windows updates are installed after connecting to the VM and before Setup.ps1 runs.
+	The VM is rebooted whenever the updates require it, and updates are searched for again until none remain or -max-update-rounds is reached.
+	The installed KBs are printed.
+
+LGPO:
+	LGPO.zip is read from the current working directory unless -lgpo-path points elsewhere.
+	With -lgpo-url it is downloaded once into the stembuild directory of the user cache directory and reused while it matches -lgpo-sha256.
+	Construct checks the checksum, when given, and that the zip contains LGPO.exe before touching the VM.
+
+Stemcell automation:
+	The VM is provisioned with the StemcellAutomation.zip built into stembuild unless -automation-zip is given.
+	A custom archive must contain Setup.ps1, PostReboot.ps1 and bosh-psmodules.zip. The SHA-256 of the archive used is printed.
+
+Transport:
+	Commands are run in the VM over WinRM by default, which requires the VM to be reachable on port 5985.
+	Before uploading anything, construct checks that -vm-ip, or an address a host name given as -vm-ip resolves to, is one of the IP addresses
+	VMware Tools reports for the VM at -vm-inventory-path,
+	and before running anything over WinRM it confirms that -vm-ip reaches that VM by reading back a file written through VMware Tools.
+	Pass -winrm-https to use HTTPS on port 5986 instead. The certificate of the VM is verified against the system CAs and -winrm-ca-cert.
+	With -transport=guestops they are run through vCenter using VMware Tools guest operations instead, and -vm-ip is not required:
+
+	%[1]s construct -transport guestops -vm-username ... -vm-password ... -vcenter-url ... -vcenter-username ... -vcenter-password ... -vm-inventory-path ...
+
 Flags:
 `, filepath.Base(os.Args[0]))
 }
@@ -119,25 +317,125 @@ Flags:
 func (p *ConstructCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&p.sourceConfig.GuestVmIp, "vm-ip", "", "IP of target machine")
 	f.StringVar(&p.sourceConfig.GuestVMUsername, "vm-username", "", "Username of target machine")
-	f.StringVar(&p.sourceConfig.GuestVMPassword, "vm-password", "", "Password of target machine. Needs to be wrapped in single quotations.")
+	f.StringVar(&p.sourceConfig.GuestVMPassword, "vm-password", "", "Password of target machine, visible to other users of this machine; prefer -vm-password-from")
+	p.vmPassword = passwordFlag{name: "vm-password", label: "VM password", value: &p.sourceConfig.GuestVMPassword}
+	setPasswordFromFlag(f, &p.vmPassword)
 	f.StringVar(&p.sourceConfig.VCenterUrl, "vcenter-url", "", "vCenter url")
 	f.StringVar(&p.sourceConfig.VCenterUsername, "vcenter-username", "", "vCenter username")
-	f.StringVar(&p.sourceConfig.VCenterPassword, "vcenter-password", "", "vCenter password")
+	f.StringVar(&p.sourceConfig.VCenterPassword, "vcenter-password", "", "vCenter password, visible to other users of this machine; prefer -vcenter-password-from")
+	p.vCenterPassword = passwordFlag{name: "vcenter-password", label: "vCenter password", value: &p.sourceConfig.VCenterPassword}
+	setPasswordFromFlag(f, &p.vCenterPassword)
 	f.StringVar(&p.sourceConfig.VmInventoryPath, "vm-inventory-path", "", "vCenter VM inventory path. (e.g: <datacenter>/vm/<vm-folder>/<vm-name>)")
 	f.StringVar(&p.sourceConfig.CaCertFile, "vcenter-ca-certs", "", "filepath for custom ca certs")
 	f.Var(newSetupFlagsValue(&p.sourceConfig), "setup-arg", "a 'flag value' combination to be passed to Setup.ps1 - can be set multiple times")
+	f.Var(postRebootFlagsValue{&p.sourceConfig}, "post-reboot-arg", "a 'flag value' combination, or a switch, to be passed to PostReboot.ps1 (Organization, Owner, SkipRandomPassword) - can be set multiple times")
+	f.BoolVar(&p.sourceConfig.InstallUpdates, "install-updates", false, "Install Windows updates, rebooting as needed, before running Setup.ps1")
+	p.sourceConfig.MaxUpdateRounds = defaultMaxUpdateRounds
+	f.Var(updateRoundsValue{&p.sourceConfig}, "max-update-rounds", "maximum number of install and reboot rounds for -install-updates")
+	f.StringVar(&p.sourceConfig.AutomationZip, "automation-zip", "", "filepath of a StemcellAutomation.zip to provision the VM with instead of the one built into stembuild")
+	f.BoolVar(&p.sourceConfig.SkipOSCheck, "skip-os-check", false, "Warn instead of failing when the guest OS does not match the Windows Server version this stembuild builds stemcells for, or cannot be read")
+	f.BoolVar(&p.sourceConfig.SkipVMIPCheck, "skip-vm-ip-check", false, "Do not compare -vm-ip with the IP addresses VMware Tools reports for the VM, e.g. when -vm-ip reaches the VM through NAT; the VM is still confirmed over WinRM")
+	f.BoolVar(&p.sourceConfig.NoLogTail, "no-log-tail", false, "Do not stream C:\\provision\\log.log from the guest while the setup scripts run")
+	f.BoolVar(&p.sourceConfig.Resume, "resume", false, "Skip steps completed by a previous run against the same VM, after checking that their results are still present on the VM")
+	p.sourceConfig.IfConstructed = construct.IfConstructedRefuse
+	f.Var(ifConstructedValue{&p.sourceConfig}, "if-constructed", "what to do when an earlier construct has left traces on the VM: refuse, or continue to run only the steps whose results are missing")
+	f.StringVar(&p.sourceConfig.StateFile, "state-file", "", "filepath for recording construct progress, default is construct-state.json in the user cache directory")
+	f.StringVar(&p.sourceConfig.CloneFrom, "clone-from", "", "vCenter inventory path of a VM to clone to -vm-inventory-path before constructing the clone")
+	f.StringVar(&p.sourceConfig.CloneNetmask, "clone-netmask", "", "subnet mask for a static -vm-ip on the clone; without it the clone gets its address from DHCP")
+	f.StringVar(&p.sourceConfig.CloneGateway, "clone-gateway", "", "default gateway for a static -vm-ip on the clone")
+	f.Var(dnsServersValue{&p.sourceConfig}, "clone-dns", "comma-separated DNS servers for the clone - can be set multiple times")
+	f.BoolVar(&p.sourceConfig.RollbackOnFailure, "rollback-on-failure", false, "Revert the VM to the snapshot taken before construct when any step fails")
+	p.sourceConfig.Transport = config.TransportWinRM
+	f.Var(transportValue{&p.sourceConfig}, "transport", "how commands are run in the guest: winrm, or guestops to use VMware Tools guest operations through vCenter")
+	setWinRMFlags(f, &p.sourceConfig.WinRM)
+	setLGPOFlags(f, &p.sourceConfig.LGPO)
+	setTimeoutFlags(f, &p.sourceConfig.Timeouts)
+	setConfigFlag(f, &p.configFile)
+}
+
+func setTimeoutFlags(f *flag.FlagSet, timeouts *config.Timeouts) {
+	f.DurationVar(&timeouts.RebootWait, "reboot-wait", construct.DefaultRebootWaitTime, "time to wait after a reboot starts before checking whether it has finished")
+	f.DurationVar(&timeouts.RebootPollInterval, "reboot-poll-interval", remotemanager.DefaultRebootPollInterval, "time before the first check whether a reboot has finished, doubling after every check up to 1m")
+	f.DurationVar(&timeouts.Reboot, "reboot-timeout", remotemanager.DefaultRebootTimeout, "time to wait for a reboot to finish before failing")
+	f.DurationVar(&timeouts.PostRebootScript, "post-reboot-timeout", construct.DefaultPostRebootTimeout, "timeout for running PostReboot.ps1")
+	f.DurationVar(&timeouts.ShutdownPollInterval, "shutdown-poll-interval", construct.DefaultShutdownPollInterval, "time before the first check whether sysprep has powered off the VM, doubling after every check up to 2m")
+	f.DurationVar(&timeouts.Shutdown, "shutdown-timeout", construct.DefaultShutdownTimeout, "time to wait for sysprep to power off the VM before failing")
+	f.DurationVar(&timeouts.WindowsUpdates, "update-timeout", construct.DefaultWindowsUpdatesTimeout, "timeout for each round of -install-updates")
+	f.DurationVar(&timeouts.Construct, "timeout", 0, "deadline for the whole construct, after which it is aborted like on Ctrl-C; no deadline when 0")
 }
 
 func (p *ConstructCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	messenger := p.messenger
+	var emitter *events.Emitter
+	if p.GlobalFlags.JSONOutput() {
+		emitter = events.NewEmitter(p.EventOutput, p.Name())
+		messenger = &ConstructCmdMessenger{Events: emitter}
+		p.sourceConfig.OutputFormat = events.FormatJSON
+	}
+
+	err := applyConfig(f, p.configFile, nil)
+	if err != nil {
+		messenger.InvalidConfig(err)
+		return subcommands.ExitFailure
+	}
+
+	// only prompt for passwords of accounts that were given
+	p.vCenterPassword.prompt = p.sourceConfig.VCenterUsername != ""
+	p.vmPassword.prompt = p.sourceConfig.GuestVMUsername != ""
+	err = resolvePasswords(p.Stdin, os.Stderr, &p.vCenterPassword, &p.vmPassword)
+	if err != nil {
+		messenger.CannotReadPassword(err)
+		return subcommands.ExitFailure
+	}
+
 	c := p.sourceConfig
-	if !p.validator.PopulatedArgs(c.GuestVmIp, c.GuestVMUsername, c.GuestVMPassword, c.VCenterUrl, c.VCenterUsername, c.VCenterPassword, c.VmInventoryPath) {
-		p.messenger.ArgumentsNotProvided()
+	required := []string{c.GuestVMUsername, c.GuestVMPassword, c.VCenterUrl, c.VCenterUsername, c.VCenterPassword, c.VmInventoryPath}
+	// a clone without a static address reports the address DHCP gave it, and guest operations go through vCenter
+	if c.CloneNetmask != "" || (c.CloneFrom == "" && c.Transport != config.TransportGuestOps) {
+		required = append(required, c.GuestVmIp)
+	}
+	if !p.validator.PopulatedArgs(required...) {
+		messenger.ArgumentsNotProvided()
 		return subcommands.ExitFailure
 	}
-	if !p.validator.LGPOInDirectory() {
-		p.messenger.LGPONotFound()
+	if !validCloneNetwork(c) {
+		messenger.InvalidCloneNetwork()
 		return subcommands.ExitFailure
 	}
+	err = c.WinRM.Validate()
+	if err != nil {
+		messenger.InvalidWinRMOptions(err)
+		return subcommands.ExitFailure
+	}
+	err = c.LGPO.Validate()
+	if err != nil {
+		messenger.InvalidLGPO(err)
+		return subcommands.ExitFailure
+	}
+	if c.LGPO.Path == "" && c.LGPO.URL == "" && !p.validator.LGPOInDirectory() {
+		messenger.LGPONotFound()
+		return subcommands.ExitFailure
+	}
+	if c.AutomationZip != "" {
+		err = p.validator.AutomationZip(c.AutomationZip)
+		if err != nil {
+			messenger.InvalidAutomationZip(err)
+			return subcommands.ExitFailure
+		}
+	}
+	// the args are checked against the PostReboot.ps1 construct will run
+	err = p.validator.PostRebootArgs(c.AutomationZip, c.PostRebootFlags)
+	if err != nil {
+		messenger.InvalidPostRebootArg(err)
+		return subcommands.ExitFailure
+	}
+	lgpoPath, err := p.validator.ResolveLGPO(c.LGPO)
+	if err != nil {
+		messenger.InvalidLGPO(err)
+		return subcommands.ExitFailure
+	}
+	// construct uploads the verified local copy
+	p.sourceConfig.LGPO = lgpo.Source{Path: lgpoPath}
 
 	p.managerFactory.SetConfig(vcenterclientfactory.FactoryConfig{
 		VCenterServer:  p.sourceConfig.VCenterUrl,
@@ -148,23 +446,57 @@ func (p *ConstructCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interfac
 		RootCACertPath: p.sourceConfig.CaCertFile,
 	})
 
-	vCenterManager, err := p.managerFactory.VCenterManager(p.ctx)
+	ctx, cancel := p.context()
+	defer cancel()
+
+	vCenterManager, err := p.managerFactory.VCenterManager(ctx)
 	if err != nil {
-		p.messenger.CannotPrepareVM(err)
+		messenger.CannotPrepareVM(p.abortError(ctx, err))
 		return subcommands.ExitFailure
 	}
 
-	vmConstruct, err := p.prepFactory.VMPreparer(p.sourceConfig, vCenterManager)
+	vmConstruct, err := p.prepFactory.VMPreparer(ctx, p.sourceConfig, vCenterManager)
 	if err != nil {
-		p.messenger.CannotPrepareVM(err)
+		messenger.CannotPrepareVM(p.abortError(ctx, err))
 		return subcommands.ExitFailure
 	}
 
 	err = vmConstruct.PrepareVM()
 	if err != nil {
-		p.messenger.CannotPrepareVM(err)
+		messenger.CannotPrepareVM(p.abortError(ctx, err))
 		return subcommands.ExitFailure
 	}
 
+	emitter.Succeeded(events.Result{OS: version.NewVersionGetter().GetOs(), Version: version.Version})
 	return subcommands.ExitSuccess
 }
+
+// context is the context of the command, given the deadline of -timeout when it is set
+func (p *ConstructCmd) context() (context.Context, context.CancelFunc) {
+	if p.sourceConfig.Timeouts.Construct > 0 {
+		return context.WithTimeout(p.ctx, p.sourceConfig.Timeouts.Construct)
+	}
+	return context.WithCancel(p.ctx)
+}
+
+// abortError explains an error caused by construct being aborted
+func (p *ConstructCmd) abortError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("construct did not finish within -timeout %s: %w", p.sourceConfig.Timeouts.Construct, err)
+	case context.Canceled:
+		return fmt.Errorf("construct was aborted: %w", err)
+	}
+	return err
+}
+
+func validCloneNetwork(c config.SourceConfig) bool {
+	staticSettings := c.CloneNetmask != "" || c.CloneGateway != "" || len(c.CloneDNSServers) > 0
+	if c.CloneFrom == "" {
+		return !staticSettings
+	}
+	if c.CloneNetmask != "" {
+		return c.CloneGateway != ""
+	}
+	return c.CloneGateway == ""
+}
@@ -1,15 +1,24 @@
 package commandparser
 
 import (
+	"errors"
 	"fmt"
 	"io"
+
+	"github.com/cloudfoundry/stembuild/events"
 )
 
 type ConstructCmdMessenger struct {
 	OutputChannel io.Writer
+	// Events receives every message as the error of a failed result instead of OutputChannel, when set
+	Events *events.Emitter
 }
 
 func (m *ConstructCmdMessenger) printMessage(message string) {
+	if m.Events != nil {
+		m.Events.Failed(errors.New(message))
+		return
+	}
 	fmt.Fprintln(m.OutputChannel, message)
 }
 
@@ -21,6 +30,14 @@ func (m *ConstructCmdMessenger) LGPONotFound() {
 	m.printMessage("Could not find LGPO.zip in the current directory")
 }
 
+func (m *ConstructCmdMessenger) InvalidLGPO(err error) {
+	m.printMessage(fmt.Sprintf("Could not get a valid LGPO.zip: %s", err))
+}
+
+func (m *ConstructCmdMessenger) InvalidAutomationZip(err error) {
+	m.printMessage(fmt.Sprintf("Invalid stemcell automation archive: %s", err))
+}
+
 func (m *ConstructCmdMessenger) CannotConnectToVM(err error) {
 	m.printMessage(fmt.Sprintf("Cannot connect to VM: %s", err))
 }
@@ -28,3 +45,23 @@ func (m *ConstructCmdMessenger) CannotConnectToVM(err error) {
 func (m *ConstructCmdMessenger) CannotPrepareVM(err error) {
 	m.printMessage(fmt.Sprintf("Could not prepare VM: %s", err))
 }
+
+func (m *ConstructCmdMessenger) InvalidCloneNetwork() {
+	m.printMessage("-clone-netmask, -clone-gateway and -clone-dns can only be used with -clone-from, and a static clone address needs both -clone-netmask and -clone-gateway")
+}
+
+func (m *ConstructCmdMessenger) InvalidPostRebootArg(err error) {
+	m.printMessage(fmt.Sprintf("Invalid -post-reboot-arg: %s", err))
+}
+
+func (m *ConstructCmdMessenger) InvalidWinRMOptions(err error) {
+	m.printMessage(fmt.Sprintf("Invalid WinRM options: %s", err))
+}
+
+func (m *ConstructCmdMessenger) InvalidConfig(err error) {
+	m.printMessage(fmt.Sprintf("Invalid configuration: %s", err))
+}
+
+func (m *ConstructCmdMessenger) CannotReadPassword(err error) {
+	m.printMessage(fmt.Sprintf("Could not read password: %s", err))
+}
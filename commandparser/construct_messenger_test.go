@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/gomega/gbytes"
 
 	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/events"
 )
 
 var _ = Describe("ConstructMessenger", func() {
@@ -50,4 +51,65 @@ var _ = Describe("ConstructMessenger", func() {
 			Eventually(g).Should(Say("Could not prepare VM: %s", preparationError))
 		})
 	})
+
+	Describe("InvalidCloneNetwork", func() {
+		It("should output an appropriate error", func() {
+			cm.InvalidCloneNetwork()
+			Eventually(g).Should(Say("a static clone address needs both -clone-netmask and -clone-gateway"))
+		})
+	})
+
+	Describe("InvalidPostRebootArg", func() {
+		It("should output an appropriate error", func() {
+			cm.InvalidPostRebootArg(errors.New("'Foo' is not a parameter of PostReboot.ps1"))
+			Eventually(g).Should(Say("Invalid -post-reboot-arg: 'Foo' is not a parameter of PostReboot.ps1"))
+		})
+	})
+
+	Describe("InvalidLGPO", func() {
+		It("should output an appropriate error", func() {
+			cm.InvalidLGPO(errors.New("LGPO.zip does not contain LGPO.exe"))
+			Eventually(g).Should(Say("Could not get a valid LGPO.zip: LGPO.zip does not contain LGPO.exe"))
+		})
+	})
+
+	Describe("InvalidAutomationZip", func() {
+		It("should output an appropriate error", func() {
+			cm.InvalidAutomationZip(errors.New("custom.zip does not contain Setup.ps1"))
+			Eventually(g).Should(Say("Invalid stemcell automation archive: custom.zip does not contain Setup.ps1"))
+		})
+	})
+
+	Describe("InvalidWinRMOptions", func() {
+		It("should output an appropriate error", func() {
+			cm.InvalidWinRMOptions(errors.New("auth must be one of basic, ntlm"))
+			Eventually(g).Should(Say("Invalid WinRM options: auth must be one of basic, ntlm"))
+		})
+	})
+
+	Describe("CannotReadPassword", func() {
+		It("should output an appropriate error", func() {
+			cm.CannotReadPassword(errors.New("-vm-password-from: stdin is not available"))
+			Eventually(g).Should(Say("Could not read password: -vm-password-from: stdin is not available"))
+		})
+	})
+
+	Describe("InvalidConfig", func() {
+		It("should output an appropriate error", func() {
+			cm.InvalidConfig(errors.New("config file construct.yml sets unknown flag vcenter-adress"))
+			Eventually(g).Should(Say("Invalid configuration: config file construct.yml sets unknown flag vcenter-adress"))
+		})
+	})
+
+	Describe("with Events", func() {
+		It("reports the message as the error of a failed result instead", func() {
+			eventBuf := NewBuffer()
+			cm.Events = events.NewEmitter(eventBuf, "construct")
+
+			cm.CannotPrepareVM(errors.New("some error"))
+
+			Expect(g.Contents()).To(BeEmpty())
+			Eventually(eventBuf).Should(Say(`"type":"result".*"status":"failed".*"error":"Could not prepare VM: some error"`))
+		})
+	})
 })
@@ -1,9 +1,17 @@
 package commandparser_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/google/subcommands"
 	. "github.com/onsi/ginkgo/v2"
@@ -11,6 +19,11 @@ import (
 
 	"github.com/cloudfoundry/stembuild/commandparser"
 	"github.com/cloudfoundry/stembuild/commandparser/commandparserfakes"
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/construct/config"
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+	"github.com/cloudfoundry/stembuild/events"
+	"github.com/cloudfoundry/stembuild/remotemanager"
 )
 
 var _ = Describe("construct", func() {
@@ -93,6 +106,254 @@ var _ = Describe("construct", func() {
 			Expect(ConstrCmd.GetSourceConfig().CaCertFile).To(Equal("somecerts.txt"))
 		})
 
+		Describe("resume flags", func() {
+			It("does not resume by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().Resume).To(BeFalse())
+				Expect(ConstrCmd.GetSourceConfig().StateFile).To(BeEmpty())
+			})
+
+			It("stores the resume flag and the state file path", func() {
+				err := f.Parse(append(args, "-resume", "-state-file", "some-state.json"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().Resume).To(BeTrue())
+				Expect(ConstrCmd.GetSourceConfig().StateFile).To(Equal("some-state.json"))
+			})
+		})
+
+		Describe("rollback-on-failure flag", func() {
+			It("does not roll back by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().RollbackOnFailure).To(BeFalse())
+			})
+
+			It("stores the rollback-on-failure flag", func() {
+				err := f.Parse(append(args, "-rollback-on-failure"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().RollbackOnFailure).To(BeTrue())
+			})
+		})
+
+		Describe("skip-os-check flag", func() {
+			It("checks the guest OS by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().SkipOSCheck).To(BeFalse())
+			})
+
+			It("stores the skip-os-check flag", func() {
+				err := f.Parse(append(args, "-skip-os-check"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().SkipOSCheck).To(BeTrue())
+			})
+		})
+
+		Describe("skip-vm-ip-check flag", func() {
+			It("checks -vm-ip against the VM by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().SkipVMIPCheck).To(BeFalse())
+			})
+
+			It("stores the skip-vm-ip-check flag", func() {
+				err := f.Parse(append(args, "-skip-vm-ip-check"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().SkipVMIPCheck).To(BeTrue())
+			})
+		})
+
+		Describe("automation-zip flag", func() {
+			It("uses the embedded archive by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().AutomationZip).To(BeEmpty())
+			})
+
+			It("stores the automation-zip path", func() {
+				err := f.Parse(append(args, "-automation-zip", "custom/StemcellAutomation.zip"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().AutomationZip).To(Equal("custom/StemcellAutomation.zip"))
+			})
+		})
+
+		Describe("no-log-tail flag", func() {
+			It("tails the guest log by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().NoLogTail).To(BeFalse())
+			})
+
+			It("stores the no-log-tail flag", func() {
+				err := f.Parse(append(args, "-no-log-tail"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().NoLogTail).To(BeTrue())
+			})
+		})
+
+		Describe("post-reboot-arg flag", func() {
+			It("stores every post-reboot arg", func() {
+				err := f.Parse(append(args, "-post-reboot-arg", "Organization SomeOrg", "-post-reboot-arg", "SkipRandomPassword"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().PostRebootFlags).To(Equal([]string{"Organization SomeOrg", "SkipRandomPassword"}))
+			})
+		})
+
+		Describe("clone flags", func() {
+			It("stores the clone source and network settings", func() {
+				err := f.Parse(append(args,
+					"-clone-from", "/my-datacenter/vm/my-folder/golden-vm",
+					"-clone-netmask", "255.255.255.0",
+					"-clone-gateway", "10.0.0.1",
+					"-clone-dns", "10.0.0.2, 10.0.0.3",
+					"-clone-dns", "10.0.0.4",
+				))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().CloneFrom).To(Equal("/my-datacenter/vm/my-folder/golden-vm"))
+				Expect(ConstrCmd.GetSourceConfig().CloneNetmask).To(Equal("255.255.255.0"))
+				Expect(ConstrCmd.GetSourceConfig().CloneGateway).To(Equal("10.0.0.1"))
+				Expect(ConstrCmd.GetSourceConfig().CloneDNSServers).To(Equal([]string{"10.0.0.2", "10.0.0.3", "10.0.0.4"}))
+			})
+		})
+
+		Describe("transport flag", func() {
+			It("defaults to winrm", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().Transport).To(Equal("winrm"))
+			})
+
+			It("stores guestops", func() {
+				err := f.Parse(append(args, "-transport", "guestops"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().Transport).To(Equal("guestops"))
+			})
+
+			It("rejects an unknown transport", func() {
+				f.SetOutput(io.Discard)
+				err := f.Parse(append(args, "-transport", "ssh"))
+				Expect(err).To(MatchError(ContainSubstring("must be one of winrm, guestops")))
+			})
+		})
+
+		Describe("if-constructed flag", func() {
+			It("refuses by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().IfConstructed).To(Equal("refuse"))
+			})
+
+			It("stores continue", func() {
+				err := f.Parse(append(args, "-if-constructed", "continue"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().IfConstructed).To(Equal("continue"))
+			})
+
+			It("rejects anything else", func() {
+				f.SetOutput(io.Discard)
+				err := f.Parse(append(args, "-if-constructed", "overwrite"))
+				Expect(err).To(MatchError(ContainSubstring("must be one of refuse, continue")))
+			})
+		})
+
+		Describe("windows update flags", func() {
+			It("does not install updates by default", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().InstallUpdates).To(BeFalse())
+				Expect(ConstrCmd.GetSourceConfig().MaxUpdateRounds).To(Equal(5))
+			})
+
+			It("stores the install-updates flag and the maximum number of rounds", func() {
+				err := f.Parse(append(args, "-install-updates", "-max-update-rounds", "8"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().InstallUpdates).To(BeTrue())
+				Expect(ConstrCmd.GetSourceConfig().MaxUpdateRounds).To(Equal(8))
+			})
+
+			It("rejects less than one round", func() {
+				f.SetOutput(io.Discard)
+				err := f.Parse(append(args, "-max-update-rounds", "0"))
+				Expect(err).To(MatchError(ContainSubstring("must be a number of at least 1")))
+			})
+		})
+
+		Describe("lgpo flags", func() {
+			It("stores the LGPO source", func() {
+				err := f.Parse(append(args, "-lgpo-url", "https://example.com/LGPO.zip", "-lgpo-sha256", "abc123"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().LGPO).To(Equal(lgpo.Source{URL: "https://example.com/LGPO.zip", SHA256: "abc123"}))
+			})
+
+			It("stores the LGPO path", func() {
+				err := f.Parse(append(args, "-lgpo-path", "/ci/LGPO.zip"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().LGPO.Path).To(Equal("/ci/LGPO.zip"))
+			})
+		})
+
+		Describe("winrm flags", func() {
+			It("stores the WinRM options", func() {
+				err := f.Parse(append(args, "-winrm-https", "-winrm-port", "8443", "-winrm-ca-cert", "winrm-ca.pem", "-winrm-auth", "ntlm"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().WinRM).To(Equal(remotemanager.WinRMOptions{HTTPS: true, Port: 8443, CACertFile: "winrm-ca.pem", Auth: "ntlm", Timeout: remotemanager.WinRmTimeout, DialTimeout: remotemanager.WinRmDialTimeout}))
+			})
+
+			It("defaults to basic auth over HTTP", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().WinRM).To(Equal(remotemanager.WinRMOptions{Auth: "basic", Timeout: remotemanager.WinRmTimeout, DialTimeout: remotemanager.WinRmDialTimeout}))
+			})
+
+			It("stores the WinRM timeouts", func() {
+				err := f.Parse(append(args, "-winrm-timeout", "5m", "-winrm-dial-timeout", "10s"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().WinRM.Timeout).To(Equal(5 * time.Minute))
+				Expect(ConstrCmd.GetSourceConfig().WinRM.DialTimeout).To(Equal(10 * time.Second))
+			})
+		})
+
+		Describe("timeout flags", func() {
+			It("defaults every timeout", func() {
+				err := f.Parse(args)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().Timeouts).To(Equal(config.Timeouts{
+					RebootWait:           construct.DefaultRebootWaitTime,
+					RebootPollInterval:   remotemanager.DefaultRebootPollInterval,
+					Reboot:               remotemanager.DefaultRebootTimeout,
+					PostRebootScript:     construct.DefaultPostRebootTimeout,
+					ShutdownPollInterval: construct.DefaultShutdownPollInterval,
+					Shutdown:             construct.DefaultShutdownTimeout,
+					WindowsUpdates:       construct.DefaultWindowsUpdatesTimeout,
+				}))
+			})
+
+			It("stores every timeout", func() {
+				err := f.Parse(append(args,
+					"-reboot-wait", "2m",
+					"-reboot-poll-interval", "30s",
+					"-reboot-timeout", "90m",
+					"-post-reboot-timeout", "3h",
+					"-shutdown-poll-interval", "15s",
+					"-shutdown-timeout", "45m",
+					"-update-timeout", "6h",
+					"-timeout", "12h",
+				))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ConstrCmd.GetSourceConfig().Timeouts).To(Equal(config.Timeouts{
+					RebootWait:           2 * time.Minute,
+					RebootPollInterval:   30 * time.Second,
+					Reboot:               90 * time.Minute,
+					PostRebootScript:     3 * time.Hour,
+					ShutdownPollInterval: 15 * time.Second,
+					Shutdown:             45 * time.Minute,
+					WindowsUpdates:       6 * time.Hour,
+					Construct:            12 * time.Hour,
+				}))
+			})
+		})
+
 		Describe("setup-arg flag", func() {
 			var args = []string{
 				"-vm-ip", "10.0.0.5",
@@ -189,6 +450,377 @@ var _ = Describe("construct", func() {
 			})
 		})
 
+		Context("with an invalid post-reboot arg", func() {
+			It("should return an error", func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+				fakeValidator.PostRebootArgsReturns(errors.New("not a parameter"))
+
+				err := f.Parse([]string{"-post-reboot-arg", "Foo Bar"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				automationZip, args := fakeValidator.PostRebootArgsArgsForCall(0)
+				Expect(automationZip).To(BeEmpty())
+				Expect(args).To(Equal([]string{"Foo Bar"}))
+				Expect(fakeMessenger.InvalidPostRebootArgCallCount()).To(Equal(1))
+				Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("with a config file and environment variables", func() {
+			var configFile string
+
+			BeforeEach(func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+
+				configFile = filepath.Join(GinkgoT().TempDir(), "construct.yml")
+				Expect(os.WriteFile(configFile, []byte(`
+vcenter-url: file.example.com
+vcenter-username: file-user
+vcenter-password: file-password
+install-updates: true
+max-update-rounds: 3
+shutdown-timeout: 2h
+post-reboot-arg: [Organization MyOrg, SkipRandomPassword]
+`), 0600)).To(Succeed())
+			})
+
+			It("prefers flags to environment variables, and environment variables to the config file", func() {
+				GinkgoT().Setenv("STEMBUILD_VCENTER_USERNAME", "env-user")
+				GinkgoT().Setenv("STEMBUILD_VCENTER_PASSWORD", "env-password")
+				Expect(f.Parse([]string{"-config", configFile, "-vcenter-password", "flag-password"})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.VCenterUrl).To(Equal("file.example.com"))
+				Expect(sourceConfig.VCenterUsername).To(Equal("env-user"))
+				Expect(sourceConfig.VCenterPassword).To(Equal("flag-password"))
+				Expect(sourceConfig.InstallUpdates).To(BeTrue())
+				Expect(sourceConfig.MaxUpdateRounds).To(Equal(3))
+				Expect(sourceConfig.Timeouts.Shutdown).To(Equal(2 * time.Hour))
+				Expect(sourceConfig.PostRebootFlags).To(Equal([]string{"Organization MyOrg", "SkipRandomPassword"}))
+			})
+
+			It("reads the config file named by STEMBUILD_CONFIG", func() {
+				GinkgoT().Setenv("STEMBUILD_CONFIG", configFile)
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.VCenterUrl).To(Equal("file.example.com"))
+			})
+
+			It("fails before preparing the VM when the config file sets an unknown flag", func() {
+				Expect(os.WriteFile(configFile, []byte("vcenter-adress: vcenter.example.com\n"), 0600)).To(Succeed())
+				Expect(f.Parse([]string{"-config", configFile})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+
+				Expect(fakeMessenger.InvalidConfigCallCount()).To(Equal(1))
+				Expect(fakeMessenger.InvalidConfigArgsForCall(0)).To(MatchError(fmt.Sprintf("config file %s sets unknown flag vcenter-adress", configFile)))
+				Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+			})
+
+			It("fails when the config file cannot be read", func() {
+				Expect(f.Parse([]string{"-config", filepath.Join(GinkgoT().TempDir(), "missing.yml")})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidConfigArgsForCall(0)).To(MatchError(HavePrefix("could not read config file: ")))
+			})
+
+			It("fails when an environment variable has an invalid value", func() {
+				GinkgoT().Setenv("STEMBUILD_MAX_UPDATE_ROUNDS", "none")
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidConfigArgsForCall(0)).To(MatchError(`invalid value "none" for STEMBUILD_MAX_UPDATE_ROUNDS: must be a number of at least 1`))
+			})
+		})
+
+		Context("with password sources", func() {
+			BeforeEach(func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+			})
+
+			It("reads the passwords from a file and an environment variable", func() {
+				passwordFile := filepath.Join(GinkgoT().TempDir(), "vm-password")
+				Expect(os.WriteFile(passwordFile, []byte("vm-secret\n"), 0600)).To(Succeed())
+				GinkgoT().Setenv("CONSTRUCT_TEST_VCENTER_PASSWORD", "vcenter-secret")
+				Expect(f.Parse([]string{"-vm-password-from", "file:" + passwordFile, "-vcenter-password-from", "env:CONSTRUCT_TEST_VCENTER_PASSWORD"})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.GuestVMPassword).To(Equal("vm-secret"))
+				Expect(sourceConfig.VCenterPassword).To(Equal("vcenter-secret"))
+			})
+
+			It("reads a password from stdin", func() {
+				stdin := filepath.Join(GinkgoT().TempDir(), "stdin")
+				Expect(os.WriteFile(stdin, []byte("vm-secret\n"), 0600)).To(Succeed())
+				var err error
+				ConstrCmd.Stdin, err = os.Open(stdin)
+				Expect(err).NotTo(HaveOccurred())
+				defer ConstrCmd.Stdin.Close()
+				Expect(f.Parse([]string{"-vm-password-from", "stdin"})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.GuestVMPassword).To(Equal("vm-secret"))
+			})
+
+			It("prefers a source on the command line to a password in the environment or the config file", func() {
+				GinkgoT().Setenv("STEMBUILD_VM_PASSWORD", "env-password")
+				GinkgoT().Setenv("CONSTRUCT_TEST_VM_PASSWORD", "vm-secret")
+				GinkgoT().Setenv("CONSTRUCT_TEST_VCENTER_PASSWORD", "vcenter-secret")
+				configFile := filepath.Join(GinkgoT().TempDir(), "construct.yml")
+				Expect(os.WriteFile(configFile, []byte("vcenter-password: file-password\n"), 0600)).To(Succeed())
+				Expect(f.Parse([]string{"-config", configFile, "-vm-password-from", "env:CONSTRUCT_TEST_VM_PASSWORD", "-vcenter-password-from", "env:CONSTRUCT_TEST_VCENTER_PASSWORD"})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.GuestVMPassword).To(Equal("vm-secret"))
+				Expect(sourceConfig.VCenterPassword).To(Equal("vcenter-secret"))
+			})
+
+			It("prefers a password on the command line to a source in the environment", func() {
+				GinkgoT().Setenv("STEMBUILD_VM_PASSWORD_FROM", "env:CONSTRUCT_TEST_UNSET")
+				Expect(f.Parse([]string{"-vm-password", "flag-password"})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.GuestVMPassword).To(Equal("flag-password"))
+			})
+
+			It("prefers a source in the environment to a password in the config file", func() {
+				GinkgoT().Setenv("STEMBUILD_VM_PASSWORD_FROM", "env:CONSTRUCT_TEST_VM_PASSWORD")
+				GinkgoT().Setenv("CONSTRUCT_TEST_VM_PASSWORD", "vm-secret")
+				configFile := filepath.Join(GinkgoT().TempDir(), "construct.yml")
+				Expect(os.WriteFile(configFile, []byte("vm-password: file-password\n"), 0600)).To(Succeed())
+				Expect(f.Parse([]string{"-config", configFile})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.GuestVMPassword).To(Equal("vm-secret"))
+			})
+
+			It("fails when a password and its source are both in the environment", func() {
+				GinkgoT().Setenv("STEMBUILD_VM_PASSWORD", "env-password")
+				GinkgoT().Setenv("STEMBUILD_VM_PASSWORD_FROM", "env:CONSTRUCT_TEST_VM_PASSWORD")
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.CannotReadPasswordArgsForCall(0)).To(MatchError("-vm-password and -vm-password-from cannot both be given"))
+			})
+
+			DescribeTable("fails before preparing the VM when the passwords cannot be read",
+				func(args []string, expectedError string) {
+					Expect(f.Parse(args)).To(Succeed())
+
+					exitStatus := ConstrCmd.Execute(emptyContext, f)
+					Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+
+					Expect(fakeMessenger.CannotReadPasswordCallCount()).To(Equal(1))
+					Expect(fakeMessenger.CannotReadPasswordArgsForCall(0)).To(MatchError(expectedError))
+					Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+				},
+				Entry("with a password and a source", []string{"-vm-password", "secret", "-vm-password-from", "env:VM_PASSWORD"},
+					"-vm-password and -vm-password-from cannot both be given"),
+				Entry("with both passwords from stdin", []string{"-vm-password-from", "stdin", "-vcenter-password-from", "stdin"},
+					"-vcenter-password-from and -vm-password-from cannot both read from stdin"),
+				Entry("with an invalid source", []string{"-vm-password-from", "secret"},
+					`-vm-password-from: "secret" must be file:<path>, env:<name> or stdin`),
+				Entry("with an unset environment variable", []string{"-vcenter-password-from", "env:CONSTRUCT_TEST_UNSET"},
+					"-vcenter-password-from: environment variable CONSTRUCT_TEST_UNSET is not set"),
+			)
+
+			It("does not read stdin when it is disabled", func() {
+				ConstrCmd.Stdin = nil
+				Expect(f.Parse([]string{"-vm-password-from", "stdin"})).To(Succeed())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.CannotReadPasswordArgsForCall(0)).To(MatchError("-vm-password-from: stdin is not available"))
+			})
+		})
+
+		Context("with an LGPO source", func() {
+			BeforeEach(func() {
+				fakeValidator.PopulatedArgsReturns(true)
+			})
+
+			It("does not look for LGPO.zip in the current directory and passes the verified copy on", func() {
+				fakeValidator.ResolveLGPOReturns("/cache/LGPO-abc.zip", nil)
+				err := f.Parse([]string{"-lgpo-url", "https://example.com/LGPO.zip", "-lgpo-sha256", strings.Repeat("a", 64)})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				Expect(fakeValidator.LGPOInDirectoryCallCount()).To(Equal(0))
+				Expect(fakeValidator.ResolveLGPOArgsForCall(0)).To(Equal(lgpo.Source{URL: "https://example.com/LGPO.zip", SHA256: strings.Repeat("a", 64)}))
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.LGPO).To(Equal(lgpo.Source{Path: "/cache/LGPO-abc.zip"}))
+			})
+
+			It("fails before resolving LGPO.zip when the flags are invalid", func() {
+				err := f.Parse([]string{"-lgpo-url", "https://example.com/LGPO.zip"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidLGPOArgsForCall(0)).To(MatchError("a SHA-256 is required to download from a URL"))
+				Expect(fakeValidator.ResolveLGPOCallCount()).To(Equal(0))
+			})
+
+			It("fails before preparing the VM when LGPO.zip cannot be verified", func() {
+				fakeValidator.ResolveLGPOReturns("", errors.New("LGPO.zip does not contain LGPO.exe"))
+				err := f.Parse([]string{"-lgpo-path", "LGPO.zip"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidLGPOArgsForCall(0)).To(MatchError("LGPO.zip does not contain LGPO.exe"))
+				Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("with an automation zip", func() {
+			BeforeEach(func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+
+				err := f.Parse([]string{"-automation-zip", "custom.zip"})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("validates the archive", func() {
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				Expect(fakeValidator.AutomationZipArgsForCall(0)).To(Equal("custom.zip"))
+			})
+
+			It("fails before preparing the VM when the archive is invalid", func() {
+				fakeValidator.AutomationZipReturns(errors.New("missing Setup.ps1"))
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidAutomationZipArgsForCall(0)).To(MatchError("missing Setup.ps1"))
+				Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+			})
+		})
+
+		It("does not validate an automation zip when none is given", func() {
+			fakeValidator.PopulatedArgsReturns(true)
+			fakeValidator.LGPOInDirectoryReturns(true)
+
+			ConstrCmd.Execute(emptyContext, f)
+
+			Expect(fakeValidator.AutomationZipCallCount()).To(Equal(0))
+		})
+
+		Context("with invalid WinRM options", func() {
+			It("fails before preparing the VM", func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+
+				err := f.Parse([]string{"-winrm-auth", "kerberos"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidWinRMOptionsArgsForCall(0)).To(MatchError("auth must be one of basic, ntlm"))
+				Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when using the guestops transport", func() {
+			It("does not require a VM IP", func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+
+				err := f.Parse([]string{"-transport", "guestops", "-vm-username", "Admin"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				Expect(fakeValidator.PopulatedArgsArgsForCall(0)).To(HaveLen(6))
+			})
+		})
+
+		Context("when cloning", func() {
+			BeforeEach(func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+			})
+
+			It("does not require a VM IP for a clone that gets its address from DHCP", func() {
+				err := f.Parse([]string{"-clone-from", "/dc/vm/golden", "-vm-username", "Admin"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				Expect(fakeValidator.PopulatedArgsArgsForCall(0)).To(HaveLen(6))
+			})
+
+			It("requires a VM IP for a clone with a static address", func() {
+				err := f.Parse([]string{"-clone-from", "/dc/vm/golden", "-clone-netmask", "255.255.255.0", "-clone-gateway", "10.0.0.1"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				Expect(fakeValidator.PopulatedArgsArgsForCall(0)).To(HaveLen(7))
+			})
+
+			It("rejects a static address without a gateway", func() {
+				err := f.Parse([]string{"-clone-from", "/dc/vm/golden", "-clone-netmask", "255.255.255.0"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidCloneNetworkCallCount()).To(Equal(1))
+				Expect(fakeVmConstruct.PrepareVMCallCount()).To(Equal(0))
+			})
+
+			It("rejects clone network settings without a VM to clone from", func() {
+				err := f.Parse([]string{"-clone-gateway", "10.0.0.1"})
+				Expect(err).ToNot(HaveOccurred())
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.InvalidCloneNetworkCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("with LGPO.zip not in current directory", func() {
 			It("should return an error", func() {
 				fakeValidator.PopulatedArgsReturns(true)
@@ -213,5 +845,97 @@ var _ = Describe("construct", func() {
 				Expect(fakeMessenger.CannotPrepareVMCallCount()).To(Equal(1))
 			})
 		})
+
+		Context("when construct is aborted", func() {
+			BeforeEach(func() {
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+			})
+
+			It("passes a context to the factory that is done once -timeout has passed", func() {
+				Expect(f.Parse([]string{"-timeout", "10ms"})).To(Succeed())
+				fakeVmConstruct.PrepareVMStub = func() error {
+					ctx, _, _ := fakeFactory.VMPreparerArgsForCall(0)
+					<-ctx.Done()
+					return fmt.Errorf("interrupted during step reboot: %w", ctx.Err())
+				}
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.CannotPrepareVMArgsForCall(0)).To(MatchError("construct did not finish within -timeout 10ms: interrupted during step reboot: context deadline exceeded"))
+			})
+
+			It("explains that construct was aborted when its context is cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				ConstrCmd = commandparser.NewConstructCmd(ctx, fakeFactory, fakeManagerFactory, fakeValidator, fakeMessenger)
+				f = flag.NewFlagSet("test", flag.ExitOnError)
+				ConstrCmd.SetFlags(f)
+				ConstrCmd.GlobalFlags = gf
+				fakeVmConstruct.PrepareVMStub = func() error {
+					cancel()
+					return errors.New("interrupted during step reboot: context canceled")
+				}
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.CannotPrepareVMArgsForCall(0)).To(MatchError("construct was aborted: interrupted during step reboot: context canceled"))
+			})
+
+			It("has no deadline without -timeout", func() {
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				ctx, _, _ := fakeFactory.VMPreparerArgsForCall(0)
+				_, hasDeadline := ctx.Deadline()
+				Expect(hasDeadline).To(BeFalse())
+			})
+		})
+
+		Context("with JSON output", func() {
+			var eventOutput *bytes.Buffer
+
+			BeforeEach(func() {
+				gf.Output = events.FormatJSON
+				eventOutput = &bytes.Buffer{}
+				ConstrCmd.EventOutput = eventOutput
+				fakeValidator.PopulatedArgsReturns(true)
+				fakeValidator.LGPOInDirectoryReturns(true)
+			})
+
+			lastEvent := func() events.Event {
+				lines := strings.Split(strings.TrimSpace(eventOutput.String()), "\n")
+				var event events.Event
+				ExpectWithOffset(1, json.Unmarshal([]byte(lines[len(lines)-1]), &event)).To(Succeed())
+				return event
+			}
+
+			It("asks the VM preparer for JSON events and ends with a successful result", func() {
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+				_, sourceConfig, _ := fakeFactory.VMPreparerArgsForCall(0)
+				Expect(sourceConfig.OutputFormat).To(Equal(events.FormatJSON))
+
+				result := lastEvent()
+				Expect(result.Type).To(Equal(events.TypeResult))
+				Expect(result.Command).To(Equal("construct"))
+				Expect(result.Status).To(Equal(events.StatusSucceeded))
+			})
+
+			It("ends with a failed result instead of a message", func() {
+				fakeVmConstruct.PrepareVMReturns(errors.New("some error"))
+
+				exitStatus := ConstrCmd.Execute(emptyContext, f)
+
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+				Expect(fakeMessenger.CannotPrepareVMCallCount()).To(Equal(0))
+				result := lastEvent()
+				Expect(result.Type).To(Equal(events.TypeResult))
+				Expect(result.Status).To(Equal(events.StatusFailed))
+				Expect(result.Error).To(Equal("Could not prepare VM: some error"))
+			})
+		})
 	})
 })
@@ -1,10 +1,35 @@
 package commandparser
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/stembuild/assets"
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
 )
 
+type scriptParameter struct {
+	name     string
+	isSwitch bool
+}
+
+var (
+	paramKeyword     = regexp.MustCompile(`(?i)^\s*(?:\[\w+\([^\]]*\)\]\s*)*param\s*\(`)
+	paramAttribute   = regexp.MustCompile(`\[\w+\([^\]]*\)\]`)
+	paramDeclaration = regexp.MustCompile(`^\s*(?:\[([\w.]+)(?:\[\])?\]\s*)?\$(\w+)`)
+	scriptComment    = regexp.MustCompile(`(?m)^\s*#.*$`)
+)
+
+// stemcellAutomationFiles are the files construct runs from StemcellAutomation.zip
+var stemcellAutomationFiles = []string{"Setup.ps1", "PostReboot.ps1", "bosh-psmodules.zip"}
+
 type ConstructValidator struct{}
 
 func (c *ConstructValidator) PopulatedArgs(args ...string) bool {
@@ -22,3 +47,144 @@ func (c *ConstructValidator) LGPOInDirectory() bool {
 
 	return err == nil
 }
+
+// ResolveLGPO returns the path of a verified local copy of LGPO.zip, downloading it into the user cache directory when it comes from a URL
+func (c *ConstructValidator) ResolveLGPO(source lgpo.Source) (string, error) {
+	return source.ResolveInDefaultCache()
+}
+
+func (c *ConstructValidator) AutomationZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %s", path, err)
+	}
+	defer r.Close() //nolint:errcheck
+
+	var missing []string
+	for _, name := range stemcellAutomationFiles {
+		if _, err := fs.Stat(r, name); err != nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s does not contain %s", path, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// PostRebootArgs checks the args against the param block of PostReboot.ps1 in automationZip, or in the built-in StemcellAutomation.zip
+func (c *ConstructValidator) PostRebootArgs(automationZip string, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	postRebootScriptParameters, err := readPostRebootScriptParameters(automationZip)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		fields := strings.Fields(arg)
+		if len(fields) == 0 {
+			return fmt.Errorf("post-reboot arg cannot be empty")
+		}
+
+		name := strings.TrimPrefix(fields[0], "-")
+		parameter, ok := findScriptParameter(postRebootScriptParameters, name)
+		if !ok && len(postRebootScriptParameters) == 0 {
+			return fmt.Errorf("'%s' is not a parameter of PostReboot.ps1, which takes no parameters", name)
+		}
+		if !ok {
+			var names []string
+			for _, p := range postRebootScriptParameters {
+				names = append(names, p.name)
+			}
+			return fmt.Errorf("'%s' is not a parameter of PostReboot.ps1, expected one of: %s", name, strings.Join(names, ", "))
+		}
+		if parameter.isSwitch && len(fields) > 1 {
+			return fmt.Errorf("'%s' is a switch and does not take a value", parameter.name)
+		}
+		if !parameter.isSwitch && len(fields) == 1 {
+			return fmt.Errorf("'%s' requires a value, e.g. '%s SomeValue'", parameter.name, parameter.name)
+		}
+	}
+	return nil
+}
+
+// PowerShell parameter names are case-insensitive
+func findScriptParameter(parameters []scriptParameter, name string) (scriptParameter, bool) {
+	for _, p := range parameters {
+		if strings.EqualFold(p.name, name) {
+			return p, true
+		}
+	}
+	return scriptParameter{}, false
+}
+
+func readPostRebootScriptParameters(automationZip string) ([]scriptParameter, error) {
+	archive := assets.StemcellAutomation
+	archiveName := "the built-in StemcellAutomation.zip"
+	if automationZip != "" {
+		var err error
+		archive, err = os.ReadFile(automationZip)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %s", automationZip, err)
+		}
+		archiveName = automationZip
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %s", archiveName, err)
+	}
+	f, err := r.Open("PostReboot.ps1")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read PostReboot.ps1 from %s: %s", archiveName, err)
+	}
+	defer f.Close() //nolint:errcheck
+	script, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read PostReboot.ps1 from %s: %s", archiveName, err)
+	}
+
+	return parseScriptParameters(script), nil
+}
+
+// parseScriptParameters returns the parameters declared in the param block at the start of a PowerShell script,
+// none when it has no param block
+func parseScriptParameters(script []byte) []scriptParameter {
+	text := scriptComment.ReplaceAllString(strings.TrimPrefix(string(script), "\ufeff"), "")
+	start := paramKeyword.FindStringIndex(text)
+	if start == nil {
+		return nil
+	}
+
+	// split the block into declarations at the commas outside of attributes and default values
+	var declarations []string
+	depth, from := 0, start[1]
+	for i := start[1]; i < len(text) && depth >= 0; i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				declarations = append(declarations, text[from:i])
+			}
+		case ',':
+			if depth == 0 {
+				declarations = append(declarations, text[from:i])
+				from = i + 1
+			}
+		}
+	}
+
+	var parameters []scriptParameter
+	for _, declaration := range declarations {
+		match := paramDeclaration.FindStringSubmatch(paramAttribute.ReplaceAllString(declaration, ""))
+		if match == nil {
+			continue
+		}
+		parameters = append(parameters, scriptParameter{name: match[2], isSwitch: strings.EqualFold(match[1], "switch")})
+	}
+	return parameters
+}
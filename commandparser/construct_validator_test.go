@@ -1,12 +1,16 @@
 package commandparser_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/cloudfoundry/stembuild/assets"
 	"github.com/cloudfoundry/stembuild/commandparser"
 )
 
@@ -71,4 +75,167 @@ var _ = Describe("ConstructValidator", func() {
 			Expect(result).To(BeFalse())
 		})
 	})
+
+	Describe("AutomationZip", func() {
+		writeArchive := func(files ...string) string {
+			path := filepath.Join(GinkgoT().TempDir(), "StemcellAutomation.zip")
+			archive, err := os.Create(path)
+			Expect(err).ToNot(HaveOccurred())
+			defer archive.Close() //nolint:errcheck
+
+			w := zip.NewWriter(archive)
+			for _, file := range files {
+				_, err = w.Create(file)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(w.Close()).To(Succeed())
+			return path
+		}
+
+		It("accepts an archive with the stemcell automation scripts and modules", func() {
+			path := writeArchive("Setup.ps1", "PostReboot.ps1", "bosh-psmodules.zip", "README.md")
+
+			Expect(c.AutomationZip(path)).To(Succeed())
+		})
+
+		It("lists the files missing from the archive", func() {
+			path := writeArchive("Setup.ps1", "scripts/PostReboot.ps1")
+
+			err := c.AutomationZip(path)
+			Expect(err).To(MatchError(path + " does not contain PostReboot.ps1, bosh-psmodules.zip"))
+		})
+
+		It("rejects a file that is not a zip archive", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "StemcellAutomation.zip")
+			Expect(os.WriteFile(path, []byte("not a zip"), 0600)).To(Succeed())
+
+			err := c.AutomationZip(path)
+			Expect(err).To(MatchError(HavePrefix("cannot open " + path + ": ")))
+		})
+
+		It("rejects a missing archive", func() {
+			err := c.AutomationZip(filepath.Join(GinkgoT().TempDir(), "missing.zip"))
+			Expect(err).To(MatchError(HavePrefix("cannot open ")))
+		})
+	})
+
+	Describe("PostRebootArgs", func() {
+		var automationZip string
+
+		BeforeEach(func() {
+			script, err := os.ReadFile(filepath.Join("..", "stemcell-automation", "PostReboot.ps1"))
+			Expect(err).ToNot(HaveOccurred())
+
+			automationZip = filepath.Join(GinkgoT().TempDir(), "StemcellAutomation.zip")
+			archive, err := os.Create(automationZip)
+			Expect(err).ToNot(HaveOccurred())
+			defer archive.Close() //nolint:errcheck
+
+			w := zip.NewWriter(archive)
+			f, err := w.Create("PostReboot.ps1")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.Write(script)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+		})
+
+		It("accepts values for string parameters and bare switches", func() {
+			err := c.PostRebootArgs(automationZip, []string{"Organization SomeOrg", "-Owner 'Some Owner'", "Owner Some Owner", "SkipRandomPassword"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("matches parameter names case-insensitively", func() {
+			Expect(c.PostRebootArgs(automationZip, []string{"organization SomeOrg"})).To(Succeed())
+		})
+
+		It("rejects a parameter the script does not have", func() {
+			err := c.PostRebootArgs(automationZip, []string{"Version 2019.1"})
+			Expect(err).To(MatchError("'Version' is not a parameter of PostReboot.ps1, expected one of: Organization, Owner, SkipRandomPassword"))
+		})
+
+		It("rejects a string parameter without a value", func() {
+			err := c.PostRebootArgs(automationZip, []string{"Organization"})
+			Expect(err).To(MatchError(ContainSubstring("'Organization' requires a value")))
+		})
+
+		It("rejects a switch with a value", func() {
+			err := c.PostRebootArgs(automationZip, []string{"SkipRandomPassword true"})
+			Expect(err).To(MatchError("'SkipRandomPassword' is a switch and does not take a value"))
+		})
+
+		It("rejects an empty arg", func() {
+			Expect(c.PostRebootArgs(automationZip, []string{" "})).ToNot(Succeed())
+		})
+
+		It("does not read the archive when there are no args", func() {
+			Expect(c.PostRebootArgs(filepath.Join(GinkgoT().TempDir(), "missing.zip"), nil)).To(Succeed())
+		})
+
+		It("returns an error when the archive cannot be read", func() {
+			err := c.PostRebootArgs(filepath.Join(GinkgoT().TempDir(), "missing.zip"), []string{"Organization SomeOrg"})
+			Expect(err).To(MatchError(HavePrefix("cannot read ")))
+		})
+
+		It("checks the args against the built-in archive without -automation-zip", func() {
+			script := readBuiltInPostReboot()
+			parameters := commandparser.ParseScriptParameters(script)
+
+			err := c.PostRebootArgs("", []string{"Version 2019.1"})
+			if len(parameters) == 0 {
+				Expect(err).To(MatchError("'Version' is not a parameter of PostReboot.ps1, which takes no parameters"))
+			} else {
+				Expect(err).To(MatchError(HavePrefix("'Version' is not a parameter of PostReboot.ps1, expected one of: ")))
+			}
+		})
+	})
+
+	Describe("parsing the param block of a script", func() {
+		It("reads the parameters of PostReboot.ps1", func() {
+			script, err := os.ReadFile(filepath.Join("..", "stemcell-automation", "PostReboot.ps1"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(commandparser.ParseScriptParameters(script)).To(Equal(map[string]bool{
+				"Organization":       false,
+				"Owner":              false,
+				"SkipRandomPassword": true,
+			}))
+		})
+
+		It("skips attributes, comments and default values", func() {
+			script := []byte("\ufeff# Provisions the VM\n" +
+				"[CmdletBinding()]\n" +
+				"Param(\n" +
+				"    # the organization\n" +
+				"    [Parameter(Mandatory = $true, Position = 0)]\n" +
+				"    [ValidateSet('a', 'b')]\n" +
+				"    [string]$Organization,\n" +
+				"    [string[]]$Tags = @('x', 'y'),\n" +
+				"    $Untyped = (Get-Date),\n" +
+				"    [Switch] $Quiet\n" +
+				")\n" +
+				"Write-Host $Organization\n")
+
+			Expect(commandparser.ParseScriptParameters(script)).To(Equal(map[string]bool{
+				"Organization": false,
+				"Tags":         false,
+				"Untyped":      false,
+				"Quiet":        true,
+			}))
+		})
+
+		It("finds no parameters in a script without a param block", func() {
+			Expect(commandparser.ParseScriptParameters([]byte("Write-Host 'param(x)'\n"))).To(BeEmpty())
+		})
+	})
 })
+
+func readBuiltInPostReboot() []byte {
+	r, err := zip.NewReader(bytes.NewReader(assets.StemcellAutomation), int64(len(assets.StemcellAutomation)))
+	Expect(err).ToNot(HaveOccurred())
+	f, err := r.Open("PostReboot.ps1")
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close() //nolint:errcheck
+	script, err := io.ReadAll(f)
+	Expect(err).ToNot(HaveOccurred())
+	return script
+}
@@ -5,3 +5,12 @@ import "github.com/cloudfoundry/stembuild/construct/config"
 func (p *ConstructCmd) GetSourceConfig() config.SourceConfig {
 	return p.sourceConfig
 }
+
+// ParseScriptParameters returns the parameters in the param block of script, mapped to whether each is a switch
+func ParseScriptParameters(script []byte) map[string]bool {
+	parameters := map[string]bool{}
+	for _, p := range parseScriptParameters(script) {
+		parameters[p.name] = p.isSwitch
+	}
+	return parameters
+}
@@ -1,7 +1,41 @@
 package commandparser
 
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/stembuild/events"
+)
+
 type GlobalFlags struct {
 	Debug       bool
 	Color       bool
 	ShowVersion bool
+	// Output is the format construct and package report progress in, events.FormatText by default or events.FormatJSON
+	Output string
+}
+
+// JSONOutput tells whether construct and package report progress as JSON events
+func (g *GlobalFlags) JSONOutput() bool {
+	return g != nil && g.Output == events.FormatJSON
+}
+
+// OutputValue is the -output flag, which accepts text or json
+type OutputValue struct {
+	GlobalFlags *GlobalFlags
+}
+
+func (v OutputValue) String() string {
+	if v.GlobalFlags == nil || v.GlobalFlags.Output == "" {
+		return events.FormatText
+	}
+	return v.GlobalFlags.Output
+}
+
+func (v OutputValue) Set(s string) error {
+	switch s {
+	case events.FormatText, events.FormatJSON:
+		v.GlobalFlags.Output = s
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s", events.FormatText, events.FormatJSON)
 }
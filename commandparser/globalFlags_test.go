@@ -0,0 +1,41 @@
+package commandparser_test
+
+import (
+	"flag"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/commandparser"
+)
+
+var _ = Describe("GlobalFlags", func() {
+	Describe("-output", func() {
+		var (
+			gf commandparser.GlobalFlags
+			f  *flag.FlagSet
+		)
+
+		BeforeEach(func() {
+			gf = commandparser.GlobalFlags{}
+			f = flag.NewFlagSet("test", flag.ContinueOnError)
+			f.Var(commandparser.OutputValue{GlobalFlags: &gf}, "output", "")
+		})
+
+		It("defaults to text", func() {
+			Expect(f.Parse(nil)).To(Succeed())
+			Expect(f.Lookup("output").Value.String()).To(Equal("text"))
+			Expect(gf.JSONOutput()).To(BeFalse())
+		})
+
+		It("accepts json", func() {
+			Expect(f.Parse([]string{"-output", "json"})).To(Succeed())
+			Expect(gf.JSONOutput()).To(BeTrue())
+		})
+
+		It("rejects other formats", func() {
+			f.SetOutput(GinkgoWriter)
+			Expect(f.Parse([]string{"-output", "yaml"})).To(MatchError(ContainSubstring("must be one of text, json")))
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package commandparser
+
+import (
+	"flag"
+
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+)
+
+// setLGPOFlags registers the flags shared by every command that needs LGPO.zip
+func setLGPOFlags(f *flag.FlagSet, source *lgpo.Source) {
+	f.StringVar(&source.Path, "lgpo-path", "", "filepath of LGPO.zip, default is LGPO.zip in the current working directory")
+	f.StringVar(&source.URL, "lgpo-url", "", "URL to download LGPO.zip from into the stembuild cache directory, requires -lgpo-sha256")
+	f.StringVar(&source.SHA256, "lgpo-sha256", "", "expected SHA-256 checksum of LGPO.zip")
+}
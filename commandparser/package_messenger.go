@@ -3,29 +3,73 @@ package commandparser
 import (
 	"fmt"
 	"io"
+
+	"github.com/cloudfoundry/stembuild/events"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 type PackageMessenger struct {
 	Output io.Writer
+	// Events receives every error as a failed result instead of Output, when set
+	Events *events.Emitter
 }
 
-func (m *PackageMessenger) InvalidOutputConfig(e error) {
+func (m *PackageMessenger) printError(e error) {
+	if m.Events != nil {
+		m.Events.Failed(e)
+		return
+	}
 	fmt.Fprintln(m.Output, e)
 }
 
+func (m *PackageMessenger) InvalidOutputConfig(e error) {
+	m.printError(e)
+}
+
 func (m *PackageMessenger) CannotCreatePackager(e error) {
-	fmt.Fprintln(m.Output, e)
+	m.printError(e)
 }
 
 func (m *PackageMessenger) DoesNotHaveEnoughSpace(e error) {
-	fmt.Fprintln(m.Output, e)
+	m.printError(e)
 }
 
 func (m *PackageMessenger) SourceParametersAreInvalid(e error) {
-	fmt.Fprintln(m.Output, e)
+	m.printError(e)
 }
 
 func (m *PackageMessenger) PackageFailed(e error) {
-	fmt.Fprintln(m.Output, e)
-	fmt.Fprintln(m.Output, "Please provide the error logs to bosh-windows-eng@pivotal.io")
+	m.printError(e)
+	if m.Events == nil {
+		fmt.Fprintln(m.Output, "Please provide the error logs to bosh-windows-eng@pivotal.io")
+	}
+}
+
+func (m *PackageMessenger) InvalidConfig(e error) {
+	m.printError(e)
+}
+
+func (m *PackageMessenger) CannotReadPassword(e error) {
+	m.printError(e)
+}
+
+// BuildReportWritten prints a summary of the report, which a JSON result names instead
+func (m *PackageMessenger) BuildReportWritten(path string, r report.Report) {
+	if m.Events != nil {
+		return
+	}
+	if len(r.Construct) > 0 {
+		report.PrintSummary(m.Output, "Construct step timings", r.Construct)
+	}
+	report.PrintSummary(m.Output, "Package step timings", r.Package)
+	fmt.Fprintf(m.Output, "\nBuild report written to %s\n", path)
+}
+
+func (m *PackageMessenger) BuildReportNotWritten(e error) {
+	message := fmt.Sprintf("could not write the build report: %s", e)
+	if m.Events != nil {
+		m.Events.Warning("", message)
+		return
+	}
+	fmt.Fprintf(m.Output, "Warning: %s\n", message)
 }
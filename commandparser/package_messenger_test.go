@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/events"
+	"github.com/cloudfoundry/stembuild/report"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -45,10 +47,58 @@ var _ = Describe("PackageMessenger", func() {
 		Eventually(buf).Should(Say(message))
 	})
 
+	It("writes the error message to the writer when CannotReadPassword is called", func() {
+		message := "-vcenter-password-from: stdin is not available"
+		messenger.CannotReadPassword(errors.New(message))
+		Eventually(buf).Should(Say(message))
+	})
+
+	It("writes the error message to the writer when InvalidConfig is called", func() {
+		message := "config file package.yml sets unknown flag o"
+		messenger.InvalidConfig(errors.New(message))
+		Eventually(buf).Should(Say(message))
+	})
+
 	It("writes the error messages to the writer when PackageFailed is called", func() {
 		message := "package failed"
 		messenger.PackageFailed(errors.New(message))
 		Eventually(buf).Should(Say(message))
 		Eventually(buf).Should(Say("Please provide the error logs to bosh-windows-eng@pivotal.io"))
 	})
+
+	It("reports the error as a failed result instead when Events is set", func() {
+		eventBuf := NewBuffer()
+		messenger.Events = events.NewEmitter(eventBuf, "package")
+
+		messenger.PackageFailed(errors.New("package failed"))
+
+		Expect(buf.Contents()).To(BeEmpty())
+		Eventually(eventBuf).Should(Say(`"type":"result".*"status":"failed".*"error":"package failed"`))
+	})
+
+	It("summarizes the step timings when BuildReportWritten is called", func() {
+		messenger.BuildReportWritten("stemcell.tgz.report.json", report.Report{
+			Construct: []report.Step{{Name: "enable-winrm", Duration: 3}},
+			Package:   []report.Step{{Name: "export", Duration: 60}},
+		})
+
+		Expect(buf).To(Say("Construct step timings:\n  enable-winrm +3s\n"))
+		Expect(buf).To(Say("Package step timings:\n  export +1m0s\n"))
+		Expect(buf).To(Say("Build report written to stemcell.tgz.report.json"))
+	})
+
+	It("warns when BuildReportNotWritten is called", func() {
+		messenger.BuildReportNotWritten(errors.New("disk full"))
+		Expect(buf).To(Say("Warning: could not write the build report: disk full"))
+	})
+
+	It("reports a report that could not be written as a warning event when Events is set", func() {
+		eventBuf := NewBuffer()
+		messenger.Events = events.NewEmitter(eventBuf, "package")
+
+		messenger.BuildReportNotWritten(errors.New("disk full"))
+
+		Expect(buf.Contents()).To(BeEmpty())
+		Expect(eventBuf).To(Say(`"status":"warning".*"message":"could not write the build report: disk full"`))
+	})
 })
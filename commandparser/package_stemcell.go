@@ -4,14 +4,18 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/google/subcommands"
 
 	"github.com/cloudfoundry/stembuild/colorlogger"
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/filesystem"
 	"github.com/cloudfoundry/stembuild/package_stemcell/config"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 //counterfeiter:generate . OSAndVersionGetter
@@ -23,7 +27,7 @@ type OSAndVersionGetter interface {
 
 //counterfeiter:generate . PackagerFactory
 type PackagerFactory interface {
-	Packager(sourceConfig config.SourceConfig, outputConfig config.OutputConfig, logger colorlogger.Logger) (Packager, error)
+	Packager(ctx context.Context, sourceConfig config.SourceConfig, outputConfig config.OutputConfig, logger colorlogger.Logger) (Packager, error)
 }
 
 //counterfeiter:generate . Packager
@@ -31,6 +35,7 @@ type Packager interface {
 	Package() error
 	ValidateFreeSpaceForPackage(fs filesystem.FileSystem) error
 	ValidateSourceParameters() error
+	StepTimings() []report.Step
 }
 
 //counterfeiter:generate . PackagerMessenger
@@ -40,12 +45,26 @@ type PackagerMessenger interface {
 	DoesNotHaveEnoughSpace(error)
 	SourceParametersAreInvalid(error)
 	PackageFailed(error)
+	InvalidConfig(error)
+	CannotReadPassword(error)
+	BuildReportWritten(path string, r report.Report)
+	BuildReportNotWritten(error)
 }
 
 type PackageCmd struct {
-	GlobalFlags        *GlobalFlags
+	GlobalFlags *GlobalFlags
+	// LogOutput receives the debug log, default is os.Stderr
+	LogOutput io.Writer
+	// Stdin is read for a password given as -vcenter-password-from stdin, and prompted for when missing. Nil disables both.
+	Stdin *os.File
+	// EventOutput receives the JSON events of -output json, default is os.Stdout
+	EventOutput        io.Writer
 	sourceConfig       config.SourceConfig
 	outputConfig       config.OutputConfig
+	patchVersion       string
+	configFile         string
+	stateFile          string
+	vCenterPassword    passwordFlag
 	osAndVersionGetter OSAndVersionGetter
 	packagerFactory    PackagerFactory
 	packagerMessenger  PackagerMessenger
@@ -53,14 +72,15 @@ type PackageCmd struct {
 
 func NewPackageCommand(o OSAndVersionGetter, p PackagerFactory, m PackagerMessenger) *PackageCmd {
 	return &PackageCmd{
+		LogOutput:          os.Stderr,
+		Stdin:              os.Stdin,
+		EventOutput:        os.Stdout,
 		osAndVersionGetter: o,
 		packagerFactory:    p,
 		packagerMessenger:  m,
 	}
 }
 
-var patchVersion string
-
 func (*PackageCmd) Name() string { return "package" }
 func (*PackageCmd) Synopsis() string {
 	return "Create a BOSH Stemcell from a VMDK file or a provisioned vCenter VM"
@@ -97,6 +117,23 @@ VMDK:
     Will create an Windows 1803 stemcell using [vmdk] 'my-1803-vmdk.vmdk'
     The final stemcell will be found in the current working directory.
 
+Passwords:
+
+  -vcenter-password-from reads the vCenter password from file:<path>, env:<name> or stdin instead of -vcenter-password,
+  which is visible to other users of this machine. When it is missing it is prompted for if stdin is a terminal.
+
+Build report:
+
+  The start, end and duration of every step are written to <stemcell>.report.json next to the stemcell, and summarized
+  when package finishes. For a vCenter VM the report includes the steps of the construct that provisioned it, when
+  construct recorded them in the same -state-file on this machine.
+
+Configuration:
+
+  Any flag can instead be given in a YAML file passed with -config, or $STEMBUILD_CONFIG, as the flag name and its value,
+  or in an environment variable named STEMBUILD_ and the flag name in upper case, e.g. STEMBUILD_VCENTER_PASSWORD or STEMBUILD_OUTPUTDIR.
+  Flags on the command line take precedence over environment variables, which take precedence over the config file.
+
 Flags:
 `, filepath.Base(os.Args[0]))
 }
@@ -105,63 +142,169 @@ func (p *PackageCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&p.sourceConfig.Vmdk, "vmdk", "", "VMDK file to create stemcell from")
 	f.StringVar(&p.sourceConfig.VmInventoryPath, "vm-inventory-path", "", "vCenter VM inventory path. (e.g: <datacenter>/vm/<vm-folder>/<vm-name>)")
 	f.StringVar(&p.sourceConfig.Username, "vcenter-username", "", "vCenter username")
-	f.StringVar(&p.sourceConfig.Password, "vcenter-password", "", "vCenter password")
+	f.StringVar(&p.sourceConfig.Password, "vcenter-password", "", "vCenter password, visible to other users of this machine; prefer -vcenter-password-from")
+	p.vCenterPassword = passwordFlag{name: "vcenter-password", label: "vCenter password", value: &p.sourceConfig.Password}
+	setPasswordFromFlag(f, &p.vCenterPassword)
 	f.StringVar(&p.sourceConfig.URL, "vcenter-url", "", "vCenter url")
 	f.StringVar(&p.sourceConfig.CaCertFile, "vcenter-ca-certs", "", "filepath for custom ca certs")
 
 	f.StringVar(&p.outputConfig.OutputDir, "outputDir", "", "Output directory, default is the current working directory.")
 	f.StringVar(&p.outputConfig.OutputDir, "o", "", "Output directory (shorthand)")
-	f.StringVar(&patchVersion, "patch-version", "", "Number or name of the patch version for the stemcell being built (e.g: for 2019.12.3 the string would be \"3\")")
+	f.StringVar(&p.stateFile, "state-file", "", "filepath construct recorded its progress in, for the construct steps of the build report; default is construct-state.json in the user cache directory")
+	f.StringVar(&p.patchVersion, "patch-version", "", "Number or name of the patch version for the stemcell being built (e.g: for 2019.12.3 the string would be \"3\")")
+	setConfigFlag(f, &p.configFile)
 }
 
-func (p *PackageCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+func (p *PackageCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 
 	logLevel := colorlogger.NONE
 	if p.GlobalFlags.Debug {
 		logLevel = colorlogger.DEBUG
 	}
 
+	messenger := p.packagerMessenger
+	var emitter *events.Emitter
+	if p.GlobalFlags.JSONOutput() {
+		emitter = events.NewEmitter(p.EventOutput, p.Name())
+		messenger = &PackageMessenger{Events: emitter}
+		p.outputConfig.OutputFormat = events.FormatJSON
+	}
+
+	err := applyConfig(f, p.configFile, map[string]string{"o": "outputDir"})
+	if err != nil {
+		messenger.InvalidConfig(err)
+		return subcommands.ExitFailure
+	}
+
+	p.vCenterPassword.prompt = p.sourceConfig.Username != ""
+	err = resolvePasswords(p.Stdin, os.Stderr, &p.vCenterPassword)
+	if err != nil {
+		messenger.CannotReadPassword(err)
+		return subcommands.ExitFailure
+	}
+
 	p.setOSandStemcellVersions()
 
-	err := p.outputConfig.ValidateConfig()
+	timings := report.NewRecorder()
+	err = runPackageStep(emitter, timings, "validate-output", p.outputConfig.ValidateConfig)
 	if err != nil {
-		p.packagerMessenger.InvalidOutputConfig(err)
+		messenger.InvalidOutputConfig(err)
 		return subcommands.ExitFailure
 	}
 
-	logger := colorlogger.New(logLevel, p.GlobalFlags.Color, os.Stderr)
-	packager, err := p.packagerFactory.Packager(p.sourceConfig, p.outputConfig, logger)
+	logger := colorlogger.New(logLevel, p.GlobalFlags.Color, p.LogOutput)
+	packager, err := p.packagerFactory.Packager(ctx, p.sourceConfig, p.outputConfig, logger)
 	if err != nil {
-		p.packagerMessenger.CannotCreatePackager(err)
+		messenger.CannotCreatePackager(err)
 		return subcommands.ExitFailure
 	}
 
-	err = packager.ValidateFreeSpaceForPackage(&filesystem.OSFileSystem{})
+	err = runPackageStep(emitter, timings, "validate-free-space", func() error {
+		return packager.ValidateFreeSpaceForPackage(&filesystem.OSFileSystem{})
+	})
 	if err != nil {
-		p.packagerMessenger.DoesNotHaveEnoughSpace(err)
+		messenger.DoesNotHaveEnoughSpace(err)
 		return subcommands.ExitFailure
 	}
 
-	err = packager.ValidateSourceParameters()
+	err = runPackageStep(emitter, timings, "validate-source", packager.ValidateSourceParameters)
 	if err != nil {
-		p.packagerMessenger.SourceParametersAreInvalid(err)
+		messenger.SourceParametersAreInvalid(err)
 		return subcommands.ExitFailure
 	}
 
-	if err := packager.Package(); err != nil {
-		p.packagerMessenger.PackageFailed(err)
+	// the packager records its own steps, so the package step as a whole is left out of the report
+	if err := runPackageStep(emitter, nil, "package", packager.Package); err != nil {
+		messenger.PackageFailed(err)
 		return subcommands.ExitFailure
 	}
 
+	reportPath := p.writeReport(messenger, logger, report.Merge(timings.Steps(), packager.StepTimings()))
+
+	if emitter != nil {
+		stemcell, err := events.NewStemcell(p.outputConfig.StemcellPath())
+		if err != nil {
+			emitter.Failed(err)
+			return subcommands.ExitFailure
+		}
+		emitter.Succeeded(events.Result{OS: p.outputConfig.Os, Version: p.outputConfig.StemcellVersion, Stemcell: stemcell, Report: reportPath})
+	}
+
 	return subcommands.ExitSuccess
 }
 
+// writeReport writes the build report next to the stemcell and returns its path, or an empty path when it could not be written
+func (p *PackageCmd) writeReport(messenger PackagerMessenger, logger colorlogger.Logger, steps []report.Step) string {
+	stemcellPath, err := filepath.Abs(p.outputConfig.StemcellPath())
+	if err == nil {
+		_, err = os.Stat(stemcellPath)
+	}
+	if err != nil {
+		messenger.BuildReportNotWritten(fmt.Errorf("could not find the stemcell: %s", err))
+		return ""
+	}
+
+	r := report.Report{
+		Stemcell:  stemcellPath,
+		OS:        p.outputConfig.Os,
+		Version:   p.outputConfig.StemcellVersion,
+		Construct: p.constructTimings(logger),
+		Package:   steps,
+	}
+	path := report.Path(stemcellPath)
+	err = r.Write(path)
+	if err != nil {
+		messenger.BuildReportNotWritten(err)
+		return ""
+	}
+
+	messenger.BuildReportWritten(path, r)
+	return path
+}
+
+// constructTimings returns the construct steps recorded for the packaged vCenter VM, which the report goes without when they cannot be read
+func (p *PackageCmd) constructTimings(logger colorlogger.Logger) []report.Step {
+	if p.sourceConfig.VmInventoryPath == "" {
+		return nil
+	}
+
+	stateFile := p.stateFile
+	if stateFile == "" {
+		var err error
+		stateFile, err = construct.DefaultCheckpointStatePath()
+		if err != nil {
+			logger.Printf("leaving construct steps out of the build report: %s", err)
+			return nil
+		}
+	}
+
+	timings, err := construct.NewFileCheckpointStore(stateFile).LoadTimings(p.sourceConfig.VmInventoryPath)
+	if err != nil {
+		logger.Printf("leaving construct steps out of the build report: %s", err)
+		return nil
+	}
+	return timings
+}
+
+// runPackageStep runs a step of package, reporting its start and end as JSON events when they are enabled,
+// and recording its timing for the build report when timings is set
+func runPackageStep(emitter *events.Emitter, timings *report.Recorder, step string, run func() error) error {
+	emitter.StepStarted(step)
+	err := timings.Time(step, run)
+	if err != nil {
+		emitter.StepFailed(step, err)
+		return err
+	}
+	emitter.StepSucceeded(step, "")
+	return nil
+}
+
 func (p *PackageCmd) setOSandStemcellVersions() {
 	p.outputConfig.Os = p.osAndVersionGetter.GetOs()
 
-	if patchVersion == "" {
+	if p.patchVersion == "" {
 		p.outputConfig.StemcellVersion = p.osAndVersionGetter.GetVersion()
 	} else {
-		p.outputConfig.StemcellVersion = p.osAndVersionGetter.GetVersionWithPatchNumber(patchVersion)
+		p.outputConfig.StemcellVersion = p.osAndVersionGetter.GetVersionWithPatchNumber(p.patchVersion)
 	}
 }
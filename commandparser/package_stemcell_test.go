@@ -1,9 +1,15 @@
 package commandparser_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/google/subcommands"
 	. "github.com/onsi/ginkgo/v2"
@@ -11,6 +17,9 @@ import (
 
 	"github.com/cloudfoundry/stembuild/commandparser"
 	"github.com/cloudfoundry/stembuild/commandparser/commandparserfakes"
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/events"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 var _ = Describe("package_stemcell", func() {
@@ -62,10 +71,22 @@ var _ = Describe("package_stemcell", func() {
 				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
 
 				Expect(packagerFactory.PackagerCallCount()).To(Equal(1))
-				actualSourceConfig, _, _ := packagerFactory.PackagerArgsForCall(0)
+				_, actualSourceConfig, _, _ := packagerFactory.PackagerArgsForCall(0)
 				Expect(actualSourceConfig.Vmdk).To(Equal("some_vmdk_file"))
 			})
 
+			It("passes the context of the command to the packager, so Ctrl-C stops the export", func() {
+				err := f.Parse([]string{"-vmdk", "some_vmdk_file"})
+				Expect(err).ToNot(HaveOccurred())
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				PkgCmd.Execute(ctx, f)
+
+				actualCtx, _, _, _ := packagerFactory.PackagerArgsForCall(0)
+				Expect(actualCtx).To(Equal(ctx))
+			})
+
 			It("packager is instantiated with expected vcenter source config", func() {
 				vcenterArgs := []string{
 					"-vcenter-url", "https://vcenter.test",
@@ -82,7 +103,7 @@ var _ = Describe("package_stemcell", func() {
 				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
 
 				Expect(packagerFactory.PackagerCallCount()).To(Equal(1))
-				actualSourceConfig, _, _ := packagerFactory.PackagerArgsForCall(0)
+				_, actualSourceConfig, _, _ := packagerFactory.PackagerArgsForCall(0)
 				Expect(actualSourceConfig.URL).To(Equal("https://vcenter.test"))
 				Expect(actualSourceConfig.Username).To(Equal("test-user"))
 				Expect(actualSourceConfig.Password).To(Equal("verysecure"))
@@ -100,7 +121,7 @@ var _ = Describe("package_stemcell", func() {
 				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
 
 				Expect(packagerFactory.PackagerCallCount()).To(Equal(1))
-				_, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
+				_, _, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
 				Expect(actualOutputConfig.OutputDir).To(Equal("some_output_dir"))
 			})
 
@@ -114,7 +135,7 @@ var _ = Describe("package_stemcell", func() {
 				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
 
 				Expect(packagerFactory.PackagerCallCount()).To(Equal(1))
-				_, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
+				_, _, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
 				Expect(actualOutputConfig.OutputDir).To(Equal("some_output_dir"))
 				Expect(actualOutputConfig.StemcellVersion).To(Equal("2019.2"))
 				Expect(actualOutputConfig.Os).To(Equal("2019"))
@@ -132,7 +153,7 @@ var _ = Describe("package_stemcell", func() {
 				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
 
 				Expect(packagerFactory.PackagerCallCount()).To(Equal(1))
-				_, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
+				_, _, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
 				Expect(actualOutputConfig.StemcellVersion).To(Equal("1803.27.36"))
 
 				Expect(oSAndVersionGetter.GetVersionWithPatchNumberCallCount()).To(Equal(1))
@@ -207,6 +228,63 @@ var _ = Describe("package_stemcell", func() {
 				Expect(receivedError).To(MatchError("invalid source parameters"))
 			})
 
+			It("reads flags from the environment and a config file, preferring the command line", func() {
+				configFile := filepath.Join(GinkgoT().TempDir(), "package.yml")
+				Expect(os.WriteFile(configFile, []byte("vmdk: file.vmdk\npatch-version: \"7\"\noutputDir: file-output-dir\n"), 0600)).To(Succeed())
+				GinkgoT().Setenv("STEMBUILD_CONFIG", configFile)
+				GinkgoT().Setenv("STEMBUILD_OUTPUTDIR", "env-output-dir")
+				GinkgoT().Setenv("STEMBUILD_PATCH_VERSION", "8")
+				oSAndVersionGetter.GetVersionWithPatchNumberReturns("2019.2.8")
+
+				outputDir := GinkgoT().TempDir()
+				Expect(f.Parse([]string{"-o", outputDir})).To(Succeed())
+
+				exitStatus := PkgCmd.Execute(context.Background(), f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, actualSourceConfig, actualOutputConfig, _ := packagerFactory.PackagerArgsForCall(0)
+				Expect(actualSourceConfig.Vmdk).To(Equal("file.vmdk"))
+				Expect(actualOutputConfig.OutputDir).To(Equal(outputDir))
+				Expect(oSAndVersionGetter.GetVersionWithPatchNumberArgsForCall(0)).To(Equal("8"))
+			})
+
+			It("reads the vCenter password from a file", func() {
+				passwordFile := filepath.Join(GinkgoT().TempDir(), "vcenter-password")
+				Expect(os.WriteFile(passwordFile, []byte("verysecure\n"), 0600)).To(Succeed())
+
+				Expect(f.Parse([]string{"-vcenter-username", "test-user", "-vcenter-password-from", "file:" + passwordFile})).To(Succeed())
+
+				exitStatus := PkgCmd.Execute(context.Background(), f)
+				Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+				_, actualSourceConfig, _, _ := packagerFactory.PackagerArgsForCall(0)
+				Expect(actualSourceConfig.Password).To(Equal("verysecure"))
+			})
+
+			It("package is not called if the vCenter password cannot be read", func() {
+				Expect(f.Parse([]string{"-vcenter-password-from", "env:PACKAGE_TEST_UNSET"})).To(Succeed())
+
+				exitStatus := PkgCmd.Execute(context.Background(), f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+
+				Expect(packagerFactory.PackagerCallCount()).To(Equal(0))
+				Expect(packagerMessenger.CannotReadPasswordArgsForCall(0)).To(MatchError("-vcenter-password-from: environment variable PACKAGE_TEST_UNSET is not set"))
+			})
+
+			It("package is not called if the config file is invalid", func() {
+				configFile := filepath.Join(GinkgoT().TempDir(), "package.yml")
+				Expect(os.WriteFile(configFile, []byte("o: some-output-dir\n"), 0600)).To(Succeed())
+
+				Expect(f.Parse([]string{"-config", configFile})).To(Succeed())
+
+				exitStatus := PkgCmd.Execute(context.Background(), f)
+				Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+
+				Expect(packagerFactory.PackagerCallCount()).To(Equal(0))
+				Expect(packagerMessenger.InvalidConfigCallCount()).To(Equal(1))
+				Expect(packagerMessenger.InvalidConfigArgsForCall(0)).To(MatchError(ContainSubstring("sets unknown flag o")))
+			})
+
 			It("exits with failure if package returns an error", func() {
 				packager.PackageReturns(errors.New("Didn't make it"))
 
@@ -222,6 +300,166 @@ var _ = Describe("package_stemcell", func() {
 				receivedError := packagerMessenger.PackageFailedArgsForCall(0)
 				Expect(receivedError).To(MatchError("Didn't make it"))
 			})
+
+			Context("when the stemcell is created", func() {
+				var (
+					outputDir    string
+					stemcellPath string
+				)
+
+				BeforeEach(func() {
+					outputDir = GinkgoT().TempDir()
+					stemcellPath = filepath.Join(outputDir, "bosh-stemcell-2019.2-vsphere-esxi-windows2019-go_agent.tgz")
+					packager.PackageStub = func() error {
+						return os.WriteFile(stemcellPath, []byte("stemcell"), 0600)
+					}
+					packager.StepTimingsReturns([]report.Step{{Name: "export", Duration: 60}, {Name: "stemcell-tar", Duration: 5}})
+				})
+
+				readReport := func(path string) report.Report {
+					contents, err := os.ReadFile(path)
+					ExpectWithOffset(1, err).NotTo(HaveOccurred())
+					var r report.Report
+					ExpectWithOffset(1, json.Unmarshal(contents, &r)).To(Succeed())
+					return r
+				}
+
+				It("writes a build report next to the stemcell", func() {
+					Expect(f.Parse([]string{"-vmdk", "some_vmdk_file", "-outputDir", outputDir})).To(Succeed())
+
+					exitStatus := PkgCmd.Execute(context.Background(), f)
+					Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+					Expect(packagerMessenger.BuildReportWrittenCallCount()).To(Equal(1))
+					path, r := packagerMessenger.BuildReportWrittenArgsForCall(0)
+					Expect(path).To(Equal(stemcellPath + ".report.json"))
+					Expect(readReport(path)).To(Equal(r))
+
+					Expect(r.Stemcell).To(Equal(stemcellPath))
+					Expect(r.OS).To(Equal("2019"))
+					Expect(r.Version).To(Equal("2019.2"))
+					Expect(r.Construct).To(BeEmpty())
+					var steps []string
+					for _, step := range r.Package {
+						steps = append(steps, step.Name)
+					}
+					Expect(steps).To(Equal([]string{"validate-output", "validate-free-space", "validate-source", "export", "stemcell-tar"}))
+				})
+
+				It("includes the construct steps recorded for the vCenter VM", func() {
+					stateFile := filepath.Join(GinkgoT().TempDir(), "construct-state.json")
+					constructSteps := []report.Step{{Name: "execute-setup-script", Start: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), End: time.Date(2024, 1, 2, 3, 5, 5, 0, time.UTC), Duration: 60}}
+					store := construct.NewFileCheckpointStore(stateFile)
+					Expect(store.Save("/path/to/vm", []string{"execute-setup-script"})).To(Succeed())
+					Expect(store.SaveTimings("/path/to/vm", constructSteps)).To(Succeed())
+
+					Expect(f.Parse([]string{
+						"-vcenter-url", "https://vcenter.test",
+						"-vcenter-username", "test-user",
+						"-vcenter-password", "verysecure",
+						"-vm-inventory-path", "/path/to/vm",
+						"-state-file", stateFile,
+						"-outputDir", outputDir,
+					})).To(Succeed())
+
+					exitStatus := PkgCmd.Execute(context.Background(), f)
+					Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+					_, r := packagerMessenger.BuildReportWrittenArgsForCall(0)
+					Expect(r.Construct).To(Equal(constructSteps))
+				})
+
+				It("warns but succeeds when the report cannot be written", func() {
+					Expect(os.Mkdir(stemcellPath+".report.json", 0700)).To(Succeed())
+					Expect(f.Parse([]string{"-vmdk", "some_vmdk_file", "-outputDir", outputDir})).To(Succeed())
+
+					exitStatus := PkgCmd.Execute(context.Background(), f)
+					Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+					Expect(packagerMessenger.BuildReportWrittenCallCount()).To(Equal(0))
+					Expect(packagerMessenger.BuildReportNotWrittenArgsForCall(0)).To(MatchError(ContainSubstring("could not write build report")))
+				})
+			})
+
+			Context("with JSON output", func() {
+				var (
+					eventOutput *bytes.Buffer
+					outputDir   string
+				)
+
+				BeforeEach(func() {
+					PkgCmd.GlobalFlags.Output = events.FormatJSON
+					eventOutput = &bytes.Buffer{}
+					PkgCmd.EventOutput = eventOutput
+					outputDir = GinkgoT().TempDir()
+					Expect(f.Parse([]string{"-vmdk", "some_vmdk_file", "-outputDir", outputDir})).To(Succeed())
+				})
+
+				decode := func() []events.Event {
+					var decoded []events.Event
+					for _, line := range strings.Split(strings.TrimSpace(eventOutput.String()), "\n") {
+						var event events.Event
+						ExpectWithOffset(1, json.Unmarshal([]byte(line), &event)).To(Succeed())
+						decoded = append(decoded, event)
+					}
+					return decoded
+				}
+
+				It("reports every step and ends with the stemcell, its checksums, OS and version", func() {
+					stemcellPath := filepath.Join(outputDir, "bosh-stemcell-2019.2-vsphere-esxi-windows2019-go_agent.tgz")
+					packager.PackageStub = func() error {
+						return os.WriteFile(stemcellPath, []byte("stemcell"), 0600)
+					}
+
+					exitStatus := PkgCmd.Execute(context.Background(), f)
+					Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+
+					_, _, outputConfig, _ := packagerFactory.PackagerArgsForCall(0)
+					Expect(outputConfig.OutputFormat).To(Equal(events.FormatJSON))
+
+					decoded := decode()
+					var steps []string
+					for _, event := range decoded[:len(decoded)-1] {
+						if event.Status == events.StatusSucceeded {
+							steps = append(steps, event.Step)
+						}
+					}
+					Expect(steps).To(Equal([]string{"validate-output", "validate-free-space", "validate-source", "package"}))
+
+					result := decoded[len(decoded)-1]
+					Expect(result.Type).To(Equal(events.TypeResult))
+					Expect(result.Command).To(Equal("package"))
+					Expect(result.Status).To(Equal(events.StatusSucceeded))
+					Expect(result.OS).To(Equal("2019"))
+					Expect(result.Version).To(Equal("2019.2"))
+					Expect(result.Stemcell).To(Equal(&events.Stemcell{
+						Path:   stemcellPath,
+						SHA1:   "54b6c82a988394df10c7e2179abd1b01bb599de8",
+						SHA256: "17934690e966c11bad06391a9056e3558ebf346d9dff99d89413185242ce453d",
+					}))
+					Expect(result.Report).To(Equal(stemcellPath + ".report.json"))
+				})
+
+				It("reports the failed step and ends with a failed result instead of a message", func() {
+					packager.PackageReturns(errors.New("Didn't make it"))
+
+					exitStatus := PkgCmd.Execute(context.Background(), f)
+					Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+					Expect(packagerMessenger.PackageFailedCallCount()).To(Equal(0))
+
+					decoded := decode()
+					failedStep := decoded[len(decoded)-2]
+					Expect(failedStep.Step).To(Equal("package"))
+					Expect(failedStep.Status).To(Equal(events.StatusFailed))
+					Expect(failedStep.Error).To(Equal("Didn't make it"))
+
+					result := decoded[len(decoded)-1]
+					Expect(result.Type).To(Equal(events.TypeResult))
+					Expect(result.Status).To(Equal(events.StatusFailed))
+					Expect(result.Error).To(Equal("Didn't make it"))
+					Expect(result.Stemcell).To(BeNil())
+				})
+			})
 		})
 	})
 })
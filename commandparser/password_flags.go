@@ -0,0 +1,76 @@
+package commandparser
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cloudfoundry/stembuild/secret"
+)
+
+// passwordFlag is a password flag together with the -<name>-from flag naming another source for it
+type passwordFlag struct {
+	name  string
+	label string
+	value *string
+	from  string
+	// prompt asks for the password on a terminal when it is missing
+	prompt bool
+}
+
+// setPasswordFromFlag registers the -<name>-from flag of a password flag
+func setPasswordFromFlag(f *flag.FlagSet, password *passwordFlag) {
+	f.StringVar(&password.from, password.name+"-from", "", fmt.Sprintf("read the %s from file:<path>, env:<name> or stdin instead of -%s; without either it is prompted for on a terminal", password.label, password.name))
+}
+
+// resolvePasswords reads every password given with -<name>-from, and prompts on stdin for missing ones.
+// A nil stdin disables both reading from stdin and prompting.
+func resolvePasswords(stdin *os.File, prompts io.Writer, passwords ...*passwordFlag) error {
+	var stdinFlag string
+	for _, password := range passwords {
+		if password.from == "" {
+			continue
+		}
+		if *password.value != "" {
+			return fmt.Errorf("-%s and -%s-from cannot both be given", password.name, password.name)
+		}
+		if password.from == "stdin" {
+			if stdinFlag != "" {
+				return fmt.Errorf("-%s-from and -%s-from cannot both read from stdin", stdinFlag, password.name)
+			}
+			stdinFlag = password.name
+		}
+	}
+
+	var stdinReader io.Reader
+	if stdin != nil {
+		stdinReader = stdin
+	}
+
+	for _, password := range passwords {
+		var provider secret.Provider
+		switch {
+		case password.from != "":
+			var err error
+			provider, err = secret.Parse(password.from, stdinReader)
+			if err != nil {
+				return fmt.Errorf("-%s-from: %s", password.name, err)
+			}
+		case *password.value == "" && password.prompt && secret.CanPrompt(stdin):
+			provider = secret.Prompt{Label: password.label, Terminal: stdin, Output: prompts}
+		default:
+			continue
+		}
+
+		value, err := provider.Secret()
+		if err != nil && password.from != "" {
+			return fmt.Errorf("-%s-from: %s", password.name, err)
+		}
+		if err != nil {
+			return err
+		}
+		*password.value = value
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package commandparser
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/subcommands"
+
+	"github.com/cloudfoundry/stembuild/preflight"
+)
+
+//counterfeiter:generate . PreflightCheckFactory
+type PreflightCheckFactory interface {
+	Checks(ctx context.Context, config preflight.Config) []preflight.Check
+}
+
+type PreflightCmd struct {
+	ctx             context.Context
+	config          preflight.Config
+	vmPassword      passwordFlag
+	vCenterPassword passwordFlag
+	checkFactory    PreflightCheckFactory
+	validator       ConstructCmdValidator
+	output          io.Writer
+	GlobalFlags     *GlobalFlags
+	// Stdin is read for passwords given as -*-password-from stdin, and prompted for when missing. Nil disables both.
+	Stdin *os.File
+}
+
+func NewPreflightCmd(ctx context.Context, checkFactory PreflightCheckFactory, validator ConstructCmdValidator, output io.Writer) *PreflightCmd {
+	return &PreflightCmd{ctx: ctx, checkFactory: checkFactory, validator: validator, output: output, Stdin: os.Stdin}
+}
+
+func (*PreflightCmd) Name() string { return "preflight" }
+func (*PreflightCmd) Synopsis() string {
+	return "Checks vCenter, guest VM and local prerequisites for construct and package without changing anything"
+}
+
+func (*PreflightCmd) Usage() string {
+	return fmt.Sprintf(`%[1]s preflight -vm-ip <IP of VM> -vm-username <vm username> -vm-password <vm password>  -vcenter-url <vCenter URL> -vcenter-username <vCenter username> -vcenter-password <vCenter password> -vm-inventory-path <vCenter VM inventory path>
+
+Runs the checks that construct and package depend on and prints a pass/fail table with remediation hints.
+Nothing on the VM, in vCenter or on this machine is changed. Exits with a nonzero code when any check does not pass.
+
+Checks:
+	- vCenter URL, TLS and credentials
+	- VM inventory path
+	- Guest operations login through VMware Tools
+	- WinRM reachability and login
+	- LGPO.zip in current working directory, or given with -lgpo-path, containing LGPO.exe; a -lgpo-url is not downloaded, only requested with HEAD unless it is cached
+	- ovftool on PATH, only a warning unless -package-vmdk is given
+	- Free disk space in the output and temp directories
+
+Passwords:
+	-vm-password-from and -vcenter-password-from read the passwords from file:<path>, env:<name> or stdin, as for construct.
+
+Example:
+	%[1]s preflight -vm-ip '10.0.0.5' -vm-username Admin -vm-password 'password' -vcenter-url vcenter.example.com -vcenter-username root -vcenter-password 'password' -vm-inventory-path '/datacenter/vm/folder/vm-name'
+
+Flags:
+`, filepath.Base(os.Args[0]))
+}
+
+func (p *PreflightCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&p.config.GuestVmIp, "vm-ip", "", "IP of target machine")
+	f.StringVar(&p.config.GuestVMUsername, "vm-username", "", "Username of target machine")
+	f.StringVar(&p.config.GuestVMPassword, "vm-password", "", "Password of target machine, visible to other users of this machine; prefer -vm-password-from")
+	p.vmPassword = passwordFlag{name: "vm-password", label: "VM password", value: &p.config.GuestVMPassword}
+	setPasswordFromFlag(f, &p.vmPassword)
+	f.StringVar(&p.config.VCenterUrl, "vcenter-url", "", "vCenter url")
+	f.StringVar(&p.config.VCenterUsername, "vcenter-username", "", "vCenter username")
+	f.StringVar(&p.config.VCenterPassword, "vcenter-password", "", "vCenter password, visible to other users of this machine; prefer -vcenter-password-from")
+	p.vCenterPassword = passwordFlag{name: "vcenter-password", label: "vCenter password", value: &p.config.VCenterPassword}
+	setPasswordFromFlag(f, &p.vCenterPassword)
+	f.StringVar(&p.config.VmInventoryPath, "vm-inventory-path", "", "vCenter VM inventory path. (e.g: <datacenter>/vm/<vm-folder>/<vm-name>)")
+	f.StringVar(&p.config.CaCertFile, "vcenter-ca-certs", "", "filepath for custom ca certs")
+	f.StringVar(&p.config.OutputDir, "outputDir", "", "Output directory the stemcell will be packaged into, default is the current working directory.")
+	f.StringVar(&p.config.OutputDir, "o", "", "Output directory (shorthand)")
+	f.Uint64Var(&p.config.MinFreeSpaceGB, "min-free-space", 20, "Minimum free space in GB required in the output and temp directories")
+	f.BoolVar(&p.config.PackageVMDK, "package-vmdk", false, "Fail instead of warning when what packaging a VMDK needs, such as ovftool, is missing")
+	setWinRMFlags(f, &p.config.WinRM)
+	setLGPOFlags(f, &p.config.LGPO)
+}
+
+func (p *PreflightCmd) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	// only prompt for passwords of accounts that were given
+	p.vCenterPassword.prompt = p.config.VCenterUsername != ""
+	p.vmPassword.prompt = p.config.GuestVMUsername != ""
+	err := resolvePasswords(p.Stdin, os.Stderr, &p.vCenterPassword, &p.vmPassword)
+	if err != nil {
+		fmt.Fprintf(p.output, "Could not read password: %s\n", err)
+		return subcommands.ExitFailure
+	}
+
+	c := p.config
+	if !p.validator.PopulatedArgs(c.GuestVmIp, c.GuestVMUsername, c.GuestVMPassword, c.VCenterUrl, c.VCenterUsername, c.VCenterPassword, c.VmInventoryPath) {
+		fmt.Fprintln(p.output, "Not all required parameters were provided. See stembuild --help for more details")
+		return subcommands.ExitFailure
+	}
+	err = c.WinRM.Validate()
+	if err != nil {
+		fmt.Fprintf(p.output, "Invalid WinRM options: %s\n", err)
+		return subcommands.ExitFailure
+	}
+
+	results := preflight.RunChecks(p.checkFactory.Checks(p.ctx, c))
+	preflight.PrintResults(p.output, results)
+
+	if !preflight.AllPassed(results) {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
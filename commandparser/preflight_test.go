@@ -0,0 +1,186 @@
+package commandparser_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/google/subcommands"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry/stembuild/commandparser"
+	"github.com/cloudfoundry/stembuild/commandparser/commandparserfakes"
+	"github.com/cloudfoundry/stembuild/preflight"
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+var _ = Describe("preflight", func() {
+	var (
+		f                *flag.FlagSet
+		preflightCmd     *commandparser.PreflightCmd
+		fakeCheckFactory *commandparserfakes.FakePreflightCheckFactory
+		fakeValidator    *commandparserfakes.FakeConstructCmdValidator
+		output           *Buffer
+	)
+
+	var args = []string{
+		"-vm-ip", "10.0.0.5",
+		"-vm-username", "Admin",
+		"-vm-password", "some_password",
+		"-vcenter-url", "vcenter.example.com",
+		"-vcenter-username", "vCenterUsername",
+		"-vcenter-password", "vCenterPassword",
+		"-vm-inventory-path", "/my-datacenter/vm/my-folder/my-vm",
+		"-vcenter-ca-certs", "somecerts.txt",
+		"-o", "some-output-dir",
+		"-min-free-space", "5",
+	}
+
+	BeforeEach(func() {
+		f = flag.NewFlagSet("test", flag.ContinueOnError)
+		fakeCheckFactory = &commandparserfakes.FakePreflightCheckFactory{}
+		fakeValidator = &commandparserfakes.FakeConstructCmdValidator{}
+		fakeValidator.PopulatedArgsReturns(true)
+		output = NewBuffer()
+
+		preflightCmd = commandparser.NewPreflightCmd(context.Background(), fakeCheckFactory, fakeValidator, output)
+		preflightCmd.SetFlags(f)
+		preflightCmd.GlobalFlags = &commandparser.GlobalFlags{}
+		preflightCmd.Stdin = nil
+	})
+
+	It("passes the flag values to the check factory", func() {
+		Expect(f.Parse(args)).To(Succeed())
+
+		preflightCmd.Execute(context.Background(), f)
+
+		Expect(fakeCheckFactory.ChecksCallCount()).To(Equal(1))
+		_, config := fakeCheckFactory.ChecksArgsForCall(0)
+		Expect(config).To(Equal(preflight.Config{
+			GuestVmIp:       "10.0.0.5",
+			GuestVMUsername: "Admin",
+			GuestVMPassword: "some_password",
+			VCenterUrl:      "vcenter.example.com",
+			VCenterUsername: "vCenterUsername",
+			VCenterPassword: "vCenterPassword",
+			VmInventoryPath: "/my-datacenter/vm/my-folder/my-vm",
+			CaCertFile:      "somecerts.txt",
+			OutputDir:       "some-output-dir",
+			MinFreeSpaceGB:  5,
+			WinRM:           remotemanager.WinRMOptions{Auth: "basic", Timeout: remotemanager.WinRmTimeout, DialTimeout: remotemanager.WinRmDialTimeout},
+		}))
+	})
+
+	It("passes the WinRM options to the check factory", func() {
+		Expect(f.Parse(append(args, "-winrm-https", "-winrm-port", "8443", "-winrm-insecure-skip-verify", "-winrm-auth", "ntlm"))).To(Succeed())
+
+		preflightCmd.Execute(context.Background(), f)
+
+		_, config := fakeCheckFactory.ChecksArgsForCall(0)
+		Expect(config.WinRM).To(Equal(remotemanager.WinRMOptions{HTTPS: true, Port: 8443, InsecureSkipVerify: true, Auth: "ntlm", Timeout: remotemanager.WinRmTimeout, DialTimeout: remotemanager.WinRmDialTimeout}))
+	})
+
+	It("reads the passwords given with -vm-password-from and -vcenter-password-from", func() {
+		passwordFile := filepath.Join(GinkgoT().TempDir(), "vcenter-password")
+		Expect(os.WriteFile(passwordFile, []byte("file-vcenter-password\n"), 0600)).To(Succeed())
+		GinkgoT().Setenv("PREFLIGHT_VM_PASSWORD", "env-vm-password")
+		Expect(f.Parse([]string{
+			"-vm-ip", "10.0.0.5",
+			"-vm-username", "Admin",
+			"-vm-password-from", "env:PREFLIGHT_VM_PASSWORD",
+			"-vcenter-url", "vcenter.example.com",
+			"-vcenter-username", "vCenterUsername",
+			"-vcenter-password-from", "file:" + passwordFile,
+			"-vm-inventory-path", "/my-datacenter/vm/my-folder/my-vm",
+		})).To(Succeed())
+
+		preflightCmd.Execute(context.Background(), f)
+
+		_, config := fakeCheckFactory.ChecksArgsForCall(0)
+		Expect(config.GuestVMPassword).To(Equal("env-vm-password"))
+		Expect(config.VCenterPassword).To(Equal("file-vcenter-password"))
+	})
+
+	It("fails without running checks when a password is given twice", func() {
+		Expect(f.Parse(append(args, "-vcenter-password-from", "stdin"))).To(Succeed())
+
+		exitStatus := preflightCmd.Execute(context.Background(), f)
+
+		Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+		Expect(fakeCheckFactory.ChecksCallCount()).To(Equal(0))
+		Expect(output).To(Say("Could not read password: -vcenter-password and -vcenter-password-from cannot both be given"))
+	})
+
+	It("passes -package-vmdk to the check factory", func() {
+		Expect(f.Parse(append(args, "-package-vmdk"))).To(Succeed())
+
+		preflightCmd.Execute(context.Background(), f)
+
+		_, config := fakeCheckFactory.ChecksArgsForCall(0)
+		Expect(config.PackageVMDK).To(BeTrue())
+	})
+
+	It("succeeds when an optional check only warns", func() {
+		fakeCheckFactory.ChecksReturns([]preflight.Check{
+			{Name: "vCenter URL and TLS", Run: func() error { return nil }},
+			{Name: "ovftool", Optional: true, Run: func() error { return errors.New("could not locate ovftool") }, Remediation: "install ovftool"},
+		})
+		Expect(f.Parse(args)).To(Succeed())
+
+		exitStatus := preflightCmd.Execute(context.Background(), f)
+
+		Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+		Expect(output).To(Say(`ovftool\s+WARN\s+could not locate ovftool`))
+		Expect(output).To(Say("install ovftool"))
+	})
+
+	It("fails without running checks when the WinRM options are invalid", func() {
+		Expect(f.Parse(append(args, "-winrm-insecure-skip-verify"))).To(Succeed())
+
+		exitStatus := preflightCmd.Execute(context.Background(), f)
+
+		Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+		Expect(fakeCheckFactory.ChecksCallCount()).To(Equal(0))
+		Expect(output).To(Say("Invalid WinRM options: "))
+	})
+
+	It("succeeds and prints the results when every check passes", func() {
+		fakeCheckFactory.ChecksReturns([]preflight.Check{
+			{Name: "vCenter URL and TLS", Run: func() error { return nil }},
+		})
+		Expect(f.Parse(args)).To(Succeed())
+
+		exitStatus := preflightCmd.Execute(context.Background(), f)
+
+		Expect(exitStatus).To(Equal(subcommands.ExitSuccess))
+		Expect(output).To(Say(`vCenter URL and TLS\s+PASS`))
+	})
+
+	It("fails when any check fails", func() {
+		fakeCheckFactory.ChecksReturns([]preflight.Check{
+			{Name: "vCenter URL and TLS", Run: func() error { return nil }},
+			{Name: "WinRM reachability", Run: func() error { return errors.New("unreachable") }, Remediation: "open port 5985"},
+		})
+		Expect(f.Parse(args)).To(Succeed())
+
+		exitStatus := preflightCmd.Execute(context.Background(), f)
+
+		Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+		Expect(output).To(Say(`WinRM reachability\s+FAIL\s+unreachable`))
+		Expect(output).To(Say("open port 5985"))
+	})
+
+	It("fails without running checks when arguments are missing", func() {
+		fakeValidator.PopulatedArgsReturns(false)
+
+		exitStatus := preflightCmd.Execute(context.Background(), f)
+
+		Expect(exitStatus).To(Equal(subcommands.ExitFailure))
+		Expect(fakeCheckFactory.ChecksCallCount()).To(Equal(0))
+		Expect(output).To(Say("Not all required parameters were provided"))
+	})
+})
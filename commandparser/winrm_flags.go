@@ -0,0 +1,18 @@
+package commandparser
+
+import (
+	"flag"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+// setWinRMFlags registers the flags shared by every command that talks to the VM over WinRM
+func setWinRMFlags(f *flag.FlagSet, options *remotemanager.WinRMOptions) {
+	f.BoolVar(&options.HTTPS, "winrm-https", false, "Connect to WinRM over HTTPS")
+	f.IntVar(&options.Port, "winrm-port", 0, "WinRM port, default is 5985, or 5986 with -winrm-https")
+	f.StringVar(&options.CACertFile, "winrm-ca-cert", "", "filepath for a PEM CA certificate that signed the WinRM HTTPS certificate of the VM")
+	f.BoolVar(&options.InsecureSkipVerify, "winrm-insecure-skip-verify", false, "Do not verify the WinRM HTTPS certificate of the VM")
+	f.StringVar(&options.Auth, "winrm-auth", remotemanager.WinRMAuthBasic, "WinRM authentication: basic or ntlm")
+	f.DurationVar(&options.Timeout, "winrm-timeout", remotemanager.WinRmTimeout, "timeout for connecting to WinRM and for WinRM commands without a longer timeout of their own")
+	f.DurationVar(&options.DialTimeout, "winrm-dial-timeout", remotemanager.WinRmDialTimeout, "timeout for checking that the WinRM port of the VM is reachable")
+}
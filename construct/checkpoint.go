@@ -0,0 +1,145 @@
+package construct
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/stembuild/report"
+)
+
+//counterfeiter:generate . CheckpointStore
+type CheckpointStore interface {
+	Load(vmInventoryPath string) ([]string, error)
+	// Save records the completed steps. Saving none discards the step timings as well.
+	Save(vmInventoryPath string, completedSteps []string) error
+	LoadTimings(vmInventoryPath string) ([]report.Step, error)
+	SaveTimings(vmInventoryPath string, timings []report.Step) error
+}
+
+type checkpoint struct {
+	CompletedSteps []string      `json:"completed_steps"`
+	StepTimings    []report.Step `json:"step_timings,omitempty"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// FileCheckpointStore records the construct steps completed for each VM, and how long they took,
+// in a single JSON file keyed by the VM's inventory path.
+type FileCheckpointStore struct {
+	path string
+}
+
+// stateFileLock serializes access to state files so concurrent constructs in
+// one process do not overwrite each other's checkpoints
+var stateFileLock sync.Mutex
+
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+func DefaultCheckpointStatePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine a directory for the construct state file: %s", err)
+	}
+	return filepath.Join(cacheDir, "stembuild", "construct-state.json"), nil
+}
+
+func (s *FileCheckpointStore) Load(vmInventoryPath string) ([]string, error) {
+	stateFileLock.Lock()
+	defer stateFileLock.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return checkpoints[vmInventoryPath].CompletedSteps, nil
+}
+
+func (s *FileCheckpointStore) Save(vmInventoryPath string, completedSteps []string) error {
+	return s.update(vmInventoryPath, func(c *checkpoint) {
+		c.CompletedSteps = completedSteps
+		if len(completedSteps) == 0 {
+			c.StepTimings = nil
+		}
+	})
+}
+
+func (s *FileCheckpointStore) LoadTimings(vmInventoryPath string) ([]report.Step, error) {
+	stateFileLock.Lock()
+	defer stateFileLock.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return checkpoints[vmInventoryPath].StepTimings, nil
+}
+
+func (s *FileCheckpointStore) SaveTimings(vmInventoryPath string, timings []report.Step) error {
+	return s.update(vmInventoryPath, func(c *checkpoint) {
+		c.StepTimings = timings
+	})
+}
+
+func (s *FileCheckpointStore) update(vmInventoryPath string, change func(*checkpoint)) error {
+	stateFileLock.Lock()
+	defer stateFileLock.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	c := checkpoints[vmInventoryPath]
+	change(&c)
+	c.UpdatedAt = time.Now().UTC()
+	checkpoints[vmInventoryPath] = c
+
+	contents, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode construct state: %s", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return fmt.Errorf("could not create directory for construct state file %s: %s", s.path, err)
+	}
+
+	// Write to a temporary file first so an interrupted run never leaves a truncated state file behind
+	tmpPath := s.path + ".tmp"
+	err = os.WriteFile(tmpPath, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write construct state file %s: %s", s.path, err)
+	}
+
+	err = os.Rename(tmpPath, s.path)
+	if err != nil {
+		return fmt.Errorf("could not write construct state file %s: %s", s.path, err)
+	}
+
+	return nil
+}
+
+func (s *FileCheckpointStore) read() (map[string]checkpoint, error) {
+	checkpoints := map[string]checkpoint{}
+
+	contents, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read construct state file %s: %s", s.path, err)
+	}
+
+	err = json.Unmarshal(contents, &checkpoints)
+	if err != nil {
+		return nil, fmt.Errorf("construct state file %s is corrupt: %s", s.path, err)
+	}
+
+	return checkpoints, nil
+}
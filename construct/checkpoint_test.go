@@ -0,0 +1,104 @@
+package construct_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/report"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileCheckpointStore", func() {
+	var (
+		stateFile string
+		store     *construct.FileCheckpointStore
+	)
+
+	BeforeEach(func() {
+		stateFile = filepath.Join(GinkgoT().TempDir(), "nested", "construct-state.json")
+		store = construct.NewFileCheckpointStore(stateFile)
+	})
+
+	It("returns no completed steps when the state file does not exist", func() {
+		steps, err := store.Load("/dc/vm/some-vm")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(BeEmpty())
+	})
+
+	It("persists completed steps per VM inventory path", func() {
+		Expect(store.Save("/dc/vm/some-vm", []string{"create-provision-dir", "upload-artifacts"})).To(Succeed())
+		Expect(store.Save("/dc/vm/other-vm", []string{"create-provision-dir"})).To(Succeed())
+
+		reloaded := construct.NewFileCheckpointStore(stateFile)
+
+		steps, err := reloaded.Load("/dc/vm/some-vm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(Equal([]string{"create-provision-dir", "upload-artifacts"}))
+
+		steps, err = reloaded.Load("/dc/vm/other-vm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(Equal([]string{"create-provision-dir"}))
+	})
+
+	It("keeps the steps of every VM when several save at once", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(vm string) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(construct.NewFileCheckpointStore(stateFile).Save(vm, []string{"create-provision-dir"})).To(Succeed())
+			}(fmt.Sprintf("/dc/vm/vm-%d", i))
+		}
+		wg.Wait()
+
+		for i := 0; i < 10; i++ {
+			steps, err := store.Load(fmt.Sprintf("/dc/vm/vm-%d", i))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(steps).To(Equal([]string{"create-provision-dir"}))
+		}
+	})
+
+	It("replaces the steps previously recorded for a VM", func() {
+		Expect(store.Save("/dc/vm/some-vm", []string{"create-provision-dir", "upload-artifacts"})).To(Succeed())
+		Expect(store.Save("/dc/vm/some-vm", nil)).To(Succeed())
+
+		steps, err := store.Load("/dc/vm/some-vm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(BeEmpty())
+	})
+
+	It("keeps the step timings of a VM until its progress is discarded", func() {
+		timings := []report.Step{{Name: "upload-artifacts", Start: time.Unix(1700000000, 0).UTC(), End: time.Unix(1700000400, 0).UTC(), Duration: 400}}
+		Expect(store.SaveTimings("/dc/vm/some-vm", timings)).To(Succeed())
+		Expect(store.Save("/dc/vm/some-vm", []string{"create-provision-dir"})).To(Succeed())
+
+		loaded, err := construct.NewFileCheckpointStore(stateFile).LoadTimings("/dc/vm/some-vm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(timings))
+
+		steps, err := store.Load("/dc/vm/some-vm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(Equal([]string{"create-provision-dir"}))
+
+		Expect(store.Save("/dc/vm/some-vm", nil)).To(Succeed())
+		loaded, err = store.LoadTimings("/dc/vm/some-vm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(BeEmpty())
+	})
+
+	It("returns an error when the state file is corrupt", func() {
+		Expect(os.MkdirAll(filepath.Dir(stateFile), 0700)).To(Succeed())
+		Expect(os.WriteFile(stateFile, []byte("{not json"), 0600)).To(Succeed())
+
+		_, err := store.Load("/dc/vm/some-vm")
+		Expect(err).To(MatchError(ContainSubstring("is corrupt")))
+	})
+})
@@ -1,13 +1,59 @@
 package config
 
+import (
+	"time"
+
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+const (
+	TransportWinRM    = "winrm"
+	TransportGuestOps = "guestops"
+)
+
 type SourceConfig struct {
-	GuestVmIp       string
-	GuestVMUsername string
-	GuestVMPassword string
-	VCenterUrl      string
-	VCenterUsername string
-	VCenterPassword string
-	VmInventoryPath string
-	CaCertFile      string
-	SetupFlags      []string
+	GuestVmIp         string
+	GuestVMUsername   string
+	GuestVMPassword   string
+	VCenterUrl        string
+	VCenterUsername   string
+	VCenterPassword   string
+	VmInventoryPath   string
+	CaCertFile        string
+	SetupFlags        []string
+	PostRebootFlags   []string
+	AutomationZip     string
+	InstallUpdates    bool
+	MaxUpdateRounds   int
+	LGPO              lgpo.Source
+	SkipOSCheck       bool
+	SkipVMIPCheck     bool
+	NoLogTail         bool
+	Resume            bool
+	IfConstructed     string
+	StateFile         string
+	RollbackOnFailure bool
+	CloneFrom         string
+	CloneNetmask      string
+	CloneGateway      string
+	CloneDNSServers   []string
+	Transport         string
+	WinRM             remotemanager.WinRMOptions
+	Timeouts          Timeouts
+	// OutputFormat of progress messages, events.FormatText by default or events.FormatJSON
+	OutputFormat string
+}
+
+// Timeouts bounds how long construct waits in each phase. A zero value keeps the default of the phase.
+type Timeouts struct {
+	RebootWait           time.Duration
+	RebootPollInterval   time.Duration
+	Reboot               time.Duration
+	PostRebootScript     time.Duration
+	ShutdownPollInterval time.Duration
+	Shutdown             time.Duration
+	WindowsUpdates       time.Duration
+	// Construct bounds the whole of construct, which has no deadline when it is zero
+	Construct time.Duration
 }
@@ -0,0 +1,358 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package constructfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/report"
+)
+
+type FakeCheckpointStore struct {
+	LoadStub        func(string) ([]string, error)
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct {
+		arg1 string
+	}
+	loadReturns struct {
+		result1 []string
+		result2 error
+	}
+	loadReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	LoadTimingsStub        func(string) ([]report.Step, error)
+	loadTimingsMutex       sync.RWMutex
+	loadTimingsArgsForCall []struct {
+		arg1 string
+	}
+	loadTimingsReturns struct {
+		result1 []report.Step
+		result2 error
+	}
+	loadTimingsReturnsOnCall map[int]struct {
+		result1 []report.Step
+		result2 error
+	}
+	SaveStub        func(string, []string) error
+	saveMutex       sync.RWMutex
+	saveArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	saveReturns struct {
+		result1 error
+	}
+	saveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveTimingsStub        func(string, []report.Step) error
+	saveTimingsMutex       sync.RWMutex
+	saveTimingsArgsForCall []struct {
+		arg1 string
+		arg2 []report.Step
+	}
+	saveTimingsReturns struct {
+		result1 error
+	}
+	saveTimingsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCheckpointStore) Load(arg1 string) ([]string, error) {
+	fake.loadMutex.Lock()
+	ret, specificReturn := fake.loadReturnsOnCall[len(fake.loadArgsForCall)]
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LoadStub
+	fakeReturns := fake.loadReturns
+	fake.recordInvocation("Load", []interface{}{arg1})
+	fake.loadMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCheckpointStore) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *FakeCheckpointStore) LoadCalls(stub func(string) ([]string, error)) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = stub
+}
+
+func (fake *FakeCheckpointStore) LoadArgsForCall(i int) string {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	argsForCall := fake.loadArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCheckpointStore) LoadReturns(result1 []string, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCheckpointStore) LoadReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	if fake.loadReturnsOnCall == nil {
+		fake.loadReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.loadReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCheckpointStore) LoadTimings(arg1 string) ([]report.Step, error) {
+	fake.loadTimingsMutex.Lock()
+	ret, specificReturn := fake.loadTimingsReturnsOnCall[len(fake.loadTimingsArgsForCall)]
+	fake.loadTimingsArgsForCall = append(fake.loadTimingsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LoadTimingsStub
+	fakeReturns := fake.loadTimingsReturns
+	fake.recordInvocation("LoadTimings", []interface{}{arg1})
+	fake.loadTimingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCheckpointStore) LoadTimingsCallCount() int {
+	fake.loadTimingsMutex.RLock()
+	defer fake.loadTimingsMutex.RUnlock()
+	return len(fake.loadTimingsArgsForCall)
+}
+
+func (fake *FakeCheckpointStore) LoadTimingsCalls(stub func(string) ([]report.Step, error)) {
+	fake.loadTimingsMutex.Lock()
+	defer fake.loadTimingsMutex.Unlock()
+	fake.LoadTimingsStub = stub
+}
+
+func (fake *FakeCheckpointStore) LoadTimingsArgsForCall(i int) string {
+	fake.loadTimingsMutex.RLock()
+	defer fake.loadTimingsMutex.RUnlock()
+	argsForCall := fake.loadTimingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCheckpointStore) LoadTimingsReturns(result1 []report.Step, result2 error) {
+	fake.loadTimingsMutex.Lock()
+	defer fake.loadTimingsMutex.Unlock()
+	fake.LoadTimingsStub = nil
+	fake.loadTimingsReturns = struct {
+		result1 []report.Step
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCheckpointStore) LoadTimingsReturnsOnCall(i int, result1 []report.Step, result2 error) {
+	fake.loadTimingsMutex.Lock()
+	defer fake.loadTimingsMutex.Unlock()
+	fake.LoadTimingsStub = nil
+	if fake.loadTimingsReturnsOnCall == nil {
+		fake.loadTimingsReturnsOnCall = make(map[int]struct {
+			result1 []report.Step
+			result2 error
+		})
+	}
+	fake.loadTimingsReturnsOnCall[i] = struct {
+		result1 []report.Step
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCheckpointStore) Save(arg1 string, arg2 []string) error {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.saveMutex.Lock()
+	ret, specificReturn := fake.saveReturnsOnCall[len(fake.saveArgsForCall)]
+	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.SaveStub
+	fakeReturns := fake.saveReturns
+	fake.recordInvocation("Save", []interface{}{arg1, arg2Copy})
+	fake.saveMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCheckpointStore) SaveCallCount() int {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	return len(fake.saveArgsForCall)
+}
+
+func (fake *FakeCheckpointStore) SaveCalls(stub func(string, []string) error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = stub
+}
+
+func (fake *FakeCheckpointStore) SaveArgsForCall(i int) (string, []string) {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	argsForCall := fake.saveArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCheckpointStore) SaveReturns(result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	fake.saveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCheckpointStore) SaveReturnsOnCall(i int, result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	if fake.saveReturnsOnCall == nil {
+		fake.saveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCheckpointStore) SaveTimings(arg1 string, arg2 []report.Step) error {
+	var arg2Copy []report.Step
+	if arg2 != nil {
+		arg2Copy = make([]report.Step, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.saveTimingsMutex.Lock()
+	ret, specificReturn := fake.saveTimingsReturnsOnCall[len(fake.saveTimingsArgsForCall)]
+	fake.saveTimingsArgsForCall = append(fake.saveTimingsArgsForCall, struct {
+		arg1 string
+		arg2 []report.Step
+	}{arg1, arg2Copy})
+	stub := fake.SaveTimingsStub
+	fakeReturns := fake.saveTimingsReturns
+	fake.recordInvocation("SaveTimings", []interface{}{arg1, arg2Copy})
+	fake.saveTimingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCheckpointStore) SaveTimingsCallCount() int {
+	fake.saveTimingsMutex.RLock()
+	defer fake.saveTimingsMutex.RUnlock()
+	return len(fake.saveTimingsArgsForCall)
+}
+
+func (fake *FakeCheckpointStore) SaveTimingsCalls(stub func(string, []report.Step) error) {
+	fake.saveTimingsMutex.Lock()
+	defer fake.saveTimingsMutex.Unlock()
+	fake.SaveTimingsStub = stub
+}
+
+func (fake *FakeCheckpointStore) SaveTimingsArgsForCall(i int) (string, []report.Step) {
+	fake.saveTimingsMutex.RLock()
+	defer fake.saveTimingsMutex.RUnlock()
+	argsForCall := fake.saveTimingsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCheckpointStore) SaveTimingsReturns(result1 error) {
+	fake.saveTimingsMutex.Lock()
+	defer fake.saveTimingsMutex.Unlock()
+	fake.SaveTimingsStub = nil
+	fake.saveTimingsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCheckpointStore) SaveTimingsReturnsOnCall(i int, result1 error) {
+	fake.saveTimingsMutex.Lock()
+	defer fake.saveTimingsMutex.Unlock()
+	fake.SaveTimingsStub = nil
+	if fake.saveTimingsReturnsOnCall == nil {
+		fake.saveTimingsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveTimingsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCheckpointStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	fake.loadTimingsMutex.RLock()
+	defer fake.loadTimingsMutex.RUnlock()
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	fake.saveTimingsMutex.RLock()
+	defer fake.saveTimingsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCheckpointStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ construct.CheckpointStore = new(FakeCheckpointStore)
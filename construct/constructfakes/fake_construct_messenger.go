@@ -5,9 +5,43 @@ import (
 	"sync"
 
 	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 type FakeConstructMessenger struct {
+	AlreadyConstructedStub        func([]string)
+	alreadyConstructedMutex       sync.RWMutex
+	alreadyConstructedArgsForCall []struct {
+		arg1 []string
+	}
+	CheckVMIPStartedStub        func(string)
+	checkVMIPStartedMutex       sync.RWMutex
+	checkVMIPStartedArgsForCall []struct {
+		arg1 string
+	}
+	CheckVMIPSucceededStub        func()
+	checkVMIPSucceededMutex       sync.RWMutex
+	checkVMIPSucceededArgsForCall []struct {
+	}
+	CheckpointNotSavedStub        func(string, error)
+	checkpointNotSavedMutex       sync.RWMutex
+	checkpointNotSavedArgsForCall []struct {
+		arg1 string
+		arg2 error
+	}
+	CheckpointsDisabledStub        func(error)
+	checkpointsDisabledMutex       sync.RWMutex
+	checkpointsDisabledArgsForCall []struct {
+		arg1 error
+	}
+	ConfirmVMIdentityStartedStub        func()
+	confirmVMIdentityStartedMutex       sync.RWMutex
+	confirmVMIdentityStartedArgsForCall []struct {
+	}
+	ConfirmVMIdentitySucceededStub        func()
+	confirmVMIdentitySucceededMutex       sync.RWMutex
+	confirmVMIdentitySucceededArgsForCall []struct {
+	}
 	CreateProvisionDirStartedStub        func()
 	createProvisionDirStartedMutex       sync.RWMutex
 	createProvisionDirStartedArgsForCall []struct {
@@ -16,6 +50,24 @@ type FakeConstructMessenger struct {
 	createProvisionDirSucceededMutex       sync.RWMutex
 	createProvisionDirSucceededArgsForCall []struct {
 	}
+	CreateSnapshotStartedStub        func(string)
+	createSnapshotStartedMutex       sync.RWMutex
+	createSnapshotStartedArgsForCall []struct {
+		arg1 string
+	}
+	CreateSnapshotSucceededStub        func()
+	createSnapshotSucceededMutex       sync.RWMutex
+	createSnapshotSucceededArgsForCall []struct {
+	}
+	DownloadFileFailedStub        func(string)
+	downloadFileFailedMutex       sync.RWMutex
+	downloadFileFailedArgsForCall []struct {
+		arg1 string
+	}
+	DownloadGuestLogsStartedStub        func()
+	downloadGuestLogsStartedMutex       sync.RWMutex
+	downloadGuestLogsStartedArgsForCall []struct {
+	}
 	EnableWinRMStartedStub        func()
 	enableWinRMStartedMutex       sync.RWMutex
 	enableWinRMStartedArgsForCall []struct {
@@ -45,6 +97,11 @@ type FakeConstructMessenger struct {
 	executeSetupScriptSucceededMutex       sync.RWMutex
 	executeSetupScriptSucceededArgsForCall []struct {
 	}
+	ExitCodeRetrievalFailedStub        func(string)
+	exitCodeRetrievalFailedMutex       sync.RWMutex
+	exitCodeRetrievalFailedArgsForCall []struct {
+		arg1 string
+	}
 	ExtractArtifactsStartedStub        func()
 	extractArtifactsStartedMutex       sync.RWMutex
 	extractArtifactsStartedArgsForCall []struct {
@@ -53,6 +110,11 @@ type FakeConstructMessenger struct {
 	extractArtifactsSucceededMutex       sync.RWMutex
 	extractArtifactsSucceededArgsForCall []struct {
 	}
+	GuestLogsNotDownloadedStub        func(error)
+	guestLogsNotDownloadedMutex       sync.RWMutex
+	guestLogsNotDownloadedArgsForCall []struct {
+		arg1 error
+	}
 	LogOutUsersStartedStub        func()
 	logOutUsersStartedMutex       sync.RWMutex
 	logOutUsersStartedArgsForCall []struct {
@@ -61,6 +123,21 @@ type FakeConstructMessenger struct {
 	logOutUsersSucceededMutex       sync.RWMutex
 	logOutUsersSucceededArgsForCall []struct {
 	}
+	OSVersionCheckSkippedStub        func(string)
+	oSVersionCheckSkippedMutex       sync.RWMutex
+	oSVersionCheckSkippedArgsForCall []struct {
+		arg1 string
+	}
+	OSVersionFileCreationFailedStub        func(string)
+	oSVersionFileCreationFailedMutex       sync.RWMutex
+	oSVersionFileCreationFailedArgsForCall []struct {
+		arg1 string
+	}
+	OSVersionMismatchIgnoredStub        func(string)
+	oSVersionMismatchIgnoredMutex       sync.RWMutex
+	oSVersionMismatchIgnoredArgsForCall []struct {
+		arg1 string
+	}
 	RebootHasFinishedStub        func()
 	rebootHasFinishedMutex       sync.RWMutex
 	rebootHasFinishedArgsForCall []struct {
@@ -69,10 +146,72 @@ type FakeConstructMessenger struct {
 	rebootHasStartedMutex       sync.RWMutex
 	rebootHasStartedArgsForCall []struct {
 	}
+	RollbackStartedStub        func(string)
+	rollbackStartedMutex       sync.RWMutex
+	rollbackStartedArgsForCall []struct {
+		arg1 string
+	}
+	RollbackSucceededStub        func()
+	rollbackSucceededMutex       sync.RWMutex
+	rollbackSucceededArgsForCall []struct {
+	}
 	ShutdownCompletedStub        func()
 	shutdownCompletedMutex       sync.RWMutex
 	shutdownCompletedArgsForCall []struct {
 	}
+	SnapshotNotDeletedStub        func(string, error)
+	snapshotNotDeletedMutex       sync.RWMutex
+	snapshotNotDeletedArgsForCall []struct {
+		arg1 string
+		arg2 error
+	}
+	SnapshotRetainedStub        func(string)
+	snapshotRetainedMutex       sync.RWMutex
+	snapshotRetainedArgsForCall []struct {
+		arg1 string
+	}
+	SnapshotReusedStub        func(string)
+	snapshotReusedMutex       sync.RWMutex
+	snapshotReusedArgsForCall []struct {
+		arg1 string
+	}
+	StepFailedStub        func(string, error)
+	stepFailedMutex       sync.RWMutex
+	stepFailedArgsForCall []struct {
+		arg1 string
+		arg2 error
+	}
+	StepSkippedStub        func(string)
+	stepSkippedMutex       sync.RWMutex
+	stepSkippedArgsForCall []struct {
+		arg1 string
+	}
+	StepStartedStub        func(string)
+	stepStartedMutex       sync.RWMutex
+	stepStartedArgsForCall []struct {
+		arg1 string
+	}
+	StepSucceededStub        func(string)
+	stepSucceededMutex       sync.RWMutex
+	stepSucceededArgsForCall []struct {
+		arg1 string
+	}
+	StepTimingsStub        func([]report.Step)
+	stepTimingsMutex       sync.RWMutex
+	stepTimingsArgsForCall []struct {
+		arg1 []report.Step
+	}
+	StepVerificationFailedStub        func(string, string)
+	stepVerificationFailedMutex       sync.RWMutex
+	stepVerificationFailedArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	TimingsNotSavedStub        func(error)
+	timingsNotSavedMutex       sync.RWMutex
+	timingsNotSavedArgsForCall []struct {
+		arg1 error
+	}
 	UploadArtifactsStartedStub        func()
 	uploadArtifactsStartedMutex       sync.RWMutex
 	uploadArtifactsStartedArgsForCall []struct {
@@ -90,6 +229,20 @@ type FakeConstructMessenger struct {
 	uploadFileSucceededMutex       sync.RWMutex
 	uploadFileSucceededArgsForCall []struct {
 	}
+	VMIPNotReportedStub        func(string)
+	vMIPNotReportedMutex       sync.RWMutex
+	vMIPNotReportedArgsForCall []struct {
+		arg1 string
+	}
+	ValidateOSVersionStartedStub        func()
+	validateOSVersionStartedMutex       sync.RWMutex
+	validateOSVersionStartedArgsForCall []struct {
+	}
+	ValidateOSVersionSucceededStub        func(string)
+	validateOSVersionSucceededMutex       sync.RWMutex
+	validateOSVersionSucceededArgsForCall []struct {
+		arg1 string
+	}
 	ValidateVMConnectionStartedStub        func()
 	validateVMConnectionStartedMutex       sync.RWMutex
 	validateVMConnectionStartedArgsForCall []struct {
@@ -106,10 +259,237 @@ type FakeConstructMessenger struct {
 	winRMDisconnectedForRebootMutex       sync.RWMutex
 	winRMDisconnectedForRebootArgsForCall []struct {
 	}
+	WindowsUpdatesFinishedStub        func([]string)
+	windowsUpdatesFinishedMutex       sync.RWMutex
+	windowsUpdatesFinishedArgsForCall []struct {
+		arg1 []string
+	}
+	WindowsUpdatesRoundLimitReachedStub        func(int)
+	windowsUpdatesRoundLimitReachedMutex       sync.RWMutex
+	windowsUpdatesRoundLimitReachedArgsForCall []struct {
+		arg1 int
+	}
+	WindowsUpdatesRoundStartedStub        func(int, int)
+	windowsUpdatesRoundStartedMutex       sync.RWMutex
+	windowsUpdatesRoundStartedArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	WindowsUpdatesRoundSucceededStub        func([]string)
+	windowsUpdatesRoundSucceededMutex       sync.RWMutex
+	windowsUpdatesRoundSucceededArgsForCall []struct {
+		arg1 []string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeConstructMessenger) AlreadyConstructed(arg1 []string) {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.alreadyConstructedMutex.Lock()
+	fake.alreadyConstructedArgsForCall = append(fake.alreadyConstructedArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.AlreadyConstructedStub
+	fake.recordInvocation("AlreadyConstructed", []interface{}{arg1Copy})
+	fake.alreadyConstructedMutex.Unlock()
+	if stub != nil {
+		fake.AlreadyConstructedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) AlreadyConstructedCallCount() int {
+	fake.alreadyConstructedMutex.RLock()
+	defer fake.alreadyConstructedMutex.RUnlock()
+	return len(fake.alreadyConstructedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) AlreadyConstructedCalls(stub func([]string)) {
+	fake.alreadyConstructedMutex.Lock()
+	defer fake.alreadyConstructedMutex.Unlock()
+	fake.AlreadyConstructedStub = stub
+}
+
+func (fake *FakeConstructMessenger) AlreadyConstructedArgsForCall(i int) []string {
+	fake.alreadyConstructedMutex.RLock()
+	defer fake.alreadyConstructedMutex.RUnlock()
+	argsForCall := fake.alreadyConstructedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPStarted(arg1 string) {
+	fake.checkVMIPStartedMutex.Lock()
+	fake.checkVMIPStartedArgsForCall = append(fake.checkVMIPStartedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CheckVMIPStartedStub
+	fake.recordInvocation("CheckVMIPStarted", []interface{}{arg1})
+	fake.checkVMIPStartedMutex.Unlock()
+	if stub != nil {
+		fake.CheckVMIPStartedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPStartedCallCount() int {
+	fake.checkVMIPStartedMutex.RLock()
+	defer fake.checkVMIPStartedMutex.RUnlock()
+	return len(fake.checkVMIPStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPStartedCalls(stub func(string)) {
+	fake.checkVMIPStartedMutex.Lock()
+	defer fake.checkVMIPStartedMutex.Unlock()
+	fake.CheckVMIPStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPStartedArgsForCall(i int) string {
+	fake.checkVMIPStartedMutex.RLock()
+	defer fake.checkVMIPStartedMutex.RUnlock()
+	argsForCall := fake.checkVMIPStartedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPSucceeded() {
+	fake.checkVMIPSucceededMutex.Lock()
+	fake.checkVMIPSucceededArgsForCall = append(fake.checkVMIPSucceededArgsForCall, struct {
+	}{})
+	stub := fake.CheckVMIPSucceededStub
+	fake.recordInvocation("CheckVMIPSucceeded", []interface{}{})
+	fake.checkVMIPSucceededMutex.Unlock()
+	if stub != nil {
+		fake.CheckVMIPSucceededStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPSucceededCallCount() int {
+	fake.checkVMIPSucceededMutex.RLock()
+	defer fake.checkVMIPSucceededMutex.RUnlock()
+	return len(fake.checkVMIPSucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CheckVMIPSucceededCalls(stub func()) {
+	fake.checkVMIPSucceededMutex.Lock()
+	defer fake.checkVMIPSucceededMutex.Unlock()
+	fake.CheckVMIPSucceededStub = stub
+}
+
+func (fake *FakeConstructMessenger) CheckpointNotSaved(arg1 string, arg2 error) {
+	fake.checkpointNotSavedMutex.Lock()
+	fake.checkpointNotSavedArgsForCall = append(fake.checkpointNotSavedArgsForCall, struct {
+		arg1 string
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.CheckpointNotSavedStub
+	fake.recordInvocation("CheckpointNotSaved", []interface{}{arg1, arg2})
+	fake.checkpointNotSavedMutex.Unlock()
+	if stub != nil {
+		fake.CheckpointNotSavedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeConstructMessenger) CheckpointNotSavedCallCount() int {
+	fake.checkpointNotSavedMutex.RLock()
+	defer fake.checkpointNotSavedMutex.RUnlock()
+	return len(fake.checkpointNotSavedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CheckpointNotSavedCalls(stub func(string, error)) {
+	fake.checkpointNotSavedMutex.Lock()
+	defer fake.checkpointNotSavedMutex.Unlock()
+	fake.CheckpointNotSavedStub = stub
+}
+
+func (fake *FakeConstructMessenger) CheckpointNotSavedArgsForCall(i int) (string, error) {
+	fake.checkpointNotSavedMutex.RLock()
+	defer fake.checkpointNotSavedMutex.RUnlock()
+	argsForCall := fake.checkpointNotSavedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeConstructMessenger) CheckpointsDisabled(arg1 error) {
+	fake.checkpointsDisabledMutex.Lock()
+	fake.checkpointsDisabledArgsForCall = append(fake.checkpointsDisabledArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.CheckpointsDisabledStub
+	fake.recordInvocation("CheckpointsDisabled", []interface{}{arg1})
+	fake.checkpointsDisabledMutex.Unlock()
+	if stub != nil {
+		fake.CheckpointsDisabledStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) CheckpointsDisabledCallCount() int {
+	fake.checkpointsDisabledMutex.RLock()
+	defer fake.checkpointsDisabledMutex.RUnlock()
+	return len(fake.checkpointsDisabledArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CheckpointsDisabledCalls(stub func(error)) {
+	fake.checkpointsDisabledMutex.Lock()
+	defer fake.checkpointsDisabledMutex.Unlock()
+	fake.CheckpointsDisabledStub = stub
+}
+
+func (fake *FakeConstructMessenger) CheckpointsDisabledArgsForCall(i int) error {
+	fake.checkpointsDisabledMutex.RLock()
+	defer fake.checkpointsDisabledMutex.RUnlock()
+	argsForCall := fake.checkpointsDisabledArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) ConfirmVMIdentityStarted() {
+	fake.confirmVMIdentityStartedMutex.Lock()
+	fake.confirmVMIdentityStartedArgsForCall = append(fake.confirmVMIdentityStartedArgsForCall, struct {
+	}{})
+	stub := fake.ConfirmVMIdentityStartedStub
+	fake.recordInvocation("ConfirmVMIdentityStarted", []interface{}{})
+	fake.confirmVMIdentityStartedMutex.Unlock()
+	if stub != nil {
+		fake.ConfirmVMIdentityStartedStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) ConfirmVMIdentityStartedCallCount() int {
+	fake.confirmVMIdentityStartedMutex.RLock()
+	defer fake.confirmVMIdentityStartedMutex.RUnlock()
+	return len(fake.confirmVMIdentityStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) ConfirmVMIdentityStartedCalls(stub func()) {
+	fake.confirmVMIdentityStartedMutex.Lock()
+	defer fake.confirmVMIdentityStartedMutex.Unlock()
+	fake.ConfirmVMIdentityStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) ConfirmVMIdentitySucceeded() {
+	fake.confirmVMIdentitySucceededMutex.Lock()
+	fake.confirmVMIdentitySucceededArgsForCall = append(fake.confirmVMIdentitySucceededArgsForCall, struct {
+	}{})
+	stub := fake.ConfirmVMIdentitySucceededStub
+	fake.recordInvocation("ConfirmVMIdentitySucceeded", []interface{}{})
+	fake.confirmVMIdentitySucceededMutex.Unlock()
+	if stub != nil {
+		fake.ConfirmVMIdentitySucceededStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) ConfirmVMIdentitySucceededCallCount() int {
+	fake.confirmVMIdentitySucceededMutex.RLock()
+	defer fake.confirmVMIdentitySucceededMutex.RUnlock()
+	return len(fake.confirmVMIdentitySucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) ConfirmVMIdentitySucceededCalls(stub func()) {
+	fake.confirmVMIdentitySucceededMutex.Lock()
+	defer fake.confirmVMIdentitySucceededMutex.Unlock()
+	fake.ConfirmVMIdentitySucceededStub = stub
+}
+
 func (fake *FakeConstructMessenger) CreateProvisionDirStarted() {
 	fake.createProvisionDirStartedMutex.Lock()
 	fake.createProvisionDirStartedArgsForCall = append(fake.createProvisionDirStartedArgsForCall, struct {
@@ -158,6 +538,118 @@ func (fake *FakeConstructMessenger) CreateProvisionDirSucceededCalls(stub func()
 	fake.CreateProvisionDirSucceededStub = stub
 }
 
+func (fake *FakeConstructMessenger) CreateSnapshotStarted(arg1 string) {
+	fake.createSnapshotStartedMutex.Lock()
+	fake.createSnapshotStartedArgsForCall = append(fake.createSnapshotStartedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CreateSnapshotStartedStub
+	fake.recordInvocation("CreateSnapshotStarted", []interface{}{arg1})
+	fake.createSnapshotStartedMutex.Unlock()
+	if stub != nil {
+		fake.CreateSnapshotStartedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) CreateSnapshotStartedCallCount() int {
+	fake.createSnapshotStartedMutex.RLock()
+	defer fake.createSnapshotStartedMutex.RUnlock()
+	return len(fake.createSnapshotStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CreateSnapshotStartedCalls(stub func(string)) {
+	fake.createSnapshotStartedMutex.Lock()
+	defer fake.createSnapshotStartedMutex.Unlock()
+	fake.CreateSnapshotStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) CreateSnapshotStartedArgsForCall(i int) string {
+	fake.createSnapshotStartedMutex.RLock()
+	defer fake.createSnapshotStartedMutex.RUnlock()
+	argsForCall := fake.createSnapshotStartedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) CreateSnapshotSucceeded() {
+	fake.createSnapshotSucceededMutex.Lock()
+	fake.createSnapshotSucceededArgsForCall = append(fake.createSnapshotSucceededArgsForCall, struct {
+	}{})
+	stub := fake.CreateSnapshotSucceededStub
+	fake.recordInvocation("CreateSnapshotSucceeded", []interface{}{})
+	fake.createSnapshotSucceededMutex.Unlock()
+	if stub != nil {
+		fake.CreateSnapshotSucceededStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) CreateSnapshotSucceededCallCount() int {
+	fake.createSnapshotSucceededMutex.RLock()
+	defer fake.createSnapshotSucceededMutex.RUnlock()
+	return len(fake.createSnapshotSucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) CreateSnapshotSucceededCalls(stub func()) {
+	fake.createSnapshotSucceededMutex.Lock()
+	defer fake.createSnapshotSucceededMutex.Unlock()
+	fake.CreateSnapshotSucceededStub = stub
+}
+
+func (fake *FakeConstructMessenger) DownloadFileFailed(arg1 string) {
+	fake.downloadFileFailedMutex.Lock()
+	fake.downloadFileFailedArgsForCall = append(fake.downloadFileFailedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DownloadFileFailedStub
+	fake.recordInvocation("DownloadFileFailed", []interface{}{arg1})
+	fake.downloadFileFailedMutex.Unlock()
+	if stub != nil {
+		fake.DownloadFileFailedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) DownloadFileFailedCallCount() int {
+	fake.downloadFileFailedMutex.RLock()
+	defer fake.downloadFileFailedMutex.RUnlock()
+	return len(fake.downloadFileFailedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) DownloadFileFailedCalls(stub func(string)) {
+	fake.downloadFileFailedMutex.Lock()
+	defer fake.downloadFileFailedMutex.Unlock()
+	fake.DownloadFileFailedStub = stub
+}
+
+func (fake *FakeConstructMessenger) DownloadFileFailedArgsForCall(i int) string {
+	fake.downloadFileFailedMutex.RLock()
+	defer fake.downloadFileFailedMutex.RUnlock()
+	argsForCall := fake.downloadFileFailedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) DownloadGuestLogsStarted() {
+	fake.downloadGuestLogsStartedMutex.Lock()
+	fake.downloadGuestLogsStartedArgsForCall = append(fake.downloadGuestLogsStartedArgsForCall, struct {
+	}{})
+	stub := fake.DownloadGuestLogsStartedStub
+	fake.recordInvocation("DownloadGuestLogsStarted", []interface{}{})
+	fake.downloadGuestLogsStartedMutex.Unlock()
+	if stub != nil {
+		fake.DownloadGuestLogsStartedStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) DownloadGuestLogsStartedCallCount() int {
+	fake.downloadGuestLogsStartedMutex.RLock()
+	defer fake.downloadGuestLogsStartedMutex.RUnlock()
+	return len(fake.downloadGuestLogsStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) DownloadGuestLogsStartedCalls(stub func()) {
+	fake.downloadGuestLogsStartedMutex.Lock()
+	defer fake.downloadGuestLogsStartedMutex.Unlock()
+	fake.DownloadGuestLogsStartedStub = stub
+}
+
 func (fake *FakeConstructMessenger) EnableWinRMStarted() {
 	fake.enableWinRMStartedMutex.Lock()
 	fake.enableWinRMStartedArgsForCall = append(fake.enableWinRMStartedArgsForCall, struct {
@@ -334,6 +826,38 @@ func (fake *FakeConstructMessenger) ExecuteSetupScriptSucceededCalls(stub func()
 	fake.ExecuteSetupScriptSucceededStub = stub
 }
 
+func (fake *FakeConstructMessenger) ExitCodeRetrievalFailed(arg1 string) {
+	fake.exitCodeRetrievalFailedMutex.Lock()
+	fake.exitCodeRetrievalFailedArgsForCall = append(fake.exitCodeRetrievalFailedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ExitCodeRetrievalFailedStub
+	fake.recordInvocation("ExitCodeRetrievalFailed", []interface{}{arg1})
+	fake.exitCodeRetrievalFailedMutex.Unlock()
+	if stub != nil {
+		fake.ExitCodeRetrievalFailedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) ExitCodeRetrievalFailedCallCount() int {
+	fake.exitCodeRetrievalFailedMutex.RLock()
+	defer fake.exitCodeRetrievalFailedMutex.RUnlock()
+	return len(fake.exitCodeRetrievalFailedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) ExitCodeRetrievalFailedCalls(stub func(string)) {
+	fake.exitCodeRetrievalFailedMutex.Lock()
+	defer fake.exitCodeRetrievalFailedMutex.Unlock()
+	fake.ExitCodeRetrievalFailedStub = stub
+}
+
+func (fake *FakeConstructMessenger) ExitCodeRetrievalFailedArgsForCall(i int) string {
+	fake.exitCodeRetrievalFailedMutex.RLock()
+	defer fake.exitCodeRetrievalFailedMutex.RUnlock()
+	argsForCall := fake.exitCodeRetrievalFailedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) ExtractArtifactsStarted() {
 	fake.extractArtifactsStartedMutex.Lock()
 	fake.extractArtifactsStartedArgsForCall = append(fake.extractArtifactsStartedArgsForCall, struct {
@@ -382,6 +906,38 @@ func (fake *FakeConstructMessenger) ExtractArtifactsSucceededCalls(stub func())
 	fake.ExtractArtifactsSucceededStub = stub
 }
 
+func (fake *FakeConstructMessenger) GuestLogsNotDownloaded(arg1 error) {
+	fake.guestLogsNotDownloadedMutex.Lock()
+	fake.guestLogsNotDownloadedArgsForCall = append(fake.guestLogsNotDownloadedArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.GuestLogsNotDownloadedStub
+	fake.recordInvocation("GuestLogsNotDownloaded", []interface{}{arg1})
+	fake.guestLogsNotDownloadedMutex.Unlock()
+	if stub != nil {
+		fake.GuestLogsNotDownloadedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) GuestLogsNotDownloadedCallCount() int {
+	fake.guestLogsNotDownloadedMutex.RLock()
+	defer fake.guestLogsNotDownloadedMutex.RUnlock()
+	return len(fake.guestLogsNotDownloadedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) GuestLogsNotDownloadedCalls(stub func(error)) {
+	fake.guestLogsNotDownloadedMutex.Lock()
+	defer fake.guestLogsNotDownloadedMutex.Unlock()
+	fake.GuestLogsNotDownloadedStub = stub
+}
+
+func (fake *FakeConstructMessenger) GuestLogsNotDownloadedArgsForCall(i int) error {
+	fake.guestLogsNotDownloadedMutex.RLock()
+	defer fake.guestLogsNotDownloadedMutex.RUnlock()
+	argsForCall := fake.guestLogsNotDownloadedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) LogOutUsersStarted() {
 	fake.logOutUsersStartedMutex.Lock()
 	fake.logOutUsersStartedArgsForCall = append(fake.logOutUsersStartedArgsForCall, struct {
@@ -430,6 +986,102 @@ func (fake *FakeConstructMessenger) LogOutUsersSucceededCalls(stub func()) {
 	fake.LogOutUsersSucceededStub = stub
 }
 
+func (fake *FakeConstructMessenger) OSVersionCheckSkipped(arg1 string) {
+	fake.oSVersionCheckSkippedMutex.Lock()
+	fake.oSVersionCheckSkippedArgsForCall = append(fake.oSVersionCheckSkippedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.OSVersionCheckSkippedStub
+	fake.recordInvocation("OSVersionCheckSkipped", []interface{}{arg1})
+	fake.oSVersionCheckSkippedMutex.Unlock()
+	if stub != nil {
+		fake.OSVersionCheckSkippedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) OSVersionCheckSkippedCallCount() int {
+	fake.oSVersionCheckSkippedMutex.RLock()
+	defer fake.oSVersionCheckSkippedMutex.RUnlock()
+	return len(fake.oSVersionCheckSkippedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) OSVersionCheckSkippedCalls(stub func(string)) {
+	fake.oSVersionCheckSkippedMutex.Lock()
+	defer fake.oSVersionCheckSkippedMutex.Unlock()
+	fake.OSVersionCheckSkippedStub = stub
+}
+
+func (fake *FakeConstructMessenger) OSVersionCheckSkippedArgsForCall(i int) string {
+	fake.oSVersionCheckSkippedMutex.RLock()
+	defer fake.oSVersionCheckSkippedMutex.RUnlock()
+	argsForCall := fake.oSVersionCheckSkippedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) OSVersionFileCreationFailed(arg1 string) {
+	fake.oSVersionFileCreationFailedMutex.Lock()
+	fake.oSVersionFileCreationFailedArgsForCall = append(fake.oSVersionFileCreationFailedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.OSVersionFileCreationFailedStub
+	fake.recordInvocation("OSVersionFileCreationFailed", []interface{}{arg1})
+	fake.oSVersionFileCreationFailedMutex.Unlock()
+	if stub != nil {
+		fake.OSVersionFileCreationFailedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) OSVersionFileCreationFailedCallCount() int {
+	fake.oSVersionFileCreationFailedMutex.RLock()
+	defer fake.oSVersionFileCreationFailedMutex.RUnlock()
+	return len(fake.oSVersionFileCreationFailedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) OSVersionFileCreationFailedCalls(stub func(string)) {
+	fake.oSVersionFileCreationFailedMutex.Lock()
+	defer fake.oSVersionFileCreationFailedMutex.Unlock()
+	fake.OSVersionFileCreationFailedStub = stub
+}
+
+func (fake *FakeConstructMessenger) OSVersionFileCreationFailedArgsForCall(i int) string {
+	fake.oSVersionFileCreationFailedMutex.RLock()
+	defer fake.oSVersionFileCreationFailedMutex.RUnlock()
+	argsForCall := fake.oSVersionFileCreationFailedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) OSVersionMismatchIgnored(arg1 string) {
+	fake.oSVersionMismatchIgnoredMutex.Lock()
+	fake.oSVersionMismatchIgnoredArgsForCall = append(fake.oSVersionMismatchIgnoredArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.OSVersionMismatchIgnoredStub
+	fake.recordInvocation("OSVersionMismatchIgnored", []interface{}{arg1})
+	fake.oSVersionMismatchIgnoredMutex.Unlock()
+	if stub != nil {
+		fake.OSVersionMismatchIgnoredStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) OSVersionMismatchIgnoredCallCount() int {
+	fake.oSVersionMismatchIgnoredMutex.RLock()
+	defer fake.oSVersionMismatchIgnoredMutex.RUnlock()
+	return len(fake.oSVersionMismatchIgnoredArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) OSVersionMismatchIgnoredCalls(stub func(string)) {
+	fake.oSVersionMismatchIgnoredMutex.Lock()
+	defer fake.oSVersionMismatchIgnoredMutex.Unlock()
+	fake.OSVersionMismatchIgnoredStub = stub
+}
+
+func (fake *FakeConstructMessenger) OSVersionMismatchIgnoredArgsForCall(i int) string {
+	fake.oSVersionMismatchIgnoredMutex.RLock()
+	defer fake.oSVersionMismatchIgnoredMutex.RUnlock()
+	argsForCall := fake.oSVersionMismatchIgnoredArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) RebootHasFinished() {
 	fake.rebootHasFinishedMutex.Lock()
 	fake.rebootHasFinishedArgsForCall = append(fake.rebootHasFinishedArgsForCall, struct {
@@ -478,6 +1130,62 @@ func (fake *FakeConstructMessenger) RebootHasStartedCalls(stub func()) {
 	fake.RebootHasStartedStub = stub
 }
 
+func (fake *FakeConstructMessenger) RollbackStarted(arg1 string) {
+	fake.rollbackStartedMutex.Lock()
+	fake.rollbackStartedArgsForCall = append(fake.rollbackStartedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RollbackStartedStub
+	fake.recordInvocation("RollbackStarted", []interface{}{arg1})
+	fake.rollbackStartedMutex.Unlock()
+	if stub != nil {
+		fake.RollbackStartedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) RollbackStartedCallCount() int {
+	fake.rollbackStartedMutex.RLock()
+	defer fake.rollbackStartedMutex.RUnlock()
+	return len(fake.rollbackStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) RollbackStartedCalls(stub func(string)) {
+	fake.rollbackStartedMutex.Lock()
+	defer fake.rollbackStartedMutex.Unlock()
+	fake.RollbackStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) RollbackStartedArgsForCall(i int) string {
+	fake.rollbackStartedMutex.RLock()
+	defer fake.rollbackStartedMutex.RUnlock()
+	argsForCall := fake.rollbackStartedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) RollbackSucceeded() {
+	fake.rollbackSucceededMutex.Lock()
+	fake.rollbackSucceededArgsForCall = append(fake.rollbackSucceededArgsForCall, struct {
+	}{})
+	stub := fake.RollbackSucceededStub
+	fake.recordInvocation("RollbackSucceeded", []interface{}{})
+	fake.rollbackSucceededMutex.Unlock()
+	if stub != nil {
+		fake.RollbackSucceededStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) RollbackSucceededCallCount() int {
+	fake.rollbackSucceededMutex.RLock()
+	defer fake.rollbackSucceededMutex.RUnlock()
+	return len(fake.rollbackSucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) RollbackSucceededCalls(stub func()) {
+	fake.rollbackSucceededMutex.Lock()
+	defer fake.rollbackSucceededMutex.Unlock()
+	fake.RollbackSucceededStub = stub
+}
+
 func (fake *FakeConstructMessenger) ShutdownCompleted() {
 	fake.shutdownCompletedMutex.Lock()
 	fake.shutdownCompletedArgsForCall = append(fake.shutdownCompletedArgsForCall, struct {
@@ -502,6 +1210,334 @@ func (fake *FakeConstructMessenger) ShutdownCompletedCalls(stub func()) {
 	fake.ShutdownCompletedStub = stub
 }
 
+func (fake *FakeConstructMessenger) SnapshotNotDeleted(arg1 string, arg2 error) {
+	fake.snapshotNotDeletedMutex.Lock()
+	fake.snapshotNotDeletedArgsForCall = append(fake.snapshotNotDeletedArgsForCall, struct {
+		arg1 string
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.SnapshotNotDeletedStub
+	fake.recordInvocation("SnapshotNotDeleted", []interface{}{arg1, arg2})
+	fake.snapshotNotDeletedMutex.Unlock()
+	if stub != nil {
+		fake.SnapshotNotDeletedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeConstructMessenger) SnapshotNotDeletedCallCount() int {
+	fake.snapshotNotDeletedMutex.RLock()
+	defer fake.snapshotNotDeletedMutex.RUnlock()
+	return len(fake.snapshotNotDeletedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) SnapshotNotDeletedCalls(stub func(string, error)) {
+	fake.snapshotNotDeletedMutex.Lock()
+	defer fake.snapshotNotDeletedMutex.Unlock()
+	fake.SnapshotNotDeletedStub = stub
+}
+
+func (fake *FakeConstructMessenger) SnapshotNotDeletedArgsForCall(i int) (string, error) {
+	fake.snapshotNotDeletedMutex.RLock()
+	defer fake.snapshotNotDeletedMutex.RUnlock()
+	argsForCall := fake.snapshotNotDeletedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeConstructMessenger) SnapshotRetained(arg1 string) {
+	fake.snapshotRetainedMutex.Lock()
+	fake.snapshotRetainedArgsForCall = append(fake.snapshotRetainedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.SnapshotRetainedStub
+	fake.recordInvocation("SnapshotRetained", []interface{}{arg1})
+	fake.snapshotRetainedMutex.Unlock()
+	if stub != nil {
+		fake.SnapshotRetainedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) SnapshotRetainedCallCount() int {
+	fake.snapshotRetainedMutex.RLock()
+	defer fake.snapshotRetainedMutex.RUnlock()
+	return len(fake.snapshotRetainedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) SnapshotRetainedCalls(stub func(string)) {
+	fake.snapshotRetainedMutex.Lock()
+	defer fake.snapshotRetainedMutex.Unlock()
+	fake.SnapshotRetainedStub = stub
+}
+
+func (fake *FakeConstructMessenger) SnapshotRetainedArgsForCall(i int) string {
+	fake.snapshotRetainedMutex.RLock()
+	defer fake.snapshotRetainedMutex.RUnlock()
+	argsForCall := fake.snapshotRetainedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) SnapshotReused(arg1 string) {
+	fake.snapshotReusedMutex.Lock()
+	fake.snapshotReusedArgsForCall = append(fake.snapshotReusedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.SnapshotReusedStub
+	fake.recordInvocation("SnapshotReused", []interface{}{arg1})
+	fake.snapshotReusedMutex.Unlock()
+	if stub != nil {
+		fake.SnapshotReusedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) SnapshotReusedCallCount() int {
+	fake.snapshotReusedMutex.RLock()
+	defer fake.snapshotReusedMutex.RUnlock()
+	return len(fake.snapshotReusedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) SnapshotReusedCalls(stub func(string)) {
+	fake.snapshotReusedMutex.Lock()
+	defer fake.snapshotReusedMutex.Unlock()
+	fake.SnapshotReusedStub = stub
+}
+
+func (fake *FakeConstructMessenger) SnapshotReusedArgsForCall(i int) string {
+	fake.snapshotReusedMutex.RLock()
+	defer fake.snapshotReusedMutex.RUnlock()
+	argsForCall := fake.snapshotReusedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) StepFailed(arg1 string, arg2 error) {
+	fake.stepFailedMutex.Lock()
+	fake.stepFailedArgsForCall = append(fake.stepFailedArgsForCall, struct {
+		arg1 string
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.StepFailedStub
+	fake.recordInvocation("StepFailed", []interface{}{arg1, arg2})
+	fake.stepFailedMutex.Unlock()
+	if stub != nil {
+		fake.StepFailedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeConstructMessenger) StepFailedCallCount() int {
+	fake.stepFailedMutex.RLock()
+	defer fake.stepFailedMutex.RUnlock()
+	return len(fake.stepFailedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) StepFailedCalls(stub func(string, error)) {
+	fake.stepFailedMutex.Lock()
+	defer fake.stepFailedMutex.Unlock()
+	fake.StepFailedStub = stub
+}
+
+func (fake *FakeConstructMessenger) StepFailedArgsForCall(i int) (string, error) {
+	fake.stepFailedMutex.RLock()
+	defer fake.stepFailedMutex.RUnlock()
+	argsForCall := fake.stepFailedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeConstructMessenger) StepSkipped(arg1 string) {
+	fake.stepSkippedMutex.Lock()
+	fake.stepSkippedArgsForCall = append(fake.stepSkippedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.StepSkippedStub
+	fake.recordInvocation("StepSkipped", []interface{}{arg1})
+	fake.stepSkippedMutex.Unlock()
+	if stub != nil {
+		fake.StepSkippedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) StepSkippedCallCount() int {
+	fake.stepSkippedMutex.RLock()
+	defer fake.stepSkippedMutex.RUnlock()
+	return len(fake.stepSkippedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) StepSkippedCalls(stub func(string)) {
+	fake.stepSkippedMutex.Lock()
+	defer fake.stepSkippedMutex.Unlock()
+	fake.StepSkippedStub = stub
+}
+
+func (fake *FakeConstructMessenger) StepSkippedArgsForCall(i int) string {
+	fake.stepSkippedMutex.RLock()
+	defer fake.stepSkippedMutex.RUnlock()
+	argsForCall := fake.stepSkippedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) StepStarted(arg1 string) {
+	fake.stepStartedMutex.Lock()
+	fake.stepStartedArgsForCall = append(fake.stepStartedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.StepStartedStub
+	fake.recordInvocation("StepStarted", []interface{}{arg1})
+	fake.stepStartedMutex.Unlock()
+	if stub != nil {
+		fake.StepStartedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) StepStartedCallCount() int {
+	fake.stepStartedMutex.RLock()
+	defer fake.stepStartedMutex.RUnlock()
+	return len(fake.stepStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) StepStartedCalls(stub func(string)) {
+	fake.stepStartedMutex.Lock()
+	defer fake.stepStartedMutex.Unlock()
+	fake.StepStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) StepStartedArgsForCall(i int) string {
+	fake.stepStartedMutex.RLock()
+	defer fake.stepStartedMutex.RUnlock()
+	argsForCall := fake.stepStartedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) StepSucceeded(arg1 string) {
+	fake.stepSucceededMutex.Lock()
+	fake.stepSucceededArgsForCall = append(fake.stepSucceededArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.StepSucceededStub
+	fake.recordInvocation("StepSucceeded", []interface{}{arg1})
+	fake.stepSucceededMutex.Unlock()
+	if stub != nil {
+		fake.StepSucceededStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) StepSucceededCallCount() int {
+	fake.stepSucceededMutex.RLock()
+	defer fake.stepSucceededMutex.RUnlock()
+	return len(fake.stepSucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) StepSucceededCalls(stub func(string)) {
+	fake.stepSucceededMutex.Lock()
+	defer fake.stepSucceededMutex.Unlock()
+	fake.StepSucceededStub = stub
+}
+
+func (fake *FakeConstructMessenger) StepSucceededArgsForCall(i int) string {
+	fake.stepSucceededMutex.RLock()
+	defer fake.stepSucceededMutex.RUnlock()
+	argsForCall := fake.stepSucceededArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) StepTimings(arg1 []report.Step) {
+	var arg1Copy []report.Step
+	if arg1 != nil {
+		arg1Copy = make([]report.Step, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.stepTimingsMutex.Lock()
+	fake.stepTimingsArgsForCall = append(fake.stepTimingsArgsForCall, struct {
+		arg1 []report.Step
+	}{arg1Copy})
+	stub := fake.StepTimingsStub
+	fake.recordInvocation("StepTimings", []interface{}{arg1Copy})
+	fake.stepTimingsMutex.Unlock()
+	if stub != nil {
+		fake.StepTimingsStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) StepTimingsCallCount() int {
+	fake.stepTimingsMutex.RLock()
+	defer fake.stepTimingsMutex.RUnlock()
+	return len(fake.stepTimingsArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) StepTimingsCalls(stub func([]report.Step)) {
+	fake.stepTimingsMutex.Lock()
+	defer fake.stepTimingsMutex.Unlock()
+	fake.StepTimingsStub = stub
+}
+
+func (fake *FakeConstructMessenger) StepTimingsArgsForCall(i int) []report.Step {
+	fake.stepTimingsMutex.RLock()
+	defer fake.stepTimingsMutex.RUnlock()
+	argsForCall := fake.stepTimingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) StepVerificationFailed(arg1 string, arg2 string) {
+	fake.stepVerificationFailedMutex.Lock()
+	fake.stepVerificationFailedArgsForCall = append(fake.stepVerificationFailedArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.StepVerificationFailedStub
+	fake.recordInvocation("StepVerificationFailed", []interface{}{arg1, arg2})
+	fake.stepVerificationFailedMutex.Unlock()
+	if stub != nil {
+		fake.StepVerificationFailedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeConstructMessenger) StepVerificationFailedCallCount() int {
+	fake.stepVerificationFailedMutex.RLock()
+	defer fake.stepVerificationFailedMutex.RUnlock()
+	return len(fake.stepVerificationFailedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) StepVerificationFailedCalls(stub func(string, string)) {
+	fake.stepVerificationFailedMutex.Lock()
+	defer fake.stepVerificationFailedMutex.Unlock()
+	fake.StepVerificationFailedStub = stub
+}
+
+func (fake *FakeConstructMessenger) StepVerificationFailedArgsForCall(i int) (string, string) {
+	fake.stepVerificationFailedMutex.RLock()
+	defer fake.stepVerificationFailedMutex.RUnlock()
+	argsForCall := fake.stepVerificationFailedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeConstructMessenger) TimingsNotSaved(arg1 error) {
+	fake.timingsNotSavedMutex.Lock()
+	fake.timingsNotSavedArgsForCall = append(fake.timingsNotSavedArgsForCall, struct {
+		arg1 error
+	}{arg1})
+	stub := fake.TimingsNotSavedStub
+	fake.recordInvocation("TimingsNotSaved", []interface{}{arg1})
+	fake.timingsNotSavedMutex.Unlock()
+	if stub != nil {
+		fake.TimingsNotSavedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) TimingsNotSavedCallCount() int {
+	fake.timingsNotSavedMutex.RLock()
+	defer fake.timingsNotSavedMutex.RUnlock()
+	return len(fake.timingsNotSavedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) TimingsNotSavedCalls(stub func(error)) {
+	fake.timingsNotSavedMutex.Lock()
+	defer fake.timingsNotSavedMutex.Unlock()
+	fake.TimingsNotSavedStub = stub
+}
+
+func (fake *FakeConstructMessenger) TimingsNotSavedArgsForCall(i int) error {
+	fake.timingsNotSavedMutex.RLock()
+	defer fake.timingsNotSavedMutex.RUnlock()
+	argsForCall := fake.timingsNotSavedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) UploadArtifactsStarted() {
 	fake.uploadArtifactsStartedMutex.Lock()
 	fake.uploadArtifactsStartedArgsForCall = append(fake.uploadArtifactsStartedArgsForCall, struct {
@@ -606,6 +1642,94 @@ func (fake *FakeConstructMessenger) UploadFileSucceededCalls(stub func()) {
 	fake.UploadFileSucceededStub = stub
 }
 
+func (fake *FakeConstructMessenger) VMIPNotReported(arg1 string) {
+	fake.vMIPNotReportedMutex.Lock()
+	fake.vMIPNotReportedArgsForCall = append(fake.vMIPNotReportedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.VMIPNotReportedStub
+	fake.recordInvocation("VMIPNotReported", []interface{}{arg1})
+	fake.vMIPNotReportedMutex.Unlock()
+	if stub != nil {
+		fake.VMIPNotReportedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) VMIPNotReportedCallCount() int {
+	fake.vMIPNotReportedMutex.RLock()
+	defer fake.vMIPNotReportedMutex.RUnlock()
+	return len(fake.vMIPNotReportedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) VMIPNotReportedCalls(stub func(string)) {
+	fake.vMIPNotReportedMutex.Lock()
+	defer fake.vMIPNotReportedMutex.Unlock()
+	fake.VMIPNotReportedStub = stub
+}
+
+func (fake *FakeConstructMessenger) VMIPNotReportedArgsForCall(i int) string {
+	fake.vMIPNotReportedMutex.RLock()
+	defer fake.vMIPNotReportedMutex.RUnlock()
+	argsForCall := fake.vMIPNotReportedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionStarted() {
+	fake.validateOSVersionStartedMutex.Lock()
+	fake.validateOSVersionStartedArgsForCall = append(fake.validateOSVersionStartedArgsForCall, struct {
+	}{})
+	stub := fake.ValidateOSVersionStartedStub
+	fake.recordInvocation("ValidateOSVersionStarted", []interface{}{})
+	fake.validateOSVersionStartedMutex.Unlock()
+	if stub != nil {
+		fake.ValidateOSVersionStartedStub()
+	}
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionStartedCallCount() int {
+	fake.validateOSVersionStartedMutex.RLock()
+	defer fake.validateOSVersionStartedMutex.RUnlock()
+	return len(fake.validateOSVersionStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionStartedCalls(stub func()) {
+	fake.validateOSVersionStartedMutex.Lock()
+	defer fake.validateOSVersionStartedMutex.Unlock()
+	fake.ValidateOSVersionStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionSucceeded(arg1 string) {
+	fake.validateOSVersionSucceededMutex.Lock()
+	fake.validateOSVersionSucceededArgsForCall = append(fake.validateOSVersionSucceededArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ValidateOSVersionSucceededStub
+	fake.recordInvocation("ValidateOSVersionSucceeded", []interface{}{arg1})
+	fake.validateOSVersionSucceededMutex.Unlock()
+	if stub != nil {
+		fake.ValidateOSVersionSucceededStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionSucceededCallCount() int {
+	fake.validateOSVersionSucceededMutex.RLock()
+	defer fake.validateOSVersionSucceededMutex.RUnlock()
+	return len(fake.validateOSVersionSucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionSucceededCalls(stub func(string)) {
+	fake.validateOSVersionSucceededMutex.Lock()
+	defer fake.validateOSVersionSucceededMutex.Unlock()
+	fake.ValidateOSVersionSucceededStub = stub
+}
+
+func (fake *FakeConstructMessenger) ValidateOSVersionSucceededArgsForCall(i int) string {
+	fake.validateOSVersionSucceededMutex.RLock()
+	defer fake.validateOSVersionSucceededMutex.RUnlock()
+	argsForCall := fake.validateOSVersionSucceededArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) ValidateVMConnectionStarted() {
 	fake.validateVMConnectionStartedMutex.Lock()
 	fake.validateVMConnectionStartedArgsForCall = append(fake.validateVMConnectionStartedArgsForCall, struct {
@@ -702,13 +1826,174 @@ func (fake *FakeConstructMessenger) WinRMDisconnectedForRebootCalls(stub func())
 	fake.WinRMDisconnectedForRebootStub = stub
 }
 
+func (fake *FakeConstructMessenger) WindowsUpdatesFinished(arg1 []string) {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.windowsUpdatesFinishedMutex.Lock()
+	fake.windowsUpdatesFinishedArgsForCall = append(fake.windowsUpdatesFinishedArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.WindowsUpdatesFinishedStub
+	fake.recordInvocation("WindowsUpdatesFinished", []interface{}{arg1Copy})
+	fake.windowsUpdatesFinishedMutex.Unlock()
+	if stub != nil {
+		fake.WindowsUpdatesFinishedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesFinishedCallCount() int {
+	fake.windowsUpdatesFinishedMutex.RLock()
+	defer fake.windowsUpdatesFinishedMutex.RUnlock()
+	return len(fake.windowsUpdatesFinishedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesFinishedCalls(stub func([]string)) {
+	fake.windowsUpdatesFinishedMutex.Lock()
+	defer fake.windowsUpdatesFinishedMutex.Unlock()
+	fake.WindowsUpdatesFinishedStub = stub
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesFinishedArgsForCall(i int) []string {
+	fake.windowsUpdatesFinishedMutex.RLock()
+	defer fake.windowsUpdatesFinishedMutex.RUnlock()
+	argsForCall := fake.windowsUpdatesFinishedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundLimitReached(arg1 int) {
+	fake.windowsUpdatesRoundLimitReachedMutex.Lock()
+	fake.windowsUpdatesRoundLimitReachedArgsForCall = append(fake.windowsUpdatesRoundLimitReachedArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.WindowsUpdatesRoundLimitReachedStub
+	fake.recordInvocation("WindowsUpdatesRoundLimitReached", []interface{}{arg1})
+	fake.windowsUpdatesRoundLimitReachedMutex.Unlock()
+	if stub != nil {
+		fake.WindowsUpdatesRoundLimitReachedStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundLimitReachedCallCount() int {
+	fake.windowsUpdatesRoundLimitReachedMutex.RLock()
+	defer fake.windowsUpdatesRoundLimitReachedMutex.RUnlock()
+	return len(fake.windowsUpdatesRoundLimitReachedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundLimitReachedCalls(stub func(int)) {
+	fake.windowsUpdatesRoundLimitReachedMutex.Lock()
+	defer fake.windowsUpdatesRoundLimitReachedMutex.Unlock()
+	fake.WindowsUpdatesRoundLimitReachedStub = stub
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundLimitReachedArgsForCall(i int) int {
+	fake.windowsUpdatesRoundLimitReachedMutex.RLock()
+	defer fake.windowsUpdatesRoundLimitReachedMutex.RUnlock()
+	argsForCall := fake.windowsUpdatesRoundLimitReachedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundStarted(arg1 int, arg2 int) {
+	fake.windowsUpdatesRoundStartedMutex.Lock()
+	fake.windowsUpdatesRoundStartedArgsForCall = append(fake.windowsUpdatesRoundStartedArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	stub := fake.WindowsUpdatesRoundStartedStub
+	fake.recordInvocation("WindowsUpdatesRoundStarted", []interface{}{arg1, arg2})
+	fake.windowsUpdatesRoundStartedMutex.Unlock()
+	if stub != nil {
+		fake.WindowsUpdatesRoundStartedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundStartedCallCount() int {
+	fake.windowsUpdatesRoundStartedMutex.RLock()
+	defer fake.windowsUpdatesRoundStartedMutex.RUnlock()
+	return len(fake.windowsUpdatesRoundStartedArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundStartedCalls(stub func(int, int)) {
+	fake.windowsUpdatesRoundStartedMutex.Lock()
+	defer fake.windowsUpdatesRoundStartedMutex.Unlock()
+	fake.WindowsUpdatesRoundStartedStub = stub
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundStartedArgsForCall(i int) (int, int) {
+	fake.windowsUpdatesRoundStartedMutex.RLock()
+	defer fake.windowsUpdatesRoundStartedMutex.RUnlock()
+	argsForCall := fake.windowsUpdatesRoundStartedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundSucceeded(arg1 []string) {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.windowsUpdatesRoundSucceededMutex.Lock()
+	fake.windowsUpdatesRoundSucceededArgsForCall = append(fake.windowsUpdatesRoundSucceededArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.WindowsUpdatesRoundSucceededStub
+	fake.recordInvocation("WindowsUpdatesRoundSucceeded", []interface{}{arg1Copy})
+	fake.windowsUpdatesRoundSucceededMutex.Unlock()
+	if stub != nil {
+		fake.WindowsUpdatesRoundSucceededStub(arg1)
+	}
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundSucceededCallCount() int {
+	fake.windowsUpdatesRoundSucceededMutex.RLock()
+	defer fake.windowsUpdatesRoundSucceededMutex.RUnlock()
+	return len(fake.windowsUpdatesRoundSucceededArgsForCall)
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundSucceededCalls(stub func([]string)) {
+	fake.windowsUpdatesRoundSucceededMutex.Lock()
+	defer fake.windowsUpdatesRoundSucceededMutex.Unlock()
+	fake.WindowsUpdatesRoundSucceededStub = stub
+}
+
+func (fake *FakeConstructMessenger) WindowsUpdatesRoundSucceededArgsForCall(i int) []string {
+	fake.windowsUpdatesRoundSucceededMutex.RLock()
+	defer fake.windowsUpdatesRoundSucceededMutex.RUnlock()
+	argsForCall := fake.windowsUpdatesRoundSucceededArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeConstructMessenger) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.alreadyConstructedMutex.RLock()
+	defer fake.alreadyConstructedMutex.RUnlock()
+	fake.checkVMIPStartedMutex.RLock()
+	defer fake.checkVMIPStartedMutex.RUnlock()
+	fake.checkVMIPSucceededMutex.RLock()
+	defer fake.checkVMIPSucceededMutex.RUnlock()
+	fake.checkpointNotSavedMutex.RLock()
+	defer fake.checkpointNotSavedMutex.RUnlock()
+	fake.checkpointsDisabledMutex.RLock()
+	defer fake.checkpointsDisabledMutex.RUnlock()
+	fake.confirmVMIdentityStartedMutex.RLock()
+	defer fake.confirmVMIdentityStartedMutex.RUnlock()
+	fake.confirmVMIdentitySucceededMutex.RLock()
+	defer fake.confirmVMIdentitySucceededMutex.RUnlock()
 	fake.createProvisionDirStartedMutex.RLock()
 	defer fake.createProvisionDirStartedMutex.RUnlock()
 	fake.createProvisionDirSucceededMutex.RLock()
 	defer fake.createProvisionDirSucceededMutex.RUnlock()
+	fake.createSnapshotStartedMutex.RLock()
+	defer fake.createSnapshotStartedMutex.RUnlock()
+	fake.createSnapshotSucceededMutex.RLock()
+	defer fake.createSnapshotSucceededMutex.RUnlock()
+	fake.downloadFileFailedMutex.RLock()
+	defer fake.downloadFileFailedMutex.RUnlock()
+	fake.downloadGuestLogsStartedMutex.RLock()
+	defer fake.downloadGuestLogsStartedMutex.RUnlock()
 	fake.enableWinRMStartedMutex.RLock()
 	defer fake.enableWinRMStartedMutex.RUnlock()
 	fake.enableWinRMSucceededMutex.RLock()
@@ -723,20 +2008,54 @@ func (fake *FakeConstructMessenger) Invocations() map[string][][]interface{} {
 	defer fake.executeSetupScriptStartedMutex.RUnlock()
 	fake.executeSetupScriptSucceededMutex.RLock()
 	defer fake.executeSetupScriptSucceededMutex.RUnlock()
+	fake.exitCodeRetrievalFailedMutex.RLock()
+	defer fake.exitCodeRetrievalFailedMutex.RUnlock()
 	fake.extractArtifactsStartedMutex.RLock()
 	defer fake.extractArtifactsStartedMutex.RUnlock()
 	fake.extractArtifactsSucceededMutex.RLock()
 	defer fake.extractArtifactsSucceededMutex.RUnlock()
+	fake.guestLogsNotDownloadedMutex.RLock()
+	defer fake.guestLogsNotDownloadedMutex.RUnlock()
 	fake.logOutUsersStartedMutex.RLock()
 	defer fake.logOutUsersStartedMutex.RUnlock()
 	fake.logOutUsersSucceededMutex.RLock()
 	defer fake.logOutUsersSucceededMutex.RUnlock()
+	fake.oSVersionCheckSkippedMutex.RLock()
+	defer fake.oSVersionCheckSkippedMutex.RUnlock()
+	fake.oSVersionFileCreationFailedMutex.RLock()
+	defer fake.oSVersionFileCreationFailedMutex.RUnlock()
+	fake.oSVersionMismatchIgnoredMutex.RLock()
+	defer fake.oSVersionMismatchIgnoredMutex.RUnlock()
 	fake.rebootHasFinishedMutex.RLock()
 	defer fake.rebootHasFinishedMutex.RUnlock()
 	fake.rebootHasStartedMutex.RLock()
 	defer fake.rebootHasStartedMutex.RUnlock()
+	fake.rollbackStartedMutex.RLock()
+	defer fake.rollbackStartedMutex.RUnlock()
+	fake.rollbackSucceededMutex.RLock()
+	defer fake.rollbackSucceededMutex.RUnlock()
 	fake.shutdownCompletedMutex.RLock()
 	defer fake.shutdownCompletedMutex.RUnlock()
+	fake.snapshotNotDeletedMutex.RLock()
+	defer fake.snapshotNotDeletedMutex.RUnlock()
+	fake.snapshotRetainedMutex.RLock()
+	defer fake.snapshotRetainedMutex.RUnlock()
+	fake.snapshotReusedMutex.RLock()
+	defer fake.snapshotReusedMutex.RUnlock()
+	fake.stepFailedMutex.RLock()
+	defer fake.stepFailedMutex.RUnlock()
+	fake.stepSkippedMutex.RLock()
+	defer fake.stepSkippedMutex.RUnlock()
+	fake.stepStartedMutex.RLock()
+	defer fake.stepStartedMutex.RUnlock()
+	fake.stepSucceededMutex.RLock()
+	defer fake.stepSucceededMutex.RUnlock()
+	fake.stepTimingsMutex.RLock()
+	defer fake.stepTimingsMutex.RUnlock()
+	fake.stepVerificationFailedMutex.RLock()
+	defer fake.stepVerificationFailedMutex.RUnlock()
+	fake.timingsNotSavedMutex.RLock()
+	defer fake.timingsNotSavedMutex.RUnlock()
 	fake.uploadArtifactsStartedMutex.RLock()
 	defer fake.uploadArtifactsStartedMutex.RUnlock()
 	fake.uploadArtifactsSucceededMutex.RLock()
@@ -745,6 +2064,12 @@ func (fake *FakeConstructMessenger) Invocations() map[string][][]interface{} {
 	defer fake.uploadFileStartedMutex.RUnlock()
 	fake.uploadFileSucceededMutex.RLock()
 	defer fake.uploadFileSucceededMutex.RUnlock()
+	fake.vMIPNotReportedMutex.RLock()
+	defer fake.vMIPNotReportedMutex.RUnlock()
+	fake.validateOSVersionStartedMutex.RLock()
+	defer fake.validateOSVersionStartedMutex.RUnlock()
+	fake.validateOSVersionSucceededMutex.RLock()
+	defer fake.validateOSVersionSucceededMutex.RUnlock()
 	fake.validateVMConnectionStartedMutex.RLock()
 	defer fake.validateVMConnectionStartedMutex.RUnlock()
 	fake.validateVMConnectionSucceededMutex.RLock()
@@ -753,6 +2078,14 @@ func (fake *FakeConstructMessenger) Invocations() map[string][][]interface{} {
 	defer fake.waitingForShutdownMutex.RUnlock()
 	fake.winRMDisconnectedForRebootMutex.RLock()
 	defer fake.winRMDisconnectedForRebootMutex.RUnlock()
+	fake.windowsUpdatesFinishedMutex.RLock()
+	defer fake.windowsUpdatesFinishedMutex.RUnlock()
+	fake.windowsUpdatesRoundLimitReachedMutex.RLock()
+	defer fake.windowsUpdatesRoundLimitReachedMutex.RUnlock()
+	fake.windowsUpdatesRoundStartedMutex.RLock()
+	defer fake.windowsUpdatesRoundStartedMutex.RUnlock()
+	fake.windowsUpdatesRoundSucceededMutex.RLock()
+	defer fake.windowsUpdatesRoundSucceededMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
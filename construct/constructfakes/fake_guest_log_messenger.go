@@ -0,0 +1,76 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package constructfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/construct"
+)
+
+type FakeGuestLogMessenger struct {
+	GuestLogLineStub        func(string)
+	guestLogLineMutex       sync.RWMutex
+	guestLogLineArgsForCall []struct {
+		arg1 string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeGuestLogMessenger) GuestLogLine(arg1 string) {
+	fake.guestLogLineMutex.Lock()
+	fake.guestLogLineArgsForCall = append(fake.guestLogLineArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GuestLogLineStub
+	fake.recordInvocation("GuestLogLine", []interface{}{arg1})
+	fake.guestLogLineMutex.Unlock()
+	if stub != nil {
+		fake.GuestLogLineStub(arg1)
+	}
+}
+
+func (fake *FakeGuestLogMessenger) GuestLogLineCallCount() int {
+	fake.guestLogLineMutex.RLock()
+	defer fake.guestLogLineMutex.RUnlock()
+	return len(fake.guestLogLineArgsForCall)
+}
+
+func (fake *FakeGuestLogMessenger) GuestLogLineCalls(stub func(string)) {
+	fake.guestLogLineMutex.Lock()
+	defer fake.guestLogLineMutex.Unlock()
+	fake.GuestLogLineStub = stub
+}
+
+func (fake *FakeGuestLogMessenger) GuestLogLineArgsForCall(i int) string {
+	fake.guestLogLineMutex.RLock()
+	defer fake.guestLogLineMutex.RUnlock()
+	argsForCall := fake.guestLogLineArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeGuestLogMessenger) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.guestLogLineMutex.RLock()
+	defer fake.guestLogLineMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeGuestLogMessenger) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ construct.GuestLogMessenger = new(FakeGuestLogMessenger)
@@ -0,0 +1,107 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package constructfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/construct"
+)
+
+type FakeGuestNetwork struct {
+	IPAddressesStub        func() ([]string, error)
+	iPAddressesMutex       sync.RWMutex
+	iPAddressesArgsForCall []struct {
+	}
+	iPAddressesReturns struct {
+		result1 []string
+		result2 error
+	}
+	iPAddressesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeGuestNetwork) IPAddresses() ([]string, error) {
+	fake.iPAddressesMutex.Lock()
+	ret, specificReturn := fake.iPAddressesReturnsOnCall[len(fake.iPAddressesArgsForCall)]
+	fake.iPAddressesArgsForCall = append(fake.iPAddressesArgsForCall, struct {
+	}{})
+	stub := fake.IPAddressesStub
+	fakeReturns := fake.iPAddressesReturns
+	fake.recordInvocation("IPAddresses", []interface{}{})
+	fake.iPAddressesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGuestNetwork) IPAddressesCallCount() int {
+	fake.iPAddressesMutex.RLock()
+	defer fake.iPAddressesMutex.RUnlock()
+	return len(fake.iPAddressesArgsForCall)
+}
+
+func (fake *FakeGuestNetwork) IPAddressesCalls(stub func() ([]string, error)) {
+	fake.iPAddressesMutex.Lock()
+	defer fake.iPAddressesMutex.Unlock()
+	fake.IPAddressesStub = stub
+}
+
+func (fake *FakeGuestNetwork) IPAddressesReturns(result1 []string, result2 error) {
+	fake.iPAddressesMutex.Lock()
+	defer fake.iPAddressesMutex.Unlock()
+	fake.IPAddressesStub = nil
+	fake.iPAddressesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGuestNetwork) IPAddressesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.iPAddressesMutex.Lock()
+	defer fake.iPAddressesMutex.Unlock()
+	fake.IPAddressesStub = nil
+	if fake.iPAddressesReturnsOnCall == nil {
+		fake.iPAddressesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.iPAddressesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGuestNetwork) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.iPAddressesMutex.RLock()
+	defer fake.iPAddressesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeGuestNetwork) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ construct.GuestNetwork = new(FakeGuestNetwork)
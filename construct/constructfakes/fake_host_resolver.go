@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package constructfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/construct"
+)
+
+type FakeHostResolver struct {
+	LookupHostStub        func(context.Context, string) ([]string, error)
+	lookupHostMutex       sync.RWMutex
+	lookupHostArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	lookupHostReturns struct {
+		result1 []string
+		result2 error
+	}
+	lookupHostReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHostResolver) LookupHost(arg1 context.Context, arg2 string) ([]string, error) {
+	fake.lookupHostMutex.Lock()
+	ret, specificReturn := fake.lookupHostReturnsOnCall[len(fake.lookupHostArgsForCall)]
+	fake.lookupHostArgsForCall = append(fake.lookupHostArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LookupHostStub
+	fakeReturns := fake.lookupHostReturns
+	fake.recordInvocation("LookupHost", []interface{}{arg1, arg2})
+	fake.lookupHostMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHostResolver) LookupHostCallCount() int {
+	fake.lookupHostMutex.RLock()
+	defer fake.lookupHostMutex.RUnlock()
+	return len(fake.lookupHostArgsForCall)
+}
+
+func (fake *FakeHostResolver) LookupHostCalls(stub func(context.Context, string) ([]string, error)) {
+	fake.lookupHostMutex.Lock()
+	defer fake.lookupHostMutex.Unlock()
+	fake.LookupHostStub = stub
+}
+
+func (fake *FakeHostResolver) LookupHostArgsForCall(i int) (context.Context, string) {
+	fake.lookupHostMutex.RLock()
+	defer fake.lookupHostMutex.RUnlock()
+	argsForCall := fake.lookupHostArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeHostResolver) LookupHostReturns(result1 []string, result2 error) {
+	fake.lookupHostMutex.Lock()
+	defer fake.lookupHostMutex.Unlock()
+	fake.LookupHostStub = nil
+	fake.lookupHostReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHostResolver) LookupHostReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.lookupHostMutex.Lock()
+	defer fake.lookupHostMutex.Unlock()
+	fake.LookupHostStub = nil
+	if fake.lookupHostReturnsOnCall == nil {
+		fake.lookupHostReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.lookupHostReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHostResolver) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.lookupHostMutex.RLock()
+	defer fake.lookupHostMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeHostResolver) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ construct.HostResolver = new(FakeHostResolver)
@@ -8,6 +8,10 @@ import (
 )
 
 type FakeRebootWaiterI struct {
+	BeforeRebootStub        func()
+	beforeRebootMutex       sync.RWMutex
+	beforeRebootArgsForCall []struct {
+	}
 	WaitForRebootFinishedStub        func() error
 	waitForRebootFinishedMutex       sync.RWMutex
 	waitForRebootFinishedArgsForCall []struct {
@@ -22,6 +26,30 @@ type FakeRebootWaiterI struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeRebootWaiterI) BeforeReboot() {
+	fake.beforeRebootMutex.Lock()
+	fake.beforeRebootArgsForCall = append(fake.beforeRebootArgsForCall, struct {
+	}{})
+	stub := fake.BeforeRebootStub
+	fake.recordInvocation("BeforeReboot", []interface{}{})
+	fake.beforeRebootMutex.Unlock()
+	if stub != nil {
+		fake.BeforeRebootStub()
+	}
+}
+
+func (fake *FakeRebootWaiterI) BeforeRebootCallCount() int {
+	fake.beforeRebootMutex.RLock()
+	defer fake.beforeRebootMutex.RUnlock()
+	return len(fake.beforeRebootArgsForCall)
+}
+
+func (fake *FakeRebootWaiterI) BeforeRebootCalls(stub func()) {
+	fake.beforeRebootMutex.Lock()
+	defer fake.beforeRebootMutex.Unlock()
+	fake.BeforeRebootStub = stub
+}
+
 func (fake *FakeRebootWaiterI) WaitForRebootFinished() error {
 	fake.waitForRebootFinishedMutex.Lock()
 	ret, specificReturn := fake.waitForRebootFinishedReturnsOnCall[len(fake.waitForRebootFinishedArgsForCall)]
@@ -78,6 +106,8 @@ func (fake *FakeRebootWaiterI) WaitForRebootFinishedReturnsOnCall(i int, result1
 func (fake *FakeRebootWaiterI) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.beforeRebootMutex.RLock()
+	defer fake.beforeRebootMutex.RUnlock()
 	fake.waitForRebootFinishedMutex.RLock()
 	defer fake.waitForRebootFinishedMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
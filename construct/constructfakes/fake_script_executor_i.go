@@ -9,10 +9,11 @@ import (
 )
 
 type FakeScriptExecutorI struct {
-	ExecutePostRebootScriptStub        func(time.Duration) error
+	ExecutePostRebootScriptStub        func(time.Duration, []string) error
 	executePostRebootScriptMutex       sync.RWMutex
 	executePostRebootScriptArgsForCall []struct {
 		arg1 time.Duration
+		arg2 []string
 	}
 	executePostRebootScriptReturns struct {
 		result1 error
@@ -36,18 +37,24 @@ type FakeScriptExecutorI struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeScriptExecutorI) ExecutePostRebootScript(arg1 time.Duration) error {
+func (fake *FakeScriptExecutorI) ExecutePostRebootScript(arg1 time.Duration, arg2 []string) error {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
 	fake.executePostRebootScriptMutex.Lock()
 	ret, specificReturn := fake.executePostRebootScriptReturnsOnCall[len(fake.executePostRebootScriptArgsForCall)]
 	fake.executePostRebootScriptArgsForCall = append(fake.executePostRebootScriptArgsForCall, struct {
 		arg1 time.Duration
-	}{arg1})
+		arg2 []string
+	}{arg1, arg2Copy})
 	stub := fake.ExecutePostRebootScriptStub
 	fakeReturns := fake.executePostRebootScriptReturns
-	fake.recordInvocation("ExecutePostRebootScript", []interface{}{arg1})
+	fake.recordInvocation("ExecutePostRebootScript", []interface{}{arg1, arg2Copy})
 	fake.executePostRebootScriptMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -61,17 +68,17 @@ func (fake *FakeScriptExecutorI) ExecutePostRebootScriptCallCount() int {
 	return len(fake.executePostRebootScriptArgsForCall)
 }
 
-func (fake *FakeScriptExecutorI) ExecutePostRebootScriptCalls(stub func(time.Duration) error) {
+func (fake *FakeScriptExecutorI) ExecutePostRebootScriptCalls(stub func(time.Duration, []string) error) {
 	fake.executePostRebootScriptMutex.Lock()
 	defer fake.executePostRebootScriptMutex.Unlock()
 	fake.ExecutePostRebootScriptStub = stub
 }
 
-func (fake *FakeScriptExecutorI) ExecutePostRebootScriptArgsForCall(i int) time.Duration {
+func (fake *FakeScriptExecutorI) ExecutePostRebootScriptArgsForCall(i int) (time.Duration, []string) {
 	fake.executePostRebootScriptMutex.RLock()
 	defer fake.executePostRebootScriptMutex.RUnlock()
 	argsForCall := fake.executePostRebootScriptArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeScriptExecutorI) ExecutePostRebootScriptReturns(result1 error) {
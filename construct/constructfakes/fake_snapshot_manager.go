@@ -0,0 +1,340 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package constructfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/construct"
+)
+
+type FakeSnapshotManager struct {
+	CreateSnapshotStub        func(string, string) error
+	createSnapshotMutex       sync.RWMutex
+	createSnapshotArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	createSnapshotReturns struct {
+		result1 error
+	}
+	createSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSnapshotStub        func(string) error
+	deleteSnapshotMutex       sync.RWMutex
+	deleteSnapshotArgsForCall []struct {
+		arg1 string
+	}
+	deleteSnapshotReturns struct {
+		result1 error
+	}
+	deleteSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	HasSnapshotStub        func(string) (bool, error)
+	hasSnapshotMutex       sync.RWMutex
+	hasSnapshotArgsForCall []struct {
+		arg1 string
+	}
+	hasSnapshotReturns struct {
+		result1 bool
+		result2 error
+	}
+	hasSnapshotReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	RevertToSnapshotStub        func(string) error
+	revertToSnapshotMutex       sync.RWMutex
+	revertToSnapshotArgsForCall []struct {
+		arg1 string
+	}
+	revertToSnapshotReturns struct {
+		result1 error
+	}
+	revertToSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSnapshotManager) CreateSnapshot(arg1 string, arg2 string) error {
+	fake.createSnapshotMutex.Lock()
+	ret, specificReturn := fake.createSnapshotReturnsOnCall[len(fake.createSnapshotArgsForCall)]
+	fake.createSnapshotArgsForCall = append(fake.createSnapshotArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CreateSnapshotStub
+	fakeReturns := fake.createSnapshotReturns
+	fake.recordInvocation("CreateSnapshot", []interface{}{arg1, arg2})
+	fake.createSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSnapshotManager) CreateSnapshotCallCount() int {
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	return len(fake.createSnapshotArgsForCall)
+}
+
+func (fake *FakeSnapshotManager) CreateSnapshotCalls(stub func(string, string) error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = stub
+}
+
+func (fake *FakeSnapshotManager) CreateSnapshotArgsForCall(i int) (string, string) {
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	argsForCall := fake.createSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSnapshotManager) CreateSnapshotReturns(result1 error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = nil
+	fake.createSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSnapshotManager) CreateSnapshotReturnsOnCall(i int, result1 error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = nil
+	if fake.createSnapshotReturnsOnCall == nil {
+		fake.createSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSnapshotManager) DeleteSnapshot(arg1 string) error {
+	fake.deleteSnapshotMutex.Lock()
+	ret, specificReturn := fake.deleteSnapshotReturnsOnCall[len(fake.deleteSnapshotArgsForCall)]
+	fake.deleteSnapshotArgsForCall = append(fake.deleteSnapshotArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteSnapshotStub
+	fakeReturns := fake.deleteSnapshotReturns
+	fake.recordInvocation("DeleteSnapshot", []interface{}{arg1})
+	fake.deleteSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSnapshotManager) DeleteSnapshotCallCount() int {
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	return len(fake.deleteSnapshotArgsForCall)
+}
+
+func (fake *FakeSnapshotManager) DeleteSnapshotCalls(stub func(string) error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = stub
+}
+
+func (fake *FakeSnapshotManager) DeleteSnapshotArgsForCall(i int) string {
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	argsForCall := fake.deleteSnapshotArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSnapshotManager) DeleteSnapshotReturns(result1 error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = nil
+	fake.deleteSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSnapshotManager) DeleteSnapshotReturnsOnCall(i int, result1 error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = nil
+	if fake.deleteSnapshotReturnsOnCall == nil {
+		fake.deleteSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSnapshotManager) HasSnapshot(arg1 string) (bool, error) {
+	fake.hasSnapshotMutex.Lock()
+	ret, specificReturn := fake.hasSnapshotReturnsOnCall[len(fake.hasSnapshotArgsForCall)]
+	fake.hasSnapshotArgsForCall = append(fake.hasSnapshotArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.HasSnapshotStub
+	fakeReturns := fake.hasSnapshotReturns
+	fake.recordInvocation("HasSnapshot", []interface{}{arg1})
+	fake.hasSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSnapshotManager) HasSnapshotCallCount() int {
+	fake.hasSnapshotMutex.RLock()
+	defer fake.hasSnapshotMutex.RUnlock()
+	return len(fake.hasSnapshotArgsForCall)
+}
+
+func (fake *FakeSnapshotManager) HasSnapshotCalls(stub func(string) (bool, error)) {
+	fake.hasSnapshotMutex.Lock()
+	defer fake.hasSnapshotMutex.Unlock()
+	fake.HasSnapshotStub = stub
+}
+
+func (fake *FakeSnapshotManager) HasSnapshotArgsForCall(i int) string {
+	fake.hasSnapshotMutex.RLock()
+	defer fake.hasSnapshotMutex.RUnlock()
+	argsForCall := fake.hasSnapshotArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSnapshotManager) HasSnapshotReturns(result1 bool, result2 error) {
+	fake.hasSnapshotMutex.Lock()
+	defer fake.hasSnapshotMutex.Unlock()
+	fake.HasSnapshotStub = nil
+	fake.hasSnapshotReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSnapshotManager) HasSnapshotReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.hasSnapshotMutex.Lock()
+	defer fake.hasSnapshotMutex.Unlock()
+	fake.HasSnapshotStub = nil
+	if fake.hasSnapshotReturnsOnCall == nil {
+		fake.hasSnapshotReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.hasSnapshotReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSnapshotManager) RevertToSnapshot(arg1 string) error {
+	fake.revertToSnapshotMutex.Lock()
+	ret, specificReturn := fake.revertToSnapshotReturnsOnCall[len(fake.revertToSnapshotArgsForCall)]
+	fake.revertToSnapshotArgsForCall = append(fake.revertToSnapshotArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RevertToSnapshotStub
+	fakeReturns := fake.revertToSnapshotReturns
+	fake.recordInvocation("RevertToSnapshot", []interface{}{arg1})
+	fake.revertToSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSnapshotManager) RevertToSnapshotCallCount() int {
+	fake.revertToSnapshotMutex.RLock()
+	defer fake.revertToSnapshotMutex.RUnlock()
+	return len(fake.revertToSnapshotArgsForCall)
+}
+
+func (fake *FakeSnapshotManager) RevertToSnapshotCalls(stub func(string) error) {
+	fake.revertToSnapshotMutex.Lock()
+	defer fake.revertToSnapshotMutex.Unlock()
+	fake.RevertToSnapshotStub = stub
+}
+
+func (fake *FakeSnapshotManager) RevertToSnapshotArgsForCall(i int) string {
+	fake.revertToSnapshotMutex.RLock()
+	defer fake.revertToSnapshotMutex.RUnlock()
+	argsForCall := fake.revertToSnapshotArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSnapshotManager) RevertToSnapshotReturns(result1 error) {
+	fake.revertToSnapshotMutex.Lock()
+	defer fake.revertToSnapshotMutex.Unlock()
+	fake.RevertToSnapshotStub = nil
+	fake.revertToSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSnapshotManager) RevertToSnapshotReturnsOnCall(i int, result1 error) {
+	fake.revertToSnapshotMutex.Lock()
+	defer fake.revertToSnapshotMutex.Unlock()
+	fake.RevertToSnapshotStub = nil
+	if fake.revertToSnapshotReturnsOnCall == nil {
+		fake.revertToSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.revertToSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSnapshotManager) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	fake.hasSnapshotMutex.RLock()
+	defer fake.hasSnapshotMutex.RUnlock()
+	fake.revertToSnapshotMutex.RLock()
+	defer fake.revertToSnapshotMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSnapshotManager) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ construct.SnapshotManager = new(FakeSnapshotManager)
@@ -8,6 +8,16 @@ import (
 )
 
 type FakeVersionGetter struct {
+	GetOsStub        func() string
+	getOsMutex       sync.RWMutex
+	getOsArgsForCall []struct {
+	}
+	getOsReturns struct {
+		result1 string
+	}
+	getOsReturnsOnCall map[int]struct {
+		result1 string
+	}
 	GetVersionStub        func() string
 	getVersionMutex       sync.RWMutex
 	getVersionArgsForCall []struct {
@@ -22,6 +32,59 @@ type FakeVersionGetter struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeVersionGetter) GetOs() string {
+	fake.getOsMutex.Lock()
+	ret, specificReturn := fake.getOsReturnsOnCall[len(fake.getOsArgsForCall)]
+	fake.getOsArgsForCall = append(fake.getOsArgsForCall, struct {
+	}{})
+	stub := fake.GetOsStub
+	fakeReturns := fake.getOsReturns
+	fake.recordInvocation("GetOs", []interface{}{})
+	fake.getOsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVersionGetter) GetOsCallCount() int {
+	fake.getOsMutex.RLock()
+	defer fake.getOsMutex.RUnlock()
+	return len(fake.getOsArgsForCall)
+}
+
+func (fake *FakeVersionGetter) GetOsCalls(stub func() string) {
+	fake.getOsMutex.Lock()
+	defer fake.getOsMutex.Unlock()
+	fake.GetOsStub = stub
+}
+
+func (fake *FakeVersionGetter) GetOsReturns(result1 string) {
+	fake.getOsMutex.Lock()
+	defer fake.getOsMutex.Unlock()
+	fake.GetOsStub = nil
+	fake.getOsReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeVersionGetter) GetOsReturnsOnCall(i int, result1 string) {
+	fake.getOsMutex.Lock()
+	defer fake.getOsMutex.Unlock()
+	fake.GetOsStub = nil
+	if fake.getOsReturnsOnCall == nil {
+		fake.getOsReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.getOsReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeVersionGetter) GetVersion() string {
 	fake.getVersionMutex.Lock()
 	ret, specificReturn := fake.getVersionReturnsOnCall[len(fake.getVersionArgsForCall)]
@@ -78,6 +141,8 @@ func (fake *FakeVersionGetter) GetVersionReturnsOnCall(i int, result1 string) {
 func (fake *FakeVersionGetter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.getOsMutex.RLock()
+	defer fake.getOsMutex.RUnlock()
 	fake.getVersionMutex.RLock()
 	defer fake.getVersionMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
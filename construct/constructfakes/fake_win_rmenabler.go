@@ -2,15 +2,17 @@
 package constructfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cloudfoundry/stembuild/construct"
 )
 
 type FakeWinRMEnabler struct {
-	EnableStub        func() error
+	EnableStub        func(context.Context) error
 	enableMutex       sync.RWMutex
 	enableArgsForCall []struct {
+		arg1 context.Context
 	}
 	enableReturns struct {
 		result1 error
@@ -22,17 +24,18 @@ type FakeWinRMEnabler struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeWinRMEnabler) Enable() error {
+func (fake *FakeWinRMEnabler) Enable(arg1 context.Context) error {
 	fake.enableMutex.Lock()
 	ret, specificReturn := fake.enableReturnsOnCall[len(fake.enableArgsForCall)]
 	fake.enableArgsForCall = append(fake.enableArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.EnableStub
 	fakeReturns := fake.enableReturns
-	fake.recordInvocation("Enable", []interface{}{})
+	fake.recordInvocation("Enable", []interface{}{arg1})
 	fake.enableMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
@@ -46,12 +49,19 @@ func (fake *FakeWinRMEnabler) EnableCallCount() int {
 	return len(fake.enableArgsForCall)
 }
 
-func (fake *FakeWinRMEnabler) EnableCalls(stub func() error) {
+func (fake *FakeWinRMEnabler) EnableCalls(stub func(context.Context) error) {
 	fake.enableMutex.Lock()
 	defer fake.enableMutex.Unlock()
 	fake.EnableStub = stub
 }
 
+func (fake *FakeWinRMEnabler) EnableArgsForCall(i int) context.Context {
+	fake.enableMutex.RLock()
+	defer fake.enableMutex.RUnlock()
+	argsForCall := fake.enableArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeWinRMEnabler) EnableReturns(result1 error) {
 	fake.enableMutex.Lock()
 	defer fake.enableMutex.Unlock()
@@ -0,0 +1,112 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package constructfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/construct"
+)
+
+type FakeWindowsUpdaterI struct {
+	InstallUpdatesStub        func() ([]string, bool, error)
+	installUpdatesMutex       sync.RWMutex
+	installUpdatesArgsForCall []struct {
+	}
+	installUpdatesReturns struct {
+		result1 []string
+		result2 bool
+		result3 error
+	}
+	installUpdatesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 bool
+		result3 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeWindowsUpdaterI) InstallUpdates() ([]string, bool, error) {
+	fake.installUpdatesMutex.Lock()
+	ret, specificReturn := fake.installUpdatesReturnsOnCall[len(fake.installUpdatesArgsForCall)]
+	fake.installUpdatesArgsForCall = append(fake.installUpdatesArgsForCall, struct {
+	}{})
+	stub := fake.InstallUpdatesStub
+	fakeReturns := fake.installUpdatesReturns
+	fake.recordInvocation("InstallUpdates", []interface{}{})
+	fake.installUpdatesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeWindowsUpdaterI) InstallUpdatesCallCount() int {
+	fake.installUpdatesMutex.RLock()
+	defer fake.installUpdatesMutex.RUnlock()
+	return len(fake.installUpdatesArgsForCall)
+}
+
+func (fake *FakeWindowsUpdaterI) InstallUpdatesCalls(stub func() ([]string, bool, error)) {
+	fake.installUpdatesMutex.Lock()
+	defer fake.installUpdatesMutex.Unlock()
+	fake.InstallUpdatesStub = stub
+}
+
+func (fake *FakeWindowsUpdaterI) InstallUpdatesReturns(result1 []string, result2 bool, result3 error) {
+	fake.installUpdatesMutex.Lock()
+	defer fake.installUpdatesMutex.Unlock()
+	fake.InstallUpdatesStub = nil
+	fake.installUpdatesReturns = struct {
+		result1 []string
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeWindowsUpdaterI) InstallUpdatesReturnsOnCall(i int, result1 []string, result2 bool, result3 error) {
+	fake.installUpdatesMutex.Lock()
+	defer fake.installUpdatesMutex.Unlock()
+	fake.InstallUpdatesStub = nil
+	if fake.installUpdatesReturnsOnCall == nil {
+		fake.installUpdatesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 bool
+			result3 error
+		})
+	}
+	fake.installUpdatesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeWindowsUpdaterI) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.installUpdatesMutex.RLock()
+	defer fake.installUpdatesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeWindowsUpdaterI) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ construct.WindowsUpdaterI = new(FakeWindowsUpdaterI)
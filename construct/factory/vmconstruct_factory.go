@@ -2,41 +2,109 @@ package vmconstruct_factory
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 
+	"github.com/cloudfoundry/stembuild/assets"
 	"github.com/cloudfoundry/stembuild/commandparser"
 	"github.com/cloudfoundry/stembuild/construct"
 	"github.com/cloudfoundry/stembuild/construct/archive"
 	"github.com/cloudfoundry/stembuild/construct/config"
-	"github.com/cloudfoundry/stembuild/iaas_cli"
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients"
+	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/vcenter_manager"
 	"github.com/cloudfoundry/stembuild/poller"
 	"github.com/cloudfoundry/stembuild/remotemanager"
 	"github.com/cloudfoundry/stembuild/version"
 )
 
+const (
+	cloneIPTimeout       = 30 * time.Minute
+	guestLogTailInterval = 15 * time.Second
+)
+
 type VMConstructFactory struct {
+	// Output receives progress messages and the output of commands run in the guest.
+	// When nil, messages go to os.Stdout and command output to os.Stdout and os.Stderr,
+	// or only to os.Stderr with JSON output so that stdout holds nothing but events.
+	Output io.Writer
+}
+
+// messenger reports progress as text or as JSON events
+type messenger interface {
+	construct.ConstructMessenger
+	construct.GuestLogMessenger
+	StemcellAutomationArchive(source, sha256 string)
+	CloneVMStarted(source, clone string)
+	CloneVMSucceeded()
+	WaitForCloneIPStarted()
+	WaitForCloneIPSucceeded(ip string)
+	WaitingForReboot(elapsed time.Duration)
+	CheckpointsDisabled(err error)
 }
 
-func (f *VMConstructFactory) VMPreparer(config config.SourceConfig, vCenterManager commandparser.VCenterManager) (commandparser.VmConstruct, error) {
-	runner := &iaas_cli.GovcRunner{}
-	client := iaas_clients.NewVcenterClient(config.VCenterUsername, config.VCenterPassword, config.VCenterUrl, config.CaCertFile, runner)
+// VMPreparer builds the construct of a VM. Everything it starts stops once ctx is done,
+// apart from reverting and deleting the snapshot of the VM.
+func (f *VMConstructFactory) VMPreparer(ctx context.Context, config config.SourceConfig, vCenterManager commandparser.VCenterManager) (commandparser.VmConstruct, error) {
+	client := iaas_clients.NewVcenterClient(ctx, config.VCenterUsername, config.VCenterPassword, config.VCenterUrl, config.CaCertFile)
+
+	output := f.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	commandOutput := f.Output
 
-	messenger := construct.NewMessenger(os.Stdout)
+	var messenger messenger = construct.NewMessenger(output)
+	if config.OutputFormat == events.FormatJSON {
+		messenger = construct.NewJSONMessenger(events.NewEmitter(output, "construct"))
+		if commandOutput == nil {
+			commandOutput = os.Stderr
+		}
+	}
+
+	automation, automationPath, err := stemcellAutomation(config.AutomationZip)
+	if err != nil {
+		return nil, err
+	}
+	automationSource := automationPath
+	if config.AutomationZip == "" {
+		automationSource = "embedded StemcellAutomation.zip"
+	}
+	automationSum := sha256.Sum256(automation)
+	messenger.StemcellAutomationArchive(automationSource, hex.EncodeToString(automationSum[:]))
 
-	ctx := context.Background()
-	err := vCenterManager.Login(ctx)
+	err = vCenterManager.Login(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "Cannot complete login due to an incorrect vCenter user name or password")
 	}
 
+	if config.CloneFrom != "" && !config.Resume {
+		err = cloneSourceVM(ctx, config, vCenterManager, messenger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	vm, err := vCenterManager.FindVM(ctx, config.VmInventoryPath)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.CloneFrom != "" {
+		config.GuestVmIp, err = waitForCloneIP(ctx, config, vCenterManager, vm, messenger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	opsManager := vCenterManager.OperationsManager(ctx, vm)
 
 	guestManager, err := vCenterManager.GuestManager(ctx, opsManager, config.GuestVMUsername, config.GuestVMPassword)
@@ -45,27 +113,47 @@ func (f *VMConstructFactory) VMPreparer(config config.SourceConfig, vCenterManag
 	}
 
 	winRMManager := &construct.WinRMManager{
-		GuestManager: guestManager,
-		Unarchiver:   &archive.Zip{},
+		GuestManager:       guestManager,
+		Unarchiver:         &archive.Zip{},
+		StemcellAutomation: automation,
 	}
 	versionGetter := version.NewVersionGetter()
 
-	winRmClientFactory := remotemanager.NewWinRmClientFactory(config.GuestVmIp, config.GuestVMUsername, config.GuestVMPassword)
-	remoteManager := remotemanager.NewWinRM(config.GuestVmIp, config.GuestVMUsername, config.GuestVMPassword, winRmClientFactory)
+	remoteManager := newRemoteManager(ctx, config, guestManager, commandOutput)
 
 	vmConnectionValidator := &construct.WinRMConnectionValidator{
 		RemoteManager: remoteManager,
 	}
 
-	rebootPoller := &poller.Poller{}
+	rebootPoller := &poller.Poller{Context: ctx}
 
-	rebootChecker := remotemanager.NewRebootChecker(remoteManager)
+	// vCenter and the guest boot time read through it tell when a reboot has finished, and the probe in the guest is only used when they cannot
+	rebootChecker := remotemanager.NewVCenterRebootChecker(ctx, &vmRebootStatus{vCenterManager: vCenterManager, vm: vm}, remotemanager.NewRebootChecker(remoteManager))
+	rebootChecker.Guest = remotemanager.NewGuestBootTime(ctx, guestManager)
 
-	rebootWaiter := remotemanager.NewRebootWaiter(rebootPoller, rebootChecker)
+	rebootWaiter := remotemanager.NewRebootWaiter(ctx, rebootPoller, rebootChecker)
+	setDuration(&rebootWaiter.Interval, config.Timeouts.RebootPollInterval)
+	setDuration(&rebootWaiter.Timeout, config.Timeouts.Reboot)
+	rebootWaiter.Progress = func(p poller.Progress) {
+		messenger.WaitingForReboot(p.Elapsed)
+	}
 
 	scriptExecutor := construct.NewScriptExecutor(remoteManager)
 
-	return construct.NewVMConstruct(
+	// recording progress is best effort unless it was asked for
+	requireCheckpoints := config.Resume || config.StateFile != ""
+	stateFile := config.StateFile
+	if stateFile == "" {
+		stateFile, err = construct.DefaultCheckpointStatePath()
+		if err != nil && requireCheckpoints {
+			return nil, err
+		}
+		if err != nil {
+			messenger.CheckpointsDisabled(err)
+		}
+	}
+
+	vmConstruct := construct.NewVMConstruct(
 		ctx,
 		remoteManager,
 		config.GuestVMUsername,
@@ -81,5 +169,190 @@ func (f *VMConstructFactory) VMPreparer(config config.SourceConfig, vCenterManag
 		rebootWaiter,
 		scriptExecutor,
 		config.SetupFlags,
-	), nil
+	)
+	vmConstruct.PostRebootFlags = config.PostRebootFlags
+	vmConstruct.StemcellAutomationPath = automationPath
+	setDuration(&vmConstruct.RebootWaitTime, config.Timeouts.RebootWait)
+	setDuration(&vmConstruct.PostRebootTimeout, config.Timeouts.PostRebootScript)
+	setDuration(&vmConstruct.ShutdownPollInterval, config.Timeouts.ShutdownPollInterval)
+	setDuration(&vmConstruct.ShutdownTimeout, config.Timeouts.Shutdown)
+	if config.InstallUpdates {
+		windowsUpdater := construct.NewWindowsUpdater(ctx, remoteManager, guestManager)
+		setDuration(&windowsUpdater.Timeout, config.Timeouts.WindowsUpdates)
+		vmConstruct.WindowsUpdater = windowsUpdater
+		vmConstruct.MaxUpdateRounds = config.MaxUpdateRounds
+	}
+	if config.LGPO.Path != "" {
+		vmConstruct.LGPOPath = config.LGPO.Path
+	}
+	vmConstruct.SkipOSCheck = config.SkipOSCheck
+	if runsOverWinRM(config) {
+		vmConstruct.VMIP = config.GuestVmIp
+		vmConstruct.GuestNetwork = &vmGuestNetwork{ctx: ctx, vCenterManager: vCenterManager, vm: vm}
+		vmConstruct.SkipVMIPCheck = config.SkipVMIPCheck
+	}
+	if !config.NoLogTail {
+		vmConstruct.LogTail = construct.NewGuestLogTail(ctx, guestManager, messenger, guestLogTailInterval)
+	}
+	if stateFile != "" {
+		vmConstruct.Checkpoints = construct.NewFileCheckpointStore(stateFile)
+	}
+	vmConstruct.RequireCheckpoints = requireCheckpoints
+	vmConstruct.Resume = config.Resume
+	vmConstruct.IfConstructed = config.IfConstructed
+	// a failed clone can simply be discarded, so only the original VM is snapshotted
+	if config.CloneFrom == "" {
+		// an aborted construct can still roll back
+		vmConstruct.Snapshots = &vmSnapshotManager{ctx: context.WithoutCancel(ctx), vCenterManager: vCenterManager, vm: vm}
+		vmConstruct.RollbackOnFailure = config.RollbackOnFailure
+	}
+
+	return vmConstruct, nil
+}
+
+// setDuration overrides a default with a configured duration, unless it is zero
+func setDuration(d *time.Duration, configured time.Duration) {
+	if configured != 0 {
+		*d = configured
+	}
+}
+
+// stemcellAutomation returns the archive to provision the VM with and the local path it is uploaded from.
+// main writes the embedded archive to the working directory.
+func stemcellAutomation(automationZip string) ([]byte, string, error) {
+	if automationZip == "" {
+		return assets.StemcellAutomation, "./StemcellAutomation.zip", nil
+	}
+
+	automation, err := os.ReadFile(automationZip)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot read stemcell automation archive: %s", err)
+	}
+	return automation, automationZip, nil
+}
+
+// vmSnapshotManager manages the snapshots of the single VM being constructed
+type vmSnapshotManager struct {
+	ctx            context.Context
+	vCenterManager commandparser.VCenterManager
+	vm             *object.VirtualMachine
+}
+
+func (s *vmSnapshotManager) CreateSnapshot(name, description string) error {
+	return s.vCenterManager.CreateSnapshot(s.ctx, s.vm, name, description)
+}
+
+// RevertToSnapshot reverts the VM and powers it back on, as it was when construct took the snapshot
+func (s *vmSnapshotManager) RevertToSnapshot(name string) error {
+	err := s.vCenterManager.RevertToSnapshot(s.ctx, s.vm, name)
+	if err != nil {
+		return err
+	}
+
+	err = s.vCenterManager.PowerOnVM(s.ctx, s.vm)
+	if err != nil {
+		return fmt.Errorf("cannot power on the VM after reverting it: %s", err)
+	}
+	return nil
+}
+
+func (s *vmSnapshotManager) DeleteSnapshot(name string) error {
+	return s.vCenterManager.DeleteSnapshot(s.ctx, s.vm, name)
+}
+
+func (s *vmSnapshotManager) HasSnapshot(name string) (bool, error) {
+	return s.vCenterManager.HasSnapshot(s.ctx, s.vm, name)
+}
+
+// vmGuestNetwork reads the IP addresses of the single VM being constructed
+type vmGuestNetwork struct {
+	ctx            context.Context
+	vCenterManager commandparser.VCenterManager
+	vm             *object.VirtualMachine
+}
+
+func (n *vmGuestNetwork) IPAddresses() ([]string, error) {
+	return n.vCenterManager.GuestIPAddresses(n.ctx, n.vm)
+}
+
+// vmRebootStatus reads the reboot status of the single VM being constructed
+type vmRebootStatus struct {
+	vCenterManager commandparser.VCenterManager
+	vm             *object.VirtualMachine
+}
+
+func (s *vmRebootStatus) RebootStatus(ctx context.Context) (mo.VirtualMachine, error) {
+	return s.vCenterManager.RebootStatus(ctx, s.vm)
+}
+
+// runsOverWinRM tells whether commands run on the machine at -vm-ip, which may not be the VM at the inventory path.
+// Guest operations always run them on the VM at the inventory path.
+func runsOverWinRM(sourceConfig config.SourceConfig) bool {
+	return sourceConfig.Transport != config.TransportGuestOps
+}
+
+func newRemoteManager(ctx context.Context, sourceConfig config.SourceConfig, guestOps remotemanager.GuestOperations, output io.Writer) remotemanager.RemoteManager {
+	if sourceConfig.Transport == config.TransportGuestOps {
+		guestOpsManager := remotemanager.NewGuestOps(ctx, guestOps)
+		if output != nil {
+			guestOpsManager.Stdout, guestOpsManager.Stderr = output, output
+		}
+		return guestOpsManager
+	}
+
+	winRmClientFactory := remotemanager.NewWinRmClientFactory(sourceConfig.GuestVmIp, sourceConfig.GuestVMUsername, sourceConfig.GuestVMPassword, sourceConfig.WinRM)
+	winRM := remotemanager.NewWinRM(sourceConfig.GuestVmIp, sourceConfig.GuestVMUsername, sourceConfig.GuestVMPassword, sourceConfig.WinRM, winRmClientFactory)
+	winRM.Context = ctx
+	if output != nil {
+		winRM.Stdout, winRM.Stderr = output, output
+	}
+	return winRM
+}
+
+func cloneSourceVM(ctx context.Context, config config.SourceConfig, vCenterManager commandparser.VCenterManager, messenger messenger) error {
+	sourceVM, err := vCenterManager.FindVM(ctx, config.CloneFrom)
+	if err != nil {
+		return fmt.Errorf("cannot find VM to clone from: %s", err)
+	}
+
+	network := vcenter_manager.CloneNetwork{DNSServers: config.CloneDNSServers}
+	if config.CloneNetmask != "" {
+		network.IP = config.GuestVmIp
+		network.Netmask = config.CloneNetmask
+		network.Gateway = config.CloneGateway
+	}
+	customization := vcenter_manager.WindowsCustomizationSpec(config.GuestVMPassword, network)
+
+	messenger.CloneVMStarted(config.CloneFrom, config.VmInventoryPath)
+	err = vCenterManager.CloneVMWithCustomization(ctx, sourceVM, config.VmInventoryPath, customization)
+	if err != nil {
+		return fmt.Errorf("cannot clone %s to %s: %s", config.CloneFrom, config.VmInventoryPath, err)
+	}
+	messenger.CloneVMSucceeded()
+
+	return nil
+}
+
+// waitForCloneIP waits for the customized clone to come up on the network and returns the address construct should use.
+// The guest reports the address of the source VM until sysprep has applied the customization, so that is waited for first.
+func waitForCloneIP(ctx context.Context, config config.SourceConfig, vCenterManager commandparser.VCenterManager, vm *object.VirtualMachine, messenger messenger) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, cloneIPTimeout)
+	defer cancel()
+
+	messenger.WaitForCloneIPStarted()
+	err := vCenterManager.WaitForCustomization(ctx, vm)
+	if err != nil {
+		return "", fmt.Errorf("clone was not customized: %s", err)
+	}
+
+	ip, err := vCenterManager.WaitForIP(ctx, vm)
+	if err != nil {
+		return "", fmt.Errorf("clone did not report an IP address within %s: %s", cloneIPTimeout, err)
+	}
+	messenger.WaitForCloneIPSucceeded(ip)
+
+	if config.CloneNetmask != "" {
+		return config.GuestVmIp, nil
+	}
+	return ip, nil
 }
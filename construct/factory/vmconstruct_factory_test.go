@@ -1,13 +1,28 @@
 package vmconstruct_factory
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 
+	"github.com/cloudfoundry/stembuild/assets"
 	"github.com/cloudfoundry/stembuild/commandparser/commandparserfakes"
 	"github.com/cloudfoundry/stembuild/construct"
 	"github.com/cloudfoundry/stembuild/construct/config"
+	"github.com/cloudfoundry/stembuild/events"
+	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
 )
 
 var _ = Describe("Factory", func() {
@@ -33,11 +48,389 @@ var _ = Describe("Factory", func() {
 				VmInventoryPath: "some-vm-inventory-path",
 			}
 
-			vmPreparer, err := factory.VMPreparer(sourceConfig, fakeVCenterManager)
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vmPreparer).To(BeAssignableToTypeOf(&construct.VMConstruct{}))
 		})
 
+		It("passes the post-reboot flags to the VMPreparer", func() {
+			sourceConfig := config.SourceConfig{
+				VmInventoryPath: "some-vm-inventory-path",
+				PostRebootFlags: []string{"Organization SomeOrg"},
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).PostRebootFlags).To(Equal([]string{"Organization SomeOrg"}))
+		})
+
+		It("passes what to do with an already constructed VM to the VMPreparer", func() {
+			sourceConfig := config.SourceConfig{
+				VmInventoryPath: "some-vm-inventory-path",
+				IfConstructed:   construct.IfConstructedContinue,
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).IfConstructed).To(Equal("continue"))
+		})
+
+		Describe("rolling back", func() {
+			var (
+				fakeVCenterManager *commandparserfakes.FakeVCenterManager
+				snapshots          construct.SnapshotManager
+			)
+
+			BeforeEach(func() {
+				fakeVCenterManager = &commandparserfakes.FakeVCenterManager{}
+				sourceConfig := config.SourceConfig{
+					VmInventoryPath:   "some-vm-inventory-path",
+					RollbackOnFailure: true,
+					StateFile:         filepath.Join(GinkgoT().TempDir(), "state.json"),
+				}
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(err).ToNot(HaveOccurred())
+				snapshots = vmPreparer.(*construct.VMConstruct).Snapshots
+				Expect(snapshots).NotTo(BeNil())
+			})
+
+			It("powers the VM back on after reverting it to the snapshot", func() {
+				fakeVCenterManager.PowerOnVMStub = func(context.Context, *object.VirtualMachine) error {
+					Expect(fakeVCenterManager.RevertToSnapshotCallCount()).To(Equal(1))
+					return nil
+				}
+
+				Expect(snapshots.RevertToSnapshot("stembuild-pre-construct")).To(Succeed())
+
+				_, _, name := fakeVCenterManager.RevertToSnapshotArgsForCall(0)
+				Expect(name).To(Equal("stembuild-pre-construct"))
+				Expect(fakeVCenterManager.PowerOnVMCallCount()).To(Equal(1))
+			})
+
+			It("does not power on a VM that could not be reverted", func() {
+				fakeVCenterManager.RevertToSnapshotReturns(errors.New("vm is locked"))
+
+				Expect(snapshots.RevertToSnapshot("stembuild-pre-construct")).To(MatchError("vm is locked"))
+				Expect(fakeVCenterManager.PowerOnVMCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the VM cannot be powered on", func() {
+				fakeVCenterManager.PowerOnVMReturns(errors.New("no host"))
+
+				err := snapshots.RevertToSnapshot("stembuild-pre-construct")
+				Expect(err).To(MatchError("cannot power on the VM after reverting it: no host"))
+			})
+		})
+
+		It("checks -vm-ip against the VM when commands run over WinRM", func() {
+			sourceConfig := config.SourceConfig{
+				GuestVmIp:       "10.0.0.5",
+				VmInventoryPath: "some-vm-inventory-path",
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			vmConstruct := vmPreparer.(*construct.VMConstruct)
+			Expect(vmConstruct.VMIP).To(Equal("10.0.0.5"))
+			Expect(vmConstruct.GuestNetwork).NotTo(BeNil())
+			Expect(vmConstruct.SkipVMIPCheck).To(BeFalse())
+
+			sourceConfig.SkipVMIPCheck = true
+			vmPreparer, err = factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).SkipVMIPCheck).To(BeTrue())
+		})
+
+		It("does not check -vm-ip when commands run through guest operations", func() {
+			sourceConfig := config.SourceConfig{
+				GuestVmIp:       "10.0.0.5",
+				VmInventoryPath: "some-vm-inventory-path",
+				Transport:       config.TransportGuestOps,
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			vmConstruct := vmPreparer.(*construct.VMConstruct)
+			Expect(vmConstruct.VMIP).To(BeEmpty())
+			Expect(vmConstruct.GuestNetwork).To(BeNil())
+		})
+
+		Context("when there is no user cache directory for the state file", func() {
+			BeforeEach(func() {
+				GinkgoT().Setenv("XDG_CACHE_HOME", "")
+				GinkgoT().Setenv("HOME", "")
+				if _, err := os.UserCacheDir(); err == nil {
+					Skip("the user cache directory does not depend on HOME on this platform")
+				}
+			})
+
+			It("warns and constructs without recording progress", func() {
+				output := new(bytes.Buffer)
+				factory.Output = output
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), config.SourceConfig{VmInventoryPath: "some-vm-inventory-path"}, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmPreparer.(*construct.VMConstruct).Checkpoints).To(BeNil())
+				Expect(vmPreparer.(*construct.VMConstruct).RequireCheckpoints).To(BeFalse())
+				Expect(output.String()).To(ContainSubstring("Warning: cannot record construct progress, so construct cannot be resumed"))
+			})
+
+			It("fails when resuming", func() {
+				_, err := factory.VMPreparer(context.Background(), config.SourceConfig{VmInventoryPath: "some-vm-inventory-path", Resume: true}, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).To(MatchError(ContainSubstring("could not determine a directory for the construct state file")))
+			})
+
+			It("requires recording progress with a state file", func() {
+				stateFile := filepath.Join(GinkgoT().TempDir(), "state.json")
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), config.SourceConfig{VmInventoryPath: "some-vm-inventory-path", StateFile: stateFile}, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmPreparer.(*construct.VMConstruct).Checkpoints).NotTo(BeNil())
+				Expect(vmPreparer.(*construct.VMConstruct).RequireCheckpoints).To(BeTrue())
+			})
+		})
+
+		It("uploads LGPO.zip from the current directory unless another path is given", func() {
+			sourceConfig := config.SourceConfig{
+				VmInventoryPath: "some-vm-inventory-path",
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).LGPOPath).To(Equal("./LGPO.zip"))
+
+			sourceConfig.LGPO.Path = "/cache/LGPO-abc.zip"
+			vmPreparer, err = factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).LGPOPath).To(Equal("/cache/LGPO-abc.zip"))
+		})
+
+		It("installs Windows updates only when asked to", func() {
+			sourceConfig := config.SourceConfig{
+				VmInventoryPath: "some-vm-inventory-path",
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).WindowsUpdater).To(BeNil())
+
+			sourceConfig.InstallUpdates = true
+			sourceConfig.MaxUpdateRounds = 4
+			vmPreparer, err = factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).WindowsUpdater).To(BeAssignableToTypeOf(&construct.WindowsUpdater{}))
+			Expect(vmPreparer.(*construct.VMConstruct).MaxUpdateRounds).To(Equal(4))
+		})
+
+		It("overrides the default timeouts with the configured ones", func() {
+			sourceConfig := config.SourceConfig{
+				VmInventoryPath: "some-vm-inventory-path",
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+				InstallUpdates:  true,
+				MaxUpdateRounds: 1,
+				Timeouts: config.Timeouts{
+					RebootWait:       2 * time.Minute,
+					PostRebootScript: 3 * time.Hour,
+					Shutdown:         45 * time.Minute,
+					WindowsUpdates:   6 * time.Hour,
+				},
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			vmConstruct := vmPreparer.(*construct.VMConstruct)
+			Expect(vmConstruct.RebootWaitTime).To(Equal(2 * time.Minute))
+			Expect(vmConstruct.PostRebootTimeout).To(Equal(3 * time.Hour))
+			Expect(vmConstruct.ShutdownPollInterval).To(Equal(construct.DefaultShutdownPollInterval))
+			Expect(vmConstruct.ShutdownTimeout).To(Equal(45 * time.Minute))
+			Expect(vmConstruct.WindowsUpdater.(*construct.WindowsUpdater).Timeout).To(Equal(6 * time.Hour))
+		})
+
+		Describe("stemcell automation archive", func() {
+			var (
+				output       *bytes.Buffer
+				sourceConfig config.SourceConfig
+			)
+
+			BeforeEach(func() {
+				output = new(bytes.Buffer)
+				factory.Output = output
+				sourceConfig = config.SourceConfig{
+					VmInventoryPath: "some-vm-inventory-path",
+					StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+				}
+			})
+
+			It("uses the embedded archive by default and prints its SHA-256", func() {
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(vmPreparer.(*construct.VMConstruct).StemcellAutomationPath).To(Equal("./StemcellAutomation.zip"))
+				sum := sha256.Sum256(assets.StemcellAutomation)
+				Expect(output.String()).To(ContainSubstring("Using embedded StemcellAutomation.zip (SHA-256 %s)", hex.EncodeToString(sum[:])))
+			})
+
+			It("uses a custom archive and prints its SHA-256", func() {
+				sourceConfig.AutomationZip = filepath.Join(GinkgoT().TempDir(), "custom.zip")
+				Expect(os.WriteFile(sourceConfig.AutomationZip, []byte("custom archive"), 0600)).To(Succeed())
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(vmPreparer.(*construct.VMConstruct).StemcellAutomationPath).To(Equal(sourceConfig.AutomationZip))
+				// printf "custom archive" | shasum -a 256
+				Expect(output.String()).To(ContainSubstring("Using %s (SHA-256 4d56dcf3b8899ac17257b855fa5f51445d3339e44936b802ab81a3b32a3afc47)", sourceConfig.AutomationZip))
+			})
+
+			It("reports progress as JSON events with JSON output", func() {
+				sourceConfig.OutputFormat = events.FormatJSON
+
+				_, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).ToNot(HaveOccurred())
+
+				var event events.Event
+				Expect(json.Unmarshal(output.Bytes(), &event)).To(Succeed())
+				Expect(event.Command).To(Equal("construct"))
+				Expect(event.Status).To(Equal(events.StatusInfo))
+				Expect(event.Message).To(HavePrefix("using embedded StemcellAutomation.zip (SHA-256 "))
+			})
+
+			It("fails when the custom archive cannot be read", func() {
+				sourceConfig.AutomationZip = filepath.Join(GinkgoT().TempDir(), "missing.zip")
+
+				_, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+				Expect(err).To(MatchError(HavePrefix("cannot read stemcell automation archive: ")))
+			})
+		})
+
+		It("tails the guest log unless it is disabled", func() {
+			sourceConfig := config.SourceConfig{
+				VmInventoryPath: "some-vm-inventory-path",
+				StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+			}
+
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).LogTail).ToNot(BeNil())
+
+			sourceConfig.NoLogTail = true
+			vmPreparer, err = factory.VMPreparer(context.Background(), sourceConfig, &commandparserfakes.FakeVCenterManager{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmPreparer.(*construct.VMConstruct).LogTail).To(BeNil())
+		})
+
+		Context("when cloning from another VM", func() {
+			var (
+				fakeVCenterManager *commandparserfakes.FakeVCenterManager
+				sourceConfig       config.SourceConfig
+			)
+
+			BeforeEach(func() {
+				fakeVCenterManager = &commandparserfakes.FakeVCenterManager{}
+				fakeVCenterManager.WaitForIPReturns("10.0.0.99", nil)
+
+				sourceConfig = config.SourceConfig{
+					GuestVMUsername: "vmUser",
+					GuestVMPassword: "vmPwd",
+					VCenterUrl:      "vCenterUrl",
+					VCenterUsername: "vCenterUser",
+					VCenterPassword: "vCenterPwd",
+					VmInventoryPath: "/dc/vm/clone",
+					CloneFrom:       "/dc/vm/golden",
+					StateFile:       filepath.Join(GinkgoT().TempDir(), "state.json"),
+				}
+			})
+
+			It("clones the source VM to the inventory path and constructs the clone", func() {
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeVCenterManager.FindVMCallCount()).To(Equal(2))
+				_, path := fakeVCenterManager.FindVMArgsForCall(0)
+				Expect(path).To(Equal("/dc/vm/golden"))
+				_, path = fakeVCenterManager.FindVMArgsForCall(1)
+				Expect(path).To(Equal("/dc/vm/clone"))
+
+				Expect(fakeVCenterManager.CloneVMWithCustomizationCallCount()).To(Equal(1))
+				_, _, clonePath, customization := fakeVCenterManager.CloneVMWithCustomizationArgsForCall(0)
+				Expect(clonePath).To(Equal("/dc/vm/clone"))
+				Expect(customization.NicSettingMap[0].Adapter.Ip).To(BeAssignableToTypeOf(&types.CustomizationDhcpIpGenerator{}))
+
+				Expect(fakeVCenterManager.WaitForCustomizationCallCount()).To(Equal(1))
+				Expect(fakeVCenterManager.WaitForIPCallCount()).To(Equal(1))
+				Expect(vmPreparer.(*construct.VMConstruct).Snapshots).To(BeNil())
+			})
+
+			It("does not ask the clone for its address before the customization has succeeded", func() {
+				fakeVCenterManager.WaitForCustomizationStub = func(context.Context, *object.VirtualMachine) error {
+					Expect(fakeVCenterManager.WaitForIPCallCount()).To(Equal(0))
+					return nil
+				}
+
+				_, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeVCenterManager.WaitForIPCallCount()).To(Equal(1))
+			})
+
+			It("returns an error when the customization of the clone fails", func() {
+				fakeVCenterManager.WaitForCustomizationReturns(errors.New("guest customization failed: sysprep failed"))
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(vmPreparer).To(BeNil())
+				Expect(err).To(MatchError("clone was not customized: guest customization failed: sysprep failed"))
+				Expect(fakeVCenterManager.WaitForIPCallCount()).To(Equal(0))
+			})
+
+			It("assigns a static address when a netmask is given", func() {
+				sourceConfig.GuestVmIp = "10.0.0.5"
+				sourceConfig.CloneNetmask = "255.255.255.0"
+				sourceConfig.CloneGateway = "10.0.0.1"
+
+				_, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, customization := fakeVCenterManager.CloneVMWithCustomizationArgsForCall(0)
+				adapter := customization.NicSettingMap[0].Adapter
+				Expect(adapter.Ip).To(Equal(&types.CustomizationFixedIp{IpAddress: "10.0.0.5"}))
+				Expect(adapter.SubnetMask).To(Equal("255.255.255.0"))
+				Expect(adapter.Gateway).To(Equal([]string{"10.0.0.1"}))
+			})
+
+			It("does not clone again when resuming", func() {
+				sourceConfig.Resume = true
+
+				_, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeVCenterManager.CloneVMWithCustomizationCallCount()).To(Equal(0))
+				Expect(fakeVCenterManager.FindVMCallCount()).To(Equal(1))
+				Expect(fakeVCenterManager.WaitForIPCallCount()).To(Equal(1))
+			})
+
+			It("returns an error when the clone fails", func() {
+				fakeVCenterManager.CloneVMWithCustomizationReturns(errors.New("name already exists"))
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(vmPreparer).To(BeNil())
+				Expect(err).To(MatchError("cannot clone /dc/vm/golden to /dc/vm/clone: name already exists"))
+			})
+
+			It("returns an error when the clone does not report an address", func() {
+				fakeVCenterManager.WaitForIPReturns("", errors.New("context deadline exceeded"))
+
+				vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
+				Expect(vmPreparer).To(BeNil())
+				Expect(err).To(MatchError(ContainSubstring("clone did not report an IP address")))
+			})
+		})
+
 		It("should return a login error when login incorrect to VCenter", func() {
 			// setup
 			fakeVCenterManager := &commandparserfakes.FakeVCenterManager{}
@@ -45,7 +438,7 @@ var _ = Describe("Factory", func() {
 			fakeVCenterManager.LoginReturns(loginFailure)
 			sourceConfig := config.SourceConfig{}
 
-			vmPreparer, err := factory.VMPreparer(sourceConfig, fakeVCenterManager)
+			vmPreparer, err := factory.VMPreparer(context.Background(), sourceConfig, fakeVCenterManager)
 
 			Expect(vmPreparer).To(BeNil())
 			Expect(err).To(HaveOccurred())
@@ -53,4 +446,28 @@ var _ = Describe("Factory", func() {
 			Expect(err.Error()).To(ContainSubstring(loginFailure.Error()))
 		})
 	})
+
+	Describe("newRemoteManager", func() {
+		It("uses WinRM by default", func() {
+			remoteManager := newRemoteManager(context.Background(), config.SourceConfig{GuestVmIp: "10.0.0.5"}, &remotemanagerfakes.FakeGuestOperations{}, nil)
+			Expect(remoteManager).To(BeAssignableToTypeOf(&remotemanager.WinRM{}))
+		})
+
+		It("uses guest operations for the guestops transport", func() {
+			remoteManager := newRemoteManager(context.Background(), config.SourceConfig{Transport: config.TransportGuestOps}, &remotemanagerfakes.FakeGuestOperations{}, nil)
+			Expect(remoteManager).To(BeAssignableToTypeOf(&remotemanager.GuestOps{}))
+		})
+
+		It("sends command output to the factory output when one is set", func() {
+			output := &bytes.Buffer{}
+
+			winRM := newRemoteManager(context.Background(), config.SourceConfig{GuestVmIp: "10.0.0.5"}, &remotemanagerfakes.FakeGuestOperations{}, output).(*remotemanager.WinRM)
+			Expect(winRM.Stdout).To(BeIdenticalTo(output))
+			Expect(winRM.Stderr).To(BeIdenticalTo(output))
+
+			guestOps := newRemoteManager(context.Background(), config.SourceConfig{Transport: config.TransportGuestOps}, &remotemanagerfakes.FakeGuestOperations{}, output).(*remotemanager.GuestOps)
+			Expect(guestOps.Stdout).To(BeIdenticalTo(output))
+			Expect(guestOps.Stderr).To(BeIdenticalTo(output))
+		})
+	})
 })
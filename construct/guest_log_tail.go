@@ -0,0 +1,110 @@
+package construct
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+const provisionLog = provisionDir + "log.log"
+
+//counterfeiter:generate . GuestLogTailer
+type GuestLogTailer interface {
+	Start()
+	Stop()
+}
+
+//counterfeiter:generate . GuestLogMessenger
+type GuestLogMessenger interface {
+	GuestLogLine(line string)
+}
+
+// GuestLogTail streams the lines appended to the provisioning log on the guest.
+// Guest file transfer cannot read part of a file, so each poll downloads the whole log and only new lines are written.
+type GuestLogTail struct {
+	ctx          context.Context
+	guestManager GuestManager
+	messenger    GuestLogMessenger
+	interval     time.Duration
+	offset       int
+	partial      string
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+func NewGuestLogTail(ctx context.Context, guestManager GuestManager, messenger GuestLogMessenger, interval time.Duration) *GuestLogTail {
+	return &GuestLogTail{
+		ctx:          ctx,
+		guestManager: guestManager,
+		messenger:    messenger,
+		interval:     interval,
+	}
+}
+
+// Start polls the log in the background until Stop is called. Lines written by an earlier Start/Stop are not repeated.
+func (t *GuestLogTail) Start() {
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			t.poll()
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends polling, writing any lines appended since the last poll
+func (t *GuestLogTail) Stop() {
+	close(t.stop)
+	<-t.done
+
+	t.poll()
+	if t.partial != "" {
+		t.messenger.GuestLogLine(t.partial)
+		t.partial = ""
+	}
+}
+
+// poll ignores download errors: the log does not exist until Setup.ps1 starts, and guest operations are unavailable while the VM reboots
+func (t *GuestLogTail) poll() {
+	reader, _, err := t.guestManager.DownloadFileInGuest(t.ctx, provisionLog)
+	if err != nil {
+		return
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+
+	if len(contents) < t.offset {
+		// the log was replaced, so start again from its beginning
+		t.offset = 0
+		t.partial = ""
+	}
+
+	lines := strings.Split(t.partial+string(contents[t.offset:]), "\n")
+	t.offset = len(contents)
+	t.partial = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) != "" {
+			t.messenger.GuestLogLine(line)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package construct_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/construct/constructfakes"
+)
+
+var _ = Describe("GuestLogTail", func() {
+	var (
+		fakeGuestManager *constructfakes.FakeGuestManager
+		fakeMessenger    *constructfakes.FakeGuestLogMessenger
+		tail             *construct.GuestLogTail
+
+		mu       sync.Mutex
+		guestLog string
+	)
+
+	setGuestLog := func(contents string) {
+		mu.Lock()
+		defer mu.Unlock()
+		guestLog = contents
+	}
+
+	lines := func() []string {
+		var result []string
+		for i := 0; i < fakeMessenger.GuestLogLineCallCount(); i++ {
+			result = append(result, fakeMessenger.GuestLogLineArgsForCall(i))
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		fakeGuestManager = &constructfakes.FakeGuestManager{}
+		fakeMessenger = &constructfakes.FakeGuestLogMessenger{}
+		setGuestLog("")
+
+		fakeGuestManager.DownloadFileInGuestCalls(func(context.Context, string) (io.Reader, int64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return strings.NewReader(guestLog), int64(len(guestLog)), nil
+		})
+
+		tail = construct.NewGuestLogTail(context.TODO(), fakeGuestManager, fakeMessenger, 10*time.Millisecond)
+	})
+
+	It("streams the lines appended to the provisioning log", func() {
+		setGuestLog("first line\r\n")
+		tail.Start()
+
+		Eventually(lines).Should(Equal([]string{"first line"}))
+
+		setGuestLog("first line\r\nsecond line\r\nthird")
+		Eventually(lines).Should(Equal([]string{"first line", "second line"}))
+
+		tail.Stop()
+		Expect(lines()).To(Equal([]string{"first line", "second line", "third"}))
+
+		_, path := fakeGuestManager.DownloadFileInGuestArgsForCall(0)
+		Expect(path).To(Equal("C:\\provision\\log.log"))
+	})
+
+	It("does not repeat lines when started again", func() {
+		setGuestLog("setup line\n")
+		tail.Start()
+		Eventually(lines).Should(HaveLen(1))
+		tail.Stop()
+
+		setGuestLog("setup line\npost-reboot line\n")
+		tail.Start()
+		Eventually(lines).Should(Equal([]string{"setup line", "post-reboot line"}))
+		tail.Stop()
+	})
+
+	It("starts from the beginning when the log is replaced", func() {
+		setGuestLog("a long line from an earlier run\n")
+		tail.Start()
+		Eventually(lines).Should(HaveLen(1))
+
+		setGuestLog("new\n")
+		Eventually(lines).Should(Equal([]string{"a long line from an earlier run", "new"}))
+		tail.Stop()
+	})
+
+	It("keeps polling while the log cannot be downloaded", func() {
+		fakeGuestManager.DownloadFileInGuestReturns(nil, 0, errors.New("guest is rebooting"))
+		tail.Start()
+
+		Eventually(fakeGuestManager.DownloadFileInGuestCallCount).Should(BeNumerically(">", 2))
+		tail.Stop()
+		Expect(fakeMessenger.GuestLogLineCallCount()).To(Equal(0))
+	})
+})
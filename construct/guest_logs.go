@@ -0,0 +1,72 @@
+package construct
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// guestLogs maps the provisioning and sysprep logs on the guest to the names they are saved under locally
+var guestLogs = []struct {
+	guestPath string
+	localName string
+}{
+	{guestPath: provisionLog, localName: "log.log"},
+	{guestPath: "C:\\Windows\\Panther\\setupact.log", localName: "panther-setupact.log"},
+	{guestPath: "C:\\Windows\\Panther\\setuperr.log", localName: "panther-setuperr.log"},
+	{guestPath: "C:\\Windows\\System32\\Sysprep\\Panther\\setupact.log", localName: "sysprep-panther-setupact.log"},
+	{guestPath: "C:\\Windows\\System32\\Sysprep\\Panther\\setuperr.log", localName: "sysprep-panther-setuperr.log"},
+}
+
+// downloadGuestLogs saves the guest logs to a new timestamped directory and adds its path to the error of the failed step.
+// Logs that do not exist on the guest are left out; the step error is returned unchanged when none could be saved.
+func (c *VMConstruct) downloadGuestLogs(stepErr error) error {
+	dir := filepath.Join(c.GuestLogsDir, fmt.Sprintf("stembuild-guest-logs-%s", time.Now().Format("20060102-150405")))
+
+	c.messenger.DownloadGuestLogsStarted()
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		c.messenger.GuestLogsNotDownloaded(err)
+		return stepErr
+	}
+
+	var lastErr error
+	saved := 0
+	for _, log := range guestLogs {
+		err = c.downloadGuestFile(log.guestPath, filepath.Join(dir, log.localName))
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %s", log.guestPath, err)
+			continue
+		}
+		saved++
+	}
+
+	if saved == 0 {
+		os.Remove(dir) //nolint:errcheck
+		c.messenger.GuestLogsNotDownloaded(lastErr)
+		return stepErr
+	}
+
+	return fmt.Errorf("%w\nGuest logs saved to %s", stepErr, dir)
+}
+
+func (c *VMConstruct) downloadGuestFile(guestPath, localPath string) error {
+	reader, _, err := c.guestManager.DownloadFileInGuest(c.ctx, guestPath)
+	if err != nil {
+		return err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	file, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
@@ -0,0 +1,235 @@
+package construct
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/stembuild/events"
+	"github.com/cloudfoundry/stembuild/report"
+)
+
+const (
+	cloneVMStep        = "clone-vm"
+	waitForCloneIPStep = "wait-for-clone-ip"
+	createSnapshotStep = "create-snapshot"
+	rollbackStep       = "rollback"
+	deleteSnapshotStep = "delete-snapshot"
+	guestLogsStep      = "download-guest-logs"
+)
+
+// JSONMessenger reports the progress of construct as JSON events. Steps are reported
+// through StepStarted, StepSucceeded and StepFailed, so the messages of individual steps only add detail.
+type JSONMessenger struct {
+	events *events.Emitter
+}
+
+func NewJSONMessenger(emitter *events.Emitter) *JSONMessenger {
+	return &JSONMessenger{events: emitter}
+}
+
+func (m *JSONMessenger) StepStarted(step string) {
+	m.events.StepStarted(step)
+}
+
+func (m *JSONMessenger) StepSucceeded(step string) {
+	m.events.StepSucceeded(step, "")
+}
+
+func (m *JSONMessenger) StepFailed(step string, err error) {
+	m.events.StepFailed(step, err)
+}
+
+func (m *JSONMessenger) StepSkipped(step string) {
+	m.events.StepSkipped(step, "completed by a previous run")
+}
+
+func (m *JSONMessenger) StepVerificationFailed(step string, reason string) {
+	m.events.Info(step, fmt.Sprintf("cannot skip step completed by a previous run: %s", reason))
+}
+
+func (m *JSONMessenger) CheckpointNotSaved(step string, err error) {
+	m.events.Warning(step, fmt.Sprintf("could not record completion of step; a later resume will repeat it: %s", err))
+}
+
+func (m *JSONMessenger) CheckpointsDisabled(err error) {
+	m.events.Warning("", fmt.Sprintf("cannot record construct progress, so construct cannot be resumed: %s", err))
+}
+
+// StepTimings prints nothing, since the events of every step carry its duration
+func (m *JSONMessenger) StepTimings(steps []report.Step) {}
+
+func (m *JSONMessenger) TimingsNotSaved(err error) {
+	m.events.Warning("", fmt.Sprintf("could not record the step timings for the build report of package: %s", err))
+}
+
+func (m *JSONMessenger) StemcellAutomationArchive(source, sha256 string) {
+	m.events.Info("", fmt.Sprintf("using %s (SHA-256 %s)", source, sha256))
+}
+
+func (m *JSONMessenger) CreateProvisionDirStarted()   {}
+func (m *JSONMessenger) CreateProvisionDirSucceeded() {}
+func (m *JSONMessenger) UploadArtifactsStarted()      {}
+func (m *JSONMessenger) UploadArtifactsSucceeded()    {}
+
+func (m *JSONMessenger) UploadFileStarted(artifact string) {
+	m.events.Info(uploadArtifactsStep, fmt.Sprintf("uploading %s", artifact))
+}
+
+func (m *JSONMessenger) UploadFileSucceeded()              {}
+func (m *JSONMessenger) EnableWinRMStarted()               {}
+func (m *JSONMessenger) EnableWinRMSucceeded()             {}
+func (m *JSONMessenger) ValidateVMConnectionStarted()      {}
+func (m *JSONMessenger) ValidateVMConnectionSucceeded()    {}
+func (m *JSONMessenger) ExtractArtifactsStarted()          {}
+func (m *JSONMessenger) ExtractArtifactsSucceeded()        {}
+func (m *JSONMessenger) ExecuteSetupScriptStarted()        {}
+func (m *JSONMessenger) ExecuteSetupScriptSucceeded()      {}
+func (m *JSONMessenger) ExecutePostRebootScriptStarted()   {}
+func (m *JSONMessenger) ExecutePostRebootScriptSucceeded() {}
+func (m *JSONMessenger) LogOutUsersStarted()               {}
+func (m *JSONMessenger) LogOutUsersSucceeded()             {}
+func (m *JSONMessenger) ValidateOSVersionStarted()         {}
+func (m *JSONMessenger) ShutdownCompleted()                {}
+
+// RebootHasStarted and RebootHasFinished are also sent for the reboots between rounds of Windows updates
+func (m *JSONMessenger) RebootHasStarted() {
+	m.events.Info("", "the reboot has started")
+}
+
+func (m *JSONMessenger) RebootHasFinished() {
+	m.events.Info("", "the reboot has finished")
+}
+
+func (m *JSONMessenger) WaitingForReboot(elapsed time.Duration) {
+	m.events.Info("", fmt.Sprintf("still waiting for the VM to reboot, %s so far", elapsed.Round(time.Second)))
+}
+
+func (m *JSONMessenger) ExecutePostRebootWarning(warning string) {
+	m.events.Warning(executePostRebootScriptStep, warning)
+}
+
+func (m *JSONMessenger) WaitingForShutdown() {
+	m.events.Info(waitForShutdownStep, "still preparing VM")
+}
+
+func (m *JSONMessenger) WinRMDisconnectedForReboot() {
+	m.events.Info(executeSetupScriptStep, "WinRM has been disconnected so the VM can reboot")
+}
+
+func (m *JSONMessenger) ValidateOSVersionSucceeded(os string) {
+	m.events.Info(validateOSVersionStep, fmt.Sprintf("Windows Server %s", os))
+}
+
+func (m *JSONMessenger) CheckVMIPStarted(ip string)  {}
+func (m *JSONMessenger) CheckVMIPSucceeded()         {}
+func (m *JSONMessenger) ConfirmVMIdentityStarted()   {}
+func (m *JSONMessenger) ConfirmVMIdentitySucceeded() {}
+
+func (m *JSONMessenger) VMIPNotReported(ip string) {
+	m.events.Warning(checkVMIPStep, fmt.Sprintf("VMware Tools reports no IP addresses for the VM to compare %s with; the VM is confirmed over WinRM before anything runs on it", ip))
+}
+
+func (m *JSONMessenger) OSVersionCheckSkipped(stembuildOS string) {
+	m.events.Warning(validateOSVersionStep, fmt.Sprintf("skipping guest OS version validation: stembuild version '%s' does not target a known Windows Server version", stembuildOS))
+}
+
+func (m *JSONMessenger) OSVersionMismatchIgnored(mismatch string) {
+	m.events.Warning(validateOSVersionStep, fmt.Sprintf("%s; continuing because -skip-os-check is set", mismatch))
+}
+
+func (m *JSONMessenger) OSVersionFileCreationFailed(errorMessage string) {
+	m.events.Warning(validateOSVersionStep, fmt.Sprintf("OS Version file creation failed: %s", errorMessage))
+}
+
+func (m *JSONMessenger) ExitCodeRetrievalFailed(errorMessage string) {
+	m.events.Warning(validateOSVersionStep, fmt.Sprintf("failed to retrieve exit code for process to create OS Version file: %s", errorMessage))
+}
+
+func (m *JSONMessenger) DownloadFileFailed(errorMessage string) {
+	m.events.Warning(validateOSVersionStep, fmt.Sprintf("failed to download OS Version file: %s", errorMessage))
+}
+
+func (m *JSONMessenger) DownloadGuestLogsStarted() {
+	m.events.Info(guestLogsStep, "downloading provisioning logs from the guest VM")
+}
+
+func (m *JSONMessenger) GuestLogsNotDownloaded(err error) {
+	m.events.Warning(guestLogsStep, fmt.Sprintf("could not download provisioning logs from the guest VM: %s", err))
+}
+
+func (m *JSONMessenger) GuestLogLine(line string) {
+	m.events.Info("guest-log", line)
+}
+
+func (m *JSONMessenger) CreateSnapshotStarted(name string) {
+	m.events.StepStarted(createSnapshotStep)
+}
+
+func (m *JSONMessenger) CreateSnapshotSucceeded() {
+	m.events.StepSucceeded(createSnapshotStep, "")
+}
+
+func (m *JSONMessenger) SnapshotReused(name string) {
+	m.events.StepSkipped(createSnapshotStep, fmt.Sprintf("keeping snapshot '%s' taken before the construct being resumed", name))
+}
+
+func (m *JSONMessenger) AlreadyConstructed(traces []string) {
+	m.events.Info("", fmt.Sprintf("VM has already been constructed, found %s; running only the steps whose results are missing", strings.Join(traces, " and ")))
+}
+
+func (m *JSONMessenger) SnapshotRetained(name string) {
+	m.events.Info("", fmt.Sprintf("the VM snapshot '%s' taken before construct has been kept", name))
+}
+
+func (m *JSONMessenger) RollbackStarted(name string) {
+	m.events.StepStarted(rollbackStep)
+}
+
+func (m *JSONMessenger) RollbackSucceeded() {
+	m.events.StepSucceeded(rollbackStep, "")
+}
+
+func (m *JSONMessenger) SnapshotNotDeleted(name string, err error) {
+	m.events.Warning(deleteSnapshotStep, fmt.Sprintf("could not delete VM snapshot '%s'; delete it in vCenter: %s", name, err))
+}
+
+func (m *JSONMessenger) WindowsUpdatesRoundStarted(round, maxRounds int) {
+	m.events.Info(installWindowsUpdatesStep, fmt.Sprintf("round %d of at most %d", round, maxRounds))
+}
+
+func (m *JSONMessenger) WindowsUpdatesRoundSucceeded(kbs []string) {
+	m.events.Info(installWindowsUpdatesStep, installedUpdates(kbs))
+}
+
+func (m *JSONMessenger) WindowsUpdatesRoundLimitReached(maxRounds int) {
+	m.events.Warning(installWindowsUpdatesStep, fmt.Sprintf("stopped installing Windows updates after %d rounds; more updates may be available", maxRounds))
+}
+
+func (m *JSONMessenger) WindowsUpdatesFinished(kbs []string) {
+	m.events.Info(installWindowsUpdatesStep, installedUpdates(kbs))
+}
+
+func (m *JSONMessenger) CloneVMStarted(source, clone string) {
+	m.events.StepStarted(cloneVMStep)
+	m.events.Info(cloneVMStep, fmt.Sprintf("cloning %s to %s", source, clone))
+}
+
+func (m *JSONMessenger) CloneVMSucceeded() {
+	m.events.StepSucceeded(cloneVMStep, "")
+}
+
+func (m *JSONMessenger) WaitForCloneIPStarted() {
+	m.events.StepStarted(waitForCloneIPStep)
+}
+
+func (m *JSONMessenger) WaitForCloneIPSucceeded(ip string) {
+	m.events.StepSucceeded(waitForCloneIPStep, ip)
+}
+
+func installedUpdates(kbs []string) string {
+	if len(kbs) == 0 {
+		return "no updates installed"
+	}
+	return fmt.Sprintf("installed %s", strings.Join(kbs, ", "))
+}
@@ -0,0 +1,108 @@
+package construct_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/events"
+)
+
+var _ = Describe("JSONMessenger", func() {
+	var (
+		output    *bytes.Buffer
+		messenger *construct.JSONMessenger
+	)
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		messenger = construct.NewJSONMessenger(events.NewEmitter(output, "construct"))
+	})
+
+	decode := func() []events.Event {
+		var decoded []events.Event
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var event events.Event
+			ExpectWithOffset(1, json.Unmarshal([]byte(line), &event)).To(Succeed())
+			decoded = append(decoded, event)
+		}
+		return decoded
+	}
+
+	It("reports steps as events", func() {
+		messenger.StepStarted("enable-winrm")
+		messenger.EnableWinRMStarted()
+		messenger.EnableWinRMSucceeded()
+		messenger.StepSucceeded("enable-winrm")
+		messenger.StepStarted("reboot")
+		messenger.StepFailed("reboot", errors.New("timed out"))
+
+		decoded := decode()
+		Expect(decoded).To(HaveLen(4))
+		Expect(decoded[0].Step).To(Equal("enable-winrm"))
+		Expect(decoded[0].Status).To(Equal(events.StatusStarted))
+		Expect(decoded[1].Step).To(Equal("enable-winrm"))
+		Expect(decoded[1].Status).To(Equal(events.StatusSucceeded))
+		Expect(decoded[1].Duration).NotTo(BeNil())
+		Expect(decoded[3].Step).To(Equal("reboot"))
+		Expect(decoded[3].Status).To(Equal(events.StatusFailed))
+		Expect(decoded[3].Error).To(Equal("timed out"))
+	})
+
+	It("reports steps completed by a previous run as skipped", func() {
+		messenger.StepSkipped("upload-artifacts")
+
+		decoded := decode()
+		Expect(decoded[0].Step).To(Equal("upload-artifacts"))
+		Expect(decoded[0].Status).To(Equal(events.StatusSkipped))
+	})
+
+	It("reports warnings within a step", func() {
+		messenger.WindowsUpdatesRoundLimitReached(5)
+
+		decoded := decode()
+		Expect(decoded[0].Step).To(Equal("install-windows-updates"))
+		Expect(decoded[0].Status).To(Equal(events.StatusWarning))
+		Expect(decoded[0].Message).To(ContainSubstring("after 5 rounds"))
+	})
+
+	It("reports cloning and snapshots as steps", func() {
+		messenger.CloneVMStarted("template", "clone")
+		messenger.CloneVMSucceeded()
+		messenger.WaitForCloneIPStarted()
+		messenger.WaitForCloneIPSucceeded("10.0.0.5")
+		messenger.CreateSnapshotStarted("stembuild-pre-construct")
+		messenger.CreateSnapshotSucceeded()
+
+		decoded := decode()
+		Expect(decoded[0].Step).To(Equal("clone-vm"))
+		Expect(decoded[2].Step).To(Equal("clone-vm"))
+		Expect(decoded[2].Status).To(Equal(events.StatusSucceeded))
+		Expect(decoded[4].Step).To(Equal("wait-for-clone-ip"))
+		Expect(decoded[4].Message).To(Equal("10.0.0.5"))
+		Expect(decoded[6].Step).To(Equal("create-snapshot"))
+		Expect(decoded[6].Status).To(Equal(events.StatusSucceeded))
+	})
+
+	It("reports guest log lines", func() {
+		messenger.GuestLogLine("Installing CF features")
+
+		decoded := decode()
+		Expect(decoded[0].Step).To(Equal("guest-log"))
+		Expect(decoded[0].Message).To(Equal("Installing CF features"))
+	})
+
+	It("reports how long a reboot has been waited for", func() {
+		messenger.WaitingForReboot(90*time.Second + 300*time.Millisecond)
+
+		decoded := decode()
+		Expect(decoded[0].Status).To(Equal(events.StatusInfo))
+		Expect(decoded[0].Message).To(Equal("still waiting for the VM to reboot, 1m30s so far"))
+	})
+})
@@ -0,0 +1,232 @@
+package lgpo
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const defaultPath = "LGPO.zip"
+
+// Source says where LGPO.zip comes from. The zero value is LGPO.zip in the current working directory.
+type Source struct {
+	// Path is a local LGPO.zip
+	Path string
+	// URL is downloaded once into the cache directory and reused while its checksum matches
+	URL string
+	// SHA256 is the expected checksum of the zip, required with URL
+	SHA256 string
+}
+
+func (s Source) Validate() error {
+	if s.Path != "" && s.URL != "" {
+		return errors.New("a path and a URL cannot both be given")
+	}
+
+	if s.URL != "" && s.SHA256 == "" {
+		return errors.New("a SHA-256 is required to download from a URL")
+	}
+
+	if s.SHA256 != "" {
+		sum, err := hex.DecodeString(s.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("%s is not a SHA-256 checksum", s.SHA256)
+		}
+	}
+
+	return nil
+}
+
+// DefaultCacheDir is where downloaded copies of LGPO.zip are kept for the current user
+func DefaultCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine a directory to cache LGPO.zip in: %s", err)
+	}
+	return filepath.Join(cacheDir, "stembuild", "lgpo"), nil
+}
+
+// Resolve returns the path of a local LGPO.zip that matches the expected checksum and contains LGPO.exe,
+// downloading it into cacheDir first when the source is a URL
+func (s Source) Resolve(cacheDir string) (string, error) {
+	err := s.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	if s.URL != "" {
+		return s.download(cacheDir)
+	}
+
+	zipPath := s.Path
+	if zipPath == "" {
+		zipPath = defaultPath
+	}
+	if _, err := os.Stat(zipPath); err != nil {
+		return "", fmt.Errorf("could not find %s: %s", zipPath, err)
+	}
+
+	err = verify(zipPath, s.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return zipPath, nil
+}
+
+// ResolveInDefaultCache is Resolve with DefaultCacheDir as the cache directory
+func (s Source) ResolveInDefaultCache() (string, error) {
+	cacheDir, err := DefaultCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return s.Resolve(cacheDir)
+}
+
+// Check verifies the source like Resolve, but never downloads: a URL passes when the cached copy
+// is valid, or else when the server answers a HEAD request for it
+func (s Source) Check(cacheDir string) error {
+	if s.URL == "" {
+		_, err := s.Resolve(cacheDir)
+		return err
+	}
+
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+
+	checksum := strings.ToLower(s.SHA256)
+	if verify(cachedPath(cacheDir, checksum), checksum) == nil {
+		return nil
+	}
+
+	resp, err := http.Head(s.URL) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("could not reach %s: %s", s.URL, err)
+	}
+	resp.Body.Close() //nolint:errcheck,gosec
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not reach %s: server returned %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+// CheckInDefaultCache is Check with DefaultCacheDir as the cache directory
+func (s Source) CheckInDefaultCache() error {
+	cacheDir, err := DefaultCacheDir()
+	if err != nil {
+		return err
+	}
+	return s.Check(cacheDir)
+}
+
+func cachedPath(cacheDir, checksum string) string {
+	return filepath.Join(cacheDir, fmt.Sprintf("LGPO-%s.zip", checksum))
+}
+
+func (s Source) download(cacheDir string) (string, error) {
+	checksum := strings.ToLower(s.SHA256)
+	cachedPath := cachedPath(cacheDir, checksum)
+
+	// a corrupt cached copy is replaced by downloading it again
+	if verify(cachedPath, checksum) == nil {
+		return cachedPath, nil
+	}
+
+	err := os.MkdirAll(cacheDir, 0750)
+	if err != nil {
+		return "", fmt.Errorf("could not create LGPO cache directory: %s", err)
+	}
+
+	tmpFile, err := os.CreateTemp(cacheDir, "LGPO-*.zip.download")
+	if err != nil {
+		return "", fmt.Errorf("could not create LGPO cache file: %s", err)
+	}
+	defer os.Remove(tmpFile.Name()) //nolint:errcheck
+
+	err = fetch(s.URL, tmpFile)
+	closeErr := tmpFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("could not download %s: %s", s.URL, err)
+	}
+	if closeErr != nil {
+		return "", fmt.Errorf("could not write LGPO cache file: %s", closeErr)
+	}
+
+	err = verify(tmpFile.Name(), checksum)
+	if err != nil {
+		return "", fmt.Errorf("downloaded %s: %s", s.URL, err)
+	}
+
+	err = os.Rename(tmpFile.Name(), cachedPath)
+	if err != nil {
+		return "", fmt.Errorf("could not write LGPO cache file: %s", err)
+	}
+	return cachedPath, nil
+}
+
+func fetch(url string, w io.Writer) error {
+	resp, err := http.Get(url) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// verify checks the checksum of the zip, when one is expected, and that it contains LGPO.exe
+func verify(zipPath, expectedSHA256 string) error {
+	if expectedSHA256 != "" {
+		actual, err := fileSHA256(zipPath)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(actual, expectedSHA256) {
+			return fmt.Errorf("%s has SHA-256 %s, expected %s", zipPath, actual, expectedSHA256)
+		}
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid zip archive: %s", zipPath, err)
+	}
+	defer r.Close() //nolint:errcheck
+
+	// Microsoft ships LGPO.exe in a versioned folder, e.g. LGPO_30/LGPO.exe
+	for _, f := range r.File {
+		if strings.EqualFold(path.Base(f.Name), "LGPO.exe") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not contain LGPO.exe", zipPath)
+}
+
+func fileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %s", filePath, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,13 @@
+package lgpo_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLGPO(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LGPO Suite")
+}
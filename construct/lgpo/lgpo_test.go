@@ -0,0 +1,253 @@
+package lgpo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+)
+
+var _ = Describe("Source", func() {
+	var (
+		tmpDir   string
+		cacheDir string
+	)
+
+	lgpoZip := func(files ...string) []byte {
+		buf := new(bytes.Buffer)
+		w := zip.NewWriter(buf)
+		for _, file := range files {
+			_, err := w.Create(file)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	checksum := func(contents []byte) string {
+		sum := sha256.Sum256(contents)
+		return hex.EncodeToString(sum[:])
+	}
+
+	writeFile := func(name string, contents []byte) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(os.WriteFile(path, contents, 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+		cacheDir = filepath.Join(tmpDir, "cache")
+	})
+
+	Describe("Validate", func() {
+		It("accepts the zero value", func() {
+			Expect(lgpo.Source{}.Validate()).To(Succeed())
+		})
+
+		It("rejects a path combined with a URL", func() {
+			err := lgpo.Source{Path: "LGPO.zip", URL: "https://example.com/LGPO.zip", SHA256: checksum(nil)}.Validate()
+			Expect(err).To(MatchError("a path and a URL cannot both be given"))
+		})
+
+		It("requires a checksum with a URL", func() {
+			err := lgpo.Source{URL: "https://example.com/LGPO.zip"}.Validate()
+			Expect(err).To(MatchError("a SHA-256 is required to download from a URL"))
+		})
+
+		It("rejects a checksum that is not a SHA-256", func() {
+			err := lgpo.Source{SHA256: "abc"}.Validate()
+			Expect(err).To(MatchError("abc is not a SHA-256 checksum"))
+		})
+	})
+
+	Describe("Resolve", func() {
+		Context("with a path", func() {
+			It("returns the path when the zip contains LGPO.exe", func() {
+				path := writeFile("LGPO.zip", lgpoZip("LGPO_30/LGPO.exe"))
+
+				resolved, err := lgpo.Source{Path: path}.Resolve(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resolved).To(Equal(path))
+			})
+
+			It("fails when the zip does not contain LGPO.exe", func() {
+				path := writeFile("LGPO.zip", lgpoZip("README.txt"))
+
+				_, err := lgpo.Source{Path: path}.Resolve(cacheDir)
+				Expect(err).To(MatchError(path + " does not contain LGPO.exe"))
+			})
+
+			It("fails when the file is not a zip", func() {
+				path := writeFile("LGPO.zip", []byte("<html>not found</html>"))
+
+				_, err := lgpo.Source{Path: path}.Resolve(cacheDir)
+				Expect(err).To(MatchError(HavePrefix(path + " is not a valid zip archive: ")))
+			})
+
+			It("fails when the checksum does not match", func() {
+				contents := lgpoZip("LGPO.exe")
+				path := writeFile("LGPO.zip", contents)
+				expected := checksum([]byte("something else"))
+
+				_, err := lgpo.Source{Path: path, SHA256: expected}.Resolve(cacheDir)
+				Expect(err).To(MatchError(path + " has SHA-256 " + checksum(contents) + ", expected " + expected))
+			})
+
+			It("fails when the file does not exist", func() {
+				_, err := lgpo.Source{Path: filepath.Join(tmpDir, "missing.zip")}.Resolve(cacheDir)
+				Expect(err).To(MatchError(HavePrefix("could not find " + filepath.Join(tmpDir, "missing.zip"))))
+			})
+		})
+
+		Context("with a URL", func() {
+			var (
+				server   *httptest.Server
+				contents []byte
+				requests int
+			)
+
+			BeforeEach(func() {
+				contents = lgpoZip("LGPO_30/LGPO.exe")
+				requests = 0
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					if r.URL.Path != "/LGPO.zip" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write(contents)
+				}))
+				DeferCleanup(server.Close)
+			})
+
+			It("downloads the zip into the cache and reuses it", func() {
+				source := lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: checksum(contents)}
+
+				resolved, err := source.Resolve(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Dir(resolved)).To(Equal(cacheDir))
+				Expect(os.ReadFile(resolved)).To(Equal(contents))
+
+				resolvedAgain, err := source.Resolve(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resolvedAgain).To(Equal(resolved))
+				Expect(requests).To(Equal(1))
+			})
+
+			It("downloads the zip into the default cache directory", func() {
+				userCacheDir := GinkgoT().TempDir()
+				GinkgoT().Setenv("XDG_CACHE_HOME", userCacheDir)
+				GinkgoT().Setenv("HOME", userCacheDir)
+				GinkgoT().Setenv("LocalAppData", userCacheDir)
+				source := lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: checksum(contents)}
+
+				resolved, err := source.ResolveInDefaultCache()
+				Expect(err).ToNot(HaveOccurred())
+
+				defaultCacheDir, err := lgpo.DefaultCacheDir()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(defaultCacheDir).To(HavePrefix(userCacheDir))
+				Expect(filepath.Dir(resolved)).To(Equal(defaultCacheDir))
+				Expect(os.ReadFile(resolved)).To(Equal(contents))
+			})
+
+			It("downloads the zip again when the cached copy is corrupt", func() {
+				source := lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: checksum(contents)}
+				resolved, err := source.Resolve(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.WriteFile(resolved, []byte("truncated"), 0600)).To(Succeed())
+
+				_, err = source.Resolve(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.ReadFile(resolved)).To(Equal(contents))
+				Expect(requests).To(Equal(2))
+			})
+
+			It("does not cache a download with the wrong checksum", func() {
+				expected := checksum([]byte("something else"))
+
+				_, err := lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: expected}.Resolve(cacheDir)
+				Expect(err).To(MatchError(ContainSubstring("expected " + expected)))
+
+				entries, err := os.ReadDir(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entries).To(BeEmpty())
+			})
+
+			It("fails when the server does not return the zip", func() {
+				_, err := lgpo.Source{URL: server.URL + "/missing.zip", SHA256: checksum(contents)}.Resolve(cacheDir)
+				Expect(err).To(MatchError("could not download " + server.URL + "/missing.zip: server returned 404 Not Found"))
+			})
+		})
+	})
+
+	Describe("Check", func() {
+		It("verifies a zip given with a path", func() {
+			path := writeFile("LGPO.zip", []byte("not a zip"))
+
+			err := lgpo.Source{Path: path}.Check(cacheDir)
+			Expect(err).To(MatchError(ContainSubstring("is not a valid zip archive")))
+		})
+
+		Context("with a URL", func() {
+			var (
+				server   *httptest.Server
+				contents []byte
+				methods  []string
+			)
+
+			BeforeEach(func() {
+				contents = lgpoZip("LGPO_30/LGPO.exe")
+				methods = nil
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					methods = append(methods, r.Method)
+					if r.URL.Path != "/LGPO.zip" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write(contents)
+				}))
+				DeferCleanup(server.Close)
+			})
+
+			It("only asks the server for the zip, without downloading or caching it", func() {
+				err := lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: checksum(contents)}.Check(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(methods).To(Equal([]string{http.MethodHead}))
+				Expect(cacheDir).NotTo(BeAnExistingFile())
+			})
+
+			It("verifies the cached copy without contacting the server", func() {
+				source := lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: checksum(contents)}
+				_, err := source.Resolve(cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				methods = nil
+
+				Expect(source.Check(cacheDir)).To(Succeed())
+				Expect(methods).To(BeEmpty())
+			})
+
+			It("fails when the server does not have the zip", func() {
+				err := lgpo.Source{URL: server.URL + "/missing.zip", SHA256: checksum(contents)}.Check(cacheDir)
+				Expect(err).To(MatchError("could not reach " + server.URL + "/missing.zip: server returned 404 Not Found"))
+			})
+
+			It("requires a checksum", func() {
+				err := lgpo.Source{URL: server.URL + "/LGPO.zip"}.Check(cacheDir)
+				Expect(err).To(MatchError("a SHA-256 is required to download from a URL"))
+				Expect(methods).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -3,7 +3,10 @@ package construct
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
+
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 type Messenger struct {
@@ -14,6 +17,10 @@ func NewMessenger(out io.Writer) *Messenger {
 	return &Messenger{out}
 }
 
+func (m *Messenger) StemcellAutomationArchive(source, sha256 string) {
+	m.out.Write([]byte(fmt.Sprintf("\nUsing %s (SHA-256 %s)\n", source, sha256))) //nolint:errcheck
+}
+
 func (m *Messenger) EnableWinRMStarted() {
 	m.out.Write([]byte("\nAttempting to enable WinRM on the guest vm...")) //nolint:errcheck//nolint:errcheck
 }
@@ -22,6 +29,27 @@ func (m *Messenger) EnableWinRMSucceeded() {
 	m.out.Write([]byte("WinRm enabled on the guest VM\n")) //nolint:errcheck
 }
 
+func (m *Messenger) CheckVMIPStarted(ip string) {
+	m.out.Write([]byte(fmt.Sprintf("\nChecking that %s is an address of the VM...", ip))) //nolint:errcheck
+}
+
+func (m *Messenger) CheckVMIPSucceeded() {
+	m.out.Write([]byte("succeeded.\n")) //nolint:errcheck
+}
+
+func (m *Messenger) VMIPNotReported(ip string) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: VMware Tools reports no IP addresses for the VM to compare %s with. "+ //nolint:errcheck
+		"The VM is confirmed over WinRM before anything runs on it.\n", ip)))
+}
+
+func (m *Messenger) ConfirmVMIdentityStarted() {
+	m.out.Write([]byte("\nConfirming that WinRM reaches the VM at the inventory path...")) //nolint:errcheck
+}
+
+func (m *Messenger) ConfirmVMIdentitySucceeded() {
+	m.out.Write([]byte("succeeded.\n")) //nolint:errcheck
+}
+
 func (m *Messenger) ValidateVMConnectionStarted() {
 	m.out.Write([]byte("\nValidating connection to vm...")) //nolint:errcheck
 }
@@ -113,6 +141,22 @@ func (m *Messenger) DownloadFileFailed(errorMessage string) {
 	m.logValidateOSWarning("Failed to download OS Version file", errorMessage)
 }
 
+func (m *Messenger) ValidateOSVersionStarted() {
+	m.out.Write([]byte("\nValidating guest OS version...")) //nolint:errcheck
+}
+
+func (m *Messenger) ValidateOSVersionSucceeded(os string) {
+	m.out.Write([]byte(fmt.Sprintf("Windows Server %s.\n", os))) //nolint:errcheck
+}
+
+func (m *Messenger) OSVersionCheckSkipped(stembuildOS string) {
+	m.out.Write([]byte(fmt.Sprintf("\nSkipping guest OS version validation: stembuild version '%s' does not target a known Windows Server version.\n", stembuildOS))) //nolint:errcheck
+}
+
+func (m *Messenger) OSVersionMismatchIgnored(mismatch string) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: %s. Continuing because -skip-os-check is set.\n", mismatch))) //nolint:errcheck
+}
+
 func (m *Messenger) logValidateOSWarning(log string, errorMessage string) {
 	matchingVersionWarning := "Ensure the version of the stemcell you're trying to build matches the corresponding base ISO you're using.\n" +
 		"For example: If you're building 2019.x, then you should be using 'Windows Server 2019' only"
@@ -125,6 +169,12 @@ func (m *Messenger) WaitingForShutdown() {
 	m.out.Write([]byte(fmt.Sprintf("%s Still preparing VM...\n", t.Format(timeStampFormat)))) //nolint:errcheck
 }
 
+func (m *Messenger) WaitingForReboot(elapsed time.Duration) {
+	t := time.Now()
+	timeStampFormat := "2006-01-02T15:04:05.999999-07:00"
+	m.out.Write([]byte(fmt.Sprintf("%s Still waiting for the VM to reboot, %s so far...\n", t.Format(timeStampFormat), elapsed.Round(time.Second)))) //nolint:errcheck
+}
+
 func (m *Messenger) ShutdownCompleted() {
 	m.out.Write([]byte("VM has now been shutdown. Run `stembuild package` to finish building the stemcell.\n")) //nolint:errcheck
 }
@@ -133,3 +183,121 @@ func (m *Messenger) WinRMDisconnectedForReboot() {
 	m.out.Write([]byte("\nWinRM has been disconnected so the VM can reboot.\n")) //nolint:errcheck
 
 }
+
+func (m *Messenger) StepSkipped(step string) {
+	m.out.Write([]byte(fmt.Sprintf("\nSkipping step '%s': it was completed by a previous run.\n", step))) //nolint:errcheck
+}
+
+// StepStarted, StepSucceeded and StepFailed print nothing, since every step prints its own messages
+
+func (m *Messenger) StepStarted(step string) {}
+
+func (m *Messenger) StepSucceeded(step string) {}
+
+func (m *Messenger) StepFailed(step string, err error) {}
+
+func (m *Messenger) StepTimings(steps []report.Step) {
+	report.PrintSummary(m.out, "Construct step timings", steps)
+}
+
+func (m *Messenger) TimingsNotSaved(err error) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: could not record the step timings for the build report of package: %s\n", err))) //nolint:errcheck
+}
+
+func (m *Messenger) StepVerificationFailed(step string, reason string) {
+	m.out.Write([]byte(fmt.Sprintf("\nCannot skip step '%s' completed by a previous run: %s. Resuming from this step.\n", step, reason))) //nolint:errcheck
+}
+
+func (m *Messenger) CheckpointNotSaved(step string, err error) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: could not record completion of step '%s'; a later resume will repeat it: %s\n", step, err))) //nolint:errcheck
+}
+
+func (m *Messenger) CheckpointsDisabled(err error) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: cannot record construct progress, so construct cannot be resumed: %s. Pass -state-file to record it elsewhere.\n", err))) //nolint:errcheck
+}
+
+func (m *Messenger) CreateSnapshotStarted(name string) {
+	m.out.Write([]byte(fmt.Sprintf("\nTaking snapshot '%s' of the VM before construct...", name))) //nolint:errcheck
+}
+
+func (m *Messenger) CreateSnapshotSucceeded() {
+	m.out.Write([]byte("succeeded.\n")) //nolint:errcheck
+}
+
+func (m *Messenger) SnapshotReused(name string) {
+	m.out.Write([]byte(fmt.Sprintf("\nKeeping snapshot '%s' taken before the construct being resumed.\n", name))) //nolint:errcheck
+}
+
+func (m *Messenger) AlreadyConstructed(traces []string) {
+	m.out.Write([]byte(fmt.Sprintf("\nVM has already been constructed, found %s. Running only the steps whose results are missing.\n", strings.Join(traces, " and ")))) //nolint:errcheck
+}
+
+func (m *Messenger) SnapshotRetained(name string) {
+	m.out.Write([]byte(fmt.Sprintf("\nThe VM snapshot '%s' taken before construct has been kept. "+ //nolint:errcheck
+		"Revert to it in vCenter to start over, or rerun with -rollback-on-failure to revert automatically.\n", name)))
+}
+
+func (m *Messenger) RollbackStarted(name string) {
+	m.out.Write([]byte(fmt.Sprintf("\nRolling the VM back to snapshot '%s'...", name))) //nolint:errcheck
+}
+
+func (m *Messenger) RollbackSucceeded() {
+	m.out.Write([]byte("succeeded.\n")) //nolint:errcheck
+}
+
+func (m *Messenger) SnapshotNotDeleted(name string, err error) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: could not delete VM snapshot '%s'; delete it in vCenter: %s\n", name, err))) //nolint:errcheck
+}
+
+func (m *Messenger) WindowsUpdatesRoundStarted(round, maxRounds int) {
+	m.out.Write([]byte(fmt.Sprintf("\nInstalling Windows updates, round %d of at most %d...", round, maxRounds))) //nolint:errcheck
+}
+
+func (m *Messenger) WindowsUpdatesRoundSucceeded(kbs []string) {
+	if len(kbs) == 0 {
+		m.out.Write([]byte("no updates installed.\n")) //nolint:errcheck
+		return
+	}
+	m.out.Write([]byte(fmt.Sprintf("installed %s.\n", strings.Join(kbs, ", ")))) //nolint:errcheck
+}
+
+func (m *Messenger) WindowsUpdatesRoundLimitReached(maxRounds int) {
+	m.out.Write([]byte(fmt.Sprintf("\nWarning: stopped installing Windows updates after %d rounds; more updates may be available\n", maxRounds))) //nolint:errcheck
+}
+
+func (m *Messenger) WindowsUpdatesFinished(kbs []string) {
+	if len(kbs) == 0 {
+		m.out.Write([]byte("\nNo Windows updates were installed.\n")) //nolint:errcheck
+		return
+	}
+	m.out.Write([]byte(fmt.Sprintf("\nInstalled Windows updates: %s\n", strings.Join(kbs, ", ")))) //nolint:errcheck
+}
+
+func (m *Messenger) CloneVMStarted(source, clone string) {
+	m.out.Write([]byte(fmt.Sprintf("\nCloning %s to %s...", source, clone))) //nolint:errcheck
+}
+
+func (m *Messenger) CloneVMSucceeded() {
+	m.out.Write([]byte("succeeded.\n")) //nolint:errcheck
+}
+
+func (m *Messenger) WaitForCloneIPStarted() {
+	m.out.Write([]byte("\nWaiting for the clone to finish guest customization and report an IP address...")) //nolint:errcheck
+}
+
+func (m *Messenger) WaitForCloneIPSucceeded(ip string) {
+	m.out.Write([]byte(fmt.Sprintf("%s.\n", ip))) //nolint:errcheck
+}
+
+func (m *Messenger) DownloadGuestLogsStarted() {
+	m.out.Write([]byte("\nDownloading provisioning logs from the guest VM...\n")) //nolint:errcheck
+}
+
+func (m *Messenger) GuestLogsNotDownloaded(err error) {
+	m.out.Write([]byte(fmt.Sprintf("Warning: could not download provisioning logs from the guest VM: %s\n", err))) //nolint:errcheck
+}
+
+func (m *Messenger) GuestLogLine(line string) {
+	timeStampFormat := "2006-01-02T15:04:05.999999-07:00"
+	m.out.Write([]byte(fmt.Sprintf("%s [guest log] %s\n", time.Now().Format(timeStampFormat), line))) //nolint:errcheck
+}
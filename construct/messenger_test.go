@@ -1,9 +1,12 @@
 package construct_test
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/report"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -17,6 +20,65 @@ var _ = Describe("Messenger", func() {
 		buf = NewBuffer()
 	})
 
+	Describe("Stemcell automation archive message", func() {
+		It("writes the archive and its SHA-256 to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.StemcellAutomationArchive("custom.zip", "abc123")
+
+			Expect(buf).To(Say("\nUsing custom.zip \\(SHA-256 abc123\\)\n"))
+		})
+	})
+
+	Describe("Step timings messages", func() {
+		It("writes the duration of every step", func() {
+			m := construct.NewMessenger(buf)
+			m.StepTimings([]report.Step{{Name: "enable-winrm", Duration: 3}, {Name: "wait-for-shutdown", Duration: 62}})
+
+			Expect(buf).To(Say("\nConstruct step timings:\n"))
+			Expect(buf).To(Say("enable-winrm +3s\n"))
+			Expect(buf).To(Say("wait-for-shutdown +1m2s\n"))
+			Expect(buf).To(Say("total +1m5s\n"))
+		})
+
+		It("warns when the timings cannot be recorded", func() {
+			m := construct.NewMessenger(buf)
+			m.TimingsNotSaved(errors.New("disk full"))
+
+			Expect(buf).To(Say("Warning: could not record the step timings for the build report of package: disk full"))
+		})
+	})
+
+	Describe("Windows updates messages", func() {
+		It("writes the installed KBs of a round on the line of its started message", func() {
+			m := construct.NewMessenger(buf)
+			m.WindowsUpdatesRoundStarted(1, 5)
+			m.WindowsUpdatesRoundSucceeded([]string{"KB5001", "KB5002"})
+
+			Expect(buf).To(Say("\nInstalling Windows updates, round 1 of at most 5...installed KB5001, KB5002.\n"))
+		})
+
+		It("writes when a round installed nothing", func() {
+			m := construct.NewMessenger(buf)
+			m.WindowsUpdatesRoundSucceeded(nil)
+
+			Expect(buf).To(Say("no updates installed.\n"))
+		})
+
+		It("writes every installed KB when finished", func() {
+			m := construct.NewMessenger(buf)
+			m.WindowsUpdatesFinished([]string{"KB5001", "KB5002"})
+
+			Expect(buf).To(Say("\nInstalled Windows updates: KB5001, KB5002\n"))
+		})
+
+		It("warns when the round limit is reached", func() {
+			m := construct.NewMessenger(buf)
+			m.WindowsUpdatesRoundLimitReached(5)
+
+			Expect(buf).To(Say("Warning: stopped installing Windows updates after 5 rounds; more updates may be available"))
+		})
+	})
+
 	Describe("Enable WinRM messages", func() {
 		It("writes the started message to the writer", func() {
 			m := construct.NewMessenger(buf)
@@ -256,6 +318,28 @@ var _ = Describe("Messenger", func() {
 			Expect(buf).To(Say(fmt.Sprintf("Warning: Failed to retrieve exit code for process to create OS Version file:\n%s\n%s", matchingVersionWarning, errorMessage)))
 		})
 
+		It("writes the OS version messages to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.ValidateOSVersionStarted()
+			m.ValidateOSVersionSucceeded("2019")
+
+			Expect(buf).To(Say("Validating guest OS version...Windows Server 2019."))
+		})
+
+		It("writes the OS version mismatch warning to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.OSVersionMismatchIgnored("guest OS is Windows Server 2022 but this stembuild builds 2019 stemcells")
+
+			Expect(buf).To(Say("Warning: guest OS is Windows Server 2022 but this stembuild builds 2019 stemcells. Continuing because -skip-os-check is set."))
+		})
+
+		It("writes the OS version check skipped message to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.OSVersionCheckSkipped("dev")
+
+			Expect(buf).To(Say("Skipping guest OS version validation: stembuild version 'dev' does not target a known Windows Server version."))
+		})
+
 		It("writes the download file failed message to the writer", func() {
 			errorMessage := "some error message"
 			m := construct.NewMessenger(buf)
@@ -278,6 +362,14 @@ var _ = Describe("Messenger", func() {
 			Expect(buf).To(Say(logLineRegex))
 		})
 
+		It("writes how long a reboot has been waited for with timestamp", func() {
+			m := construct.NewMessenger(buf)
+			m.WaitingForReboot(90 * time.Second)
+			dateTimeRegex := "\\d{4}\\-\\d{2}-\\d{2}T\\d{2}:\\d{2}:\\d{2}\\.\\d*(\\-|\\+)\\d{2}:\\d{2}"
+
+			Expect(buf).To(Say(fmt.Sprintf("%s\\s*Still waiting for the VM to reboot, 1m30s so far...\n", dateTimeRegex)))
+		})
+
 		It("writes the shutdown message to the writer", func() {
 			m := construct.NewMessenger(buf)
 			m.ShutdownCompleted()
@@ -287,4 +379,105 @@ var _ = Describe("Messenger", func() {
 
 	})
 
+	Describe("checkpoint messages", func() {
+		It("writes the step skipped message to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.StepSkipped("upload-artifacts")
+
+			Expect(buf).To(Say("Skipping step 'upload-artifacts': it was completed by a previous run."))
+		})
+
+		It("writes the verification failed message to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.StepVerificationFailed("upload-artifacts", "some reason")
+
+			Expect(buf).To(Say("Cannot skip step 'upload-artifacts' completed by a previous run: some reason. Resuming from this step."))
+		})
+
+		It("writes the checkpoint warning to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.CheckpointNotSaved("upload-artifacts", errors.New("disk full"))
+
+			Expect(buf).To(Say("Warning: could not record completion of step 'upload-artifacts'; a later resume will repeat it: disk full"))
+		})
+	})
+
+	Describe("snapshot messages", func() {
+		It("writes the snapshot retained message to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.SnapshotRetained("stembuild-pre-construct")
+
+			Expect(buf).To(Say("The VM snapshot 'stembuild-pre-construct' taken before construct has been kept."))
+			Expect(buf).To(Say("-rollback-on-failure"))
+		})
+
+		It("writes the already constructed message to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.AlreadyConstructed([]string{"C:\\provision\\stembuild-construct.txt", "C:\\var\\vcap\\bosh\\etc\\stemcell_version"})
+
+			Expect(buf.Contents()).To(ContainSubstring("VM has already been constructed, found C:\\provision\\stembuild-construct.txt and C:\\var\\vcap\\bosh\\etc\\stemcell_version. Running only the steps whose results are missing."))
+		})
+
+		It("writes the -vm-ip check messages to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.CheckVMIPStarted("10.0.0.5")
+			m.CheckVMIPSucceeded()
+			m.ConfirmVMIdentityStarted()
+			m.ConfirmVMIdentitySucceeded()
+
+			Expect(buf).To(Say(`Checking that 10.0.0.5 is an address of the VM...succeeded.`))
+			Expect(buf).To(Say(`Confirming that WinRM reaches the VM at the inventory path...succeeded.`))
+		})
+
+		It("warns when VMware Tools reports no IP addresses for the VM", func() {
+			m := construct.NewMessenger(buf)
+			m.VMIPNotReported("10.0.0.5")
+
+			Expect(buf.Contents()).To(ContainSubstring("Warning: VMware Tools reports no IP addresses for the VM to compare 10.0.0.5 with."))
+		})
+
+		It("writes the rollback messages to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.RollbackStarted("stembuild-pre-construct")
+			m.RollbackSucceeded()
+
+			Expect(buf).To(Say(`Rolling the VM back to snapshot 'stembuild-pre-construct'...succeeded.`))
+		})
+
+		It("writes the snapshot deletion warning to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.SnapshotNotDeleted("stembuild-pre-construct", errors.New("task failed"))
+
+			Expect(buf).To(Say("Warning: could not delete VM snapshot 'stembuild-pre-construct'; delete it in vCenter: task failed"))
+		})
+	})
+
+	Describe("clone messages", func() {
+		It("writes the clone messages to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.CloneVMStarted("/dc/vm/golden", "/dc/vm/clone")
+			m.CloneVMSucceeded()
+			m.WaitForCloneIPStarted()
+			m.WaitForCloneIPSucceeded("10.0.0.5")
+
+			Expect(buf).To(Say("Cloning /dc/vm/golden to /dc/vm/clone...succeeded."))
+			Expect(buf).To(Say(`report an IP address...10\.0\.0\.5\.`))
+		})
+	})
+
+	Describe("guest log messages", func() {
+		It("writes guest log lines with a timestamp and prefix", func() {
+			m := construct.NewMessenger(buf)
+			m.GuestLogLine("Installing Windows features")
+
+			Expect(buf).To(Say(`\d{4}-\d{2}-\d{2}T\S+ \[guest log\] Installing Windows features\n`))
+		})
+
+		It("writes the guest logs warning to the writer", func() {
+			m := construct.NewMessenger(buf)
+			m.GuestLogsNotDownloaded(errors.New("guest is powered off"))
+
+			Expect(buf).To(Say("Warning: could not download provisioning logs from the guest VM: guest is powered off"))
+		})
+	})
 })
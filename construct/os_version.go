@@ -0,0 +1,96 @@
+package construct
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const osVersionFile = "C:\\Windows\\Temp\\stembuild-os-version.txt"
+
+// windowsBuilds maps the build number reported by the guest to the OS line of a stemcell,
+// matching the builds accepted by Validate-OSVersion in the stemcell automation scripts
+var windowsBuilds = map[string]string{
+	"9600":  "2012R2",
+	"17134": "1803",
+	"17763": "2019",
+	"20348": "2022",
+}
+
+// validateOSVersion checks that the guest runs the Windows Server version this stembuild builds stemcells for.
+// A guest version that cannot be read fails the check like a mismatch does, unless -skip-os-check is set.
+// The build number is read with PowerShell rather than Create-VersionFile: that helper only exists in the guest
+// once StemcellAutomation.zip has been uploaded, and it writes the stembuild version to the stemcell_version file,
+// which marks the VM as constructed, instead of reporting the guest OS.
+func (c *VMConstruct) validateOSVersion() error {
+	stembuildOS := c.versionGetter.GetOs()
+	if !isKnownOS(stembuildOS) {
+		c.messenger.OSVersionCheckSkipped(stembuildOS)
+		return nil
+	}
+
+	c.messenger.ValidateOSVersionStarted()
+
+	command := fmt.Sprintf("-NoProfile -Command \"[System.Environment]::OSVersion.Version.Build | Out-File -Encoding ascii -FilePath '%s'\"", osVersionFile)
+	pid, err := c.guestManager.StartProgramInGuest(c.ctx, powershell, command)
+	if err != nil {
+		return c.osVersionUnreadable(fmt.Sprintf("cannot create the OS version file: %s", err), c.messenger.OSVersionFileCreationFailed)
+	}
+
+	exitCode, err := c.guestManager.ExitCodeForProgramInGuest(c.ctx, pid)
+	if err != nil {
+		return c.osVersionUnreadable(fmt.Sprintf("cannot get the exit code of creating the OS version file: %s", err), c.messenger.ExitCodeRetrievalFailed)
+	}
+	if exitCode != 0 {
+		return c.osVersionUnreadable(fmt.Sprintf("creating the OS version file exited with code %d", exitCode), c.messenger.OSVersionFileCreationFailed)
+	}
+
+	fileReader, _, err := c.guestManager.DownloadFileInGuest(c.ctx, osVersionFile)
+	if err != nil {
+		return c.osVersionUnreadable(fmt.Sprintf("cannot download the OS version file: %s", err), c.messenger.DownloadFileFailed)
+	}
+	contents, err := io.ReadAll(fileReader)
+	if err != nil {
+		return c.osVersionUnreadable(fmt.Sprintf("cannot download the OS version file: %s", err), c.messenger.DownloadFileFailed)
+	}
+
+	build := strings.Trim(string(contents), "\ufeff\x00 \r\n\t")
+	if _, err := strconv.Atoi(build); err != nil {
+		return c.osVersionUnreadable(fmt.Sprintf("the OS version file does not contain a build number: %q", build), c.messenger.DownloadFileFailed)
+	}
+	guestOS, ok := windowsBuilds[build]
+	if !ok {
+		guestOS = fmt.Sprintf("build %s", build)
+	}
+
+	if guestOS != stembuildOS {
+		mismatch := fmt.Sprintf("guest OS is Windows Server %s but this stembuild builds %s stemcells", guestOS, stembuildOS)
+		if !c.SkipOSCheck {
+			return fmt.Errorf("%s. Use the stembuild for the guest OS, or rerun with -skip-os-check", mismatch)
+		}
+		c.messenger.OSVersionMismatchIgnored(mismatch)
+		return nil
+	}
+
+	c.messenger.ValidateOSVersionSucceeded(guestOS)
+	return nil
+}
+
+// osVersionUnreadable fails the check when the guest OS version cannot be read, or only warns through warn when -skip-os-check is set
+func (c *VMConstruct) osVersionUnreadable(reason string, warn func(errorMessage string)) error {
+	if !c.SkipOSCheck {
+		return fmt.Errorf("cannot validate the guest OS version: %s. Rerun with -skip-os-check to construct without validating it", reason)
+	}
+	warn(reason)
+	return nil
+}
+
+func isKnownOS(os string) bool {
+	for _, known := range windowsBuilds {
+		if os == known {
+			return true
+		}
+	}
+	return false
+}
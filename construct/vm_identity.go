@@ -0,0 +1,109 @@
+package construct
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const vmIdentityFile = "C:\\Windows\\Temp\\stembuild-vm-identity.txt"
+
+//counterfeiter:generate . GuestNetwork
+type GuestNetwork interface {
+	IPAddresses() ([]string, error)
+}
+
+// HostResolver resolves a host name to its addresses, as *net.Resolver does
+//
+//counterfeiter:generate . HostResolver
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// checkVMIP fails when the addresses VMware Tools reports for the VM at the inventory path do not include VMIP,
+// or any address VMIP resolves to when it is a host name, so that nothing is uploaded to a VM whose commands would run on another machine.
+// Without reported addresses there is nothing to compare, and confirmVMIdentity is left to tell.
+func (c *VMConstruct) checkVMIP() error {
+	c.messenger.CheckVMIPStarted(c.VMIP)
+
+	vmIPs := []string{c.VMIP}
+	if net.ParseIP(c.VMIP) == nil {
+		var err error
+		vmIPs, err = c.Resolver.LookupHost(c.ctx, c.VMIP)
+		if err != nil {
+			return fmt.Errorf("cannot resolve -vm-ip %s: %s", c.VMIP, err)
+		}
+	}
+
+	addresses, err := c.GuestNetwork.IPAddresses()
+	if err != nil {
+		return fmt.Errorf("cannot read the IP addresses of the VM from vCenter: %s", err)
+	}
+	if len(addresses) == 0 {
+		c.messenger.VMIPNotReported(c.VMIP)
+		return nil
+	}
+
+	for _, address := range addresses {
+		for _, vmIP := range vmIPs {
+			if sameIP(address, vmIP) {
+				c.messenger.CheckVMIPSucceeded()
+				return nil
+			}
+		}
+	}
+
+	vmIP := c.VMIP
+	if net.ParseIP(c.VMIP) == nil {
+		vmIP = fmt.Sprintf("%s (%s)", c.VMIP, strings.Join(vmIPs, ", "))
+	}
+	return fmt.Errorf("-vm-ip %s does not belong to the VM at %s, whose IP addresses are %s. Check -vm-ip and -vm-inventory-path. "+
+		"If -vm-ip reaches the VM through NAT, rerun with -skip-vm-ip-check; the VM is still confirmed over WinRM before anything runs on it",
+		vmIP, c.vmInventoryPath, strings.Join(addresses, ", "))
+}
+
+// confirmVMIdentity writes a nonce to the VM at the inventory path through guest operations and reads it back over WinRM,
+// so that nothing is run over WinRM on a machine other than the VM being constructed
+func (c *VMConstruct) confirmVMIdentity() error {
+	c.messenger.ConfirmVMIdentityStarted()
+
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return fmt.Errorf("cannot confirm the identity of the VM: %s", err)
+	}
+	nonce := hex.EncodeToString(b)
+
+	command := fmt.Sprintf("-NoProfile -Command \"Set-Content -Path '%s' -Value '%s'\"", vmIdentityFile, nonce)
+	pid, err := c.guestManager.StartProgramInGuest(c.ctx, powershell, command)
+	if err != nil {
+		return fmt.Errorf("cannot confirm the identity of the VM: %s", err)
+	}
+	exitCode, err := c.guestManager.ExitCodeForProgramInGuest(c.ctx, pid)
+	if err != nil {
+		return fmt.Errorf("cannot confirm the identity of the VM: %s", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("cannot confirm the identity of the VM: writing %s exited with code %d", vmIdentityFile, exitCode)
+	}
+
+	rawCheck := fmt.Sprintf("$found = (Get-Content -Path '%[1]s' -ErrorAction SilentlyContinue) -eq '%[2]s'; "+
+		"Remove-Item -Path '%[1]s' -ErrorAction SilentlyContinue; if ($found) { exit 0 } else { exit 1 }", vmIdentityFile, nonce)
+	checkExitCode, err := c.remoteManager.ExecuteCommand("powershell.exe -EncodedCommand " + EncodePowershellCommand([]byte(rawCheck)))
+	if checkExitCode == 1 {
+		return fmt.Errorf("-vm-ip %s reaches a different machine than the VM at %s. Check -vm-ip and -vm-inventory-path", c.VMIP, c.vmInventoryPath)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot confirm the identity of the VM over WinRM: %s", err)
+	}
+
+	c.messenger.ConfirmVMIdentitySucceeded()
+	return nil
+}
+
+func sameIP(a, b string) bool {
+	return net.ParseIP(a).Equal(net.ParseIP(b))
+}
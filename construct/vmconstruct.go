@@ -5,14 +5,18 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf16"
 
 	"github.com/cloudfoundry/stembuild/poller"
 	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -20,6 +24,7 @@ import (
 //counterfeiter:generate . VersionGetter
 type VersionGetter interface {
 	GetVersion() string
+	GetOs() string
 }
 
 type VMConstruct struct {
@@ -37,8 +42,48 @@ type VMConstruct struct {
 	versionGetter         VersionGetter
 	rebootWaiter          RebootWaiterI
 	scriptExecutor        ScriptExecutorI
-	RebootWaitTime        time.Duration
-	SetupFlags            []string
+	// RebootWaitTime is how long to wait after a reboot starts before checking whether it has finished
+	RebootWaitTime    time.Duration
+	PostRebootTimeout time.Duration
+	// ShutdownPollInterval and ShutdownTimeout bound the wait for sysprep to power off the VM.
+	// The time between checks doubles after every check, up to ShutdownMaxInterval.
+	ShutdownPollInterval time.Duration
+	ShutdownMaxInterval  time.Duration
+	ShutdownTimeout      time.Duration
+	SetupFlags           []string
+	PostRebootFlags      []string
+	SkipOSCheck          bool
+	GuestLogsDir         string
+	LogTail              GuestLogTailer
+	Checkpoints          CheckpointStore
+	Resume               bool
+	Snapshots            SnapshotManager
+	RollbackOnFailure    bool
+	// VMIP is the address of the VM that commands are run on over WinRM. When it is set, construct checks
+	// that it belongs to the VM at the inventory path before uploading anything and before running anything over WinRM.
+	VMIP string
+	// GuestNetwork reads the IP addresses of the VM that VMIP is compared with, unless SkipVMIPCheck is set
+	GuestNetwork  GuestNetwork
+	SkipVMIPCheck bool
+	// Resolver resolves VMIP when it is a host name, default is net.DefaultResolver
+	Resolver HostResolver
+	// IfConstructed is what to do when an earlier construct has left traces on the VM,
+	// IfConstructedRefuse or IfConstructedContinue. The VM is not checked when it is empty.
+	IfConstructed string
+	// RequireCheckpoints fails construct when its progress cannot be recorded, as when -resume or -state-file
+	// was given. Otherwise construct warns and runs without Checkpoints.
+	RequireCheckpoints bool
+	// WindowsUpdater installs Windows updates before Setup.ps1 runs, which is skipped when nil
+	WindowsUpdater  WindowsUpdaterI
+	MaxUpdateRounds int
+	// LGPOPath is the local LGPO.zip uploaded to the VM, ./LGPO.zip by default
+	LGPOPath string
+	// StemcellAutomationPath is the local archive uploaded to the VM, ./StemcellAutomation.zip by default
+	StemcellAutomationPath string
+	// Timings records how long each step takes, for the summary and the build report of package
+	Timings *report.Recorder
+
+	resumedTimings []report.Step
 }
 
 const provisionDir = "C:\\provision\\"
@@ -50,6 +95,26 @@ const stemcellAutomationPostRebootScript = provisionDir + "PostReboot.ps1"
 const powershell = "C:\\Windows\\System32\\WindowsPowerShell\\V1.0\\powershell.exe"
 const boshPsModules = "bosh-psmodules.zip"
 const winRMPsScript = "BOSH.WinRM.psm1"
+const stemcellVersionFile = "C:\\var\\vcap\\bosh\\etc\\stemcell_version"
+const preConstructSnapshot = "stembuild-pre-construct"
+
+// constructMarker is written when the provision directory is created, so that a later construct can tell the VM has been constructed
+const constructMarker = provisionDir + "stembuild-construct.txt"
+
+const (
+	// IfConstructedRefuse fails construct before anything is changed on a VM that has already been constructed
+	IfConstructedRefuse = "refuse"
+	// IfConstructedContinue runs only the steps whose results are missing on a VM that has already been constructed
+	IfConstructedContinue = "continue"
+)
+
+const (
+	DefaultRebootWaitTime       = 60 * time.Second
+	DefaultPostRebootTimeout    = 24 * time.Hour
+	DefaultShutdownPollInterval = time.Minute
+	DefaultShutdownMaxInterval  = 2 * time.Minute
+	DefaultShutdownTimeout      = time.Hour
+)
 
 func NewVMConstruct(
 	ctx context.Context,
@@ -70,33 +135,47 @@ func NewVMConstruct(
 ) *VMConstruct {
 
 	return &VMConstruct{
-		ctx:                   ctx,
-		remoteManager:         remoteManager,
-		Client:                client,
-		guestManager:          guestManager,
-		vmInventoryPath:       vmInventoryPath,
-		vmUsername:            vmUsername,
-		vmPassword:            vmPassword,
-		winRMEnabler:          winRMEnabler,
-		vmConnectionValidator: vmConnectionValidator,
-		messenger:             messenger,
-		poller:                poller,
-		versionGetter:         versionGetter,
-		rebootWaiter:          rebootWaiter,
-		scriptExecutor:        scriptExecutor,
-		RebootWaitTime:        time.Second * 60,
-		SetupFlags:            setupFlags,
+		ctx:                    ctx,
+		remoteManager:          remoteManager,
+		Client:                 client,
+		guestManager:           guestManager,
+		vmInventoryPath:        vmInventoryPath,
+		vmUsername:             vmUsername,
+		vmPassword:             vmPassword,
+		winRMEnabler:           winRMEnabler,
+		vmConnectionValidator:  vmConnectionValidator,
+		messenger:              messenger,
+		poller:                 poller,
+		versionGetter:          versionGetter,
+		rebootWaiter:           rebootWaiter,
+		scriptExecutor:         scriptExecutor,
+		RebootWaitTime:         DefaultRebootWaitTime,
+		PostRebootTimeout:      DefaultPostRebootTimeout,
+		ShutdownPollInterval:   DefaultShutdownPollInterval,
+		ShutdownMaxInterval:    DefaultShutdownMaxInterval,
+		ShutdownTimeout:        DefaultShutdownTimeout,
+		SetupFlags:             setupFlags,
+		LGPOPath:               "./LGPO.zip",
+		StemcellAutomationPath: fmt.Sprintf("./%s", stemcellAutomationName),
+		Timings:                report.NewRecorder(),
+		Resolver:               net.DefaultResolver,
 	}
 }
 
 //counterfeiter:generate . ScriptExecutorI
 type ScriptExecutorI interface {
 	ExecuteSetupScript(stembuildVersion string, setupFlags []string) error
-	ExecutePostRebootScript(timeout time.Duration) error
+	ExecutePostRebootScript(timeout time.Duration, postRebootFlags []string) error
+}
+
+//counterfeiter:generate . WindowsUpdaterI
+type WindowsUpdaterI interface {
+	InstallUpdates() (kbs []string, rebootRequired bool, err error)
 }
 
 //counterfeiter:generate . RebootWaiterI
 type RebootWaiterI interface {
+	BeforeReboot()
 	WaitForRebootFinished() error
 }
 
@@ -116,9 +195,18 @@ type IaasClient interface {
 	IsPoweredOff(vmInventoryPath string) (bool, error)
 }
 
+//counterfeiter:generate . SnapshotManager
+type SnapshotManager interface {
+	CreateSnapshot(name, description string) error
+	// RevertToSnapshot leaves the VM running, as it was before construct
+	RevertToSnapshot(name string) error
+	DeleteSnapshot(name string) error
+	HasSnapshot(name string) (bool, error)
+}
+
 //counterfeiter:generate . WinRMEnabler
 type WinRMEnabler interface {
-	Enable() error
+	Enable(ctx context.Context) error
 }
 
 //counterfeiter:generate . VMConnectionValidator
@@ -152,85 +240,563 @@ type ConstructMessenger interface {
 	WinRMDisconnectedForReboot()
 	LogOutUsersStarted()
 	LogOutUsersSucceeded()
+	ValidateOSVersionStarted()
+	ValidateOSVersionSucceeded(os string)
+	OSVersionCheckSkipped(stembuildOS string)
+	OSVersionMismatchIgnored(mismatch string)
+	OSVersionFileCreationFailed(errorMessage string)
+	ExitCodeRetrievalFailed(errorMessage string)
+	DownloadFileFailed(errorMessage string)
+	DownloadGuestLogsStarted()
+	GuestLogsNotDownloaded(err error)
+	StepSkipped(step string)
+	StepVerificationFailed(step string, reason string)
+	CheckpointNotSaved(step string, err error)
+	CheckpointsDisabled(err error)
+	CreateSnapshotStarted(name string)
+	CreateSnapshotSucceeded()
+	SnapshotReused(name string)
+	AlreadyConstructed(traces []string)
+	CheckVMIPStarted(ip string)
+	CheckVMIPSucceeded()
+	VMIPNotReported(ip string)
+	ConfirmVMIdentityStarted()
+	ConfirmVMIdentitySucceeded()
+	SnapshotRetained(name string)
+	RollbackStarted(name string)
+	RollbackSucceeded()
+	SnapshotNotDeleted(name string, err error)
+	WindowsUpdatesRoundStarted(round, maxRounds int)
+	WindowsUpdatesRoundSucceeded(kbs []string)
+	WindowsUpdatesRoundLimitReached(maxRounds int)
+	WindowsUpdatesFinished(kbs []string)
+	StepTimings(steps []report.Step)
+	TimingsNotSaved(err error)
+	StepStarted(step string)
+	StepSucceeded(step string)
+	StepFailed(step string, err error)
 }
 
-func (c *VMConstruct) PrepareVM() error {
+const (
+	validateOSVersionStep       = "validate-os-version"
+	createProvisionDirStep      = "create-provision-dir"
+	uploadArtifactsStep         = "upload-artifacts"
+	enableWinRMStep             = "enable-winrm"
+	validateVMConnectionStep    = "validate-vm-connection"
+	checkVMIPStep               = "check-vm-ip"
+	confirmVMIdentityStep       = "confirm-vm-identity"
+	installWindowsUpdatesStep   = "install-windows-updates"
+	extractArtifactsStep        = "extract-artifacts"
+	logOutUsersStep             = "log-out-users"
+	executeSetupScriptStep      = "execute-setup-script"
+	rebootStep                  = "reboot"
+	executePostRebootScriptStep = "execute-post-reboot-script"
+	waitForShutdownStep         = "wait-for-shutdown"
+)
+
+type constructStep struct {
+	name string
+	run  func() error
+	// verify reports whether the results of the step, completed in an earlier run, are still present on the VM.
+	// Steps without a verify func leave nothing behind and are re-run every time.
+	verify func() (bool, error)
+}
+
+func (c *VMConstruct) steps() []constructStep {
 	stembuildVersion := c.versionGetter.GetVersion()
 
-	err := c.createProvisionDirectory()
+	var updateSteps []constructStep
+	if c.WindowsUpdater != nil {
+		updateSteps = append(updateSteps, constructStep{
+			name: installWindowsUpdatesStep,
+			run:  c.installWindowsUpdates,
+		})
+	}
+
+	var ipCheckSteps, identitySteps []constructStep
+	if c.VMIP != "" {
+		if c.GuestNetwork != nil && !c.SkipVMIPCheck {
+			ipCheckSteps = append(ipCheckSteps, constructStep{
+				name: checkVMIPStep,
+				run:  c.checkVMIP,
+			})
+		}
+		identitySteps = append(identitySteps, constructStep{
+			name: confirmVMIdentityStep,
+			run:  c.confirmVMIdentity,
+		})
+	}
+
+	steps := []constructStep{
+		{
+			name: validateOSVersionStep,
+			run:  c.validateOSVersion,
+		},
+	}
+	steps = append(steps, ipCheckSteps...)
+	steps = append(steps, []constructStep{
+		{
+			name: createProvisionDirStep,
+			run:  c.createProvisionDirectory,
+			verify: func() (bool, error) {
+				return c.guestPathsExist(provisionDir)
+			},
+		},
+		{
+			name: uploadArtifactsStep,
+			run: func() error {
+				c.messenger.UploadArtifactsStarted()
+				err := c.uploadArtifacts()
+				if err != nil {
+					return err
+				}
+				c.messenger.UploadArtifactsSucceeded()
+				return nil
+			},
+			verify: func() (bool, error) {
+				return c.guestPathsExist(lgpoDest, stemcellAutomationDest)
+			},
+		},
+		{
+			name: enableWinRMStep,
+			run: func() error {
+				c.messenger.EnableWinRMStarted()
+				err := c.winRMEnabler.Enable(c.ctx)
+				if err != nil {
+					return err
+				}
+				c.messenger.EnableWinRMSucceeded()
+				return nil
+			},
+			verify: c.vmIsConnectable,
+		},
+		{
+			name: validateVMConnectionStep,
+			run: func() error {
+				c.messenger.ValidateVMConnectionStarted()
+				err := c.vmConnectionValidator.Validate()
+				if err != nil {
+					return err
+				}
+				c.messenger.ValidateVMConnectionSucceeded()
+				return nil
+			},
+		},
+	}...)
+	steps = append(steps, identitySteps...)
+	steps = append(steps, updateSteps...)
+
+	return append(steps, []constructStep{
+		{
+			name: extractArtifactsStep,
+			run: func() error {
+				c.messenger.ExtractArtifactsStarted()
+				err := c.extractArchive()
+				if err != nil {
+					return err
+				}
+				c.messenger.ExtractArtifactsSucceeded()
+				return nil
+			},
+			verify: func() (bool, error) {
+				return c.guestPathsExist(stemcellAutomationSetupScript, stemcellAutomationPostRebootScript)
+			},
+		},
+		{
+			name: logOutUsersStep,
+			run: func() error {
+				c.messenger.LogOutUsersStarted()
+				err := c.logOutUsers()
+				if err != nil {
+					return err
+				}
+				c.messenger.LogOutUsersSucceeded()
+				return nil
+			},
+		},
+		{
+			name: executeSetupScriptStep,
+			run: func() error {
+				c.messenger.ExecuteSetupScriptStarted()
+				// Setup.ps1 reboots the VM once it has finished
+				c.rebootWaiter.BeforeReboot()
+				err := c.tailingGuestLog(func() error {
+					return c.scriptExecutor.ExecuteSetupScript(stembuildVersion, c.SetupFlags)
+				})
+				if err != nil {
+					return err
+				}
+				c.messenger.ExecuteSetupScriptSucceeded()
+				c.messenger.WinRMDisconnectedForReboot()
+				return nil
+			},
+			verify: func() (bool, error) {
+				// Setup.ps1 writes the stemcell version file as its last provisioning action
+				return c.guestPathsExist(stemcellVersionFile)
+			},
+		},
+		{
+			name: rebootStep,
+			run: func() error {
+				c.messenger.RebootHasStarted()
+				err := c.sleep(c.RebootWaitTime)
+				if err != nil {
+					return err
+				}
+				err = c.rebootWaiter.WaitForRebootFinished()
+				if err != nil {
+					return err
+				}
+				c.messenger.RebootHasFinished()
+				return nil
+			},
+			verify: c.vmIsConnectable,
+		},
+		{
+			name: executePostRebootScriptStep,
+			run: func() error {
+				c.messenger.ExecutePostRebootScriptStarted()
+				err := c.tailingGuestLog(func() error {
+					return c.scriptExecutor.ExecutePostRebootScript(c.PostRebootTimeout, c.PostRebootFlags)
+				})
+				if err != nil {
+					if strings.Contains(err.Error(), "winrm connection event") {
+						c.messenger.ExecutePostRebootWarning(err.Error())
+					} else {
+						return fmt.Errorf("failure in post-reboot script: %s", err)
+					}
+				}
+				c.messenger.ExecutePostRebootScriptSucceeded()
+				return nil
+			},
+			verify: c.vmIsPoweredOff,
+		},
+		{
+			name: waitForShutdownStep,
+			run: func() error {
+				err := c.isPoweredOff()
+				if err != nil {
+					return err
+				}
+				c.messenger.ShutdownCompleted()
+				return nil
+			},
+			verify: c.vmIsPoweredOff,
+		},
+	}...)
+}
+
+func (c *VMConstruct) tailingGuestLog(run func() error) error {
+	if c.LogTail == nil {
+		return run()
+	}
+
+	c.LogTail.Start()
+	defer c.LogTail.Stop()
+	return run()
+}
+
+func (c *VMConstruct) PrepareVM() error {
+	previouslyCompleted, err := c.loadCheckpoints()
 	if err != nil {
 		return err
 	}
-	c.messenger.UploadArtifactsStarted()
-	err = c.uploadArtifacts()
+
+	if !c.Resume {
+		previouslyCompleted, err = c.checkConstructed()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.takeSnapshot(c.Resume || len(previouslyCompleted) > 0)
 	if err != nil {
 		return err
 	}
-	c.messenger.UploadArtifactsSucceeded()
 
-	c.messenger.EnableWinRMStarted()
-	err = c.winRMEnabler.Enable()
+	err = c.runSteps(previouslyCompleted)
 	if err != nil {
-		return err
+		return c.handleFailure(err)
+	}
+
+	c.deleteSnapshot()
+	c.messenger.StepTimings(c.timings())
+	return nil
+}
+
+func (c *VMConstruct) runSteps(previouslyCompleted []string) error {
+	resuming := len(previouslyCompleted) > 0
+	var completed []string
+	// once the automation scripts are extracted, failures leave logs on the guest worth downloading
+	extracted := false
+
+	for _, step := range c.steps() {
+		if c.ctx.Err() != nil {
+			return fmt.Errorf("stopped before step %s: %w", step.name, c.ctx.Err())
+		}
+
+		if resuming {
+			skip, err := c.canSkip(step, previouslyCompleted)
+			if err != nil {
+				return err
+			}
+			if skip {
+				c.messenger.StepSkipped(step.name)
+				completed = append(completed, step.name)
+				extracted = extracted || step.name == extractArtifactsStep
+				continue
+			}
+			if step.verify != nil {
+				resuming = false
+			}
+		}
+
+		c.messenger.StepStarted(step.name)
+		err := c.Timings.Time(step.name, step.run)
+		if err != nil && c.ctx.Err() != nil {
+			// the step failed because construct was aborted, which leaves no time to download the guest logs
+			err = fmt.Errorf("interrupted during step %s: %w", step.name, err)
+			c.messenger.StepFailed(step.name, err)
+			return err
+		}
+		if err != nil {
+			c.messenger.StepFailed(step.name, err)
+			if extracted {
+				return c.downloadGuestLogs(err)
+			}
+			return err
+		}
+
+		c.messenger.StepSucceeded(step.name)
+		completed = append(completed, step.name)
+		c.saveCheckpoints(step.name, completed)
+		extracted = extracted || step.name == extractArtifactsStep
 	}
-	c.messenger.EnableWinRMSucceeded()
 
-	c.messenger.ValidateVMConnectionStarted()
-	err = c.vmConnectionValidator.Validate()
+	return nil
+}
+
+// takeSnapshot records the state of the VM before construct changes anything.
+// A run that continues an earlier one keeps the snapshot taken by that run, since the VM has already been changed since then.
+func (c *VMConstruct) takeSnapshot(continuing bool) error {
+	if c.Snapshots == nil {
+		return nil
+	}
+
+	exists, err := c.Snapshots.HasSnapshot(preConstructSnapshot)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot check VM for an existing snapshot: %s", err)
+	}
+	if exists {
+		if continuing {
+			c.messenger.SnapshotReused(preConstructSnapshot)
+			return nil
+		}
+		return fmt.Errorf("VM already has a snapshot named '%s' from an earlier construct. "+
+			"Rerun with -resume, or revert to the snapshot and delete it before running construct again", preConstructSnapshot)
 	}
-	c.messenger.ValidateVMConnectionSucceeded()
 
-	c.messenger.ExtractArtifactsStarted()
-	err = c.extractArchive()
+	c.messenger.CreateSnapshotStarted(preConstructSnapshot)
+	description := fmt.Sprintf("Taken by stembuild %s before construct", c.versionGetter.GetVersion())
+	err = c.Snapshots.CreateSnapshot(preConstructSnapshot, description)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot snapshot VM before construct: %s", err)
+	}
+	c.messenger.CreateSnapshotSucceeded()
+
+	return nil
+}
+
+func (c *VMConstruct) handleFailure(constructErr error) error {
+	if c.Snapshots == nil {
+		return constructErr
+	}
+
+	if !c.RollbackOnFailure {
+		c.messenger.SnapshotRetained(preConstructSnapshot)
+		return constructErr
 	}
-	c.messenger.ExtractArtifactsSucceeded()
 
-	c.messenger.LogOutUsersStarted()
-	err = c.logOutUsers()
+	c.messenger.RollbackStarted(preConstructSnapshot)
+	err := c.Snapshots.RevertToSnapshot(preConstructSnapshot)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s; rolling back to snapshot '%s' also failed: %s", constructErr, preConstructSnapshot, err)
 	}
-	c.messenger.LogOutUsersSucceeded()
+	c.messenger.RollbackSucceeded()
+
+	// the VM is back in its original state, so nothing recorded for it can be resumed
+	c.saveCheckpoints("rollback", nil)
+	c.deleteSnapshot()
+
+	return constructErr
+}
 
-	c.messenger.ExecuteSetupScriptStarted()
-	err = c.scriptExecutor.ExecuteSetupScript(stembuildVersion, c.SetupFlags)
+func (c *VMConstruct) deleteSnapshot() {
+	if c.Snapshots == nil {
+		return
+	}
+
+	err := c.Snapshots.DeleteSnapshot(preConstructSnapshot)
 	if err != nil {
-		return err
+		c.messenger.SnapshotNotDeleted(preConstructSnapshot, err)
 	}
-	c.messenger.ExecuteSetupScriptSucceeded()
-	c.messenger.WinRMDisconnectedForReboot()
+}
 
-	c.messenger.RebootHasStarted()
-	time.Sleep(c.RebootWaitTime)
-	err = c.rebootWaiter.WaitForRebootFinished()
+// loadCheckpoints returns the steps completed by an earlier run when resuming.
+// Otherwise it discards any earlier progress so that a later resume cannot rely on it.
+func (c *VMConstruct) loadCheckpoints() ([]string, error) {
+	if c.Checkpoints == nil {
+		return nil, nil
+	}
+
+	if !c.Resume {
+		err := c.Checkpoints.Save(c.vmInventoryPath, nil)
+		if err != nil && c.RequireCheckpoints {
+			return nil, err
+		}
+		if err != nil {
+			c.messenger.CheckpointsDisabled(err)
+			c.Checkpoints = nil
+		}
+		return nil, nil
+	}
+
+	completed, err := c.Checkpoints.Load(c.vmInventoryPath)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot resume construct: %s", err)
+	}
+
+	// without the earlier timings the summary only covers the steps run again, which does not stop the resume
+	c.resumedTimings, _ = c.Checkpoints.LoadTimings(c.vmInventoryPath)
+	return completed, nil
+}
+
+// timings returns how long the steps took, including the steps completed by the run being resumed
+func (c *VMConstruct) timings() []report.Step {
+	return report.Merge(c.resumedTimings, c.Timings.Steps())
+}
+
+func (c *VMConstruct) saveCheckpoints(step string, completed []string) {
+	if c.Checkpoints == nil {
+		return
+	}
+
+	err := c.Checkpoints.Save(c.vmInventoryPath, completed)
+	if err != nil {
+		c.messenger.CheckpointNotSaved(step, err)
+		return
+	}
+	if len(completed) == 0 {
+		return
+	}
+
+	// the timings are kept with the checkpoints, for the build report of package and for a later resume
+	err = c.Checkpoints.SaveTimings(c.vmInventoryPath, c.timings())
+	if err != nil {
+		c.messenger.TimingsNotSaved(err)
+	}
+}
+
+// checkConstructed looks for the traces of an earlier construct before the VM is changed.
+// Unless IfConstructed refuses to construct the VM again, it returns every step as completed,
+// so that only the steps whose results are missing on the VM are run.
+func (c *VMConstruct) checkConstructed() ([]string, error) {
+	if c.IfConstructed == "" {
+		return nil, nil
 	}
-	c.messenger.RebootHasFinished()
 
-	c.messenger.ExecutePostRebootScriptStarted()
-	err = c.scriptExecutor.ExecutePostRebootScript(24 * time.Hour)
+	poweredOff, err := c.Client.IsPoweredOff(c.vmInventoryPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "winrm connection event") {
-			c.messenger.ExecutePostRebootWarning(err.Error())
-		} else {
-			return fmt.Errorf("failure in post-reboot script: %s", err)
+		return nil, fmt.Errorf("cannot check whether the VM has already been constructed: %s", err)
+	}
+	if poweredOff {
+		return nil, errors.New("VM is powered off. Either an earlier construct has already syspreped it and it is ready for `stembuild package`, " +
+			"or it must be powered on before running construct")
+	}
+
+	var traces []string
+	for _, path := range []string{constructMarker, stemcellVersionFile} {
+		exists, err := c.guestPathsExist(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check whether the VM has already been constructed: %s", err)
+		}
+		if exists {
+			traces = append(traces, path)
 		}
 	}
+	if len(traces) == 0 {
+		return nil, nil
+	}
+
+	if c.IfConstructed != IfConstructedContinue {
+		return nil, fmt.Errorf("VM has already been constructed, found %s. "+
+			"Rerun with -if-constructed continue to run only the missing steps, or revert the VM before running construct again", strings.Join(traces, " and "))
+	}
 
-	c.messenger.ExecutePostRebootScriptSucceeded()
+	c.messenger.AlreadyConstructed(traces)
+	var all []string
+	for _, step := range c.steps() {
+		all = append(all, step.name)
+	}
+	return all, nil
+}
 
-	err = c.isPoweredOff(time.Minute)
+func (c *VMConstruct) canSkip(step constructStep, previouslyCompleted []string) (bool, error) {
+	stepCompleted := false
+	for _, name := range previouslyCompleted {
+		if name == step.name {
+			stepCompleted = true
+			break
+		}
+	}
+
+	if !stepCompleted || step.verify == nil {
+		return false, nil
+	}
+
+	present, err := step.verify()
 	if err != nil {
-		return err
+		c.messenger.StepVerificationFailed(step.name, err.Error())
+		return false, nil
+	}
+	if !present {
+		c.messenger.StepVerificationFailed(step.name, "its results are no longer present on the VM")
+		return false, nil
 	}
-	c.messenger.ShutdownCompleted()
 
-	return nil
+	return true, nil
+}
+
+func (c *VMConstruct) guestPathsExist(paths ...string) (bool, error) {
+	var conditions []string
+	for _, p := range paths {
+		conditions = append(conditions, fmt.Sprintf("(Test-Path '%s')", p))
+	}
+	command := fmt.Sprintf("-NoProfile -Command \"if (%s) { exit 0 } else { exit 1 }\"", strings.Join(conditions, " -and "))
+
+	pid, err := c.guestManager.StartProgramInGuest(c.ctx, powershell, command)
+	if err != nil {
+		return false, err
+	}
+
+	exitCode, err := c.guestManager.ExitCodeForProgramInGuest(c.ctx, pid)
+	if err != nil {
+		return false, err
+	}
+
+	return exitCode == 0, nil
+}
+
+func (c *VMConstruct) vmIsConnectable() (bool, error) {
+	err := c.vmConnectionValidator.Validate()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *VMConstruct) vmIsPoweredOff() (bool, error) {
+	return c.Client.IsPoweredOff(c.vmInventoryPath)
 }
 
 func (c *VMConstruct) createProvisionDirectory() error {
@@ -239,20 +805,41 @@ func (c *VMConstruct) createProvisionDirectory() error {
 	if err != nil {
 		return err
 	}
+	err = c.writeConstructMarker()
+	if err != nil {
+		return err
+	}
 	c.messenger.CreateProvisionDirSucceeded()
 	return nil
 }
 
+func (c *VMConstruct) writeConstructMarker() error {
+	command := fmt.Sprintf("-NoProfile -Command \"Set-Content -Path '%s' -Value 'constructed by stembuild %s'\"", constructMarker, c.versionGetter.GetVersion())
+	pid, err := c.guestManager.StartProgramInGuest(c.ctx, powershell, command)
+	if err != nil {
+		return fmt.Errorf("cannot write construct marker: %s", err)
+	}
+
+	exitCode, err := c.guestManager.ExitCodeForProgramInGuest(c.ctx, pid)
+	if err != nil {
+		return fmt.Errorf("cannot write construct marker: %s", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("cannot write construct marker: exited with code %d", exitCode)
+	}
+	return nil
+}
+
 func (c *VMConstruct) uploadArtifacts() error {
 	c.messenger.UploadFileStarted("LGPO")
-	err := c.Client.UploadArtifact(c.vmInventoryPath, "./LGPO.zip", lgpoDest, c.vmUsername, c.vmPassword)
+	err := c.Client.UploadArtifact(c.vmInventoryPath, c.LGPOPath, lgpoDest, c.vmUsername, c.vmPassword)
 	if err != nil {
 		return err
 	}
 	c.messenger.UploadFileSucceeded()
 
 	c.messenger.UploadFileStarted("stemcell preparation artifacts")
-	err = c.Client.UploadArtifact(c.vmInventoryPath, fmt.Sprintf("./%s", stemcellAutomationName), stemcellAutomationDest, c.vmUsername, c.vmPassword)
+	err = c.Client.UploadArtifact(c.vmInventoryPath, c.StemcellAutomationPath, stemcellAutomationDest, c.vmUsername, c.vmPassword)
 	if err != nil {
 		return err
 	}
@@ -303,8 +890,14 @@ func (e *ScriptExecutor) ExecuteSetupScript(stembuildVersion string, setupFlags
 	return err
 }
 
-func (e *ScriptExecutor) ExecutePostRebootScript(timeout time.Duration) error {
-	_, err := e.remoteManager.ExecuteCommandWithTimeout("powershell.exe "+stemcellAutomationPostRebootScript, timeout)
+func (e *ScriptExecutor) ExecutePostRebootScript(timeout time.Duration, postRebootFlags []string) error {
+	var automationPostRebootScriptArgs []string
+	for _, arg := range postRebootFlags {
+		automationPostRebootScriptArgs = append(automationPostRebootScriptArgs, postRebootScriptArg(arg))
+	}
+
+	powershellCommand := strings.TrimSpace(fmt.Sprintf("powershell.exe %s %s", stemcellAutomationPostRebootScript, strings.Join(automationPostRebootScriptArgs, " ")))
+	_, err := e.remoteManager.ExecuteCommandWithTimeout(powershellCommand, timeout)
 
 	if err != nil && strings.Contains(err.Error(), remotemanager.PowershellExecutionErrorMessage) {
 		return err
@@ -318,18 +911,51 @@ func (e *ScriptExecutor) ExecutePostRebootScript(timeout time.Duration) error {
 
 }
 
-func (c *VMConstruct) isPoweredOff(duration time.Duration) error {
-	err := c.poller.Poll(duration, func() (bool, error) {
-		isPoweredOff, err := c.Client.IsPoweredOff(c.vmInventoryPath)
+// postRebootScriptArg turns a 'Name value' post-reboot arg into a PowerShell parameter.
+// The value is passed as a single-quoted string, so that a value with spaces stays one argument;
+// quotes the user put around the value are dropped first.
+func postRebootScriptArg(arg string) string {
+	arg = strings.TrimSpace(arg)
+	nameEnd := strings.IndexFunc(arg, unicode.IsSpace)
+	if nameEnd == -1 {
+		return "-" + strings.TrimPrefix(arg, "-")
+	}
+	name := strings.TrimPrefix(arg[:nameEnd], "-")
+	value := strings.TrimSpace(arg[nameEnd:])
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return fmt.Sprintf("-%s '%s'", name, strings.ReplaceAll(value, "'", "''"))
+}
 
-		if err != nil {
-			return false, err
-		}
+// sleep waits for d, or returns the error of the context of construct when it is done first
+func (c *VMConstruct) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-		c.messenger.WaitingForShutdown()
+	select {
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
 
-		return isPoweredOff, nil
+func (c *VMConstruct) isPoweredOff() error {
+	err := c.poller.PollUntil(c.ctx, poller.Options{
+		Interval:    c.ShutdownPollInterval,
+		MaxInterval: c.ShutdownMaxInterval,
+		Jitter:      poller.DefaultJitter,
+		Timeout:     c.ShutdownTimeout,
+		Progress: func(poller.Progress) {
+			c.messenger.WaitingForShutdown()
+		},
+	}, func() (bool, error) {
+		return c.Client.IsPoweredOff(c.vmInventoryPath)
 	})
+	if errors.Is(err, poller.ErrTimedOut) {
+		return fmt.Errorf("the VM did not power off within %s, increase -shutdown-timeout if sysprep needs longer", c.ShutdownTimeout)
+	}
 	return err
 }
 
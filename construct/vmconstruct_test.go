@@ -2,15 +2,26 @@ package construct_test
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing/iotest"
 	"time"
+	"unicode/utf16"
 
 	"github.com/cloudfoundry/stembuild/construct"
 	"github.com/cloudfoundry/stembuild/construct/constructfakes"
+	"github.com/cloudfoundry/stembuild/poller"
 	"github.com/cloudfoundry/stembuild/poller/pollerfakes"
 	"github.com/cloudfoundry/stembuild/remotemanager"
 	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
+	"github.com/cloudfoundry/stembuild/report"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -64,6 +75,7 @@ var _ = Describe("construct_helpers", func() {
 			fakeSetupFlags,
 		)
 		vmConstruct.RebootWaitTime = 0
+		vmConstruct.GuestLogsDir = GinkgoT().TempDir()
 
 		fakeGuestManager.StartProgramInGuestReturnsOnCall(0, 0, nil)
 		fakeGuestManager.ExitCodeForProgramInGuestReturnsOnCall(0, 0, nil)
@@ -95,7 +107,7 @@ var _ = Describe("construct_helpers", func() {
 		It("executes post-reboot script with correct arguments", func() {
 			e := construct.NewScriptExecutor(fakeRemoteManager)
 			superLongTimeout := 24 * time.Hour
-			err := e.ExecutePostRebootScript(superLongTimeout)
+			err := e.ExecutePostRebootScript(superLongTimeout, nil)
 			executeCommandCallArg, timeout := fakeRemoteManager.ExecuteCommandWithTimeoutArgsForCall(0)
 
 			Expect(err).NotTo(HaveOccurred())
@@ -104,6 +116,24 @@ var _ = Describe("construct_helpers", func() {
 			Expect(timeout).To(Equal(superLongTimeout))
 		})
 
+		It("passes post-reboot flags to the post-reboot script", func() {
+			e := construct.NewScriptExecutor(fakeRemoteManager)
+			err := e.ExecutePostRebootScript(time.Hour, []string{"Organization SomeOrg", "SkipRandomPassword"})
+			executeCommandCallArg, _ := fakeRemoteManager.ExecuteCommandWithTimeoutArgsForCall(0)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executeCommandCallArg).To(HaveSuffix("PostReboot.ps1 -Organization 'SomeOrg' -SkipRandomPassword"))
+		})
+
+		It("quotes post-reboot values so that values with spaces stay one argument", func() {
+			e := construct.NewScriptExecutor(fakeRemoteManager)
+			err := e.ExecutePostRebootScript(time.Hour, []string{"-Owner Some Owner", "Organization 'Some Org'", `Owner "O'Brien"`})
+			executeCommandCallArg, _ := fakeRemoteManager.ExecuteCommandWithTimeoutArgsForCall(0)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executeCommandCallArg).To(HaveSuffix(`PostReboot.ps1 -Owner 'Some Owner' -Organization 'Some Org' -Owner 'O''Brien'`))
+		})
+
 		It("returns an error when there is a powershell script execution error", func() {
 			e := construct.NewScriptExecutor(fakeRemoteManager)
 			superLongTimeout := 24 * time.Hour
@@ -111,7 +141,7 @@ var _ = Describe("construct_helpers", func() {
 			powershellErr := fmt.Errorf("%s: %s", powershellErrorPrefix, "a command failed to run")
 			fakeRemoteManager.ExecuteCommandWithTimeoutReturns(2, powershellErr)
 
-			err := e.ExecutePostRebootScript(superLongTimeout)
+			err := e.ExecutePostRebootScript(superLongTimeout, nil)
 
 			Expect(err).To(MatchError(powershellErr))
 		})
@@ -123,7 +153,7 @@ var _ = Describe("construct_helpers", func() {
 
 			fakeRemoteManager.ExecuteCommandWithTimeoutReturns(1, winRMError)
 
-			err := e.ExecutePostRebootScript(superLongTimeout)
+			err := e.ExecutePostRebootScript(superLongTimeout, nil)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("winrm connection event"))
@@ -132,6 +162,210 @@ var _ = Describe("construct_helpers", func() {
 	})
 
 	Describe("PrepareVM", func() {
+		Describe("validating the guest OS version", func() {
+			BeforeEach(func() {
+				fakeVersionGetter.GetOsReturns("2019")
+				fakeGuestManager.DownloadFileInGuestReturns(strings.NewReader("17763\r\n"), 7, nil)
+			})
+
+			It("checks the guest OS build before anything is uploaded", func() {
+				fakeGuestManager.StartProgramInGuestStub = func(_ context.Context, _ string, args string) (int64, error) {
+					if strings.Contains(args, "OSVersion") {
+						Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+						Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(0))
+					}
+					return 0, nil
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				_, command, args := fakeGuestManager.StartProgramInGuestArgsForCall(0)
+				Expect(command).To(ContainSubstring("powershell.exe"))
+				Expect(args).To(ContainSubstring("OSVersion.Version.Build"))
+
+				_, path := fakeGuestManager.DownloadFileInGuestArgsForCall(0)
+				Expect(path).To(Equal("C:\\Windows\\Temp\\stembuild-os-version.txt"))
+
+				Expect(fakeMessenger.ValidateOSVersionSucceededCallCount()).To(Equal(1))
+				Expect(fakeMessenger.ValidateOSVersionSucceededArgsForCall(0)).To(Equal("2019"))
+			})
+
+			It("fails before uploading when the guest runs a different OS", func() {
+				fakeGuestManager.DownloadFileInGuestReturns(strings.NewReader("20348"), 5, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("guest OS is Windows Server 2022 but this stembuild builds 2019 stemcells")))
+
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(0))
+			})
+
+			It("reports the build number of an unsupported guest OS", func() {
+				fakeGuestManager.DownloadFileInGuestReturns(strings.NewReader("14393"), 5, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("guest OS is Windows Server build 14393")))
+			})
+
+			It("warns and continues on a mismatch when the OS check is skipped", func() {
+				vmConstruct.SkipOSCheck = true
+				fakeGuestManager.DownloadFileInGuestReturns(strings.NewReader("20348"), 5, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.OSVersionMismatchIgnoredCallCount()).To(Equal(1))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+			})
+
+			Context("when the guest OS version cannot be read", func() {
+				It("fails when the OS version file cannot be created", func() {
+					fakeGuestManager.StartProgramInGuestReturnsOnCall(0, 0, errors.New("guest operations unavailable"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("cannot validate the guest OS version: cannot create the OS version file: guest operations unavailable")))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("fails when the exit code of creating the OS version file cannot be retrieved", func() {
+					fakeGuestManager.ExitCodeForProgramInGuestReturnsOnCall(0, 0, errors.New("process not found"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("cannot get the exit code of creating the OS version file: process not found")))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("fails when creating the OS version file exits with an error", func() {
+					fakeGuestManager.ExitCodeForProgramInGuestReturnsOnCall(0, 1, nil)
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("creating the OS version file exited with code 1")))
+					Expect(fakeGuestManager.DownloadFileInGuestCallCount()).To(Equal(0))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("fails when the OS version file cannot be downloaded", func() {
+					fakeGuestManager.DownloadFileInGuestReturns(nil, 0, errors.New("file not found"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("cannot download the OS version file: file not found")))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("fails when the OS version file cannot be read", func() {
+					fakeGuestManager.DownloadFileInGuestReturns(iotest.ErrReader(errors.New("connection reset")), 5, nil)
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("cannot download the OS version file: connection reset")))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("fails when the OS version file does not contain a build number", func() {
+					fakeGuestManager.DownloadFileInGuestReturns(strings.NewReader("Out-File : Access is denied."), 28, nil)
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring(`the OS version file does not contain a build number: "Out-File : Access is denied."`)))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("warns and continues when the OS check is skipped", func() {
+					vmConstruct.SkipOSCheck = true
+					fakeGuestManager.DownloadFileInGuestReturns(nil, 0, errors.New("file not found"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeMessenger.DownloadFileFailedCallCount()).To(Equal(1))
+					Expect(fakeMessenger.DownloadFileFailedArgsForCall(0)).To(Equal("cannot download the OS version file: file not found"))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+				})
+			})
+
+			It("skips the check when stembuild does not target a known OS", func() {
+				fakeVersionGetter.GetOsReturns("dev")
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.OSVersionCheckSkippedCallCount()).To(Equal(1))
+				for i := 0; i < fakeGuestManager.StartProgramInGuestCallCount(); i++ {
+					_, _, args := fakeGuestManager.StartProgramInGuestArgsForCall(i)
+					Expect(args).NotTo(ContainSubstring("OSVersion"))
+				}
+			})
+		})
+
+		Describe("reporting steps", func() {
+			It("reports the start and success of every step", func() {
+				err := vmConstruct.PrepareVM()
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeMessenger.StepStartedCallCount()).To(Equal(fakeMessenger.StepSucceededCallCount()))
+				Expect(fakeMessenger.StepStartedArgsForCall(0)).To(Equal("validate-os-version"))
+				Expect(fakeMessenger.StepSucceededArgsForCall(0)).To(Equal("validate-os-version"))
+				Expect(fakeMessenger.StepFailedCallCount()).To(Equal(0))
+			})
+
+			It("reports the step that failed", func() {
+				fakeVcenterClient.MakeDirectoryReturns(errors.New("failed to create dir"))
+
+				err := vmConstruct.PrepareVM()
+
+				Expect(err).To(HaveOccurred())
+				Expect(fakeMessenger.StepFailedCallCount()).To(Equal(1))
+				step, stepErr := fakeMessenger.StepFailedArgsForCall(0)
+				Expect(step).To(Equal("create-provision-dir"))
+				Expect(stepErr).To(MatchError("failed to create dir"))
+			})
+
+			It("reports the step that was interrupted when construct is aborted", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				vmConstruct = construct.NewVMConstruct(ctx, fakeRemoteManager, "fakeUser", "fakePass", "fakeVmPath", fakeVcenterClient,
+					fakeGuestManager, fakeWinRMEnabler, fakeVMConnectionValidator, fakeMessenger, fakePoller, fakeVersionGetter,
+					fakeRebootWaiter, fakeScriptExecutor, fakeSetupFlags)
+				vmConstruct.GuestLogsDir = GinkgoT().TempDir()
+				fakeScriptExecutor.ExecuteSetupScriptCalls(func(string, []string) error {
+					cancel()
+					return errors.New("error executing 'Setup.ps1': context canceled")
+				})
+
+				err := vmConstruct.PrepareVM()
+
+				Expect(err).To(MatchError("interrupted during step execute-setup-script: error executing 'Setup.ps1': context canceled"))
+				step, _ := fakeMessenger.StepFailedArgsForCall(0)
+				Expect(step).To(Equal("execute-setup-script"))
+				Expect(fakeMessenger.DownloadGuestLogsStartedCallCount()).To(Equal(0))
+				Expect(fakeRebootWaiter.WaitForRebootFinishedCallCount()).To(Equal(0))
+			})
+
+			It("runs no step once construct is aborted", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				vmConstruct = construct.NewVMConstruct(ctx, fakeRemoteManager, "fakeUser", "fakePass", "fakeVmPath", fakeVcenterClient,
+					fakeGuestManager, fakeWinRMEnabler, fakeVMConnectionValidator, fakeMessenger, fakePoller, fakeVersionGetter,
+					fakeRebootWaiter, fakeScriptExecutor, fakeSetupFlags)
+
+				err := vmConstruct.PrepareVM()
+
+				Expect(err).To(MatchError(context.Canceled))
+				Expect(err).To(MatchError(ContainSubstring("stopped before step validate-os-version")))
+				Expect(fakeMessenger.StepStartedCallCount()).To(Equal(0))
+			})
+
+			It("summarizes how long every step took", func() {
+				err := vmConstruct.PrepareVM()
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeMessenger.StepTimingsCallCount()).To(Equal(1))
+				steps := fakeMessenger.StepTimingsArgsForCall(0)
+				Expect(steps).To(HaveLen(11))
+				Expect(steps[0].Name).To(Equal("validate-os-version"))
+				Expect(steps[10].Name).To(Equal("wait-for-shutdown"))
+				Expect(steps[0].End).NotTo(BeTemporally("<", steps[0].Start))
+			})
+		})
+
 		Describe("can create provision directory", func() {
 			It("creates it successfully", func() {
 				err := vmConstruct.PrepareVM()
@@ -154,6 +388,44 @@ var _ = Describe("construct_helpers", func() {
 				Expect(fakeMessenger.CreateProvisionDirStartedCallCount()).To(Equal(1))
 				Expect(fakeMessenger.CreateProvisionDirSucceededCallCount()).To(Equal(0))
 			})
+
+			It("leaves a construct marker in the provision directory", func() {
+				fakeVersionGetter.GetVersionReturns("2019.1")
+				fakeGuestManager.StartProgramInGuestStub = func(_ context.Context, _ string, args string) (int64, error) {
+					if strings.Contains(args, "Set-Content") {
+						Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+					}
+					return 0, nil
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).ToNot(HaveOccurred())
+
+				var markerArgs []string
+				for i := 0; i < fakeGuestManager.StartProgramInGuestCallCount(); i++ {
+					_, _, args := fakeGuestManager.StartProgramInGuestArgsForCall(i)
+					if strings.Contains(args, "Set-Content") {
+						markerArgs = append(markerArgs, args)
+					}
+				}
+				Expect(markerArgs).To(HaveLen(1))
+				Expect(markerArgs[0]).To(ContainSubstring("C:\\provision\\stembuild-construct.txt"))
+				Expect(markerArgs[0]).To(ContainSubstring("constructed by stembuild 2019.1"))
+			})
+
+			It("fails when the construct marker cannot be written", func() {
+				fakeGuestManager.StartProgramInGuestStub = func(_ context.Context, _ string, args string) (int64, error) {
+					if strings.Contains(args, "Set-Content") {
+						return 0, errors.New("guest operations are unavailable")
+					}
+					return 0, nil
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("cannot write construct marker: guest operations are unavailable"))
+				Expect(fakeMessenger.CreateProvisionDirSucceededCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(0))
+			})
 		})
 
 		Describe("enable WinRM", func() {
@@ -182,7 +454,7 @@ var _ = Describe("construct_helpers", func() {
 			It("checks for WinRM connectivity after WinRM enabled", func() {
 				var calls []string
 
-				fakeWinRMEnabler.EnableCalls(func() error {
+				fakeWinRMEnabler.EnableCalls(func(context.Context) error {
 					calls = append(calls, "enableWinRMCall")
 					return nil
 				})
@@ -234,6 +506,25 @@ var _ = Describe("construct_helpers", func() {
 					Expect(fakeMessenger.UploadFileSucceededCallCount()).To(Equal(2))
 				})
 
+				It("uploads LGPO.zip from a custom path", func() {
+					vmConstruct.LGPOPath = "/cache/LGPO-abc.zip"
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).ToNot(HaveOccurred())
+					_, artifact, dest, _, _ := fakeVcenterClient.UploadArtifactArgsForCall(0)
+					Expect(artifact).To(Equal("/cache/LGPO-abc.zip"))
+					Expect(dest).To(Equal("C:\\provision\\LGPO.zip"))
+				})
+
+				It("uploads a custom stemcell automation archive", func() {
+					vmConstruct.StemcellAutomationPath = "/custom/StemcellAutomation.zip"
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).ToNot(HaveOccurred())
+					_, artifact, dest, _, _ := fakeVcenterClient.UploadArtifactArgsForCall(1)
+					Expect(artifact).To(Equal("/custom/StemcellAutomation.zip"))
+					Expect(dest).To(Equal("C:\\provision\\StemcellAutomation.zip"))
+				})
 			})
 
 			Context("Fails to upload one or more artifacts", func() {
@@ -342,7 +633,7 @@ var _ = Describe("construct_helpers", func() {
 
 				err := vmConstruct.PrepareVM()
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("failed to execute setup script"))
+				Expect(err.Error()).To(HavePrefix("failed to execute setup script"))
 
 				Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(1))
 				Expect(fakeMessenger.ExecuteSetupScriptStartedCallCount()).To(Equal(1))
@@ -367,6 +658,91 @@ var _ = Describe("construct_helpers", func() {
 			})
 
 		})
+		Describe("installing Windows updates", func() {
+			var fakeWindowsUpdater *constructfakes.FakeWindowsUpdaterI
+
+			BeforeEach(func() {
+				fakeWindowsUpdater = &constructfakes.FakeWindowsUpdaterI{}
+				vmConstruct.WindowsUpdater = fakeWindowsUpdater
+				vmConstruct.MaxUpdateRounds = 3
+			})
+
+			It("does not install updates unless asked to", func() {
+				vmConstruct.WindowsUpdater = nil
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeMessenger.WindowsUpdatesRoundStartedCallCount()).To(Equal(0))
+			})
+
+			It("installs updates after validating the connection and before the setup script", func() {
+				var calls []string
+				fakeVMConnectionValidator.ValidateCalls(func() error {
+					calls = append(calls, "validateVMConnCall")
+					return nil
+				})
+				fakeWindowsUpdater.InstallUpdatesCalls(func() ([]string, bool, error) {
+					calls = append(calls, "installUpdatesCall")
+					return nil, false, nil
+				})
+				fakeScriptExecutor.ExecuteSetupScriptCalls(func(string, []string) error {
+					calls = append(calls, "executeSetupScriptCall")
+					return nil
+				})
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(Equal([]string{"validateVMConnCall", "installUpdatesCall", "executeSetupScriptCall"}))
+			})
+
+			It("reboots between rounds until no updates remain and reports the installed KBs", func() {
+				fakeWindowsUpdater.InstallUpdatesReturnsOnCall(0, []string{"KB1", "KB2"}, true, nil)
+				fakeWindowsUpdater.InstallUpdatesReturnsOnCall(1, []string{"KB3"}, false, nil)
+				fakeWindowsUpdater.InstallUpdatesReturnsOnCall(2, nil, false, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeWindowsUpdater.InstallUpdatesCallCount()).To(Equal(3))
+				Expect(fakeRemoteManager.ExecuteCommandArgsForCall(0)).To(HavePrefix("shutdown /r"))
+				// once for the updates, once after the setup script
+				Expect(fakeRebootWaiter.BeforeRebootCallCount()).To(Equal(2))
+				Expect(fakeRebootWaiter.WaitForRebootFinishedCallCount()).To(Equal(2))
+				Expect(fakeMessenger.WindowsUpdatesRoundSucceededArgsForCall(0)).To(Equal([]string{"KB1", "KB2"}))
+				Expect(fakeMessenger.WindowsUpdatesFinishedArgsForCall(0)).To(Equal([]string{"KB1", "KB2", "KB3"}))
+				Expect(fakeMessenger.WindowsUpdatesRoundLimitReachedCallCount()).To(Equal(0))
+			})
+
+			It("stops after the maximum number of rounds", func() {
+				fakeWindowsUpdater.InstallUpdatesReturns([]string{"KB1"}, true, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeWindowsUpdater.InstallUpdatesCallCount()).To(Equal(3))
+				Expect(fakeMessenger.WindowsUpdatesRoundLimitReachedArgsForCall(0)).To(Equal(3))
+				Expect(fakeMessenger.WindowsUpdatesFinishedArgsForCall(0)).To(Equal([]string{"KB1", "KB1", "KB1"}))
+				Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(1))
+			})
+
+			It("fails when updates cannot be installed", func() {
+				fakeWindowsUpdater.InstallUpdatesReturns(nil, false, errors.New("0x80240022"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("failed to install Windows updates: 0x80240022"))
+				Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(0))
+			})
+
+			It("fails when the VM does not come back from the reboot", func() {
+				fakeWindowsUpdater.InstallUpdatesReturns([]string{"KB1"}, true, nil)
+				fakeRebootWaiter.WaitForRebootFinishedReturns(errors.New("polling is hard"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("polling is hard"))
+				Expect(fakeWindowsUpdater.InstallUpdatesCallCount()).To(Equal(1))
+			})
+		})
+
 		Describe("can check if vm is rebooting", func() {
 			It("waits for reboot finished after the setup script has been executed", func() {
 				var calls []string
@@ -386,17 +762,28 @@ var _ = Describe("construct_helpers", func() {
 
 				Expect(calls[0]).To(Equal("executeSetupScriptCalls"))
 				Expect(calls[1]).To(Equal("waitForRebootFinishedCall"))
+				Expect(fakeRebootWaiter.BeforeRebootCallCount()).To(Equal(1))
 
 				Expect(fakeMessenger.RebootHasStartedCallCount()).To(Equal(1))
 				Expect(fakeMessenger.RebootHasFinishedCallCount()).To(Equal(1))
 			})
 
+			It("lets the reboot waiter see the VM before the setup script reboots it", func() {
+				fakeScriptExecutor.ExecuteSetupScriptCalls(func(version string, setupFlags []string) error {
+					Expect(fakeRebootWaiter.BeforeRebootCallCount()).To(Equal(1))
+					return nil
+				})
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
 			It("returns failure when it cannot determine if VM is rebooting", func() {
 				fakeRebootWaiter.WaitForRebootFinishedReturnsOnCall(0, errors.New("polling is hard"))
 
 				err := vmConstruct.PrepareVM()
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("polling is hard"))
+				Expect(err.Error()).To(HavePrefix("polling is hard"))
 
 				Expect(fakeMessenger.RebootHasStartedCallCount()).To(Equal(1))
 				Expect(fakeMessenger.RebootHasFinishedCallCount()).To(Equal(0))
@@ -413,7 +800,7 @@ var _ = Describe("construct_helpers", func() {
 					return nil
 				})
 
-				fakeScriptExecutor.ExecutePostRebootScriptCalls(func(duration time.Duration) error {
+				fakeScriptExecutor.ExecutePostRebootScriptCalls(func(duration time.Duration, postRebootFlags []string) error {
 					calls = append(calls, "executePostRebootScriptCalls")
 					return nil
 				})
@@ -454,6 +841,16 @@ var _ = Describe("construct_helpers", func() {
 				Expect(fakeMessenger.ExecutePostRebootScriptSucceededCallCount()).To(Equal(1))
 			})
 
+			It("passes the post-reboot flags to the post-reboot script", func() {
+				vmConstruct.PostRebootFlags = []string{"Owner SomeOwner"}
+
+				err := vmConstruct.PrepareVM()
+
+				Expect(err).NotTo(HaveOccurred())
+				_, postRebootFlags := fakeScriptExecutor.ExecutePostRebootScriptArgsForCall(0)
+				Expect(postRebootFlags).To(Equal([]string{"Owner SomeOwner"}))
+			})
+
 			It("returns error if running post-reboot command fails", func() {
 				postRebootError := errors.New("failed to execute command")
 				fakeScriptExecutor.ExecutePostRebootScriptReturnsOnCall(0, postRebootError)
@@ -484,8 +881,8 @@ var _ = Describe("construct_helpers", func() {
 		})
 
 		Describe("can check that the VM is powered off", func() {
-			It("runs every minute and returns successfully if polling succeeds", func() {
-				fakePoller.PollReturns(nil)
+			It("polls with backoff from every minute and returns successfully if polling succeeds", func() {
+				fakePoller.PollUntilReturns(nil)
 
 				fakeVcenterClient.IsPoweredOffReturnsOnCall(0, false, nil)
 				fakeVcenterClient.IsPoweredOffReturnsOnCall(1, true, nil)
@@ -495,41 +892,832 @@ var _ = Describe("construct_helpers", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(fakeMessenger.ShutdownCompletedCallCount()).To(Equal(1))
 
-				Expect(fakePoller.PollCallCount()).To(Equal(1))
-				pollDuration, pollFunc := fakePoller.PollArgsForCall(0)
+				Expect(fakePoller.PollUntilCallCount()).To(Equal(1))
+				_, opts, pollFunc := fakePoller.PollUntilArgsForCall(0)
 
-				Expect(pollDuration).To(Equal(1 * time.Minute))
+				Expect(opts.Interval).To(Equal(1 * time.Minute))
+				Expect(opts.MaxInterval).To(Equal(construct.DefaultShutdownMaxInterval))
+				Expect(opts.Jitter).To(Equal(poller.DefaultJitter))
+				Expect(opts.Timeout).To(Equal(construct.DefaultShutdownTimeout))
 
 				Expect(fakeVcenterClient.IsPoweredOffCallCount()).To(Equal(0))
-				Expect(fakeMessenger.WaitingForShutdownCallCount()).To(Equal(0))
 
 				isPoweredOff, err := pollFunc()
 				Expect(isPoweredOff).To(BeFalse())
 				Expect(err).NotTo(HaveOccurred())
-				Expect(fakeMessenger.WaitingForShutdownCallCount()).To(Equal(1))
 
 				isPoweredOff, err = pollFunc()
 				Expect(isPoweredOff).To(BeTrue())
 				Expect(err).NotTo(HaveOccurred())
-				Expect(fakeMessenger.WaitingForShutdownCallCount()).To(Equal(2))
 
 				isPoweredOff, err = pollFunc() //nolint:ineffassign,staticcheck
 				Expect(err).To(MatchError("checking for powered off is hard"))
-				Expect(fakeMessenger.WaitingForShutdownCallCount()).To(Equal(2))
 
 				Expect(fakeVcenterClient.IsPoweredOffCallCount()).To(Equal(3))
 			})
 
+			It("reports that it is still waiting on every check that finds the VM running", func() {
+				err := vmConstruct.PrepareVM()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, opts, _ := fakePoller.PollUntilArgsForCall(0)
+				Expect(fakeMessenger.WaitingForShutdownCallCount()).To(Equal(0))
+
+				opts.Progress(poller.Progress{Attempt: 1, Elapsed: time.Minute, NextWait: 2 * time.Minute})
+				opts.Progress(poller.Progress{Attempt: 2, Elapsed: 3 * time.Minute, NextWait: 2 * time.Minute})
+				Expect(fakeMessenger.WaitingForShutdownCallCount()).To(Equal(2))
+			})
+
+			It("polls with the context of construct", func() {
+				err := vmConstruct.PrepareVM()
+				Expect(err).ToNot(HaveOccurred())
+
+				ctx, _, _ := fakePoller.PollUntilArgsForCall(0)
+				Expect(ctx).To(Equal(context.TODO()))
+			})
+
 			It("returns failure when it cannot determine VM power state", func() {
 				errorString := "cannot determine VM state"
-				fakePoller.PollReturnsOnCall(0, errors.New(errorString))
+				fakePoller.PollUntilReturnsOnCall(0, errors.New(errorString))
 
 				err := vmConstruct.PrepareVM()
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal(errorString))
+				Expect(err.Error()).To(HavePrefix(errorString))
 
 				Expect(fakeMessenger.ShutdownCompletedCallCount()).To(Equal(0))
 			})
+
+			It("uses the configured poll intervals and timeout", func() {
+				vmConstruct.ShutdownPollInterval = 5 * time.Second
+				vmConstruct.ShutdownMaxInterval = 30 * time.Second
+				vmConstruct.ShutdownTimeout = 20 * time.Minute
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				_, opts, _ := fakePoller.PollUntilArgsForCall(0)
+				Expect(opts.Interval).To(Equal(5 * time.Second))
+				Expect(opts.MaxInterval).To(Equal(30 * time.Second))
+				Expect(opts.Timeout).To(Equal(20 * time.Minute))
+			})
+
+			It("returns a timeout error when the VM does not power off in time", func() {
+				vmConstruct.ShutdownTimeout = 20 * time.Minute
+				fakePoller.PollUntilReturns(fmt.Errorf("%w after 20m0s", poller.ErrTimedOut))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(HavePrefix("the VM did not power off within 20m0s, increase -shutdown-timeout if sysprep needs longer")))
+
+				Expect(fakeMessenger.ShutdownCompletedCallCount()).To(Equal(0))
+			})
+		})
+
+		Describe("tailing the guest log", func() {
+			var fakeLogTail *constructfakes.FakeGuestLogTailer
+
+			BeforeEach(func() {
+				fakeLogTail = &constructfakes.FakeGuestLogTailer{}
+				vmConstruct.LogTail = fakeLogTail
+			})
+
+			It("tails the guest log while each setup script runs", func() {
+				fakeScriptExecutor.ExecuteSetupScriptCalls(func(string, []string) error {
+					Expect(fakeLogTail.StartCallCount()).To(Equal(1))
+					Expect(fakeLogTail.StopCallCount()).To(Equal(0))
+					return nil
+				})
+				fakeScriptExecutor.ExecutePostRebootScriptCalls(func(time.Duration, []string) error {
+					Expect(fakeLogTail.StartCallCount()).To(Equal(2))
+					Expect(fakeLogTail.StopCallCount()).To(Equal(1))
+					return nil
+				})
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeLogTail.StartCallCount()).To(Equal(2))
+				Expect(fakeLogTail.StopCallCount()).To(Equal(2))
+			})
+
+			It("stops tailing when a setup script fails", func() {
+				fakeScriptExecutor.ExecuteSetupScriptReturns(errors.New("setup failed"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(HaveOccurred())
+
+				Expect(fakeLogTail.StopCallCount()).To(Equal(1))
+			})
+		})
+
+		Describe("downloading guest logs", func() {
+			BeforeEach(func() {
+				fakeGuestManager.DownloadFileInGuestCalls(func(_ context.Context, path string) (io.Reader, int64, error) {
+					if strings.Contains(path, "Sysprep") {
+						return nil, 0, errors.New("file not found")
+					}
+					return strings.NewReader("contents of " + path), 0, nil
+				})
+			})
+
+			It("saves the guest logs and adds their location to the error when a step after extraction fails", func() {
+				fakeScriptExecutor.ExecuteSetupScriptReturns(errors.New("setup failed"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("setup failed\nGuest logs saved to "))
+
+				dirs, globErr := filepath.Glob(filepath.Join(vmConstruct.GuestLogsDir, "stembuild-guest-logs-*"))
+				Expect(globErr).NotTo(HaveOccurred())
+				Expect(dirs).To(HaveLen(1))
+				Expect(err.Error()).To(HaveSuffix(dirs[0]))
+
+				contents, readErr := os.ReadFile(filepath.Join(dirs[0], "log.log"))
+				Expect(readErr).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("contents of C:\\provision\\log.log"))
+				Expect(filepath.Join(dirs[0], "panther-setupact.log")).To(BeAnExistingFile())
+				Expect(filepath.Join(dirs[0], "panther-setuperr.log")).To(BeAnExistingFile())
+				Expect(filepath.Join(dirs[0], "sysprep-panther-setupact.log")).NotTo(BeAnExistingFile())
+
+				Expect(fakeMessenger.DownloadGuestLogsStartedCallCount()).To(Equal(1))
+			})
+
+			It("returns the step error unchanged and warns when no logs can be downloaded", func() {
+				fakeGuestManager.DownloadFileInGuestCalls(func(context.Context, string) (io.Reader, int64, error) {
+					return nil, 0, errors.New("guest is powered off")
+				})
+				fakePoller.PollUntilReturns(errors.New("cannot determine VM state"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("cannot determine VM state"))
+
+				Expect(fakeMessenger.GuestLogsNotDownloadedCallCount()).To(Equal(1))
+				dirs, _ := filepath.Glob(filepath.Join(vmConstruct.GuestLogsDir, "stembuild-guest-logs-*"))
+				Expect(dirs).To(BeEmpty())
+			})
+
+			It("does not download logs when a step before extraction fails", func() {
+				fakeWinRMEnabler.EnableReturns(errors.New("failed to enable winRM"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("failed to enable winRM"))
+				Expect(fakeMessenger.DownloadGuestLogsStartedCallCount()).To(Equal(0))
+			})
+		})
+
+		Describe("checkpoints", func() {
+			var fakeCheckpoints *constructfakes.FakeCheckpointStore
+
+			BeforeEach(func() {
+				fakeCheckpoints = &constructfakes.FakeCheckpointStore{}
+				vmConstruct.Checkpoints = fakeCheckpoints
+			})
+
+			It("discards earlier progress and records each completed step", func() {
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeCheckpoints.LoadCallCount()).To(Equal(0))
+				Expect(fakeCheckpoints.SaveCallCount()).To(Equal(12))
+
+				vmPath, steps := fakeCheckpoints.SaveArgsForCall(0)
+				Expect(vmPath).To(Equal("fakeVmPath"))
+				Expect(steps).To(BeEmpty())
+
+				_, steps = fakeCheckpoints.SaveArgsForCall(1)
+				Expect(steps).To(Equal([]string{"validate-os-version"}))
+
+				_, steps = fakeCheckpoints.SaveArgsForCall(11)
+				Expect(steps).To(HaveLen(11))
+				Expect(steps[10]).To(Equal("wait-for-shutdown"))
+			})
+
+			It("stops recording progress when a step fails", func() {
+				fakeWinRMEnabler.EnableReturns(errors.New("failed to enable winRM"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(HaveOccurred())
+
+				_, steps := fakeCheckpoints.SaveArgsForCall(fakeCheckpoints.SaveCallCount() - 1)
+				Expect(steps).To(Equal([]string{"validate-os-version", "create-provision-dir", "upload-artifacts"}))
+			})
+
+			It("warns but continues when progress cannot be recorded", func() {
+				fakeCheckpoints.SaveReturnsOnCall(2, errors.New("disk full"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.CheckpointNotSavedCallCount()).To(Equal(1))
+				step, saveErr := fakeMessenger.CheckpointNotSavedArgsForCall(0)
+				Expect(step).To(Equal("create-provision-dir"))
+				Expect(saveErr).To(MatchError("disk full"))
+			})
+
+			Context("when the state file cannot be written", func() {
+				BeforeEach(func() {
+					notADirectory := filepath.Join(GinkgoT().TempDir(), "file")
+					Expect(os.WriteFile(notADirectory, nil, 0600)).To(Succeed())
+					vmConstruct.Checkpoints = construct.NewFileCheckpointStore(filepath.Join(notADirectory, "construct-state.json"))
+				})
+
+				It("warns once and constructs the VM without recording progress", func() {
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeMessenger.CheckpointsDisabledCallCount()).To(Equal(1))
+					Expect(fakeMessenger.CheckpointsDisabledArgsForCall(0)).To(MatchError(ContainSubstring("construct-state.json")))
+					Expect(fakeMessenger.CheckpointNotSavedCallCount()).To(Equal(0))
+					Expect(fakeMessenger.TimingsNotSavedCallCount()).To(Equal(0))
+					Expect(fakeMessenger.ShutdownCompletedCallCount()).To(Equal(1))
+				})
+
+				It("fails before changing the VM when recording progress was asked for", func() {
+					vmConstruct.RequireCheckpoints = true
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("construct-state.json")))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+			})
+
+			It("records the timings of the completed steps with their checkpoints", func() {
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeCheckpoints.SaveTimingsCallCount()).To(Equal(11))
+				vmPath, timings := fakeCheckpoints.SaveTimingsArgsForCall(10)
+				Expect(vmPath).To(Equal("fakeVmPath"))
+				Expect(timings).To(HaveLen(11))
+				Expect(timings[10].Name).To(Equal("wait-for-shutdown"))
+			})
+
+			It("warns but continues when the timings cannot be recorded", func() {
+				fakeCheckpoints.SaveTimingsReturns(errors.New("disk full"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.TimingsNotSavedCallCount()).To(Equal(11))
+				Expect(fakeMessenger.TimingsNotSavedArgsForCall(0)).To(MatchError("disk full"))
+			})
+
+			Context("when resuming", func() {
+				BeforeEach(func() {
+					vmConstruct.Resume = true
+					fakeCheckpoints.LoadReturns([]string{
+						"create-provision-dir",
+						"upload-artifacts",
+						"enable-winrm",
+						"validate-vm-connection",
+						"extract-artifacts",
+						"log-out-users",
+						"execute-setup-script",
+						"reboot",
+					}, nil)
+					fakeVcenterClient.IsPoweredOffReturns(false, nil)
+				})
+
+				It("skips completed steps whose results are still present on the VM", func() {
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeCheckpoints.LoadCallCount()).To(Equal(1))
+					Expect(fakeCheckpoints.LoadArgsForCall(0)).To(Equal("fakeVmPath"))
+
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+					Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(0))
+					Expect(fakeWinRMEnabler.EnableCallCount()).To(Equal(0))
+					Expect(fakeRemoteManager.ExtractArchiveCallCount()).To(Equal(0))
+					Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(0))
+					Expect(fakeRebootWaiter.WaitForRebootFinishedCallCount()).To(Equal(0))
+
+					Expect(fakeMessenger.LogOutUsersStartedCallCount()).To(Equal(1))
+					Expect(fakeMessenger.ValidateVMConnectionStartedCallCount()).To(Equal(1))
+					Expect(fakeScriptExecutor.ExecutePostRebootScriptCallCount()).To(Equal(1))
+
+					Expect(fakeMessenger.StepSkippedCallCount()).To(Equal(6))
+					Expect(fakeMessenger.StepSkippedArgsForCall(0)).To(Equal("create-provision-dir"))
+					Expect(fakeMessenger.StepSkippedArgsForCall(5)).To(Equal("reboot"))
+				})
+
+				It("checks the guest for the results of completed steps", func() {
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					_, command, args := fakeGuestManager.StartProgramInGuestArgsForCall(0)
+					Expect(command).To(ContainSubstring("powershell.exe"))
+					Expect(args).To(ContainSubstring("Test-Path 'C:\\provision\\'"))
+
+					_, _, args = fakeGuestManager.StartProgramInGuestArgsForCall(1)
+					Expect(args).To(ContainSubstring("Test-Path 'C:\\provision\\LGPO.zip'"))
+					Expect(args).To(ContainSubstring("Test-Path 'C:\\provision\\StemcellAutomation.zip'"))
+				})
+
+				It("reruns a completed step and every later step when its results are missing", func() {
+					fakeGuestManager.ExitCodeForProgramInGuestReturnsOnCall(1, 1, nil)
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+					Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(2))
+					Expect(fakeWinRMEnabler.EnableCallCount()).To(Equal(1))
+					Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(1))
+					Expect(fakeRebootWaiter.WaitForRebootFinishedCallCount()).To(Equal(1))
+
+					Expect(fakeMessenger.StepSkippedCallCount()).To(Equal(1))
+					Expect(fakeMessenger.StepVerificationFailedCallCount()).To(Equal(1))
+					step, _ := fakeMessenger.StepVerificationFailedArgsForCall(0)
+					Expect(step).To(Equal("upload-artifacts"))
+				})
+
+				It("reruns a completed step when its results cannot be checked", func() {
+					fakeVMConnectionValidator.ValidateReturnsOnCall(0, errors.New("winrm is down"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeWinRMEnabler.EnableCallCount()).To(Equal(1))
+					step, reason := fakeMessenger.StepVerificationFailedArgsForCall(0)
+					Expect(step).To(Equal("enable-winrm"))
+					Expect(reason).To(Equal("winrm is down"))
+				})
+
+				It("keeps the timings of the skipped steps from the run being resumed", func() {
+					earlier := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+					fakeCheckpoints.LoadTimingsReturns([]report.Step{
+						{Name: "create-provision-dir", Start: earlier, End: earlier.Add(time.Second), Duration: 1},
+						{Name: "log-out-users", Start: earlier, End: earlier.Add(time.Second), Duration: 1},
+					}, nil)
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeCheckpoints.LoadTimingsArgsForCall(0)).To(Equal("fakeVmPath"))
+					steps := fakeMessenger.StepTimingsArgsForCall(0)
+					Expect(steps[0].Name).To(Equal("create-provision-dir"))
+					Expect(steps[0].Start).To(Equal(earlier))
+					for _, step := range steps[1:] {
+						Expect(step.Start).NotTo(Equal(earlier))
+					}
+				})
+
+				It("returns an error when the recorded progress cannot be loaded", func() {
+					fakeCheckpoints.LoadReturns(nil, errors.New("state file is corrupt"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError("cannot resume construct: state file is corrupt"))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Describe("an already constructed VM", func() {
+			const (
+				markerPid      = 101
+				versionFilePid = 102
+			)
+			var markerExists, versionFileExists bool
+
+			BeforeEach(func() {
+				vmConstruct.IfConstructed = construct.IfConstructedRefuse
+				markerExists, versionFileExists = false, false
+
+				fakeGuestManager.StartProgramInGuestStub = func(_ context.Context, _ string, args string) (int64, error) {
+					if strings.Contains(args, "Test-Path 'C:\\provision\\stembuild-construct.txt'") {
+						return markerPid, nil
+					}
+					if strings.Contains(args, "Test-Path 'C:\\var\\vcap\\bosh\\etc\\stemcell_version'") {
+						return versionFilePid, nil
+					}
+					return 0, nil
+				}
+				fakeGuestManager.ExitCodeForProgramInGuestStub = func(_ context.Context, pid int64) (int32, error) {
+					if (pid == markerPid && !markerExists) || (pid == versionFilePid && !versionFileExists) {
+						return 1, nil
+					}
+					return 0, nil
+				}
+			})
+
+			It("constructs a VM without traces of an earlier construct", func() {
+				startProgram := fakeGuestManager.StartProgramInGuestStub
+				fakeGuestManager.StartProgramInGuestStub = func(ctx context.Context, command string, args string) (int64, error) {
+					if strings.Contains(args, "Test-Path 'C:\\provision\\stembuild-construct.txt'") {
+						Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+					}
+					return startProgram(ctx, command, args)
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.AlreadyConstructedCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+				Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(1))
+			})
+
+			It("refuses a VM with the construct marker before changing it", func() {
+				markerExists = true
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("VM has already been constructed, found C:\\provision\\stembuild-construct.txt.")))
+				Expect(err).To(MatchError(ContainSubstring("Rerun with -if-constructed continue")))
+
+				Expect(fakeMessenger.StepStartedCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+			})
+
+			It("refuses a VM with the stemcell version file", func() {
+				versionFileExists = true
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("found C:\\var\\vcap\\bosh\\etc\\stemcell_version")))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+			})
+
+			It("refuses a powered off VM without running anything in the guest", func() {
+				fakeVcenterClient.IsPoweredOffReturnsOnCall(0, true, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("VM is powered off")))
+				Expect(err).To(MatchError(ContainSubstring("ready for `stembuild package`")))
+
+				Expect(fakeVcenterClient.IsPoweredOffArgsForCall(0)).To(Equal("fakeVmPath"))
+				Expect(fakeGuestManager.StartProgramInGuestCallCount()).To(Equal(0))
+			})
+
+			It("fails when the VM cannot be checked", func() {
+				fakeVcenterClient.IsPoweredOffReturnsOnCall(0, false, errors.New("not authorized"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("cannot check whether the VM has already been constructed: not authorized"))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+			})
+
+			It("does not check the VM when resuming", func() {
+				vmConstruct.Resume = true
+				markerExists = true
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeMessenger.AlreadyConstructedCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+			})
+
+			It("does not check the VM when no action is set", func() {
+				vmConstruct.IfConstructed = ""
+				markerExists = true
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+			})
+
+			Context("when continuing", func() {
+				BeforeEach(func() {
+					vmConstruct.IfConstructed = construct.IfConstructedContinue
+					markerExists, versionFileExists = true, true
+				})
+
+				It("runs only the steps whose results are missing", func() {
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeMessenger.AlreadyConstructedCallCount()).To(Equal(1))
+					Expect(fakeMessenger.AlreadyConstructedArgsForCall(0)).To(Equal([]string{
+						"C:\\provision\\stembuild-construct.txt",
+						"C:\\var\\vcap\\bosh\\etc\\stemcell_version",
+					}))
+
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+					Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(0))
+					Expect(fakeRemoteManager.ExtractArchiveCallCount()).To(Equal(0))
+					Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(0))
+					Expect(fakeScriptExecutor.ExecutePostRebootScriptCallCount()).To(Equal(1))
+				})
+
+				It("constructs a VM without traces of an earlier construct from the start", func() {
+					markerExists, versionFileExists = false, false
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeMessenger.AlreadyConstructedCallCount()).To(Equal(0))
+					Expect(fakeMessenger.StepSkippedCallCount()).To(Equal(0))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(1))
+				})
+
+				It("keeps the snapshot taken by the earlier construct", func() {
+					fakeSnapshots := &constructfakes.FakeSnapshotManager{}
+					fakeSnapshots.HasSnapshotReturns(true, nil)
+					vmConstruct.Snapshots = fakeSnapshots
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeMessenger.SnapshotReusedCallCount()).To(Equal(1))
+					Expect(fakeSnapshots.CreateSnapshotCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Describe("checking the VM behind -vm-ip", func() {
+			const identityWritePid = 201
+			var (
+				fakeGuestNetwork *constructfakes.FakeGuestNetwork
+				nonce            string
+			)
+
+			BeforeEach(func() {
+				fakeGuestNetwork = &constructfakes.FakeGuestNetwork{}
+				fakeGuestNetwork.IPAddressesReturns([]string{"fe80::1", "10.0.0.5"}, nil)
+				vmConstruct.VMIP = "10.0.0.5"
+				vmConstruct.GuestNetwork = fakeGuestNetwork
+				nonce = ""
+
+				fakeGuestManager.StartProgramInGuestStub = func(_ context.Context, _ string, args string) (int64, error) {
+					if strings.Contains(args, "stembuild-vm-identity.txt") {
+						nonce = regexp.MustCompile(`-Value '([0-9a-f]+)'`).FindStringSubmatch(args)[1]
+						return identityWritePid, nil
+					}
+					return 0, nil
+				}
+			})
+
+			It("compares -vm-ip with the addresses of the VM before uploading anything", func() {
+				fakeGuestNetwork.IPAddressesStub = func() ([]string, error) {
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+					return []string{"fe80::1", "10.0.0.5"}, nil
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGuestNetwork.IPAddressesCallCount()).To(Equal(1))
+				Expect(fakeMessenger.CheckVMIPStartedArgsForCall(0)).To(Equal("10.0.0.5"))
+				Expect(fakeMessenger.CheckVMIPSucceededCallCount()).To(Equal(1))
+			})
+
+			It("fails before uploading anything when -vm-ip is not an address of the VM", func() {
+				fakeGuestNetwork.IPAddressesReturns([]string{"10.0.0.6", "fe80::1"}, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("-vm-ip 10.0.0.5 does not belong to the VM at fakeVmPath, whose IP addresses are 10.0.0.6, fe80::1")))
+				Expect(err).To(MatchError(ContainSubstring("-skip-vm-ip-check")))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.UploadArtifactCallCount()).To(Equal(0))
+			})
+
+			It("warns and relies on WinRM when VMware Tools reports no addresses", func() {
+				fakeGuestNetwork.IPAddressesReturns(nil, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.VMIPNotReportedCallCount()).To(Equal(1))
+				Expect(fakeMessenger.VMIPNotReportedArgsForCall(0)).To(Equal("10.0.0.5"))
+				Expect(fakeMessenger.ConfirmVMIdentitySucceededCallCount()).To(Equal(1))
+			})
+
+			It("returns an error when the addresses cannot be read", func() {
+				fakeGuestNetwork.IPAddressesReturns(nil, errors.New("no permission"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("cannot read the IP addresses of the VM from vCenter: no permission"))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+			})
+
+			It("does not compare addresses with -skip-vm-ip-check", func() {
+				vmConstruct.SkipVMIPCheck = true
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGuestNetwork.IPAddressesCallCount()).To(Equal(0))
+				Expect(fakeMessenger.ConfirmVMIdentitySucceededCallCount()).To(Equal(1))
+			})
+
+			Context("when -vm-ip is a host name", func() {
+				var fakeResolver *constructfakes.FakeHostResolver
+
+				BeforeEach(func() {
+					fakeResolver = &constructfakes.FakeHostResolver{}
+					fakeResolver.LookupHostReturns([]string{"192.168.1.9", "10.0.0.5"}, nil)
+					vmConstruct.VMIP = "build-vm.example.com"
+					vmConstruct.Resolver = fakeResolver
+				})
+
+				It("compares the addresses the host name resolves to with the addresses of the VM", func() {
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+
+					_, host := fakeResolver.LookupHostArgsForCall(0)
+					Expect(host).To(Equal("build-vm.example.com"))
+					Expect(fakeGuestNetwork.IPAddressesCallCount()).To(Equal(1))
+					Expect(fakeMessenger.CheckVMIPSucceededCallCount()).To(Equal(1))
+					Expect(fakeMessenger.ConfirmVMIdentitySucceededCallCount()).To(Equal(1))
+				})
+
+				It("fails before uploading anything when the host name resolves to another machine", func() {
+					fakeResolver.LookupHostReturns([]string{"192.168.1.9"}, nil)
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("-vm-ip build-vm.example.com (192.168.1.9) does not belong to the VM at fakeVmPath")))
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+				})
+
+				It("fails when the host name cannot be resolved", func() {
+					fakeResolver.LookupHostReturns(nil, errors.New("no such host"))
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError(ContainSubstring("cannot resolve -vm-ip build-vm.example.com: no such host")))
+					Expect(fakeGuestNetwork.IPAddressesCallCount()).To(Equal(0))
+				})
+
+				It("does not resolve the host name with -skip-vm-ip-check", func() {
+					vmConstruct.SkipVMIPCheck = true
+
+					err := vmConstruct.PrepareVM()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeResolver.LookupHostCallCount()).To(Equal(0))
+				})
+			})
+
+			It("confirms over WinRM that it reaches the VM before running anything on it", func() {
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(nonce).NotTo(BeEmpty())
+
+				command := fakeRemoteManager.ExecuteCommandArgsForCall(0)
+				Expect(command).To(HavePrefix("powershell.exe -EncodedCommand "))
+				decoded := decodePowershellCommand(strings.TrimPrefix(command, "powershell.exe -EncodedCommand "))
+				Expect(decoded).To(ContainSubstring("C:\\Windows\\Temp\\stembuild-vm-identity.txt"))
+				Expect(decoded).To(ContainSubstring("-eq '" + nonce + "'"))
+				Expect(decoded).To(ContainSubstring("Remove-Item"))
+
+				Expect(fakeMessenger.ConfirmVMIdentityStartedCallCount()).To(Equal(1))
+				Expect(fakeMessenger.ConfirmVMIdentitySucceededCallCount()).To(Equal(1))
+			})
+
+			It("fails before running anything when WinRM reaches a different machine", func() {
+				fakeRemoteManager.ExecuteCommandReturnsOnCall(0, 1, errors.New("powershell encountered an issue"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("-vm-ip 10.0.0.5 reaches a different machine than the VM at fakeVmPath. Check -vm-ip and -vm-inventory-path"))
+				Expect(fakeRemoteManager.ExecuteCommandCallCount()).To(Equal(1))
+				Expect(fakeRemoteManager.ExtractArchiveCallCount()).To(Equal(0))
+				Expect(fakeScriptExecutor.ExecuteSetupScriptCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the nonce cannot be checked over WinRM", func() {
+				fakeRemoteManager.ExecuteCommandReturnsOnCall(0, -1, errors.New("connection refused"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("cannot confirm the identity of the VM over WinRM: connection refused"))
+				Expect(fakeRemoteManager.ExtractArchiveCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the nonce cannot be written to the VM", func() {
+				fakeGuestManager.ExitCodeForProgramInGuestStub = func(_ context.Context, pid int64) (int32, error) {
+					if pid == identityWritePid {
+						return 5, nil
+					}
+					return 0, nil
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("cannot confirm the identity of the VM: writing C:\\Windows\\Temp\\stembuild-vm-identity.txt exited with code 5")))
+				Expect(fakeRemoteManager.ExecuteCommandCallCount()).To(Equal(0))
+			})
+		})
+
+		Describe("snapshots", func() {
+			var fakeSnapshots *constructfakes.FakeSnapshotManager
+
+			BeforeEach(func() {
+				fakeSnapshots = &constructfakes.FakeSnapshotManager{}
+				vmConstruct.Snapshots = fakeSnapshots
+				fakeVersionGetter.GetVersionReturns("2019.1")
+			})
+
+			It("snapshots the VM before changing it and deletes the snapshot on success", func() {
+				fakeSnapshots.CreateSnapshotStub = func(string, string) error {
+					Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+					return nil
+				}
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeSnapshots.CreateSnapshotCallCount()).To(Equal(1))
+				name, description := fakeSnapshots.CreateSnapshotArgsForCall(0)
+				Expect(name).To(Equal("stembuild-pre-construct"))
+				Expect(description).To(ContainSubstring("2019.1"))
+
+				Expect(fakeSnapshots.DeleteSnapshotCallCount()).To(Equal(1))
+				Expect(fakeSnapshots.DeleteSnapshotArgsForCall(0)).To(Equal("stembuild-pre-construct"))
+				Expect(fakeSnapshots.RevertToSnapshotCallCount()).To(Equal(0))
+			})
+
+			It("warns but succeeds when the snapshot cannot be deleted", func() {
+				fakeSnapshots.DeleteSnapshotReturns(errors.New("task failed"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMessenger.SnapshotNotDeletedCallCount()).To(Equal(1))
+				name, deleteErr := fakeMessenger.SnapshotNotDeletedArgsForCall(0)
+				Expect(name).To(Equal("stembuild-pre-construct"))
+				Expect(deleteErr).To(MatchError("task failed"))
+			})
+
+			It("does not change the VM when the snapshot cannot be taken", func() {
+				fakeSnapshots.CreateSnapshotReturns(errors.New("no space on datastore"))
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError("cannot snapshot VM before construct: no space on datastore"))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+			})
+
+			It("refuses to start over a VM that still has a snapshot from an earlier construct", func() {
+				fakeSnapshots.HasSnapshotReturns(true, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).To(MatchError(ContainSubstring("already has a snapshot named 'stembuild-pre-construct'")))
+				Expect(fakeSnapshots.CreateSnapshotCallCount()).To(Equal(0))
+				Expect(fakeVcenterClient.MakeDirectoryCallCount()).To(Equal(0))
+			})
+
+			It("keeps the existing snapshot when resuming", func() {
+				vmConstruct.Resume = true
+				fakeSnapshots.HasSnapshotReturns(true, nil)
+
+				err := vmConstruct.PrepareVM()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeSnapshots.CreateSnapshotCallCount()).To(Equal(0))
+				Expect(fakeMessenger.SnapshotReusedCallCount()).To(Equal(1))
+				Expect(fakeSnapshots.DeleteSnapshotCallCount()).To(Equal(1))
+			})
+
+			Context("when a step fails", func() {
+				BeforeEach(func() {
+					fakeWinRMEnabler.EnableReturns(errors.New("failed to enable winRM"))
+				})
+
+				It("keeps the snapshot and tells the user about it", func() {
+					err := vmConstruct.PrepareVM()
+					Expect(err).To(MatchError("failed to enable winRM"))
+
+					Expect(fakeSnapshots.RevertToSnapshotCallCount()).To(Equal(0))
+					Expect(fakeSnapshots.DeleteSnapshotCallCount()).To(Equal(0))
+					Expect(fakeMessenger.SnapshotRetainedCallCount()).To(Equal(1))
+					Expect(fakeMessenger.SnapshotRetainedArgsForCall(0)).To(Equal("stembuild-pre-construct"))
+				})
+
+				Context("with rollback on failure", func() {
+					var fakeCheckpoints *constructfakes.FakeCheckpointStore
+
+					BeforeEach(func() {
+						vmConstruct.RollbackOnFailure = true
+						fakeCheckpoints = &constructfakes.FakeCheckpointStore{}
+						vmConstruct.Checkpoints = fakeCheckpoints
+					})
+
+					It("reverts the VM, deletes the snapshot and discards recorded progress", func() {
+						err := vmConstruct.PrepareVM()
+						Expect(err).To(MatchError("failed to enable winRM"))
+
+						Expect(fakeSnapshots.RevertToSnapshotCallCount()).To(Equal(1))
+						Expect(fakeSnapshots.RevertToSnapshotArgsForCall(0)).To(Equal("stembuild-pre-construct"))
+						Expect(fakeSnapshots.DeleteSnapshotCallCount()).To(Equal(1))
+						Expect(fakeMessenger.RollbackSucceededCallCount()).To(Equal(1))
+
+						_, steps := fakeCheckpoints.SaveArgsForCall(fakeCheckpoints.SaveCallCount() - 1)
+						Expect(steps).To(BeEmpty())
+					})
+
+					It("returns both errors and keeps the snapshot when the revert fails", func() {
+						fakeSnapshots.RevertToSnapshotReturns(errors.New("vm is locked"))
+
+						err := vmConstruct.PrepareVM()
+						Expect(err).To(MatchError("failed to enable winRM; rolling back to snapshot 'stembuild-pre-construct' also failed: vm is locked"))
+						Expect(fakeSnapshots.DeleteSnapshotCallCount()).To(Equal(0))
+					})
+				})
+			})
 		})
 	})
 })
+
+func decodePowershellCommand(encoded string) string {
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	Expect(err).NotTo(HaveOccurred())
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
@@ -0,0 +1,182 @@
+package construct
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+const windowsUpdatesResultFile = provisionDir + "windows-updates.txt"
+const DefaultWindowsUpdatesTimeout = 4 * time.Hour
+
+// The Windows Update API refuses to download and install updates from a remote session,
+// so the search and install run as SYSTEM in a scheduled task and the result is written to a file.
+// The task uses the BOSH.WindowsUpdates module from the uploaded StemcellAutomation.zip. Its Install-UpdateBatch
+// ends by restarting the guest or re-enabling WinRM itself, which construct does between rounds instead.
+var installWindowsUpdatesScript = fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+$result = '%[1]s'
+$script = '%[2]sinstall-windows-updates.ps1'
+$task = 'stembuild-install-windows-updates'
+Remove-Item $result -ErrorAction SilentlyContinue
+Set-Content -Path $script -Value @'
+$lines = @()
+$modules = 'C:\Windows\Temp\stembuild-psmodules'
+try {
+	Expand-Archive -Path '%[3]s' -DestinationPath $modules -Force
+	Expand-Archive -Path "$modules\bosh-psmodules.zip" -DestinationPath $modules -Force
+	Import-Module "$modules\BOSH.Utils" -Force
+	$windowsUpdates = Import-Module "$modules\BOSH.WindowsUpdates" -Force -PassThru
+	& $windowsUpdates {
+		# construct reboots between rounds, and has enabled WinRM already
+		function script:Invoke-RebootOrComplete {}
+		function script:Enable-WinRM {}
+		function script:Restart-Computer { $script:RestartRequired = 1 }
+		$script:UpdateSession = New-Object -ComObject 'Microsoft.Update.Session'
+		$script:UpdateSession.ClientApplicationID = 'BOSH.WindowsUpdates'
+		$script:Cycles = 0
+		$script:CycleUpdateCount = 0
+		$script:MaxUpdatesPerCycle = 500
+		$script:RestartRequired = 0
+		$script:MoreUpdates = 0
+	}
+	Get-UpdateBatch
+	$results = @()
+	if ((& $windowsUpdates { $script:MoreUpdates }) -eq 1) {
+		# Install-UpdateBatch breaks out of the enclosing loop when nothing could be downloaded
+		do { $results = @(Install-UpdateBatch) } while ($false)
+	}
+	foreach ($update in $results | Where-Object { $_.PSObject.Properties['Title'] -and $_.PSObject.Properties['Result'] }) {
+		# 2 is succeeded, 3 succeeded with errors
+		if ((@(2, 3) -contains $update.Result) -and ($update.Title -match '\((KB\d+)\)')) { $lines += $Matches[1] }
+	}
+	if ((& $windowsUpdates { $script:RestartRequired }) -eq 1) { $lines += 'reboot-required' }
+	$lines += 'done'
+} catch {
+	$lines += "error: $_"
+} finally {
+	Remove-Item $modules -Recurse -Force -ErrorAction SilentlyContinue
+}
+$lines | Out-File -Encoding ascii -FilePath '%[1]s.tmp'
+Move-Item -Force '%[1]s.tmp' '%[1]s'
+'@
+try {
+	$action = New-ScheduledTaskAction -Execute 'powershell.exe' -Argument "-NoProfile -ExecutionPolicy Bypass -File $script"
+	Register-ScheduledTask -TaskName $task -Action $action -User 'NT AUTHORITY\SYSTEM' -RunLevel Highest -Force | Out-Null
+	Start-ScheduledTask -TaskName $task
+	# the task is Queued before it is Running, and Ready once it has finished
+	do { Start-Sleep -Seconds 10 } while ((Get-ScheduledTask -TaskName $task).State -ne 'Ready')
+} finally {
+	Unregister-ScheduledTask -TaskName $task -Confirm:$false -ErrorAction SilentlyContinue
+}
+if (-not (Test-Path $result)) { throw 'the Windows Update task did not write a result' }
+`, windowsUpdatesResultFile, provisionDir, stemcellAutomationDest)
+
+type WindowsUpdater struct {
+	ctx           context.Context
+	remoteManager remotemanager.RemoteManager
+	guestManager  GuestManager
+	// Timeout bounds a single round of searching for, downloading and installing updates
+	Timeout time.Duration
+}
+
+func NewWindowsUpdater(ctx context.Context, remoteManager remotemanager.RemoteManager, guestManager GuestManager) *WindowsUpdater {
+	return &WindowsUpdater{ctx: ctx, remoteManager: remoteManager, guestManager: guestManager, Timeout: DefaultWindowsUpdatesTimeout}
+}
+
+// InstallUpdates installs every update available to the guest and returns the KBs installed
+// and whether the guest must reboot to finish installing them
+func (u *WindowsUpdater) InstallUpdates() ([]string, bool, error) {
+	command := "powershell.exe -EncodedCommand " + EncodePowershellCommand([]byte(installWindowsUpdatesScript))
+	_, err := u.remoteManager.ExecuteCommandWithTimeout(command, u.Timeout)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reader, _, err := u.guestManager.DownloadFileInGuest(u.ctx, windowsUpdatesResultFile)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not download the Windows Update result: %s", err)
+	}
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not download the Windows Update result: %s", err)
+	}
+
+	return parseWindowsUpdatesResult(string(contents))
+}
+
+func parseWindowsUpdatesResult(result string) ([]string, bool, error) {
+	var kbs []string
+	rebootRequired := false
+
+	for _, line := range strings.Split(result, "\n") {
+		line = strings.Trim(line, "\ufeff\x00 \r\t")
+		switch {
+		case line == "done":
+			return kbs, rebootRequired, nil
+		case line == "reboot-required":
+			rebootRequired = true
+		case strings.HasPrefix(line, "error: "):
+			return nil, false, fmt.Errorf("windows update failed in the guest: %s", strings.TrimPrefix(line, "error: "))
+		case strings.HasPrefix(line, "KB"):
+			kbs = append(kbs, line)
+		}
+	}
+
+	return nil, false, fmt.Errorf("windows update result is incomplete: %q", result)
+}
+
+// installWindowsUpdates installs updates in rounds, rebooting between them when the updates require it,
+// until a round finds nothing to install or MaxUpdateRounds is reached
+func (c *VMConstruct) installWindowsUpdates() error {
+	var installed []string
+
+	for round := 1; round <= c.MaxUpdateRounds; round++ {
+		c.messenger.WindowsUpdatesRoundStarted(round, c.MaxUpdateRounds)
+		kbs, rebootRequired, err := c.WindowsUpdater.InstallUpdates()
+		if err != nil {
+			return fmt.Errorf("failed to install Windows updates: %s", err)
+		}
+		c.messenger.WindowsUpdatesRoundSucceeded(kbs)
+		installed = append(installed, kbs...)
+
+		if rebootRequired {
+			err = c.rebootForUpdates()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(kbs) == 0 && !rebootRequired {
+			c.messenger.WindowsUpdatesFinished(installed)
+			return nil
+		}
+	}
+
+	c.messenger.WindowsUpdatesRoundLimitReached(c.MaxUpdateRounds)
+	c.messenger.WindowsUpdatesFinished(installed)
+	return nil
+}
+
+func (c *VMConstruct) rebootForUpdates() error {
+	c.rebootWaiter.BeforeReboot()
+	_, err := c.remoteManager.ExecuteCommand(`shutdown /r /f /t 5 /c "stembuild windows updates"`)
+	if err != nil {
+		return fmt.Errorf("failed to reboot after installing Windows updates: %s", err)
+	}
+
+	c.messenger.RebootHasStarted()
+	err = c.sleep(c.RebootWaitTime)
+	if err != nil {
+		return err
+	}
+	err = c.rebootWaiter.WaitForRebootFinished()
+	if err != nil {
+		return err
+	}
+	c.messenger.RebootHasFinished()
+	return nil
+}
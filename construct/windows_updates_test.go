@@ -0,0 +1,104 @@
+package construct_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/construct"
+	"github.com/cloudfoundry/stembuild/construct/constructfakes"
+	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
+)
+
+var _ = Describe("WindowsUpdater", func() {
+	var (
+		updater           *construct.WindowsUpdater
+		fakeRemoteManager *remotemanagerfakes.FakeRemoteManager
+		fakeGuestManager  *constructfakes.FakeGuestManager
+	)
+
+	returnResult := func(result string) {
+		fakeGuestManager.DownloadFileInGuestReturns(strings.NewReader(result), int64(len(result)), nil)
+	}
+
+	BeforeEach(func() {
+		fakeRemoteManager = &remotemanagerfakes.FakeRemoteManager{}
+		fakeGuestManager = &constructfakes.FakeGuestManager{}
+		updater = construct.NewWindowsUpdater(context.Background(), fakeRemoteManager, fakeGuestManager)
+	})
+
+	It("runs the update script and returns the installed KBs", func() {
+		returnResult("\ufeffKB5001\r\nKB5002\r\nreboot-required\r\ndone\r\n")
+
+		kbs, rebootRequired, err := updater.InstallUpdates()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kbs).To(Equal([]string{"KB5001", "KB5002"}))
+		Expect(rebootRequired).To(BeTrue())
+
+		command, timeout := fakeRemoteManager.ExecuteCommandWithTimeoutArgsForCall(0)
+		Expect(command).To(HavePrefix("powershell.exe -EncodedCommand "))
+		Expect(timeout).To(Equal(4 * time.Hour))
+
+		_, path := fakeGuestManager.DownloadFileInGuestArgsForCall(0)
+		Expect(path).To(Equal("C:\\provision\\windows-updates.txt"))
+	})
+
+	It("installs the updates with the BOSH.WindowsUpdates module in a scheduled task that is always unregistered", func() {
+		returnResult("done\r\n")
+
+		_, _, err := updater.InstallUpdates()
+		Expect(err).NotTo(HaveOccurred())
+
+		command, _ := fakeRemoteManager.ExecuteCommandWithTimeoutArgsForCall(0)
+		script := decodePowershellCommand(strings.TrimPrefix(command, "powershell.exe -EncodedCommand "))
+		Expect(script).To(ContainSubstring(`Expand-Archive -Path 'C:\provision\StemcellAutomation.zip'`))
+		Expect(script).To(ContainSubstring(`Import-Module "$modules\BOSH.WindowsUpdates"`))
+		Expect(script).To(ContainSubstring("Get-UpdateBatch"))
+		Expect(script).To(ContainSubstring("Install-UpdateBatch"))
+		Expect(script).NotTo(ContainSubstring("CreateUpdateInstaller"))
+		Expect(script).To(ContainSubstring("while ((Get-ScheduledTask -TaskName $task).State -ne 'Ready')"))
+		Expect(script).To(MatchRegexp(`(?s)\} finally \{\s+Unregister-ScheduledTask -TaskName \$task`))
+	})
+
+	It("returns no KBs when nothing was installed", func() {
+		returnResult("done\r\n")
+
+		kbs, rebootRequired, err := updater.InstallUpdates()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kbs).To(BeEmpty())
+		Expect(rebootRequired).To(BeFalse())
+	})
+
+	It("returns the error reported by the guest", func() {
+		returnResult("error: Exception from HRESULT: 0x80240022\r\n")
+
+		_, _, err := updater.InstallUpdates()
+		Expect(err).To(MatchError("windows update failed in the guest: Exception from HRESULT: 0x80240022"))
+	})
+
+	It("fails on an incomplete result", func() {
+		returnResult("KB5001\r\n")
+
+		_, _, err := updater.InstallUpdates()
+		Expect(err).To(MatchError(HavePrefix("windows update result is incomplete: ")))
+	})
+
+	It("fails when the update script fails", func() {
+		fakeRemoteManager.ExecuteCommandWithTimeoutReturns(1, errors.New("powershell encountered an issue"))
+
+		_, _, err := updater.InstallUpdates()
+		Expect(err).To(MatchError("powershell encountered an issue"))
+		Expect(fakeGuestManager.DownloadFileInGuestCallCount()).To(Equal(0))
+	})
+
+	It("fails when the result cannot be downloaded", func() {
+		fakeGuestManager.DownloadFileInGuestReturns(nil, 0, errors.New("file not found"))
+
+		_, _, err := updater.InstallUpdates()
+		Expect(err).To(MatchError("could not download the Windows Update result: file not found"))
+	})
+})
@@ -15,11 +15,16 @@ type zipUnarchiver interface {
 type WinRMManager struct {
 	GuestManager GuestManager
 	Unarchiver   zipUnarchiver
+	// StemcellAutomation is the archive the WinRM module is read from, the embedded one when nil
+	StemcellAutomation []byte
 }
 
-func (w *WinRMManager) Enable() error {
+func (w *WinRMManager) Enable(ctx context.Context) error {
 	failureString := "failed to enable WinRM: %s"
-	saZip := assets.StemcellAutomation
+	saZip := w.StemcellAutomation
+	if saZip == nil {
+		saZip = assets.StemcellAutomation
+	}
 
 	bmZip, err := w.Unarchiver.Unzip(saZip, boshPsModules)
 	if err != nil {
@@ -35,12 +40,12 @@ func (w *WinRMManager) Enable() error {
 
 	base64WinRM := EncodePowershellCommand(rawWinRMwtCmd)
 
-	pid, err := w.GuestManager.StartProgramInGuest(context.Background(), powershell, fmt.Sprintf("-EncodedCommand %s", base64WinRM))
+	pid, err := w.GuestManager.StartProgramInGuest(ctx, powershell, fmt.Sprintf("-EncodedCommand %s", base64WinRM))
 	if err != nil {
 		return fmt.Errorf(failureString, err)
 	}
 
-	exitCode, err := w.GuestManager.ExitCodeForProgramInGuest(context.Background(), pid)
+	exitCode, err := w.GuestManager.ExitCodeForProgramInGuest(ctx, pid)
 	if err != nil {
 		return fmt.Errorf(failureString, err)
 	}
@@ -1,6 +1,7 @@
 package construct_test
 
 import (
+	"context"
 	"errors"
 
 	"github.com/cloudfoundry/stembuild/assets"
@@ -39,7 +40,7 @@ var _ = Describe("WinRMManager", func() {
 			fakeZipUnarchiver.UnzipReturnsOnCall(0, []byte("bosh-psmodules.zip extracted byte array"), nil)
 			fakeZipUnarchiver.UnzipReturnsOnCall(1, []byte("BOSH.WinRM.psm1 extracted byte array"), nil)
 
-			err := winrmManager.Enable()
+			err := winrmManager.Enable(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(fakeZipUnarchiver.UnzipCallCount()).To(Equal(2))
@@ -64,12 +65,23 @@ var _ = Describe("WinRMManager", func() {
 			Expect(pid).To(Equal(expectedPid))
 		})
 
+		It("reads the WinRM module from a custom stemcell automation archive", func() {
+			winrmManager.StemcellAutomation = []byte("custom archive")
+
+			err := winrmManager.Enable(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+
+			archive, fileName := fakeZipUnarchiver.UnzipArgsForCall(0)
+			Expect(fileName).To(Equal("bosh-psmodules.zip"))
+			Expect(archive).To(Equal([]byte("custom archive")))
+		})
+
 		It("returns a failure when fails to find BOSH.WinRM.psm1 in bosh-psmodules.zip", func() {
 			execError := errors.New("failed to find BOSH.WinRM.psm1")
 			fakeZipUnarchiver.UnzipReturnsOnCall(0, []byte("bosh-psmodules.zip extracted byte array"), nil)
 			fakeZipUnarchiver.UnzipReturnsOnCall(1, nil, execError)
 
-			err := winrmManager.Enable()
+			err := winrmManager.Enable(context.Background())
 			Expect(err).To(MatchError("failed to enable WinRM: failed to find BOSH.WinRM.psm1"))
 
 			Expect(fakeGuestManager.StartProgramInGuestCallCount()).To(Equal(0))
@@ -80,7 +92,7 @@ var _ = Describe("WinRMManager", func() {
 			execError := errors.New("failed to find bosh-psmodules.zip")
 			fakeZipUnarchiver.UnzipReturnsOnCall(0, nil, execError)
 
-			err := winrmManager.Enable()
+			err := winrmManager.Enable(context.Background())
 			Expect(err).To(MatchError("failed to enable WinRM: failed to find bosh-psmodules.zip"))
 			Expect(fakeZipUnarchiver.UnzipCallCount()).To(Equal(1))
 
@@ -93,7 +105,7 @@ var _ = Describe("WinRMManager", func() {
 			startError := errors.New("failed to start program in guest")
 			fakeGuestManager.StartProgramInGuestReturns(0, startError)
 
-			err := winrmManager.Enable()
+			err := winrmManager.Enable(context.Background())
 			Expect(err).To(MatchError("failed to enable WinRM: failed to start program in guest"))
 
 			Expect(fakeGuestManager.ExitCodeForProgramInGuestCallCount()).To(Equal(0))
@@ -104,7 +116,7 @@ var _ = Describe("WinRMManager", func() {
 			fakeGuestManager.StartProgramInGuestReturns(expectedPid, nil)
 			fakeGuestManager.ExitCodeForProgramInGuestReturns(int32(120), nil)
 
-			err := winrmManager.Enable()
+			err := winrmManager.Enable(context.Background())
 			Expect(err).To(MatchError("failed to enable WinRM: WinRM process on guest VM exited with code 120"))
 
 			Expect(fakeGuestManager.ExitCodeForProgramInGuestCallCount()).To(Equal(1))
@@ -119,7 +131,7 @@ var _ = Describe("WinRMManager", func() {
 			execError := errors.New("failed to find PID")
 			fakeGuestManager.ExitCodeForProgramInGuestReturns(int32(1), execError)
 
-			err := winrmManager.Enable()
+			err := winrmManager.Enable(context.Background())
 			Expect(err).To(MatchError("failed to enable WinRM: failed to find PID"))
 
 			Expect(fakeGuestManager.ExitCodeForProgramInGuestCallCount()).To(Equal(1))
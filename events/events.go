@@ -0,0 +1,196 @@
+package events
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Formats of the progress output of construct and package
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const (
+	TypeStep   = "step"
+	TypeResult = "result"
+)
+
+const (
+	StatusStarted   = "started"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+	StatusInfo      = "info"
+	StatusWarning   = "warning"
+)
+
+// Event is a single line of JSON output. The last event of a command is its result.
+type Event struct {
+	Type      string    `json:"type"`
+	Command   string    `json:"command"`
+	Step      string    `json:"step,omitempty"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	// Duration is set on the events that end a step, and on the result
+	Duration *float64  `json:"duration_seconds,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	OS       string    `json:"os,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	Stemcell *Stemcell `json:"stemcell,omitempty"`
+	// Report is the path of the build report written next to the stemcell
+	Report string `json:"report,omitempty"`
+}
+
+// Stemcell is the stemcell built by package
+type Stemcell struct {
+	Path   string `json:"path"`
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+}
+
+// Result is what a successful command reports in its result event
+type Result struct {
+	OS       string
+	Version  string
+	Stemcell *Stemcell
+	Report   string
+}
+
+// Emitter writes the events of a command to out, one JSON object per line.
+// It is safe for concurrent use, and a nil Emitter discards every event.
+type Emitter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	command string
+	begin   time.Time
+	started map[string]time.Time
+}
+
+func NewEmitter(out io.Writer, command string) *Emitter {
+	return &Emitter{out: out, command: command, begin: time.Now(), started: map[string]time.Time{}}
+}
+
+func (e *Emitter) StepStarted(step string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.started[step] = time.Now()
+	e.mu.Unlock()
+	e.emit(Event{Type: TypeStep, Step: step, Status: StatusStarted})
+}
+
+func (e *Emitter) StepSucceeded(step, message string) {
+	e.endStep(Event{Type: TypeStep, Step: step, Status: StatusSucceeded, Message: message})
+}
+
+func (e *Emitter) StepFailed(step string, err error) {
+	e.endStep(Event{Type: TypeStep, Step: step, Status: StatusFailed, Error: err.Error()})
+}
+
+func (e *Emitter) StepSkipped(step, message string) {
+	e.emit(Event{Type: TypeStep, Step: step, Status: StatusSkipped, Message: message})
+}
+
+// Info reports progress within a step, or of the whole command when step is empty
+func (e *Emitter) Info(step, message string) {
+	e.emit(Event{Type: TypeStep, Step: step, Status: StatusInfo, Message: message})
+}
+
+// Warning reports a problem that does not stop the command
+func (e *Emitter) Warning(step, message string) {
+	e.emit(Event{Type: TypeStep, Step: step, Status: StatusWarning, Message: message})
+}
+
+// Succeeded ends the output with a successful result
+func (e *Emitter) Succeeded(result Result) {
+	if e == nil {
+		return
+	}
+	duration := time.Since(e.begin).Seconds()
+	e.emit(Event{
+		Type:     TypeResult,
+		Status:   StatusSucceeded,
+		Duration: &duration,
+		OS:       result.OS,
+		Version:  result.Version,
+		Stemcell: result.Stemcell,
+		Report:   result.Report,
+	})
+}
+
+// Failed ends the output with a failed result
+func (e *Emitter) Failed(err error) {
+	if e == nil {
+		return
+	}
+	duration := time.Since(e.begin).Seconds()
+	e.emit(Event{Type: TypeResult, Status: StatusFailed, Duration: &duration, Error: err.Error()})
+}
+
+func (e *Emitter) endStep(event Event) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	if started, ok := e.started[event.Step]; ok {
+		duration := time.Since(started).Seconds()
+		event.Duration = &duration
+		delete(e.started, event.Step)
+	}
+	e.mu.Unlock()
+	e.emit(event)
+}
+
+func (e *Emitter) emit(event Event) {
+	if e == nil {
+		return
+	}
+	event.Command = e.command
+	event.Timestamp = time.Now()
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.out.Write(append(line, '\n')) //nolint:errcheck
+}
+
+// NewStemcell describes the stemcell at stemcellPath with its absolute path and checksums
+func NewStemcell(stemcellPath string) (*Stemcell, error) {
+	absPath, err := filepath.Abs(stemcellPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the path of the stemcell: %s", err)
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the stemcell: %s", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	sha1Hash, sha256Hash := sha1.New(), sha256.New() //nolint:gosec
+	_, err = io.Copy(io.MultiWriter(sha1Hash, sha256Hash), f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the stemcell: %s", err)
+	}
+
+	return &Stemcell{Path: absPath, SHA1: sum(sha1Hash), SHA256: sum(sha256Hash)}, nil
+}
+
+func sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
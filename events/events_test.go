@@ -0,0 +1,140 @@
+package events_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/events"
+)
+
+func decode(output *bytes.Buffer) []map[string]interface{} {
+	var decoded []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var event map[string]interface{}
+		ExpectWithOffset(1, json.Unmarshal([]byte(line), &event)).To(Succeed())
+		decoded = append(decoded, event)
+	}
+	return decoded
+}
+
+var _ = Describe("Emitter", func() {
+	var (
+		output  *bytes.Buffer
+		emitter *events.Emitter
+	)
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		emitter = events.NewEmitter(output, "construct")
+	})
+
+	It("writes one JSON event per line", func() {
+		emitter.StepStarted("upload-artifacts")
+		emitter.Info("upload-artifacts", "uploading LGPO.zip")
+		emitter.StepSucceeded("upload-artifacts", "")
+
+		decoded := decode(output)
+		Expect(decoded).To(HaveLen(3))
+		Expect(decoded[0]).To(HaveKeyWithValue("type", "step"))
+		Expect(decoded[0]).To(HaveKeyWithValue("command", "construct"))
+		Expect(decoded[0]).To(HaveKeyWithValue("step", "upload-artifacts"))
+		Expect(decoded[0]).To(HaveKeyWithValue("status", "started"))
+		Expect(decoded[0]).To(HaveKey("timestamp"))
+		Expect(decoded[0]).NotTo(HaveKey("duration_seconds"))
+		Expect(decoded[1]).To(HaveKeyWithValue("status", "info"))
+		Expect(decoded[1]).To(HaveKeyWithValue("message", "uploading LGPO.zip"))
+		Expect(decoded[2]).To(HaveKeyWithValue("status", "succeeded"))
+		Expect(decoded[2]).To(HaveKey("duration_seconds"))
+	})
+
+	It("reports the error of a failed step with its duration", func() {
+		emitter.StepStarted("reboot")
+		emitter.StepFailed("reboot", errors.New("timed out"))
+
+		decoded := decode(output)
+		Expect(decoded[1]).To(HaveKeyWithValue("step", "reboot"))
+		Expect(decoded[1]).To(HaveKeyWithValue("status", "failed"))
+		Expect(decoded[1]).To(HaveKeyWithValue("error", "timed out"))
+		Expect(decoded[1]).To(HaveKey("duration_seconds"))
+	})
+
+	It("reports skipped steps and warnings", func() {
+		emitter.StepSkipped("enable-winrm", "completed by a previous run")
+		emitter.Warning("", "snapshot was not deleted")
+
+		decoded := decode(output)
+		Expect(decoded[0]).To(HaveKeyWithValue("status", "skipped"))
+		Expect(decoded[0]).To(HaveKeyWithValue("message", "completed by a previous run"))
+		Expect(decoded[1]).To(HaveKeyWithValue("status", "warning"))
+		Expect(decoded[1]).NotTo(HaveKey("step"))
+	})
+
+	It("ends with a successful result", func() {
+		emitter.Succeeded(events.Result{
+			OS:       "2019",
+			Version:  "2019.2",
+			Stemcell: &events.Stemcell{Path: "/stemcells/stemcell.tgz", SHA1: "a1", SHA256: "b2"},
+		})
+
+		decoded := decode(output)
+		Expect(decoded[0]).To(HaveKeyWithValue("type", "result"))
+		Expect(decoded[0]).To(HaveKeyWithValue("status", "succeeded"))
+		Expect(decoded[0]).To(HaveKeyWithValue("os", "2019"))
+		Expect(decoded[0]).To(HaveKeyWithValue("version", "2019.2"))
+		Expect(decoded[0]).To(HaveKey("duration_seconds"))
+		Expect(decoded[0]).To(HaveKeyWithValue("stemcell", map[string]interface{}{
+			"path":   "/stemcells/stemcell.tgz",
+			"sha1":   "a1",
+			"sha256": "b2",
+		}))
+	})
+
+	It("ends with a failed result", func() {
+		emitter.Failed(errors.New("could not prepare VM"))
+
+		decoded := decode(output)
+		Expect(decoded[0]).To(HaveKeyWithValue("type", "result"))
+		Expect(decoded[0]).To(HaveKeyWithValue("status", "failed"))
+		Expect(decoded[0]).To(HaveKeyWithValue("error", "could not prepare VM"))
+		Expect(decoded[0]).NotTo(HaveKey("stemcell"))
+	})
+
+	It("discards events when nil", func() {
+		var nilEmitter *events.Emitter
+		Expect(func() {
+			nilEmitter.StepStarted("reboot")
+			nilEmitter.StepSucceeded("reboot", "")
+			nilEmitter.StepFailed("reboot", errors.New("failed"))
+			nilEmitter.Info("", "info")
+			nilEmitter.Succeeded(events.Result{})
+			nilEmitter.Failed(errors.New("failed"))
+		}).NotTo(Panic())
+	})
+})
+
+var _ = Describe("NewStemcell", func() {
+	It("returns the absolute path and checksums of the stemcell", func() {
+		stemcellPath := filepath.Join(GinkgoT().TempDir(), "stemcell.tgz")
+		Expect(os.WriteFile(stemcellPath, []byte("stemcell"), 0600)).To(Succeed())
+
+		stemcell, err := events.NewStemcell(stemcellPath)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stemcell.Path).To(Equal(stemcellPath))
+		Expect(stemcell.SHA1).To(Equal("54b6c82a988394df10c7e2179abd1b01bb599de8"))
+		Expect(stemcell.SHA256).To(Equal("17934690e966c11bad06391a9056e3558ebf346d9dff99d89413185242ce453d"))
+	})
+
+	It("returns an error when the stemcell cannot be read", func() {
+		_, err := events.NewStemcell(filepath.Join(GinkgoT().TempDir(), "missing.tgz"))
+
+		Expect(err).To(MatchError(ContainSubstring("could not read the stemcell")))
+	})
+})
@@ -18,6 +18,7 @@ import (
 type ProcManager interface {
 	StartProgram(ctx context.Context, auth types.BaseGuestAuthentication, spec types.BaseGuestProgramSpec) (int64, error)
 	ListProcesses(ctx context.Context, auth types.BaseGuestAuthentication, pids []int64) ([]types.GuestProcessInfo, error)
+	TerminateProcess(ctx context.Context, auth types.BaseGuestAuthentication, pid int64) error
 	Client() *vim25.Client
 }
 
@@ -25,11 +26,15 @@ type ProcManager interface {
 type FileManager interface {
 	InitiateFileTransferFromGuest(ctx context.Context, auth types.BaseGuestAuthentication, guestFilePath string) (*types.FileTransferInformation, error)
 	TransferURL(ctx context.Context, u string) (*url.URL, error)
+	InitiateFileTransferToGuest(ctx context.Context, auth types.BaseGuestAuthentication, guestFilePath string, fileAttributes types.BaseGuestFileAttributes, fileSize int64, overwrite bool) (string, error)
+	DeleteFile(ctx context.Context, auth types.BaseGuestAuthentication, filePath string) error
+	MakeDirectory(ctx context.Context, auth types.BaseGuestAuthentication, directoryPath string, createParentDirectories bool) error
 }
 
 //counterfeiter:generate . DownloadClient
 type DownloadClient interface {
 	Download(ctx context.Context, u *url.URL, param *soap.Download) (io.ReadCloser, int64, error)
+	Upload(ctx context.Context, f io.Reader, u *url.URL, param *soap.Upload) error
 }
 
 type GuestManager struct {
@@ -70,7 +75,11 @@ func (g *GuestManager) ExitCodeForProgramInGuest(ctx context.Context, pid int64)
 		}
 
 		if procs[0].EndTime == nil {
-			<-time.After(time.Millisecond * 250)
+			select {
+			case <-ctx.Done():
+				return -1, fmt.Errorf("vcenter_client - could not observe program exiting: %s", ctx.Err())
+			case <-time.After(time.Millisecond * 250):
+			}
 			continue
 		}
 
@@ -78,6 +87,15 @@ func (g *GuestManager) ExitCodeForProgramInGuest(ctx context.Context, pid int64)
 	}
 }
 
+func (g *GuestManager) TerminateProgramInGuest(ctx context.Context, pid int64) error {
+	err := g.processManager.TerminateProcess(ctx, &g.auth, pid)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - could not terminate program: %s", err.Error())
+	}
+
+	return nil
+}
+
 func (g *GuestManager) DownloadFileInGuest(ctx context.Context, path string) (io.Reader, int64, error) {
 	info, err := g.fileManager.InitiateFileTransferFromGuest(ctx, &g.auth, path)
 	if err != nil {
@@ -98,3 +116,49 @@ func (g *GuestManager) DownloadFileInGuest(ctx context.Context, path string) (io
 
 	return f, n, nil
 }
+
+func (g *GuestManager) UploadFileInGuest(ctx context.Context, path string, src io.Reader, size int64) error {
+	transferURL, err := g.fileManager.InitiateFileTransferToGuest(ctx, &g.auth, path, &types.GuestWindowsFileAttributes{}, size, true)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - unable to upload file: %s", err.Error())
+	}
+
+	u, err := g.fileManager.TransferURL(ctx, transferURL)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - unable to upload file: %s", err.Error())
+	}
+
+	p := soap.DefaultUpload
+	p.ContentLength = size
+
+	err = g.client.Upload(ctx, src, u, &p)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - unable to upload file: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (g *GuestManager) DeleteFileInGuest(ctx context.Context, path string) error {
+	err := g.fileManager.DeleteFile(ctx, &g.auth, path)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - unable to delete file: %s", err.Error())
+	}
+
+	return nil
+}
+
+// MakeDirectoryInGuest creates path and any missing parents, like mkdir -p it succeeds if path already exists
+func (g *GuestManager) MakeDirectoryInGuest(ctx context.Context, path string) error {
+	err := g.fileManager.MakeDirectory(ctx, &g.auth, path, true)
+	if err != nil && soap.IsSoapFault(err) {
+		if _, ok := soap.ToSoapFault(err).VimFault().(types.FileAlreadyExists); ok {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("vcenter_client - unable to create directory: %s", err.Error())
+	}
+
+	return nil
+}
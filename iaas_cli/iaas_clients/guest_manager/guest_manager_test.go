@@ -3,10 +3,12 @@ package guest_manager_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/guest_manager"
@@ -79,6 +81,33 @@ var _ = Describe("GuestManager", func() {
 			_, err := guestManager.ExitCodeForProgramInGuest(ctx, 1000)
 			Expect(err).To(MatchError("vcenter_client - could not observe program exiting"))
 		})
+
+		It("stops waiting for the program when the context is done", func() {
+			procManager.ListProcessesReturns([]types.GuestProcessInfo{{}}, nil)
+			timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+
+			_, err := guestManager.ExitCodeForProgramInGuest(timeoutCtx, 1000)
+			Expect(err).To(MatchError("vcenter_client - could not observe program exiting: context deadline exceeded"))
+		})
+	})
+
+	Describe("TerminateProgramInGuest", func() {
+		It("terminates the program on the guest", func() {
+			err := guestManager.TerminateProgramInGuest(ctx, 1000)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(procManager.TerminateProcessCallCount()).To(Equal(1))
+			_, _, pid := procManager.TerminateProcessArgsForCall(0)
+			Expect(pid).To(Equal(int64(1000)))
+		})
+
+		It("returns an error if TerminateProcess does", func() {
+			procManager.TerminateProcessReturns(errors.New("no such process"))
+
+			err := guestManager.TerminateProgramInGuest(ctx, 1000)
+			Expect(err).To(MatchError("vcenter_client - could not terminate program: no such process"))
+		})
 	})
 
 	Describe("DownloadFileInGuest", func() {
@@ -118,4 +147,86 @@ var _ = Describe("GuestManager", func() {
 			Expect(client.DownloadCallCount()).To(Equal(1))
 		})
 	})
+
+	Describe("UploadFileInGuest", func() {
+		It("returns an error if InitiateFileTransferToGuest fails", func() {
+			fileManager.InitiateFileTransferToGuestReturns("", errors.New("couldn't initiate file transfer :("))
+
+			err := guestManager.UploadFileInGuest(context.TODO(), "MYPATH", strings.NewReader("contents"), 8)
+			Expect(err).To(MatchError("vcenter_client - unable to upload file: couldn't initiate file transfer :("))
+		})
+
+		It("returns an error if Upload fails", func() {
+			client.UploadReturns(errors.New("connection reset"))
+
+			err := guestManager.UploadFileInGuest(context.TODO(), "MYPATH", strings.NewReader("contents"), 8)
+			Expect(err).To(MatchError("vcenter_client - unable to upload file: connection reset"))
+		})
+
+		It("successfully uploads file, overwriting any existing file", func() {
+			fileManager.InitiateFileTransferToGuestReturns("my.dude.edu", nil)
+			src := strings.NewReader("contents")
+
+			err := guestManager.UploadFileInGuest(context.TODO(), "C://PATH", src, 8)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, path, _, size, overwrite := fileManager.InitiateFileTransferToGuestArgsForCall(0)
+			Expect(path).To(Equal("C://PATH"))
+			Expect(size).To(Equal(int64(8)))
+			Expect(overwrite).To(BeTrue())
+
+			_, transferURL := fileManager.TransferURLArgsForCall(0)
+			Expect(transferURL).To(Equal("my.dude.edu"))
+			_, uploaded, _, param := client.UploadArgsForCall(0)
+			Expect(uploaded).To(Equal(src))
+			Expect(param.ContentLength).To(Equal(int64(8)))
+		})
+	})
+
+	Describe("DeleteFileInGuest", func() {
+		It("deletes the file", func() {
+			err := guestManager.DeleteFileInGuest(context.TODO(), "C://PATH")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, path := fileManager.DeleteFileArgsForCall(0)
+			Expect(path).To(Equal("C://PATH"))
+		})
+
+		It("returns an error if DeleteFile does", func() {
+			fileManager.DeleteFileReturns(errors.New("file in use"))
+
+			err := guestManager.DeleteFileInGuest(context.TODO(), "C://PATH")
+			Expect(err).To(MatchError("vcenter_client - unable to delete file: file in use"))
+		})
+	})
+
+	Describe("MakeDirectoryInGuest", func() {
+		It("creates the directory and its parents", func() {
+			err := guestManager.MakeDirectoryInGuest(context.TODO(), "C:\\provision\\logs")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, path, createParents := fileManager.MakeDirectoryArgsForCall(0)
+			Expect(path).To(Equal("C:\\provision\\logs"))
+			Expect(createParents).To(BeTrue())
+		})
+
+		It("succeeds if the directory already exists", func() {
+			fileManager.MakeDirectoryReturns(soap.WrapSoapFault(&soap.Fault{
+				String: "file already exists",
+				Detail: struct {
+					Fault types.AnyType `xml:",any,typeattr"`
+				}{Fault: types.FileAlreadyExists{}},
+			}))
+
+			err := guestManager.MakeDirectoryInGuest(context.TODO(), "C:\\provision")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error with the vSphere fault", func() {
+			fileManager.MakeDirectoryReturns(errors.New("ServerFaultCode: Permission to perform this operation was denied."))
+
+			err := guestManager.MakeDirectoryInGuest(context.TODO(), "C:\\provision")
+			Expect(err).To(MatchError("vcenter_client - unable to create directory: ServerFaultCode: Permission to perform this operation was denied."))
+		})
+	})
 })
@@ -11,6 +11,19 @@ import (
 )
 
 type FakeFileManager struct {
+	DeleteFileStub        func(context.Context, types.BaseGuestAuthentication, string) error
+	deleteFileMutex       sync.RWMutex
+	deleteFileArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 string
+	}
+	deleteFileReturns struct {
+		result1 error
+	}
+	deleteFileReturnsOnCall map[int]struct {
+		result1 error
+	}
 	InitiateFileTransferFromGuestStub        func(context.Context, types.BaseGuestAuthentication, string) (*types.FileTransferInformation, error)
 	initiateFileTransferFromGuestMutex       sync.RWMutex
 	initiateFileTransferFromGuestArgsForCall []struct {
@@ -26,6 +39,38 @@ type FakeFileManager struct {
 		result1 *types.FileTransferInformation
 		result2 error
 	}
+	InitiateFileTransferToGuestStub        func(context.Context, types.BaseGuestAuthentication, string, types.BaseGuestFileAttributes, int64, bool) (string, error)
+	initiateFileTransferToGuestMutex       sync.RWMutex
+	initiateFileTransferToGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 string
+		arg4 types.BaseGuestFileAttributes
+		arg5 int64
+		arg6 bool
+	}
+	initiateFileTransferToGuestReturns struct {
+		result1 string
+		result2 error
+	}
+	initiateFileTransferToGuestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	MakeDirectoryStub        func(context.Context, types.BaseGuestAuthentication, string, bool) error
+	makeDirectoryMutex       sync.RWMutex
+	makeDirectoryArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 string
+		arg4 bool
+	}
+	makeDirectoryReturns struct {
+		result1 error
+	}
+	makeDirectoryReturnsOnCall map[int]struct {
+		result1 error
+	}
 	TransferURLStub        func(context.Context, string) (*url.URL, error)
 	transferURLMutex       sync.RWMutex
 	transferURLArgsForCall []struct {
@@ -44,6 +89,69 @@ type FakeFileManager struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeFileManager) DeleteFile(arg1 context.Context, arg2 types.BaseGuestAuthentication, arg3 string) error {
+	fake.deleteFileMutex.Lock()
+	ret, specificReturn := fake.deleteFileReturnsOnCall[len(fake.deleteFileArgsForCall)]
+	fake.deleteFileArgsForCall = append(fake.deleteFileArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteFileStub
+	fakeReturns := fake.deleteFileReturns
+	fake.recordInvocation("DeleteFile", []interface{}{arg1, arg2, arg3})
+	fake.deleteFileMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFileManager) DeleteFileCallCount() int {
+	fake.deleteFileMutex.RLock()
+	defer fake.deleteFileMutex.RUnlock()
+	return len(fake.deleteFileArgsForCall)
+}
+
+func (fake *FakeFileManager) DeleteFileCalls(stub func(context.Context, types.BaseGuestAuthentication, string) error) {
+	fake.deleteFileMutex.Lock()
+	defer fake.deleteFileMutex.Unlock()
+	fake.DeleteFileStub = stub
+}
+
+func (fake *FakeFileManager) DeleteFileArgsForCall(i int) (context.Context, types.BaseGuestAuthentication, string) {
+	fake.deleteFileMutex.RLock()
+	defer fake.deleteFileMutex.RUnlock()
+	argsForCall := fake.deleteFileArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeFileManager) DeleteFileReturns(result1 error) {
+	fake.deleteFileMutex.Lock()
+	defer fake.deleteFileMutex.Unlock()
+	fake.DeleteFileStub = nil
+	fake.deleteFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFileManager) DeleteFileReturnsOnCall(i int, result1 error) {
+	fake.deleteFileMutex.Lock()
+	defer fake.deleteFileMutex.Unlock()
+	fake.DeleteFileStub = nil
+	if fake.deleteFileReturnsOnCall == nil {
+		fake.deleteFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFileManager) InitiateFileTransferFromGuest(arg1 context.Context, arg2 types.BaseGuestAuthentication, arg3 string) (*types.FileTransferInformation, error) {
 	fake.initiateFileTransferFromGuestMutex.Lock()
 	ret, specificReturn := fake.initiateFileTransferFromGuestReturnsOnCall[len(fake.initiateFileTransferFromGuestArgsForCall)]
@@ -110,6 +218,139 @@ func (fake *FakeFileManager) InitiateFileTransferFromGuestReturnsOnCall(i int, r
 	}{result1, result2}
 }
 
+func (fake *FakeFileManager) InitiateFileTransferToGuest(arg1 context.Context, arg2 types.BaseGuestAuthentication, arg3 string, arg4 types.BaseGuestFileAttributes, arg5 int64, arg6 bool) (string, error) {
+	fake.initiateFileTransferToGuestMutex.Lock()
+	ret, specificReturn := fake.initiateFileTransferToGuestReturnsOnCall[len(fake.initiateFileTransferToGuestArgsForCall)]
+	fake.initiateFileTransferToGuestArgsForCall = append(fake.initiateFileTransferToGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 string
+		arg4 types.BaseGuestFileAttributes
+		arg5 int64
+		arg6 bool
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
+	stub := fake.InitiateFileTransferToGuestStub
+	fakeReturns := fake.initiateFileTransferToGuestReturns
+	fake.recordInvocation("InitiateFileTransferToGuest", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.initiateFileTransferToGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFileManager) InitiateFileTransferToGuestCallCount() int {
+	fake.initiateFileTransferToGuestMutex.RLock()
+	defer fake.initiateFileTransferToGuestMutex.RUnlock()
+	return len(fake.initiateFileTransferToGuestArgsForCall)
+}
+
+func (fake *FakeFileManager) InitiateFileTransferToGuestCalls(stub func(context.Context, types.BaseGuestAuthentication, string, types.BaseGuestFileAttributes, int64, bool) (string, error)) {
+	fake.initiateFileTransferToGuestMutex.Lock()
+	defer fake.initiateFileTransferToGuestMutex.Unlock()
+	fake.InitiateFileTransferToGuestStub = stub
+}
+
+func (fake *FakeFileManager) InitiateFileTransferToGuestArgsForCall(i int) (context.Context, types.BaseGuestAuthentication, string, types.BaseGuestFileAttributes, int64, bool) {
+	fake.initiateFileTransferToGuestMutex.RLock()
+	defer fake.initiateFileTransferToGuestMutex.RUnlock()
+	argsForCall := fake.initiateFileTransferToGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+}
+
+func (fake *FakeFileManager) InitiateFileTransferToGuestReturns(result1 string, result2 error) {
+	fake.initiateFileTransferToGuestMutex.Lock()
+	defer fake.initiateFileTransferToGuestMutex.Unlock()
+	fake.InitiateFileTransferToGuestStub = nil
+	fake.initiateFileTransferToGuestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFileManager) InitiateFileTransferToGuestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.initiateFileTransferToGuestMutex.Lock()
+	defer fake.initiateFileTransferToGuestMutex.Unlock()
+	fake.InitiateFileTransferToGuestStub = nil
+	if fake.initiateFileTransferToGuestReturnsOnCall == nil {
+		fake.initiateFileTransferToGuestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.initiateFileTransferToGuestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFileManager) MakeDirectory(arg1 context.Context, arg2 types.BaseGuestAuthentication, arg3 string, arg4 bool) error {
+	fake.makeDirectoryMutex.Lock()
+	ret, specificReturn := fake.makeDirectoryReturnsOnCall[len(fake.makeDirectoryArgsForCall)]
+	fake.makeDirectoryArgsForCall = append(fake.makeDirectoryArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 string
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.MakeDirectoryStub
+	fakeReturns := fake.makeDirectoryReturns
+	fake.recordInvocation("MakeDirectory", []interface{}{arg1, arg2, arg3, arg4})
+	fake.makeDirectoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFileManager) MakeDirectoryCallCount() int {
+	fake.makeDirectoryMutex.RLock()
+	defer fake.makeDirectoryMutex.RUnlock()
+	return len(fake.makeDirectoryArgsForCall)
+}
+
+func (fake *FakeFileManager) MakeDirectoryCalls(stub func(context.Context, types.BaseGuestAuthentication, string, bool) error) {
+	fake.makeDirectoryMutex.Lock()
+	defer fake.makeDirectoryMutex.Unlock()
+	fake.MakeDirectoryStub = stub
+}
+
+func (fake *FakeFileManager) MakeDirectoryArgsForCall(i int) (context.Context, types.BaseGuestAuthentication, string, bool) {
+	fake.makeDirectoryMutex.RLock()
+	defer fake.makeDirectoryMutex.RUnlock()
+	argsForCall := fake.makeDirectoryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeFileManager) MakeDirectoryReturns(result1 error) {
+	fake.makeDirectoryMutex.Lock()
+	defer fake.makeDirectoryMutex.Unlock()
+	fake.MakeDirectoryStub = nil
+	fake.makeDirectoryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFileManager) MakeDirectoryReturnsOnCall(i int, result1 error) {
+	fake.makeDirectoryMutex.Lock()
+	defer fake.makeDirectoryMutex.Unlock()
+	fake.MakeDirectoryStub = nil
+	if fake.makeDirectoryReturnsOnCall == nil {
+		fake.makeDirectoryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.makeDirectoryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFileManager) TransferURL(arg1 context.Context, arg2 string) (*url.URL, error) {
 	fake.transferURLMutex.Lock()
 	ret, specificReturn := fake.transferURLReturnsOnCall[len(fake.transferURLArgsForCall)]
@@ -178,8 +419,14 @@ func (fake *FakeFileManager) TransferURLReturnsOnCall(i int, result1 *url.URL, r
 func (fake *FakeFileManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deleteFileMutex.RLock()
+	defer fake.deleteFileMutex.RUnlock()
 	fake.initiateFileTransferFromGuestMutex.RLock()
 	defer fake.initiateFileTransferFromGuestMutex.RUnlock()
+	fake.initiateFileTransferToGuestMutex.RLock()
+	defer fake.initiateFileTransferToGuestMutex.RUnlock()
+	fake.makeDirectoryMutex.RLock()
+	defer fake.makeDirectoryMutex.RUnlock()
 	fake.transferURLMutex.RLock()
 	defer fake.transferURLMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
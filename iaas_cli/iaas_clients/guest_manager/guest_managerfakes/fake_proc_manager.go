@@ -51,6 +51,19 @@ type FakeProcManager struct {
 		result1 int64
 		result2 error
 	}
+	TerminateProcessStub        func(context.Context, types.BaseGuestAuthentication, int64) error
+	terminateProcessMutex       sync.RWMutex
+	terminateProcessArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 int64
+	}
+	terminateProcessReturns struct {
+		result1 error
+	}
+	terminateProcessReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -245,6 +258,69 @@ func (fake *FakeProcManager) StartProgramReturnsOnCall(i int, result1 int64, res
 	}{result1, result2}
 }
 
+func (fake *FakeProcManager) TerminateProcess(arg1 context.Context, arg2 types.BaseGuestAuthentication, arg3 int64) error {
+	fake.terminateProcessMutex.Lock()
+	ret, specificReturn := fake.terminateProcessReturnsOnCall[len(fake.terminateProcessArgsForCall)]
+	fake.terminateProcessArgsForCall = append(fake.terminateProcessArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.BaseGuestAuthentication
+		arg3 int64
+	}{arg1, arg2, arg3})
+	stub := fake.TerminateProcessStub
+	fakeReturns := fake.terminateProcessReturns
+	fake.recordInvocation("TerminateProcess", []interface{}{arg1, arg2, arg3})
+	fake.terminateProcessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProcManager) TerminateProcessCallCount() int {
+	fake.terminateProcessMutex.RLock()
+	defer fake.terminateProcessMutex.RUnlock()
+	return len(fake.terminateProcessArgsForCall)
+}
+
+func (fake *FakeProcManager) TerminateProcessCalls(stub func(context.Context, types.BaseGuestAuthentication, int64) error) {
+	fake.terminateProcessMutex.Lock()
+	defer fake.terminateProcessMutex.Unlock()
+	fake.TerminateProcessStub = stub
+}
+
+func (fake *FakeProcManager) TerminateProcessArgsForCall(i int) (context.Context, types.BaseGuestAuthentication, int64) {
+	fake.terminateProcessMutex.RLock()
+	defer fake.terminateProcessMutex.RUnlock()
+	argsForCall := fake.terminateProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProcManager) TerminateProcessReturns(result1 error) {
+	fake.terminateProcessMutex.Lock()
+	defer fake.terminateProcessMutex.Unlock()
+	fake.TerminateProcessStub = nil
+	fake.terminateProcessReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProcManager) TerminateProcessReturnsOnCall(i int, result1 error) {
+	fake.terminateProcessMutex.Lock()
+	defer fake.terminateProcessMutex.Unlock()
+	fake.TerminateProcessStub = nil
+	if fake.terminateProcessReturnsOnCall == nil {
+		fake.terminateProcessReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.terminateProcessReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProcManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -254,6 +330,8 @@ func (fake *FakeProcManager) Invocations() map[string][][]interface{} {
 	defer fake.listProcessesMutex.RUnlock()
 	fake.startProgramMutex.RLock()
 	defer fake.startProgramMutex.RUnlock()
+	fake.terminateProcessMutex.RLock()
+	defer fake.terminateProcessMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -1,216 +1,414 @@
 package iaas_clients
 
 import (
-	"encoding/json"
-	"errors"
+	"bytes"
+	"context"
+	"crypto/sha1"
 	"fmt"
+	"hash"
+	"io"
 	"net/url"
 	"os"
-	"regexp"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/cloudfoundry/stembuild/iaas_cli"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/guest"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/guest_manager"
 )
 
+// VcenterClient performs the vCenter operations needed by construct and package. It logs in on first use and
+// keeps its session, so it is safe for concurrent use.
 type VcenterClient struct {
-	Url           string
-	credentialUrl string
-	redactedUrl   string
-	caCertFile    string
-	Runner        iaas_cli.CliRunner
-}
+	Url         string
+	username    string
+	password    string
+	redactedUrl string
+	caCertFile  string
+	ctx         context.Context
 
-func NewVcenterClient(username string, password string, u string, caCertFile string, runner iaas_cli.CliRunner) *VcenterClient {
+	mu        sync.Mutex
+	vimClient *vim25.Client
+}
 
-	encodedUser := url.QueryEscape(username)
-	encodedPassword := url.QueryEscape(password)
-	urlWithCredentials := fmt.Sprintf("%s:%s@%s", encodedUser, encodedPassword, u)
-	urlWithRedactedPassword := fmt.Sprintf("%s:REDACTED@%s", encodedUser, u)
-	return &VcenterClient{Url: u, credentialUrl: urlWithCredentials, redactedUrl: urlWithRedactedPassword, caCertFile: caCertFile, Runner: runner}
+// NewVcenterClient returns a client whose vCenter calls, uploads and downloads all stop once ctx is done
+func NewVcenterClient(ctx context.Context, username string, password string, u string, caCertFile string) *VcenterClient {
+	urlWithRedactedPassword := fmt.Sprintf("%s:REDACTED@%s", url.QueryEscape(username), u)
+	return &VcenterClient{Url: u, username: username, password: password, redactedUrl: urlWithRedactedPassword, caCertFile: caCertFile, ctx: ctx}
 }
 
 func (c *VcenterClient) ValidateUrl() error {
-	args := []string{"about", "-u", c.Url}
 	errMsg := fmt.Sprintf("vcenter_client - unable to validate url: %s", c.Url)
 	if c.caCertFile != "" {
-		args = append(args, fmt.Sprintf("-tls-ca-certs=%s", c.caCertFile))
 		errMsg = fmt.Sprintf("vcenter_client - invalid ca certs or url: %s", c.Url)
 	}
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return errors.New(errMsg)
+
+	soapClient, err := c.soapClient()
+	if err != nil {
+		return fmt.Errorf("%s: %s", errMsg, err)
+	}
+
+	_, err = vim25.NewClient(c.ctx, soapClient)
+	if err != nil {
+		return fmt.Errorf("%s: %s", errMsg, err)
 	}
-	return nil
 
+	return nil
 }
 
 func (c *VcenterClient) ValidateCredentials() error {
-	args := c.buildGovcCommand("about")
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - invalid credentials for: %s", c.redactedUrl)
+	_, err := c.client()
+	if err != nil {
+		return fmt.Errorf("vcenter_client - invalid credentials for: %s: %s", c.redactedUrl, err)
 	}
 
 	return nil
 }
 
 func (c *VcenterClient) FindVM(vmInventoryPath string) error {
-	args := c.buildGovcCommand("find", "-maxdepth=0", vmInventoryPath)
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - unable to find VM: %s. Ensure your inventory path is formatted properly and includes \"vm\" in its path, example: /my-datacenter/vm/my-folder/my-vm-name", vmInventoryPath)
+	_, err := c.findVM(vmInventoryPath)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - unable to find VM: %s. Ensure your inventory path is formatted properly and includes \"vm\" in its path, example: /my-datacenter/vm/my-folder/my-vm-name: %s", vmInventoryPath, err)
 	}
 
 	return nil
 }
 
 func (c *VcenterClient) ListDevices(vmInventoryPath string) ([]string, error) {
-	args := c.buildGovcCommand("device.ls", "-vm", vmInventoryPath)
-	o, exitCode, err := c.Runner.RunWithOutput(args)
+	devices, _, err := c.devices(vmInventoryPath)
+	if err != nil {
+		return []string{}, fmt.Errorf("vcenter_client - failed to list devices in vCenter: %s", err)
+	}
 
-	if exitCode != 0 {
-		return []string{}, fmt.Errorf("vcenter_client - failed to list devices in vCenter, govc exit code %d", exitCode)
+	names := []string{}
+	for _, device := range devices {
+		names = append(names, devices.Name(device))
 	}
+	return names, nil
+}
 
+func (c *VcenterClient) RemoveDevice(vmInventoryPath string, deviceName string) error {
+	devices, vm, err := c.devices(vmInventoryPath)
 	if err != nil {
-		return []string{}, fmt.Errorf("vcenter_client - failed to parse list of devices. Err: %s", err)
+		return fmt.Errorf("vcenter_client - %s could not be removed: %s", deviceName, err)
 	}
 
-	entries := strings.Split(o, "\n")
-	devices := []string{}
-	r, _ := regexp.Compile(`\S+`)
-	for _, entry := range entries {
-		if entry != "" {
-			devices = append(devices, r.FindString(entry))
-		}
+	device := devices.Find(deviceName)
+	if device == nil {
+		return fmt.Errorf("vcenter_client - %s could not be removed: device not found", deviceName)
 	}
-	return devices, nil
-}
-func (c *VcenterClient) RemoveDevice(vmInventoryPath string, deviceName string) error {
-	args := c.buildGovcCommand("device.remove", "-vm", vmInventoryPath, deviceName)
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - %s could not be removed", deviceName)
+
+	err = vm.RemoveDevice(c.ctx, false, device)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be removed: %s", deviceName, err)
 	}
 	return nil
 }
 
 func (c *VcenterClient) EjectCDRom(vmInventoryPath string, deviceName string) error {
+	devices, vm, err := c.devices(vmInventoryPath)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be ejected: %s", deviceName, err)
+	}
 
-	args := c.buildGovcCommand("device.cdrom.eject", "-vm", vmInventoryPath, "-device", deviceName)
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - %s could not be ejected", deviceName)
+	cdrom, err := devices.FindCdrom(deviceName)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be ejected: %s", deviceName, err)
+	}
+
+	err = vm.EditDevice(c.ctx, devices.EjectIso(cdrom))
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be ejected: %s", deviceName, err)
 	}
 	return nil
 }
 
+// ExportVM writes the disks of the VM, an OVF descriptor and a SHA1 manifest to <destination>/<vm name>
 func (c *VcenterClient) ExportVM(vmInventoryPath string, destination string) error {
 	_, err := os.Stat(destination)
 	if err != nil {
 		return fmt.Errorf("vcenter_client - provided destination directory: %s does not exist", destination)
 	}
-	args := c.buildGovcCommand("export.ovf", "-sha", "1", "-vm", vmInventoryPath, destination)
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - %s could not be exported", vmInventoryPath)
+
+	vm, err := c.findVM(vmInventoryPath)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be exported: %s", vmInventoryPath, err)
+	}
+
+	err = c.exportOVF(vm, filepath.Join(destination, vm.Name()))
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be exported: %s", vmInventoryPath, err)
 	}
 	return nil
 }
 
+func (c *VcenterClient) exportOVF(vm *object.VirtualMachine, dest string) error {
+	name := vm.Name()
+
+	err := os.MkdirAll(dest, 0750)
+	if err != nil {
+		return err
+	}
+
+	lease, err := vm.Export(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	info, err := lease.Wait(c.ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	updater := lease.StartUpdater(c.ctx, info)
+	defer updater.Done()
+
+	manifest := new(bytes.Buffer)
+	descriptorParams := types.OvfCreateDescriptorParams{
+		Name: name,
+	}
+
+	for _, item := range info.Items {
+		if filepath.Ext(item.Path) != ".vmdk" {
+			continue
+		}
+		if !strings.HasPrefix(item.Path, name) {
+			item.Path = name + "-" + item.Path
+		}
+
+		h := sha1.New()
+		err = lease.DownloadFile(c.ctx, filepath.Join(dest, item.Path), item, soap.Download{Writer: h})
+		if err != nil {
+			return err
+		}
+		addManifestEntry(manifest, item.Path, h)
+
+		descriptorParams.OvfFiles = append(descriptorParams.OvfFiles, item.File())
+	}
+
+	err = lease.Complete(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	descriptor, err := ovf.NewManager(vm.Client()).CreateDescriptor(c.ctx, vm, descriptorParams)
+	if err != nil {
+		return err
+	}
+
+	h := sha1.New()
+	err = writeFile(filepath.Join(dest, name+".ovf"), io.TeeReader(strings.NewReader(descriptor.OvfDescriptor), h))
+	if err != nil {
+		return err
+	}
+	addManifestEntry(manifest, name+".ovf", h)
+
+	return writeFile(filepath.Join(dest, name+".mf"), manifest)
+}
+
+func addManifestEntry(manifest io.Writer, path string, h hash.Hash) {
+	_, _ = fmt.Fprintf(manifest, "SHA1(%s)= %x\n", path, h.Sum(nil))
+}
+
+func writeFile(path string, r io.Reader) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, r)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
 func (c *VcenterClient) UploadArtifact(vmInventoryPath, artifact, destination, username, password string) error {
-	vmCredentials := fmt.Sprintf("%s:%s", username, password)
-	args := c.buildGovcCommand("guest.upload", "-f", "-l", vmCredentials, "-vm", vmInventoryPath, artifact, destination)
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - %s could not be uploaded", artifact)
+	guestManager, err := c.guestManager(vmInventoryPath, username, password)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be uploaded: %s", artifact, err)
+	}
+
+	file, err := os.Open(artifact)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be uploaded: %s", artifact, err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be uploaded: %s", artifact, err)
+	}
+
+	err = guestManager.UploadFileInGuest(c.ctx, destination, file, fileInfo.Size())
+	if err != nil {
+		return fmt.Errorf("vcenter_client - %s could not be uploaded: %s", artifact, err)
 	}
 	return nil
 }
 
 func (c *VcenterClient) MakeDirectory(vmInventoryPath, path, username, password string) error {
-	vmCredentials := fmt.Sprintf("%s:%s", username, password)
+	guestManager, err := c.guestManager(vmInventoryPath, username, password)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - directory `%s` could not be created: %s", path, err)
+	}
 
-	args := c.buildGovcCommand("guest.mkdir", "-l", vmCredentials, "-vm", vmInventoryPath, "-p", path)
-	errCode := c.Runner.Run(args)
-	if errCode != 0 {
-		return fmt.Errorf("vcenter_client - directory `%s` could not be created", path)
+	err = guestManager.MakeDirectoryInGuest(c.ctx, path)
+	if err != nil {
+		return fmt.Errorf("vcenter_client - directory `%s` could not be created: %s", path, err)
 	}
 	return nil
 }
 
 func (c *VcenterClient) Start(vmInventoryPath, username, password, command string, args ...string) (string, error) {
-	vmCredentials := fmt.Sprintf("%s:%s", username, password)
-
-	cmdArgs := c.buildGovcCommand(append([]string{"guest.start", "-l", vmCredentials, "-vm", vmInventoryPath, command}, args...)...)
-	pid, exitCode, err := c.Runner.RunWithOutput(cmdArgs)
+	guestManager, err := c.guestManager(vmInventoryPath, username, password)
 	if err != nil {
 		return "", fmt.Errorf("vcenter_client - failed to run '%s': %s", command, err)
 	}
-	if exitCode != 0 {
-		return "", fmt.Errorf("vcenter_client - '%s' returned exit code: %d", command, exitCode)
-	}
-	// We trim this suffix since govc outputs the pid with an '\n' in the output
-	return strings.TrimSuffix(pid, "\n"), nil
-}
 
-type govcPS struct {
-	ProcessInfo []struct {
-		Name      string
-		Pid       int
-		Owner     string
-		CmdLine   string
-		StartTime string
-		EndTime   string
-		ExitCode  int
+	pid, err := guestManager.StartProgramInGuest(c.ctx, command, strings.Join(args, " "))
+	if err != nil {
+		return "", fmt.Errorf("vcenter_client - failed to run '%s': %s", command, err)
 	}
+
+	return strconv.FormatInt(pid, 10), nil
 }
 
 func (c *VcenterClient) WaitForExit(vmInventoryPath, username, password, pid string) (int, error) {
-	vmCredentials := fmt.Sprintf("%s:%s", username, password)
-	args := c.buildGovcCommand("guest.ps", "-l", vmCredentials, "-vm", vmInventoryPath, "-p", pid, "-X", "-json")
-	output, exitCode, err := c.Runner.RunWithOutput(args)
+	processID, err := strconv.ParseInt(pid, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("vcenter_client - invalid PID %s: %s", pid, err)
+	}
+
+	guestManager, err := c.guestManager(vmInventoryPath, username, password)
 	if err != nil {
 		return 0, fmt.Errorf("vcenter_client - failed to fetch exit code for PID %s: %s", pid, err)
 	}
-	if exitCode != 0 {
-		return 0, fmt.Errorf("vcenter_client - fetching PID %s returned with exit code: %d", pid, exitCode)
+
+	exitCode, err := guestManager.ExitCodeForProgramInGuest(c.ctx, processID)
+	if err != nil {
+		return 0, fmt.Errorf("vcenter_client - failed to fetch exit code for PID %s: %s", pid, err)
 	}
 
-	ps := govcPS{}
-	err = json.Unmarshal([]byte(output), &ps)
+	return int(exitCode), nil
+}
+
+func (c *VcenterClient) IsPoweredOff(vmInventoryPath string) (bool, error) {
+	vm, err := c.findVM(vmInventoryPath)
 	if err != nil {
-		return 0, fmt.Errorf("vcenter_client - received bad JSON output for PID %s: %s", pid, output)
+		return false, fmt.Errorf("vcenter_client - failed to get vm info: %s", err)
 	}
-	if len(ps.ProcessInfo) != 1 {
-		return 0, fmt.Errorf("vcenter_client - couldn't get exit code for PID %s", pid)
+
+	powerState, err := vm.PowerState(c.ctx)
+	if err != nil {
+		return false, fmt.Errorf("vcenter_client - failed to determine vm power state: %s", err)
 	}
 
-	return ps.ProcessInfo[0].ExitCode, nil
+	return powerState == types.VirtualMachinePowerStatePoweredOff, nil
 }
 
-func (c *VcenterClient) buildGovcCommand(args ...string) []string {
-	commonArgs := []string{"-u", c.credentialUrl}
+func (c *VcenterClient) soapClient() (*soap.Client, error) {
+	vCenterURL, err := soap.ParseURL(c.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	soapClient := soap.NewClient(vCenterURL, false)
 	if c.caCertFile != "" {
-		commonArgs = append(commonArgs, fmt.Sprintf("-tls-ca-certs=%s", c.caCertFile))
+		err = soapClient.SetRootCAs(c.caCertFile)
+		if err != nil {
+			return nil, err
+		}
 	}
-	args = append(args[:1], append(commonArgs, args[1:]...)...)
-	return args
+
+	return soapClient, nil
 }
 
-func (c *VcenterClient) IsPoweredOff(vmInventoryPath string) (bool, error) {
-	args := c.buildGovcCommand("vm.info", vmInventoryPath)
-	out, exitCode, err := c.Runner.RunWithOutput(args)
-	if exitCode != 0 {
-		return false, fmt.Errorf("vcenter_client - failed to get vm info, govc exit code: %d", exitCode)
+// client returns a logged in client, logging in on the first call
+func (c *VcenterClient) client() (*vim25.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.vimClient != nil {
+		return c.vimClient, nil
 	}
+
+	soapClient, err := c.soapClient()
 	if err != nil {
-		return false, fmt.Errorf("vcenter_client - failed to determine vm power state: %s", err)
+		return nil, err
+	}
+
+	vimClient, err := vim25.NewClient(c.ctx, soapClient)
+	if err != nil {
+		return nil, err
+	}
+	vimClient.RoundTripper = session.KeepAlive(vimClient.RoundTripper, 10*time.Minute)
+
+	err = session.NewManager(vimClient).Login(c.ctx, url.UserPassword(c.username, c.password))
+	if err != nil {
+		return nil, err
 	}
 
-	if strings.Contains(out, "poweredOff") {
-		return true, nil
+	c.vimClient = vimClient
+	return vimClient, nil
+}
+
+func (c *VcenterClient) findVM(vmInventoryPath string) (*object.VirtualMachine, error) {
+	vimClient, err := c.client()
+	if err != nil {
+		return nil, err
 	}
 
-	return false, nil
+	return find.NewFinder(vimClient, false).VirtualMachine(c.ctx, vmInventoryPath)
+}
+
+func (c *VcenterClient) devices(vmInventoryPath string) (object.VirtualDeviceList, *object.VirtualMachine, error) {
+	vm, err := c.findVM(vmInventoryPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	devices, err := vm.Device(c.ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return devices, vm, nil
+}
+
+func (c *VcenterClient) guestManager(vmInventoryPath, username, password string) (*guest_manager.GuestManager, error) {
+	vm, err := c.findVM(vmInventoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	opsManager := guest.NewOperationsManager(vm.Client(), vm.Reference())
+
+	processManager, err := opsManager.ProcessManager(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fileManager, err := opsManager.FileManager(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := types.NamePasswordAuthentication{
+		Username: username,
+		Password: password,
+	}
+	return guest_manager.NewGuestManager(auth, processManager, fileManager, vm.Client()), nil
 }
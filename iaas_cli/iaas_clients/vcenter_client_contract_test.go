@@ -8,7 +8,6 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/cloudfoundry/stembuild/iaas_cli"
 	vcenterclientfactory "github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/factory"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -86,13 +85,12 @@ var _ = Describe("VcenterManager", func() {
 			})
 		})
 
-		Context("govc_cli client login", func() {
+		Context("VcenterClient login", func() {
 			It("Succeeds", func() {
 				if runtime.GOOS == "windows" {
 					Skip("windows cannot run a vcsim server")
 				}
-				runner := &iaas_cli.GovcRunner{}
-				client := NewVcenterClient(vCenterUsername, vCenterPassword, vCenterUrl, certPath, runner)
+				client := NewVcenterClient(context.Background(), vCenterUsername, vCenterPassword, vCenterUrl, certPath)
 
 				err := client.ValidateCredentials()
 				Expect(err).NotTo(HaveOccurred())
@@ -1,470 +1,237 @@
 package iaas_clients
 
 import (
-	"errors"
+	"context"
+	"crypto/sha1"
 	"fmt"
-
-	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clifakes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("VcenterClient", func() {
+	const vmPath = "/DC0/vm/DC0_H0_VM0"
+
 	var (
-		runner                  *iaas_clifakes.FakeCliRunner
-		username, password, url string
-		vcenterClient           *VcenterClient
-		credentialUrl           string
-		caCertFile              string
+		cmd           *exec.Cmd
+		vcenterUrl    string
+		certPath      string
+		vcenterClient *VcenterClient
 	)
 
 	BeforeEach(func() {
-		runner = &iaas_clifakes.FakeCliRunner{}
-		username, password, caCertFile, url = "username", "password", "", "url"
-		vcenterClient = NewVcenterClient(username, password, url, caCertFile, runner)
-		credentialUrl = fmt.Sprintf("%s:%s@%s", username, password, url)
-	})
+		if runtime.GOOS == "windows" {
+			Skip("windows cannot run a vcsim server")
+		}
 
-	Context("NewVcenterClient", func() {
-		It("url encodes credentials with special characters", func() {
-			client := NewVcenterClient(`special\chars!user#`, `special^chars*pass`, url, caCertFile, runner)
+		workingDir, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		certPath = filepath.Join(workingDir, "fixtures", "dummycert")
+		keyPath := filepath.Join(workingDir, "fixtures", "dummykey")
 
-			urlEncodedCredentials := `special%5Cchars%21user%23:special%5Echars%2Apass@url`
-			expectedArgs := []string{"about", "-u", urlEncodedCredentials}
+		vcsimBinary := filepath.Join(os.Getenv("GOPATH"), "bin", "vcsim")
 
-			runner.RunReturns(0)
-			err := client.ValidateCredentials()
-			argsForRun := runner.RunArgsForCall(0)
+		vcenterUrl = "127.0.0.1:8990/sdk"
+		cmd = exec.Command(vcsimBinary, "-l", "127.0.0.1:8990", "-username", "user", "-password", "pass", "-tlscert", certPath, "-tlskey", keyPath)
 
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-		})
-	})
-
-	Context("A ca cert file is specified", func() {
-		It("Passes the ca cert to govc", func() {
-			vcenterClient = NewVcenterClient(username, password, url, "somefile.txt", runner)
-			expectedArgs := []string{"about", "-u", credentialUrl, "-tls-ca-certs=somefile.txt"}
+		err = cmd.Start()
+		Expect(err).ToNot(HaveOccurred())
 
-			runner.RunReturns(0)
-			err := vcenterClient.ValidateCredentials()
-			argsForRun := runner.RunArgsForCall(0)
+		time.Sleep(3 * time.Second) // the vcsim server needs a moment to come up
 
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-		})
+		vcenterClient = NewVcenterClient(context.Background(), "user", "pass", vcenterUrl, certPath)
 	})
 
-	Context("ValidateCredentials", func() {
-		It("When the login credentials are correct, login is successful", func() {
-			expectedArgs := []string{"about", "-u", credentialUrl}
-
-			runner.RunReturns(0)
-			err := vcenterClient.ValidateCredentials()
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-		})
-
-		It("When the login credentials are incorrect, login is a failure", func() {
-			expectedArgs := []string{"about", "-u", credentialUrl}
-
-			runner.RunReturns(1)
-			err := vcenterClient.ValidateCredentials()
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(HaveOccurred())
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-			Expect(err).To(MatchError("vcenter_client - invalid credentials for: username:REDACTED@url"))
-		})
+	AfterEach(func() {
+		if cmd != nil && cmd.Process != nil {
+			Expect(cmd.Process.Kill()).To(Succeed())
+			_ = cmd.Wait()
+		}
 	})
 
-	Context("validateUrl", func() {
-		It("When the url is valid, there is no error", func() {
-			expectedArgs := []string{"about", "-u", url}
-
-			runner.RunReturns(0)
-			err := vcenterClient.ValidateUrl()
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-		})
-
-		It("When the url is invalid, there is an error", func() {
-			expectedArgs := []string{"about", "-u", url}
-
-			runner.RunReturns(1)
-			err := vcenterClient.ValidateUrl()
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(HaveOccurred())
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-			Expect(err).To(MatchError("vcenter_client - unable to validate url: url"))
+	Context("ValidateUrl", func() {
+		It("succeeds when the url is valid and the certificate is trusted", func() {
+			Expect(vcenterClient.ValidateUrl()).To(Succeed())
 		})
 
-		It("a validateUrl failure mentions the ca cert if one was specified", func() {
+		It("mentions the ca cert when the certificate is not trusted", func() {
+			vcenterClient = NewVcenterClient(context.Background(), "user", "pass", vcenterUrl, filepath.Join("fixtures", "fakecert"))
 
-			vcenterClient = NewVcenterClient(username, password, url, "somefile.txt", runner)
-			expectedArgs := []string{"about", "-u", url, "-tls-ca-certs=somefile.txt"}
-
-			runner.RunReturns(1)
 			err := vcenterClient.ValidateUrl()
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(HaveOccurred())
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-			Expect(err).To(MatchError("vcenter_client - invalid ca certs or url: url"))
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - invalid ca certs or url: 127.0.0.1:8990/sdk: ")))
 		})
 
-		It("passes the ca cert to govc when specified", func() {
+		It("returns the reason the url is invalid", func() {
+			vcenterClient = NewVcenterClient(context.Background(), "user", "pass", "127.0.0.1:1/sdk", "")
 
-			vcenterClient = NewVcenterClient(username, password, url, "somefile.txt", runner)
-			expectedArgs := []string{"about", "-u", url, "-tls-ca-certs=somefile.txt"}
-
-			runner.RunReturns(0)
 			err := vcenterClient.ValidateUrl()
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
+			Expect(err).To(MatchError(ContainSubstring("vcenter_client - unable to validate url: 127.0.0.1:1/sdk: ")))
+			Expect(err).To(MatchError(ContainSubstring("connection refused")))
 		})
 	})
 
-	Context("FindVM", func() {
-		It("If the VM path is valid, and the VM is found", func() {
-			expectedArgs := []string{"find", "-u", credentialUrl, "-maxdepth=0", "validVMPath"}
-			runner.RunReturns(0)
-			err := vcenterClient.FindVM("validVMPath")
-			argsForRun := runner.RunArgsForCall(0)
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
+	Context("ValidateCredentials", func() {
+		It("succeeds when the credentials are correct", func() {
+			Expect(vcenterClient.ValidateCredentials()).To(Succeed())
 		})
 
-		It("If the VM path is invalid", func() {
-			expectedArgs := []string{"find", "-u", credentialUrl, "-maxdepth=0", "invalidVMPath"}
-			runner.RunReturns(1)
-			err := vcenterClient.FindVM("invalidVMPath")
-			argsForRun := runner.RunArgsForCall(0)
+		It("redacts the password and includes the vSphere fault when the credentials are incorrect", func() {
+			vcenterClient = NewVcenterClient(context.Background(), `special\chars!user#`, "wrong", vcenterUrl, certPath)
 
-			Expect(err).To(HaveOccurred())
-			Expect(runner.RunCallCount()).To(Equal(1))
-			Expect(argsForRun).To(Equal(expectedArgs))
-			Expect(err).To(MatchError("vcenter_client - unable to find VM: invalidVMPath. Ensure your inventory path is formatted properly and includes \"vm\" in its path, example: /my-datacenter/vm/my-folder/my-vm-name"))
+			err := vcenterClient.ValidateCredentials()
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - invalid credentials for: special%5Cchars%21user%23:REDACTED@127.0.0.1:8990/sdk: ")))
+			Expect(err).To(MatchError(ContainSubstring("Login failure")))
+			Expect(err.Error()).NotTo(ContainSubstring("wrong"))
 		})
 	})
 
-	Describe("RemoveDevice", func() {
-		It("Removes a device from the given VM", func() {
-			runner.RunReturns(0)
-			err := vcenterClient.RemoveDevice("validVMPath", "device")
-
-			Expect(err).To(Not(HaveOccurred()))
-			expectedArgs := []string{"device.remove", "-u", credentialUrl, "-vm", "validVMPath", "device"}
-			Expect(runner.RunArgsForCall(0)).To(Equal(expectedArgs))
+	Context("FindVM", func() {
+		It("finds the VM", func() {
+			Expect(vcenterClient.FindVM(vmPath)).To(Succeed())
 		})
 
-		It("Returns an error if VCenter reports a failure removing a device", func() {
-			runner.RunReturns(1)
-			err := vcenterClient.RemoveDevice("VMPath", "deviceName")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - deviceName could not be removed"))
+		It("returns an error explaining the inventory path format", func() {
+			err := vcenterClient.FindVM("/DC0/vm/missing")
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - unable to find VM: /DC0/vm/missing. Ensure your inventory path is formatted properly")))
+			Expect(err).To(MatchError(ContainSubstring("not found")))
 		})
-	})
-
-	Describe("EjectCDrom", func() {
-		It("Ejects a cd rom from the given VM", func() {
-			runner.RunReturns(0)
-			err := vcenterClient.EjectCDRom("validVMPath", "deviceName")
 
-			Expect(err).To(Not(HaveOccurred()))
-			expectedArgs := []string{"device.cdrom.eject", "-u", credentialUrl, "-vm", "validVMPath", "-device", "deviceName"}
-			Expect(runner.RunArgsForCall(0)).To(Equal(expectedArgs))
-		})
+		It("stops calling vCenter once its context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			vcenterClient = NewVcenterClient(ctx, "user", "pass", vcenterUrl, certPath)
+			Expect(vcenterClient.FindVM(vmPath)).To(Succeed())
 
-		It("Returns an error if VCenter reports a failure ejecting the cd rom", func() {
-			runner.RunReturns(1)
-			err := vcenterClient.EjectCDRom("VMPath", "deviceName")
+			cancel()
 
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - deviceName could not be ejected"))
+			Expect(vcenterClient.FindVM(vmPath)).To(MatchError(ContainSubstring("context canceled")))
 		})
 	})
 
 	Context("ListDevices", func() {
-		var govcListDevicesOutput = `ide-200            VirtualIDEController          IDE 0
-ide-201            VirtualIDEController          IDE 1
-ps2-300            VirtualPS2Controller          PS2 controller 0
-pci-100            VirtualPCIController          PCI controller 0
-sio-400            VirtualSIOController          SIO controller 0
-floppy-8000        VirtualFloppy                 Remote
-ethernet-0         VirtualE1000e                 internal-network
-`
-
-		It("returns a list of devices for the given VM", func() {
-			runner.RunWithOutputReturns(govcListDevicesOutput, 0, nil)
-
-			devices, err := vcenterClient.ListDevices("/path/to/vm")
-
+		It("returns the names of the devices of the VM", func() {
+			devices, err := vcenterClient.ListDevices(vmPath)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(devices).To(ConsistOf(
-				"ide-200", "ide-201", "ps2-300", "pci-100", "sio-400", "floppy-8000", "ethernet-0",
-			))
-
-			Expect(runner.RunWithOutputArgsForCall(0)).To(Equal([]string{"device.ls", "-u", credentialUrl, "-vm", "/path/to/vm"}))
-		})
-
-		It("returns an error if govc runner returns non zero exit code", func() {
-			runner.RunWithOutputReturns("", 1, nil)
-
-			_, err := vcenterClient.ListDevices("/path/to/vm")
-
-			Expect(err).To(MatchError("vcenter_client - failed to list devices in vCenter, govc exit code 1"))
-		})
-
-		It("returns an error if RunWithOutput encounters an error", func() {
-			runner.RunWithOutputReturns("", 0, errors.New("some environment error"))
-
-			_, err := vcenterClient.ListDevices("/path/to/vm")
-
-			Expect(err).To(MatchError("vcenter_client - failed to parse list of devices. Err: some environment error"))
+			Expect(devices).To(ContainElements("ethernet-0", "cdrom-203"))
 		})
 
-		It("returns govc exit code error, when both govc exit code is non zero and RunWithOutput encounters an error", func() {
-			runner.RunWithOutputReturns("", 1, errors.New("some environment error"))
-
-			_, err := vcenterClient.ListDevices("/path/to/vm")
-
-			Expect(err).To(MatchError("vcenter_client - failed to list devices in vCenter, govc exit code 1"))
+		It("returns an error when the VM cannot be found", func() {
+			_, err := vcenterClient.ListDevices("/DC0/vm/missing")
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - failed to list devices in vCenter: ")))
 		})
 	})
 
-	Context("ExportVM", func() {
-		var destinationDir string
-		BeforeEach(func() {
-			destinationDir = GinkgoT().TempDir() // automatically cleaned up
-		})
-
-		It("exports the VM to local machine from vcenter using vm inventory path", func() {
-			expectedArgs := []string{"export.ovf", "-u", credentialUrl, "-sha", "1", "-vm", "validVMPath", destinationDir}
-			runner.RunReturns(0)
-			err := vcenterClient.ExportVM("validVMPath", destinationDir)
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunCallCount()).To(Equal(1))
-
-			argsForRun := runner.RunArgsForCall(0)
-			Expect(argsForRun).To(Equal(expectedArgs))
-		})
-
-		It("Returns an error message if ExportVM fails to export the VM", func() {
-			vmInventoryPath := "validVMPath"
-			expectedArgs := []string{"export.ovf", "-u", credentialUrl, "-sha", "1", "-vm", vmInventoryPath, destinationDir}
-			runner.RunReturns(1)
-			err := vcenterClient.ExportVM("validVMPath", destinationDir)
-
-			expectedErrorMsg := fmt.Sprintf("vcenter_client - %s could not be exported", vmInventoryPath)
-			Expect(err).To(HaveOccurred())
-			Expect(runner.RunCallCount()).To(Equal(1))
+	Describe("RemoveDevice", func() {
+		It("removes the device from the VM", func() {
+			Expect(vcenterClient.RemoveDevice(vmPath, "ethernet-0")).To(Succeed())
 
-			argsForRun := runner.RunArgsForCall(0)
-			Expect(argsForRun).To(Equal(expectedArgs))
-			Expect(err.Error()).To(Equal(expectedErrorMsg))
+			devices, err := vcenterClient.ListDevices(vmPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(devices).NotTo(ContainElement("ethernet-0"))
 		})
 
-		It("prints an appropriate error message if the given directory doesn't exist", func() {
-			err := vcenterClient.ExportVM("validVMPath", "/FooBar/stuff")
-			Expect(err).To(HaveOccurred())
-
-			Expect(err.Error()).To(Equal("vcenter_client - provided destination directory: /FooBar/stuff does not exist"))
+		It("returns an error when the device does not exist", func() {
+			err := vcenterClient.RemoveDevice(vmPath, "floppy-9999")
+			Expect(err).To(MatchError("vcenter_client - floppy-9999 could not be removed: device not found"))
 		})
 	})
 
-	Describe("UploadArtifact", func() {
-		It("Uploads artifact to the given vm", func() {
-			runner.RunReturns(0)
-			err := vcenterClient.UploadArtifact("validVMPath", "artifact", "C:\\provision\\artifact", "user", "pass")
-
-			Expect(err).To(Not(HaveOccurred()))
-			expectedArgs := []string{"guest.upload", "-u", credentialUrl, "-f", "-l", "user:pass", "-vm", "validVMPath", "artifact", "C:\\provision\\artifact"}
-			Expect(runner.RunArgsForCall(0)).To(Equal(expectedArgs))
+	Describe("EjectCDRom", func() {
+		It("ejects the cd rom of the VM", func() {
+			Expect(vcenterClient.EjectCDRom(vmPath, "cdrom-203")).To(Succeed())
 		})
 
-		It("Returns an error if VCenter reports a failure uploading the artifact", func() {
-			runner.RunReturns(1)
-			err := vcenterClient.UploadArtifact("validVMPath", "artifact", "C:\\provision\\artifact", "user", "pass")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - artifact could not be uploaded"))
+		It("returns an error when the device is not a cd rom", func() {
+			err := vcenterClient.EjectCDRom(vmPath, "ethernet-0")
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - ethernet-0 could not be ejected: ")))
 		})
 	})
 
-	Describe("MakeDirectory", func() {
-		It("Creates the directory on the vm", func() {
-			runner.RunReturns(0)
-			err := vcenterClient.MakeDirectory("validVMPath", "C:\\provision", "user", "pass")
-
-			Expect(err).To(Not(HaveOccurred()))
-			expectedArgs := []string{"guest.mkdir", "-u", credentialUrl, "-l", "user:pass", "-vm", "validVMPath", "-p", "C:\\provision"}
-			Expect(runner.RunArgsForCall(0)).To(Equal(expectedArgs))
+	Context("ExportVM", func() {
+		It("returns an error if the destination directory doesn't exist", func() {
+			err := vcenterClient.ExportVM(vmPath, filepath.Join(GinkgoT().TempDir(), "missing"))
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - provided destination directory: ")))
 		})
 
-		It("Returns an error if VCenter reports a failure making the directory", func() {
-			runner.RunReturns(1)
-			err := vcenterClient.MakeDirectory("validVMPath", "C:\\provision", "user", "pass")
-
-			Expect(err).To(HaveOccurred())
-			expectedArgs := []string{"guest.mkdir", "-u", credentialUrl, "-l", "user:pass", "-vm", "validVMPath", "-p", "C:\\provision"}
-			Expect(runner.RunArgsForCall(0)).To(Equal(expectedArgs))
-
-			Expect(err).To(MatchError("vcenter_client - directory `C:\\provision` could not be created"))
+		It("returns an error with the reason the VM could not be exported", func() {
+			err := vcenterClient.ExportVM("/DC0/vm/missing", GinkgoT().TempDir())
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - /DC0/vm/missing could not be exported: ")))
 		})
-	})
 
-	Describe("Start", func() {
-		It("runs the command on the vm", func() {
-			runner.RunWithOutputReturns("1856\n", 0, nil) // govc add '\n' to the output
-			pid, err := vcenterClient.Start("validVMPath", "user", "pass", "command", "arg1", "arg2", "arg3")
+		It("downloads only the disks of the VM, with a descriptor and a SHA1 manifest of the files", func() {
+			destination := GinkgoT().TempDir()
+			err := vcenterClient.ExportVM(vmPath, destination)
+			if err != nil && strings.Contains(err.Error(), "does not implement") {
+				Skip("this vcsim cannot export VMs: " + err.Error())
+			}
+			Expect(err).NotTo(HaveOccurred())
 
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(pid).To(Equal("1856"))
-			expectedArgs := []string{"guest.start", "-u", credentialUrl, "-l", "user:pass", "-vm", "validVMPath", "command", "arg1", "arg2", "arg3"}
-			Expect(runner.RunWithOutputCallCount()).To(Equal(1))
-			Expect(runner.RunWithOutputArgsForCall(0)).To(Equal(expectedArgs))
-		})
-		It("returns an error when RunWithOutput fails", func() {
-			runner.RunWithOutputReturns("", 0, errors.New("error"))
-			_, err := vcenterClient.Start("validVMPath", "user", "pass", "command2", "arg1", "arg2", "arg3")
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - failed to run 'command2': error"))
+			exportDir := filepath.Join(destination, "DC0_H0_VM0")
+			entries, err := os.ReadDir(exportDir)
+			Expect(err).NotTo(HaveOccurred())
+			var files, disks []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+				if filepath.Ext(entry.Name()) == ".vmdk" {
+					disks = append(disks, entry.Name())
+				}
+			}
+			Expect(disks).NotTo(BeEmpty())
+			for _, disk := range disks {
+				Expect(disk).To(HavePrefix("DC0_H0_VM0"))
+			}
+			Expect(files).To(ConsistOf(append([]string{"DC0_H0_VM0.ovf", "DC0_H0_VM0.mf"}, disks...)))
+
+			descriptor, err := os.ReadFile(filepath.Join(exportDir, "DC0_H0_VM0.ovf"))
+			Expect(err).NotTo(HaveOccurred())
+			for _, disk := range disks {
+				Expect(string(descriptor)).To(ContainSubstring(`ovf:href="` + disk + `"`))
+			}
 
-		})
-		It("returns an error when RunWithOutput returns an errCode", func() {
-			runner.RunWithOutputReturns("", 1, nil)
-			_, err := vcenterClient.Start("validVMPath", "user", "pass", "command2", "arg1", "arg2", "arg3")
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - 'command2' returned exit code: 1"))
+			manifest, err := os.ReadFile(filepath.Join(exportDir, "DC0_H0_VM0.mf"))
+			Expect(err).NotTo(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(manifest)), "\n")
+			Expect(lines).To(HaveLen(len(disks) + 1))
+			for _, file := range append(disks, "DC0_H0_VM0.ovf") {
+				contents, err := os.ReadFile(filepath.Join(exportDir, file))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lines).To(ContainElement(fmt.Sprintf("SHA1(%s)= %x", file, sha1.Sum(contents))))
+			}
 		})
 	})
 
-	Describe("WaitForExit", func() {
-		// Sample output came from running `govc guest.ps` with the JSON flag set
-		const sampleOutput = `{"ProcessInfo":[{"Name":"powershell.exe","Pid":1296,"Owner":"Administrator","CmdLine":"\"c:\\Windows\\System32\\WindowsPowershell\\v1.0\\powershell.exe\" dir","StartTime":"2019-03-26T18:33:31Z","EndTime":"2019-03-26T18:33:34Z","ExitCode":42}]}`
-		const sampleOutputPidNotFound = `{"ProcessInfo":null}`
-		const sampleOutputBadJson = `bad bad json format`
-
-		It("returns the process' exit code upon success", func() {
-			runner.RunWithOutputReturns(sampleOutput, 0, nil)
-			exitCode, err := vcenterClient.WaitForExit("validVMPath", "user", "pass", "1296")
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(exitCode).To(Equal(42))
-			expectedArgs := []string{"guest.ps", "-u", credentialUrl, "-l", "user:pass", "-vm", "validVMPath", "-p", "1296", "-X", "-json"}
-			Expect(runner.RunWithOutputCallCount()).To(Equal(1))
-			Expect(runner.RunWithOutputArgsForCall(0)).To(Equal(expectedArgs))
+	Describe("guest operations", func() {
+		It("returns the vSphere fault when the guest cannot run the program", func() {
+			_, err := vcenterClient.Start(vmPath, "user", "pass", "cmd.exe", "/c", "exit", "0")
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - failed to run 'cmd.exe': ")))
 		})
 
-		It("returns an error if the process ID cannot be found", func() {
-			runner.RunWithOutputReturns(sampleOutputPidNotFound, 0, nil)
-			_, err := vcenterClient.WaitForExit("validVMPath", "user", "pass", "1296")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - couldn't get exit code for PID 1296"))
+		It("returns an error for a malformed PID", func() {
+			_, err := vcenterClient.WaitForExit(vmPath, "user", "pass", "not-a-pid")
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - invalid PID not-a-pid: ")))
 		})
 
-		It("returns an error if a malformed json is returned", func() {
-			runner.RunWithOutputReturns(sampleOutputBadJson, 0, nil)
-			_, err := vcenterClient.WaitForExit("validVMPath", "user", "pass", "1296")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - received bad JSON output for PID 1296: bad bad json format"))
-
-		})
-
-		It("returns an error when RunWithOutput fails", func() {
-			runner.RunWithOutputReturns(sampleOutput, 0, errors.New("bad command error"))
-			_, err := vcenterClient.WaitForExit("validVMPath", "user", "pass", "3369")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - failed to fetch exit code for PID 3369: bad command error"))
-
-		})
-
-		It("returns an error when RunWithOutput returns an errCode", func() {
-			runner.RunWithOutputReturns(sampleOutput, 20, nil)
-			_, err := vcenterClient.WaitForExit("validVMPath", "user", "pass", "11678")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - fetching PID 11678 returned with exit code: 20"))
+		It("returns an error when the artifact cannot be read", func() {
+			err := vcenterClient.UploadArtifact(vmPath, filepath.Join(GinkgoT().TempDir(), "missing.zip"), `C:\provision\missing.zip`, "user", "pass")
+			Expect(err).To(MatchError(ContainSubstring("missing.zip could not be uploaded: ")))
 		})
 	})
 
 	Describe("IsPoweredOff", func() {
-		It("Uses vm.info correctly to get power state", func() {
-			expectedArgs := []string{"vm.info", "-u", credentialUrl, "validVMPath"}
-			runner.RunWithOutputReturns("some result", 0, nil)
-
-			_, err := vcenterClient.IsPoweredOff("validVMPath")
-			argsForRun := runner.RunWithOutputArgsForCall(0)
-
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(argsForRun).To(Not(ContainElement("-vm.ipath")))
-			Expect(argsForRun).To(Equal(expectedArgs))
-
-		})
-		It("Gets the power state of the vm and returns false when vm is not powered off", func() {
-			runner.RunWithOutputReturns("Power state:  poweredOn", 0, nil)
-			out, err := vcenterClient.IsPoweredOff("validVMPath")
-
-			Expect(out).To(BeFalse())
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunWithOutputCallCount()).To(Equal(1))
-		})
-		It("Gets the power state of the vm and returns true when the vm is powered off", func() {
-			runner.RunWithOutputReturns("Power state:  poweredOff", 0, nil)
-			out, err := vcenterClient.IsPoweredOff("validVMPath")
-
-			Expect(out).To(BeTrue())
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(runner.RunWithOutputCallCount()).To(Equal(1))
-		})
-
-		It("Returns an exit code error if the runner returns a non zero exit code", func() {
-			runner.RunWithOutputReturns("", 1, nil)
-			_, err := vcenterClient.IsPoweredOff("validVMPath")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - failed to get vm info, govc exit code: 1"))
-		})
-
-		It("Returns an error if VCenter reports a failure getting the power state", func() {
-			runner.RunWithOutputReturns("", 0, errors.New("some power state issue"))
-			_, err := vcenterClient.IsPoweredOff("validVMPath")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - failed to determine vm power state: some power state issue"))
+		It("returns false when the VM is powered on", func() {
+			poweredOff, err := vcenterClient.IsPoweredOff(vmPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(poweredOff).To(BeFalse())
 		})
 
-		It("Returns an exit code error if the runner returns a non zero exit code and VCenter reports a failure getting the power state", func() {
-			runner.RunWithOutputReturns("", 1, errors.New("some power state issue"))
-			_, err := vcenterClient.IsPoweredOff("validVMPath")
-
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError("vcenter_client - failed to get vm info, govc exit code: 1"))
+		It("returns an error when the VM cannot be found", func() {
+			_, err := vcenterClient.IsPoweredOff("/DC0/vm/missing")
+			Expect(err).To(MatchError(HavePrefix("vcenter_client - failed to get vm info: ")))
 		})
 	})
-
 })
@@ -0,0 +1,59 @@
+package vcenter_manager
+
+import (
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// gmtTimeZone is the Windows time zone index for Greenwich Mean Time
+const gmtTimeZone = 85
+
+// CloneNetwork describes the address given to the first network adapter of a clone.
+// An empty IP means the address is assigned by DHCP.
+type CloneNetwork struct {
+	IP         string
+	Netmask    string
+	Gateway    string
+	DNSServers []string
+}
+
+// WindowsCustomizationSpec returns a sysprep customization that names the guest after the clone,
+// sets the Administrator password and configures the network of the first adapter
+func WindowsCustomizationSpec(adminPassword string, network CloneNetwork) types.CustomizationSpec {
+	adapter := types.CustomizationIPSettings{
+		Ip: &types.CustomizationDhcpIpGenerator{},
+	}
+	if network.IP != "" {
+		adapter = types.CustomizationIPSettings{
+			Ip:            &types.CustomizationFixedIp{IpAddress: network.IP},
+			SubnetMask:    network.Netmask,
+			Gateway:       []string{network.Gateway},
+			DnsServerList: network.DNSServers,
+		}
+	}
+
+	return types.CustomizationSpec{
+		Identity: &types.CustomizationSysprep{
+			GuiUnattended: types.CustomizationGuiUnattended{
+				Password: &types.CustomizationPassword{
+					Value:     adminPassword,
+					PlainText: true,
+				},
+				TimeZone: gmtTimeZone,
+			},
+			UserData: types.CustomizationUserData{
+				FullName:     "stembuild",
+				OrgName:      "stembuild",
+				ComputerName: &types.CustomizationVirtualMachineName{},
+			},
+			Identification: types.CustomizationIdentification{
+				JoinWorkgroup: "WORKGROUP",
+			},
+		},
+		GlobalIPSettings: types.CustomizationGlobalIPSettings{
+			DnsServerList: network.DNSServers,
+		},
+		NicSettingMap: []types.CustomizationAdapterMapping{
+			{Adapter: adapter},
+		},
+	}
+}
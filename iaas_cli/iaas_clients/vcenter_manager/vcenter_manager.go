@@ -2,14 +2,18 @@ package vcenter_manager
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/guest"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/guest_manager"
@@ -70,6 +74,15 @@ func (v *VCenterManager) FindVM(ctx context.Context, inventoryPath string) (*obj
 
 // CloneVM clones vm to clonePath. It currently does no network configuration (i.e. there is no IP assigned)
 func (v *VCenterManager) CloneVM(ctx context.Context, vm *object.VirtualMachine, clonePath string) error {
+	return v.cloneVM(ctx, vm, clonePath, nil)
+}
+
+// CloneVMWithCustomization clones the VM, powers the clone on and applies the guest customization to it
+func (v *VCenterManager) CloneVMWithCustomization(ctx context.Context, vm *object.VirtualMachine, clonePath string, customization types.CustomizationSpec) error {
+	return v.cloneVM(ctx, vm, clonePath, &customization)
+}
+
+func (v *VCenterManager) cloneVM(ctx context.Context, vm *object.VirtualMachine, clonePath string, customization *types.CustomizationSpec) error {
 
 	dc := strings.Split(vm.InventoryPath, "/")[0]
 
@@ -98,7 +111,8 @@ func (v *VCenterManager) CloneVM(ctx context.Context, vm *object.VirtualMachine,
 		Location: types.VirtualMachineRelocateSpec{
 			Pool: &ref,
 		},
-		PowerOn: true,
+		PowerOn:       true,
+		Customization: customization,
 	}
 
 	task, err := vm.Clone(ctx, folder, path.Base(clonePath), config)
@@ -114,6 +128,168 @@ func (v *VCenterManager) CloneVM(ctx context.Context, vm *object.VirtualMachine,
 	return nil
 }
 
+// WaitForIP waits until VMware Tools in the guest reports an IPv4 address for the VM
+func (v *VCenterManager) WaitForIP(ctx context.Context, vm *object.VirtualMachine) (string, error) {
+	return vm.WaitForIP(ctx, true)
+}
+
+// customizationPollInterval is the time between queries for the events of a guest customization
+var customizationPollInterval = 5 * time.Second
+
+// customizationEvents are the events vCenter logs for a VM when its guest customization has finished
+var customizationEvents = []string{
+	"CustomizationSucceeded",
+	"CustomizationFailed",
+	"CustomizationSysprepFailed",
+	"CustomizationNetworkSetupFailed",
+	"CustomizationUnknownFailure",
+}
+
+// WaitForCustomization waits until vCenter logs that the guest customization of the VM has finished,
+// and returns an error when it has failed. Until then the guest may still reboot and change its IP address.
+func (v *VCenterManager) WaitForCustomization(ctx context.Context, vm *object.VirtualMachine) error {
+	req := types.QueryEvents{
+		This: *v.vimClient.ServiceContent.EventManager,
+		Filter: types.EventFilterSpec{
+			Entity: &types.EventFilterSpecByEntity{
+				Entity:    vm.Reference(),
+				Recursion: types.EventFilterSpecRecursionOptionSelf,
+			},
+			EventTypeId: customizationEvents,
+		},
+	}
+
+	for {
+		res, err := methods.QueryEvents(ctx, v.vimClient, &req)
+		if err != nil {
+			return err
+		}
+
+		// the latest event decides, should the VM have been customized more than once
+		var latest types.BaseEvent
+		for _, e := range res.Returnval {
+			if latest == nil || e.GetEvent().Key > latest.GetEvent().Key {
+				latest = e
+			}
+		}
+		if latest != nil {
+			if _, ok := latest.(*types.CustomizationSucceeded); ok {
+				return nil
+			}
+			return fmt.Errorf("guest customization failed: %s", latest.GetEvent().FullFormattedMessage)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(customizationPollInterval):
+		}
+	}
+}
+
+// CreateSnapshot takes a snapshot of the VM's disks only, without memory or guest quiescing
+func (v *VCenterManager) CreateSnapshot(ctx context.Context, vm *object.VirtualMachine, name, description string) error {
+	task, err := vm.CreateSnapshot(ctx, name, description, false, false)
+	if err != nil {
+		return err
+	}
+
+	return task.WaitEx(ctx)
+}
+
+// RevertToSnapshot reverts the VM to the named snapshot. Snapshots are taken without memory,
+// so the VM is powered off afterwards; callers that need it running use PowerOnVM.
+func (v *VCenterManager) RevertToSnapshot(ctx context.Context, vm *object.VirtualMachine, name string) error {
+	task, err := vm.RevertToSnapshot(ctx, name, true)
+	if err != nil {
+		return err
+	}
+
+	return task.WaitEx(ctx)
+}
+
+// PowerOnVM powers the VM on, and does nothing when it is already powered on
+func (v *VCenterManager) PowerOnVM(ctx context.Context, vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	if state == types.VirtualMachinePowerStatePoweredOn {
+		return nil
+	}
+
+	task, err := vm.PowerOn(ctx)
+	if err != nil {
+		return err
+	}
+
+	return task.WaitEx(ctx)
+}
+
+func (v *VCenterManager) DeleteSnapshot(ctx context.Context, vm *object.VirtualMachine, name string) error {
+	consolidate := true
+	task, err := vm.RemoveSnapshot(ctx, name, false, &consolidate)
+	if err != nil {
+		return err
+	}
+
+	return task.WaitEx(ctx)
+}
+
+func (v *VCenterManager) HasSnapshot(ctx context.Context, vm *object.VirtualMachine, name string) (bool, error) {
+	var o mo.VirtualMachine
+	err := vm.Properties(ctx, vm.Reference(), []string{"snapshot"}, &o)
+	if err != nil {
+		return false, err
+	}
+
+	if o.Snapshot == nil {
+		return false, nil
+	}
+
+	return snapshotTreeContains(o.Snapshot.RootSnapshotList, name), nil
+}
+
+// GuestIPAddresses returns the IP addresses VMware Tools in the guest reports for the network adapters of the VM
+func (v *VCenterManager) GuestIPAddresses(ctx context.Context, vm *object.VirtualMachine) ([]string, error) {
+	var o mo.VirtualMachine
+	err := vm.Properties(ctx, vm.Reference(), []string{"guest.net"}, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	if o.Guest == nil {
+		return addresses, nil
+	}
+	for _, nic := range o.Guest.Net {
+		addresses = append(addresses, nic.IpAddress...)
+	}
+	return addresses, nil
+}
+
+// RebootStatus reads the power state and boot time of the VM and the status of VMware Tools in its guest
+func (v *VCenterManager) RebootStatus(ctx context.Context, vm *object.VirtualMachine) (mo.VirtualMachine, error) {
+	var o mo.VirtualMachine
+	err := vm.Properties(ctx, vm.Reference(), []string{
+		"runtime.powerState",
+		"runtime.bootTime",
+		"guest.toolsRunningStatus",
+		"guest.toolsVersionStatus2",
+		"guestHeartbeatStatus",
+	}, &o)
+	return o, err
+}
+
+func snapshotTreeContains(tree []types.VirtualMachineSnapshotTree, name string) bool {
+	for _, snapshot := range tree {
+		if snapshot.Name == name || snapshotTreeContains(snapshot.ChildSnapshotList, name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (v *VCenterManager) OperationsManager(ctx context.Context, vm *object.VirtualMachine) *guest.OperationsManager {
 	return guest.NewOperationsManager(v.vimClient, vm.Reference())
 }
@@ -202,8 +202,8 @@ func revertSnapshot(vmIpath string, snapshotName string) {
 
 func waitForVmToBeReady(vmIp string, vmUsername string, vmPassword string) {
 	By("Waiting for reverting snapshot to finish...")
-	clientFactory := remotemanager.NewWinRmClientFactory(vmIp, vmUsername, vmPassword)
-	rm := remotemanager.NewWinRM(vmIp, vmUsername, vmPassword, clientFactory)
+	clientFactory := remotemanager.NewWinRmClientFactory(vmIp, vmUsername, vmPassword, remotemanager.WinRMOptions{})
+	rm := remotemanager.NewWinRM(vmIp, vmUsername, vmPassword, remotemanager.WinRMOptions{}, clientFactory)
 	Expect(rm).ToNot(BeNil())
 
 	start := time.Now()
@@ -14,8 +14,8 @@ var _ = Describe("WinRM Remote Manager", func() {
 	var rm remotemanager.RemoteManager
 
 	BeforeEach(func() {
-		clientFactory := remotemanager.NewWinRmClientFactory(conf.TargetIP, conf.VMUsername, conf.VMPassword)
-		rm = remotemanager.NewWinRM(conf.TargetIP, conf.VMUsername, conf.VMPassword, clientFactory)
+		clientFactory := remotemanager.NewWinRmClientFactory(conf.TargetIP, conf.VMUsername, conf.VMPassword, remotemanager.WinRMOptions{})
+		rm = remotemanager.NewWinRM(conf.TargetIP, conf.VMUsername, conf.VMPassword, remotemanager.WinRMOptions{}, clientFactory)
 		Expect(rm).ToNot(BeNil())
 	})
 
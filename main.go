@@ -5,16 +5,20 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
 
 	"github.com/google/subcommands"
 
 	"github.com/cloudfoundry/stembuild/assets"
+	batchfactory "github.com/cloudfoundry/stembuild/batch/factory"
 	"github.com/cloudfoundry/stembuild/commandparser"
 	vmconstructfactory "github.com/cloudfoundry/stembuild/construct/factory"
 	vcenterclientfactory "github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/factory"
 	packagerfactory "github.com/cloudfoundry/stembuild/package_stemcell/factory"
+	preflightfactory "github.com/cloudfoundry/stembuild/preflight/factory"
 	"github.com/cloudfoundry/stembuild/version"
 )
 
@@ -34,11 +38,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Ctrl-C aborts construct, preflight, batch and the vCenter export of package gracefully, and a second one exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	var gf commandparser.GlobalFlags
 	packageCmd := commandparser.NewPackageCommand(version.NewVersionGetter(), &packagerfactory.PackagerFactory{}, &commandparser.PackageMessenger{Output: os.Stderr})
 	packageCmd.GlobalFlags = &gf
-	constructCmd := commandparser.NewConstructCmd(context.Background(), &vmconstructfactory.VMConstructFactory{}, &vcenterclientfactory.ManagerFactory{}, &commandparser.ConstructValidator{}, &commandparser.ConstructCmdMessenger{OutputChannel: os.Stderr})
+	constructCmd := commandparser.NewConstructCmd(ctx, &vmconstructfactory.VMConstructFactory{}, &vcenterclientfactory.ManagerFactory{}, &commandparser.ConstructValidator{}, &commandparser.ConstructCmdMessenger{OutputChannel: os.Stderr})
 	constructCmd.GlobalFlags = &gf
+	preflightCmd := commandparser.NewPreflightCmd(ctx, &preflightfactory.CheckFactory{}, &commandparser.ConstructValidator{}, os.Stdout)
+	preflightCmd.GlobalFlags = &gf
+	batchCmd := commandparser.NewBatchCmd(ctx, &batchfactory.CommandFactory{}, os.Stdout)
+	batchCmd.GlobalFlags = &gf
 
 	var commands = make([]subcommands.Command, 0)
 
@@ -47,6 +62,7 @@ func main() {
 	fs.BoolVar(&gf.Color, "color", false, "Colorize debug output")
 	fs.BoolVar(&gf.ShowVersion, "version", false, "Show Stembuild version")
 	fs.BoolVar(&gf.ShowVersion, "v", false, "Stembuild version (shorthand)")
+	fs.Var(commandparser.OutputValue{GlobalFlags: &gf}, "output", "Progress output of construct and package: text, or json for one JSON event per line on stdout")
 
 	commander := subcommands.NewCommander(fs, path.Base(os.Args[0]))
 
@@ -56,9 +72,13 @@ func main() {
 
 	commander.Register(packageCmd, "")
 	commander.Register(constructCmd, "")
+	commander.Register(preflightCmd, "")
+	commander.Register(batchCmd, "")
 
 	commands = append(commands, packageCmd)
 	commands = append(commands, constructCmd)
+	commands = append(commands, preflightCmd)
+	commands = append(commands, batchCmd)
 
 	// Override the default usage text of Google's Subcommand with our own
 	fs.Usage = func() { sh.Explain(commander.Error) }
@@ -70,7 +90,6 @@ func main() {
 		os.Exit(0)
 	}
 
-	ctx := context.Background()
 	i := int(commander.Execute(ctx))
 	_ = os.Remove(s)
 	os.Exit(i)
@@ -11,6 +11,13 @@ type OutputConfig struct {
 	Os              string
 	StemcellVersion string
 	OutputDir       string
+	// OutputFormat of progress messages, events.FormatText by default or events.FormatJSON
+	OutputFormat string
+}
+
+// StemcellPath is where the stemcell is written
+func (c OutputConfig) StemcellPath() string {
+	return filepath.Join(c.OutputDir, stemcellFilename(c.StemcellVersion, c.Os))
 }
 
 func (c OutputConfig) ValidateConfig() error {
@@ -32,7 +39,7 @@ func (c OutputConfig) ValidateConfig() error {
 		return err
 	}
 
-	name := filepath.Join(c.OutputDir, stemcellFilename(c.StemcellVersion, c.Os))
+	name := c.StemcellPath()
 	if _, err := os.Stat(name); !os.IsNotExist(err) {
 		return fmt.Errorf("error with output file (%s): %v (file may already exist)", name, err)
 	}
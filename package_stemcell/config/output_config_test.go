@@ -166,4 +166,11 @@ var _ = Describe("OutputConfig", func() {
 			})
 		})
 	})
+
+	Describe("StemcellPath", func() {
+		It("names the stemcell after the OS and version in the output directory", func() {
+			c := config.OutputConfig{Os: "2019", StemcellVersion: "2019.4", OutputDir: "/stemcells"}
+			Expect(c.StemcellPath()).To(Equal(filepath.Join("/stemcells", "bosh-stemcell-2019.4-vsphere-esxi-windows2019-go_agent.tgz")))
+		})
+	})
 })
@@ -1,21 +1,39 @@
 package factory
 
 import (
+	"context"
 	"errors"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/cloudfoundry/stembuild/colorlogger"
 	"github.com/cloudfoundry/stembuild/commandparser"
-	"github.com/cloudfoundry/stembuild/iaas_cli"
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients"
 	"github.com/cloudfoundry/stembuild/package_stemcell/config"
 	"github.com/cloudfoundry/stembuild/package_stemcell/package_parameters"
 	"github.com/cloudfoundry/stembuild/package_stemcell/packagers"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
-type PackagerFactory struct{}
+type PackagerFactory struct {
+	// Output receives progress messages of vCenter packagers, default is os.Stdout
+	Output io.Writer
+}
+
+// events reports progress as JSON events on Output, or os.Stdout, when the output config asks for them
+func (f *PackagerFactory) events(outputConfig config.OutputConfig) *events.Emitter {
+	if outputConfig.OutputFormat != events.FormatJSON {
+		return nil
+	}
+	if f.Output == nil {
+		return events.NewEmitter(os.Stdout, "package")
+	}
+	return events.NewEmitter(f.Output, "package")
+}
 
-func (f *PackagerFactory) Packager(sourceConfig config.SourceConfig, outputConfig config.OutputConfig, logger colorlogger.Logger) (commandparser.Packager, error) {
+func (f *PackagerFactory) Packager(ctx context.Context, sourceConfig config.SourceConfig, outputConfig config.OutputConfig, logger colorlogger.Logger) (commandparser.Packager, error) {
 	source, err := sourceConfig.GetSource()
 	if err != nil {
 		return nil, err
@@ -25,11 +43,11 @@ func (f *PackagerFactory) Packager(sourceConfig config.SourceConfig, outputConfi
 	case config.VCENTER:
 		client :=
 			iaas_clients.NewVcenterClient(
+				ctx,
 				sourceConfig.Username,
 				sourceConfig.Password,
 				sourceConfig.URL,
 				sourceConfig.CaCertFile,
-				&iaas_cli.GovcRunner{},
 			)
 
 		return &packagers.VCenterPackager{
@@ -37,6 +55,9 @@ func (f *PackagerFactory) Packager(sourceConfig config.SourceConfig, outputConfi
 			OutputConfig: outputConfig,
 			Client:       client,
 			Logger:       logger,
+			Output:       f.Output,
+			Events:       f.events(outputConfig),
+			Timings:      report.NewRecorder(),
 		}, nil
 	case config.VMDK:
 		options :=
@@ -46,6 +67,8 @@ func (f *PackagerFactory) Packager(sourceConfig config.SourceConfig, outputConfi
 			Stop:         make(chan struct{}),
 			BuildOptions: options,
 			Logger:       logger,
+			Events:       f.events(outputConfig),
+			Timings:      report.NewRecorder(),
 		}
 
 		vmdkPackager.BuildOptions.VMDKFile = sourceConfig.Vmdk
@@ -1,10 +1,14 @@
 package factory_test
 
 import (
+	"bytes"
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry/stembuild/colorlogger"
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/package_stemcell/config"
 	"github.com/cloudfoundry/stembuild/package_stemcell/factory"
 	"github.com/cloudfoundry/stembuild/package_stemcell/packagers"
@@ -33,11 +37,12 @@ var _ = Describe("Factory", func() {
 					Vmdk: "path/to/a/vmdk",
 				}
 
-				actualPackager, err := packagerFactory.Packager(sourceConfig, outputConfig, logger)
+				actualPackager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(actualPackager).To(BeAssignableToTypeOf(&packagers.VmdkPackager{}))
 				Expect(actualPackager).NotTo(BeAssignableToTypeOf(&packagers.VCenterPackager{}))
+				Expect(actualPackager.(*packagers.VmdkPackager).Timings).NotTo(BeNil())
 			})
 		})
 
@@ -50,11 +55,46 @@ var _ = Describe("Factory", func() {
 					VmInventoryPath: "some-vm-inventory-path",
 				}
 
-				actualPackager, err := packagerFactory.Packager(sourceConfig, outputConfig, logger)
+				actualPackager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(actualPackager).To(BeAssignableToTypeOf(&packagers.VCenterPackager{}))
 				Expect(actualPackager).NotTo(BeAssignableToTypeOf(&packagers.VmdkPackager{}))
+				Expect(actualPackager.(*packagers.VCenterPackager).Timings).NotTo(BeNil())
+			})
+
+			It("passes the factory output to the packager", func() {
+				output := new(bytes.Buffer)
+				packagerFactory.Output = output
+				sourceConfig := config.SourceConfig{
+					Username:        "user",
+					Password:        "pass",
+					URL:             "some-url",
+					VmInventoryPath: "some-vm-inventory-path",
+				}
+
+				actualPackager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actualPackager.(*packagers.VCenterPackager).Output).To(BeIdenticalTo(output))
+			})
+
+			It("reports progress as JSON events only when the output config asks for them", func() {
+				sourceConfig := config.SourceConfig{
+					Username:        "user",
+					Password:        "pass",
+					URL:             "some-url",
+					VmInventoryPath: "some-vm-inventory-path",
+				}
+
+				actualPackager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actualPackager.(*packagers.VCenterPackager).Events).To(BeNil())
+
+				jsonOutputConfig := outputConfig
+				jsonOutputConfig.OutputFormat = events.FormatJSON
+				actualPackager, err = packagerFactory.Packager(context.Background(), sourceConfig, jsonOutputConfig, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actualPackager.(*packagers.VCenterPackager).Events).NotTo(BeNil())
 			})
 		})
 
@@ -65,7 +105,7 @@ var _ = Describe("Factory", func() {
 					VmInventoryPath: "some-vm",
 				}
 
-				packager, err := packagerFactory.Packager(sourceConfig, outputConfig, logger)
+				packager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("configuration provided for VMDK & vCenter sources"))
 				Expect(packager).To(BeNil())
@@ -80,7 +120,7 @@ var _ = Describe("Factory", func() {
 					URL:             "some-url",
 				}
 
-				packager, err := packagerFactory.Packager(sourceConfig, outputConfig, logger)
+				packager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("missing vCenter configurations"))
 				Expect(packager).To(BeNil())
@@ -91,7 +131,7 @@ var _ = Describe("Factory", func() {
 			It("returns an error", func() {
 				sourceConfig := config.SourceConfig{}
 
-				packager, err := packagerFactory.Packager(sourceConfig, outputConfig, logger)
+				packager, err := packagerFactory.Packager(context.Background(), sourceConfig, outputConfig, logger)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("no configuration was provided"))
 				Expect(packager).To(BeNil())
@@ -3,14 +3,17 @@ package packagers
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
 
 	"github.com/cloudfoundry/stembuild/colorlogger"
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/filesystem"
 	"github.com/cloudfoundry/stembuild/package_stemcell/config"
+	"github.com/cloudfoundry/stembuild/report"
 )
 
 //counterfeiter:generate . IaasClient
@@ -24,19 +27,40 @@ type IaasClient interface {
 	EjectCDRom(vmInventoryPath string, deviceName string) error
 }
 
+// packageStep is the step of the package command that packagers report progress in
+const packageStep = "package"
+
+// Steps of packaging recorded in the build report
+const (
+	removeDevicesStep     = "remove-devices"
+	exportStep            = "export"
+	vmxGenerationStep     = "vmx-generation"
+	ovftoolConversionStep = "ovftool-conversion"
+	imageGzipStep         = "image-gzip"
+	stemcellTarStep       = "stemcell-tar"
+)
+
 type VCenterPackager struct {
 	SourceConfig config.SourceConfig
 	OutputConfig config.OutputConfig
 	Client       IaasClient
 	Logger       colorlogger.Logger
+	// Output receives progress messages, default is os.Stdout
+	Output io.Writer
+	// Events receives progress messages as JSON events instead of Output, when set
+	Events *events.Emitter
+	// Timings records how long each step of Package takes, when set
+	Timings *report.Recorder
 }
 
 func (v VCenterPackager) Package() error {
-	err := v.executeOnMatchingDevice(v.Client.RemoveDevice, "^(floppy-|ethernet-)")
-	if err != nil {
-		return err
-	}
-	err = v.executeOnMatchingDevice(v.Client.EjectCDRom, "^(cdrom-)")
+	err := v.Timings.Time(removeDevicesStep, func() error {
+		err := v.executeOnMatchingDevice(v.Client.RemoveDevice, "^(floppy-|ethernet-)")
+		if err != nil {
+			return err
+		}
+		return v.executeOnMatchingDevice(v.Client.EjectCDRom, "^(cdrom-)")
+	})
 	if err != nil {
 		return err
 	}
@@ -51,29 +75,66 @@ func (v VCenterPackager) Package() error {
 	if err != nil {
 		return errors.New("failed to create stemcell directory")
 	}
-	err = v.Client.ExportVM(v.SourceConfig.VmInventoryPath, workingDir)
+	err = v.Timings.Time(exportStep, func() error {
+		return v.Client.ExportVM(v.SourceConfig.VmInventoryPath, workingDir)
+	})
 
 	if err != nil {
 		return errors.New("failed to export the prepared VM")
 	}
 
-	fmt.Println("Converting VMDK into stemcell")
+	progress(v.output(), v.Events, "Converting VMDK into stemcell")
 	vmName := path.Base(v.SourceConfig.VmInventoryPath)
-	shaSum, err := TarGenerator(filepath.Join(stemcellDir, "image"), filepath.Join(workingDir, vmName)) //nolint:ineffassign,staticcheck
-	manifestContents := CreateManifest(v.OutputConfig.Os, v.OutputConfig.StemcellVersion, shaSum)
-	err = WriteManifest(manifestContents, stemcellDir)
-
+	var shaSum string
+	err = v.Timings.Time(imageGzipStep, func() error {
+		shaSum, err = TarGenerator(filepath.Join(stemcellDir, "image"), filepath.Join(workingDir, vmName))
+		return err
+	})
 	if err != nil {
-		return errors.New("failed to create stemcell.MF file")
+		return err
 	}
 
 	stemcellFilename := StemcellFilename(v.OutputConfig.StemcellVersion, v.OutputConfig.Os)
-	_, err = TarGenerator(filepath.Join(v.OutputConfig.OutputDir, stemcellFilename), stemcellDir) //nolint:ineffassign,staticcheck
+	err = v.Timings.Time(stemcellTarStep, func() error {
+		manifestContents := CreateManifest(v.OutputConfig.Os, v.OutputConfig.StemcellVersion, shaSum)
+		err := WriteManifest(manifestContents, stemcellDir)
+
+		if err != nil {
+			return errors.New("failed to create stemcell.MF file")
+		}
+
+		_, err = TarGenerator(filepath.Join(v.OutputConfig.OutputDir, stemcellFilename), stemcellDir)
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("Stemcell successfully created: %s\n", stemcellFilename)
+	progress(v.output(), v.Events, fmt.Sprintf("Stemcell successfully created: %s", stemcellFilename))
 	return nil
 }
 
+// StepTimings returns how long each step of Package took
+func (v VCenterPackager) StepTimings() []report.Step {
+	return v.Timings.Steps()
+}
+
+// progress writes a message to out, or reports it within the package step when JSON events are enabled
+func progress(out io.Writer, emitter *events.Emitter, message string) {
+	if emitter != nil {
+		emitter.Info(packageStep, message)
+		return
+	}
+	fmt.Fprintln(out, message)
+}
+
+func (v VCenterPackager) output() io.Writer {
+	if v.Output == nil {
+		return os.Stdout
+	}
+	return v.Output
+}
+
 func (v VCenterPackager) executeOnMatchingDevice(action func(a, b string) error, devicePattern string) error {
 	deviceList, err := v.Client.ListDevices(v.SourceConfig.VmInventoryPath)
 	if err != nil {
@@ -5,17 +5,21 @@ import (
 	"bytes"
 	"compress/gzip"
 	"crypto/sha1"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/filesystem"
 	"github.com/cloudfoundry/stembuild/package_stemcell/config"
 	"github.com/cloudfoundry/stembuild/package_stemcell/packagers"
 	"github.com/cloudfoundry/stembuild/package_stemcell/packagers/packagersfakes"
+	"github.com/cloudfoundry/stembuild/report"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -181,6 +185,47 @@ stemcell_formats:
 			Expect(actualStemcellManifestContent).To(Equal(expectedManifestContent))
 		})
 
+		It("writes its progress to Output", func() {
+			output := new(bytes.Buffer)
+			packager.Output = output
+
+			Expect(packager.Package()).To(Succeed())
+			Expect(output.String()).To(ContainSubstring("Converting VMDK into stemcell"))
+			Expect(output.String()).To(ContainSubstring("Stemcell successfully created: "))
+		})
+
+		It("reports its progress as JSON events instead when Events is set", func() {
+			output := new(bytes.Buffer)
+			eventOutput := new(bytes.Buffer)
+			packager.Output = output
+			packager.Events = events.NewEmitter(eventOutput, "package")
+
+			Expect(packager.Package()).To(Succeed())
+			Expect(output.String()).To(BeEmpty())
+
+			lines := strings.Split(strings.TrimSpace(eventOutput.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			var event events.Event
+			Expect(json.Unmarshal([]byte(lines[0]), &event)).To(Succeed())
+			Expect(event.Step).To(Equal("package"))
+			Expect(event.Status).To(Equal(events.StatusInfo))
+			Expect(event.Message).To(Equal("Converting VMDK into stemcell"))
+		})
+
+		It("records how long each step took when Timings is set", func() {
+			packager.Output = new(bytes.Buffer)
+			packager.Timings = report.NewRecorder()
+
+			Expect(packager.Package()).To(Succeed())
+
+			var steps []string
+			for _, step := range packager.StepTimings() {
+				steps = append(steps, step.Name)
+				Expect(step.Failed).To(BeFalse())
+			}
+			Expect(steps).To(Equal([]string{"remove-devices", "export", "image-gzip", "stemcell-tar"}))
+		})
+
 		It("removes all ethernet and floppy devices", func() {
 			fullDeviceList := []string{"video-674", "cdrom-12", "ps2-450", "ethernet-1", "floppy-8000", "floppy-9000", "video-500"}
 			expectedDeviceList := []string{"ethernet-1", "floppy-8000", "floppy-9000"}
@@ -238,5 +283,24 @@ stemcell_formats:
 			Expect(vmPath).To(Equal(sourceConfig.VmInventoryPath))
 			Expect(err.Error()).To(Equal("failed to export the prepared VM"))
 		})
+
+		It("returns an error without writing a stemcell when the image cannot be created", func() {
+			fakeVcenterClient.ExportVMReturns(nil)
+			fakeVcenterClient.ExportVMStub = nil
+
+			err := packager.Package()
+
+			Expect(err).To(MatchError(HavePrefix("unable to open ")))
+			stemcellFilename := packagers.StemcellFilename(packager.OutputConfig.StemcellVersion, packager.OutputConfig.Os)
+			Expect(filepath.Join(packager.OutputConfig.OutputDir, stemcellFilename)).NotTo(BeAnExistingFile())
+		})
+
+		It("returns an error when the stemcell cannot be written", func() {
+			packager.OutputConfig.OutputDir = filepath.Join(GinkgoT().TempDir(), "missing")
+
+			err := packager.Package()
+
+			Expect(err).To(MatchError(HavePrefix("unable to create destination file with name ")))
+		})
 	})
 })
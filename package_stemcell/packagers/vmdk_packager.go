@@ -15,9 +15,11 @@ import (
 	"time"
 
 	"github.com/cloudfoundry/stembuild/colorlogger"
+	"github.com/cloudfoundry/stembuild/events"
 	"github.com/cloudfoundry/stembuild/filesystem"
 	"github.com/cloudfoundry/stembuild/package_stemcell/ovftool"
 	"github.com/cloudfoundry/stembuild/package_stemcell/package_parameters"
+	"github.com/cloudfoundry/stembuild/report"
 	"github.com/cloudfoundry/stembuild/templates"
 )
 
@@ -32,6 +34,10 @@ type VmdkPackager struct {
 	Stop         chan struct{}
 	BuildOptions package_parameters.VmdkPackageParameters
 	Logger       colorlogger.Logger
+	// Events receives progress messages as JSON events instead of stdout, when set
+	Events *events.Emitter
+	// Timings records how long each step of Package takes, when set
+	Timings *report.Recorder
 }
 
 var ErrInterrupt = errors.New("interrupt")
@@ -255,15 +261,28 @@ func (c *VmdkPackager) CreateImage() error {
 	if err != nil {
 		return err
 	}
-	if err := templates.WriteVMXTemplate(vmdkPath, hwVersion, vmxPath); err != nil {
+	err = c.Timings.Time(vmxGenerationStep, func() error {
+		return templates.WriteVMXTemplate(vmdkPath, hwVersion, vmxPath)
+	})
+	if err != nil {
 		return err
 	}
 
 	ovaPath := filepath.Join(tmpdir, "image.ova")
-	if err := c.ConvertVMX2OVA(vmxPath, ovaPath); err != nil {
+	err = c.Timings.Time(ovftoolConversionStep, func() error {
+		return c.ConvertVMX2OVA(vmxPath, ovaPath)
+	})
+	if err != nil {
 		return err
 	}
 
+	return c.Timings.Time(imageGzipStep, func() error {
+		return c.gzipImage(ovaPath, filepath.Join(tmpdir, "image"))
+	})
+}
+
+// gzipImage compresses the ova into the image file and records its sha1 sum
+func (c *VmdkPackager) gzipImage(ovaPath, imagePath string) error {
 	// reader
 	r, err := os.Open(ovaPath)
 	if err != nil {
@@ -272,7 +291,7 @@ func (c *VmdkPackager) CreateImage() error {
 	defer r.Close()
 
 	// image file (writer)
-	c.Image = filepath.Join(tmpdir, "image")
+	c.Image = imagePath
 	f, err := os.OpenFile(c.Image, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
@@ -310,7 +329,7 @@ func (c *VmdkPackager) ConvertVMDK() (string, error) {
 	}
 	c.Manifest = filepath.Join(c.tmpdir, "stemcell.MF")
 
-	if err := c.CreateStemcell(); err != nil {
+	if err := c.Timings.Time(stemcellTarStep, c.CreateStemcell); err != nil {
 		return "", err
 	}
 
@@ -354,12 +373,17 @@ func (c *VmdkPackager) Package() error {
 	}
 
 	c.Logger.Printf("created stemcell (%s) in: %s", stemcellPath, time.Since(start))
-	fmt.Printf("created stemcell: %s", stemcellPath)
+	progress(os.Stdout, c.Events, fmt.Sprintf("created stemcell: %s", stemcellPath))
 
 	c.Cleanup()
 	return nil
 }
 
+// StepTimings returns how long each step of Package took
+func (c *VmdkPackager) StepTimings() []report.Step {
+	return c.Timings.Steps()
+}
+
 func (c *VmdkPackager) ValidateSourceParameters() error {
 	if validVMDK, err := IsValidVMDK(c.BuildOptions.VMDKFile); err != nil {
 		return err
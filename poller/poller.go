@@ -1,15 +1,54 @@
 package poller
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
 	"time"
 )
 
-type Poller struct{}
+// DefaultJitter spreads polls by up to 10% of their interval either way
+const DefaultJitter = 0.1
+
+var ErrTimedOut = errors.New("timed out")
+
+type Poller struct {
+	// Context stops Poll with its error once it is done, default is context.Background()
+	Context context.Context
+}
+
+// Options controls how PollUntil waits between calls of its function
+type Options struct {
+	// Interval is the wait before the first call
+	Interval time.Duration
+	// MaxInterval caps the wait as it grows by Multiplier after every call.
+	// The wait stays at Interval when MaxInterval is not larger.
+	MaxInterval time.Duration
+	// Multiplier is how much the wait grows after every call, default is 2
+	Multiplier float64
+	// Jitter randomizes every wait by up to this fraction of it either way, between 0 and 1
+	Jitter float64
+	// Timeout is the longest PollUntil polls for before giving up, no limit when it is zero
+	Timeout time.Duration
+	// Progress is called after every call that did not finish polling
+	Progress func(Progress)
+}
+
+// Progress reports a call of the polling function that did not finish polling
+type Progress struct {
+	Attempt  int
+	Elapsed  time.Duration
+	NextWait time.Duration
+}
 
 func (p *Poller) Poll(duration time.Duration, loopFunc func() (bool, error)) error {
 	poll := true
 	for poll {
-		time.Sleep(duration)
+		err := sleep(p.context(), duration)
+		if err != nil {
+			return err
+		}
 		out, err := loopFunc()
 		if err != nil {
 			return err
@@ -18,3 +57,76 @@ func (p *Poller) Poll(duration time.Duration, loopFunc func() (bool, error)) err
 	}
 	return nil
 }
+
+// PollUntil calls loopFunc after every wait until it returns true or an error.
+// It stops with the error of ctx once ctx is done, and returns an error wrapping ErrTimedOut
+// when loopFunc has not returned true within the timeout of opts.
+func (p *Poller) PollUntil(ctx context.Context, opts Options, loopFunc func() (bool, error)) error {
+	start := time.Now()
+	interval := opts.Interval
+	wait := opts.jitter(interval)
+	for attempt := 1; ; attempt++ {
+		if opts.Timeout > 0 {
+			wait = min(wait, max(opts.Timeout-time.Since(start), 0))
+		}
+		err := sleep(ctx, wait)
+		if err != nil {
+			return err
+		}
+		out, err := loopFunc()
+		if err != nil {
+			return err
+		}
+		if out {
+			return nil
+		}
+
+		elapsed := time.Since(start)
+		if opts.Timeout > 0 && elapsed >= opts.Timeout {
+			return fmt.Errorf("%w after %s", ErrTimedOut, opts.Timeout)
+		}
+
+		interval = opts.next(interval)
+		wait = opts.jitter(interval)
+		if opts.Progress != nil {
+			opts.Progress(Progress{Attempt: attempt, Elapsed: elapsed, NextWait: wait})
+		}
+	}
+}
+
+func (o Options) next(interval time.Duration) time.Duration {
+	if o.MaxInterval <= interval {
+		return interval
+	}
+	multiplier := o.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	return min(time.Duration(float64(interval)*multiplier), o.MaxInterval)
+}
+
+func (o Options) jitter(interval time.Duration) time.Duration {
+	if o.Jitter == 0 {
+		return interval
+	}
+	return time.Duration(float64(interval) * (1 + o.Jitter*(2*rand.Float64()-1)))
+}
+
+func (p *Poller) context() context.Context {
+	if p.Context == nil {
+		return context.Background()
+	}
+	return p.Context
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
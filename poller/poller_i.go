@@ -1,6 +1,7 @@
 package poller
 
 import (
+	"context"
 	"time"
 )
 
@@ -9,4 +10,5 @@ import (
 //counterfeiter:generate . PollerI
 type PollerI interface {
 	Poll(duration time.Duration, loopFunc func() (bool, error)) error
+	PollUntil(ctx context.Context, opts Options, loopFunc func() (bool, error)) error
 }
@@ -1,6 +1,7 @@
 package poller_test
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -31,5 +32,140 @@ var _ = Describe("Poller", func() {
 				return true, errors.New("polling is hard :(")
 			})).To(MatchError("polling is hard :("))
 		})
+		It("stops with the error of its context once the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			p := poller.Poller{Context: ctx}
+			callCount := 0
+
+			err := p.Poll(time.Millisecond, func() (bool, error) {
+				callCount++
+				if callCount == 2 {
+					cancel()
+				}
+				return false, nil
+			})
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(callCount).To(Equal(2))
+		})
+	})
+
+	Describe("PollUntil", func() {
+		It("calls the polling function until it returns true", func() {
+			p := poller.Poller{}
+			callCount := 0
+
+			Expect(p.PollUntil(context.Background(), poller.Options{Interval: time.Millisecond}, func() (bool, error) {
+				callCount++
+				return callCount == 3, nil
+			})).To(Succeed())
+			Expect(callCount).To(Equal(3))
+		})
+
+		It("reports progress after every call that did not finish polling", func() {
+			p := poller.Poller{}
+			var progress []poller.Progress
+			callCount := 0
+
+			Expect(p.PollUntil(context.Background(), poller.Options{
+				Interval: time.Millisecond,
+				Progress: func(pr poller.Progress) { progress = append(progress, pr) },
+			}, func() (bool, error) {
+				callCount++
+				return callCount == 3, nil
+			})).To(Succeed())
+
+			Expect(progress).To(HaveLen(2))
+			Expect(progress[0].Attempt).To(Equal(1))
+			Expect(progress[1].Attempt).To(Equal(2))
+			Expect(progress[1].Elapsed).To(BeNumerically(">=", 2*time.Millisecond))
+		})
+
+		It("backs off exponentially up to the maximum interval", func() {
+			p := poller.Poller{}
+			var waits []time.Duration
+
+			Expect(p.PollUntil(context.Background(), poller.Options{
+				Interval:    time.Millisecond,
+				MaxInterval: 5 * time.Millisecond,
+				Progress:    func(pr poller.Progress) { waits = append(waits, pr.NextWait) },
+			}, func() (bool, error) {
+				return len(waits) == 4, nil
+			})).To(Succeed())
+
+			Expect(waits).To(Equal([]time.Duration{2 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond, 5 * time.Millisecond}))
+		})
+
+		It("grows the wait by the multiplier", func() {
+			p := poller.Poller{}
+			var waits []time.Duration
+
+			Expect(p.PollUntil(context.Background(), poller.Options{
+				Interval:    time.Millisecond,
+				MaxInterval: time.Second,
+				Multiplier:  3,
+				Progress:    func(pr poller.Progress) { waits = append(waits, pr.NextWait) },
+			}, func() (bool, error) {
+				return len(waits) == 2, nil
+			})).To(Succeed())
+
+			Expect(waits).To(Equal([]time.Duration{3 * time.Millisecond, 9 * time.Millisecond}))
+		})
+
+		It("keeps every wait within the jitter of the interval", func() {
+			p := poller.Poller{}
+			var waits []time.Duration
+
+			Expect(p.PollUntil(context.Background(), poller.Options{
+				Interval: time.Millisecond,
+				Jitter:   0.5,
+				Progress: func(pr poller.Progress) { waits = append(waits, pr.NextWait) },
+			}, func() (bool, error) {
+				return len(waits) == 20, nil
+			})).To(Succeed())
+
+			for _, wait := range waits {
+				Expect(wait).To(BeNumerically(">=", 500*time.Microsecond))
+				Expect(wait).To(BeNumerically("<=", 1500*time.Microsecond))
+			}
+		})
+
+		It("returns a timeout error when the function does not return true in time", func() {
+			p := poller.Poller{}
+			startTime := time.Now()
+
+			err := p.PollUntil(context.Background(), poller.Options{
+				Interval:    10 * time.Millisecond,
+				MaxInterval: time.Minute,
+				Timeout:     50 * time.Millisecond,
+			}, func() (bool, error) {
+				return false, nil
+			})
+			Expect(err).To(MatchError(poller.ErrTimedOut))
+			Expect(err).To(MatchError("timed out after 50ms"))
+			Expect(time.Since(startTime)).To(BeNumerically("<", 500*time.Millisecond))
+		})
+
+		It("returns an error when polling fails", func() {
+			p := poller.Poller{}
+			Expect(p.PollUntil(context.Background(), poller.Options{}, func() (bool, error) {
+				return false, errors.New("polling is hard :(")
+			})).To(MatchError("polling is hard :("))
+		})
+
+		It("stops with the error of the context once it is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			p := poller.Poller{}
+			callCount := 0
+
+			err := p.PollUntil(ctx, poller.Options{Interval: time.Millisecond}, func() (bool, error) {
+				callCount++
+				if callCount == 2 {
+					cancel()
+				}
+				return false, nil
+			})
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(callCount).To(Equal(2))
+		})
 	})
 })
@@ -2,6 +2,7 @@
 package pollerfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -21,6 +22,19 @@ type FakePollerI struct {
 	pollReturnsOnCall map[int]struct {
 		result1 error
 	}
+	PollUntilStub        func(context.Context, poller.Options, func() (bool, error)) error
+	pollUntilMutex       sync.RWMutex
+	pollUntilArgsForCall []struct {
+		arg1 context.Context
+		arg2 poller.Options
+		arg3 func() (bool, error)
+	}
+	pollUntilReturns struct {
+		result1 error
+	}
+	pollUntilReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -87,11 +101,76 @@ func (fake *FakePollerI) PollReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakePollerI) PollUntil(arg1 context.Context, arg2 poller.Options, arg3 func() (bool, error)) error {
+	fake.pollUntilMutex.Lock()
+	ret, specificReturn := fake.pollUntilReturnsOnCall[len(fake.pollUntilArgsForCall)]
+	fake.pollUntilArgsForCall = append(fake.pollUntilArgsForCall, struct {
+		arg1 context.Context
+		arg2 poller.Options
+		arg3 func() (bool, error)
+	}{arg1, arg2, arg3})
+	stub := fake.PollUntilStub
+	fakeReturns := fake.pollUntilReturns
+	fake.recordInvocation("PollUntil", []interface{}{arg1, arg2, arg3})
+	fake.pollUntilMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakePollerI) PollUntilCallCount() int {
+	fake.pollUntilMutex.RLock()
+	defer fake.pollUntilMutex.RUnlock()
+	return len(fake.pollUntilArgsForCall)
+}
+
+func (fake *FakePollerI) PollUntilCalls(stub func(context.Context, poller.Options, func() (bool, error)) error) {
+	fake.pollUntilMutex.Lock()
+	defer fake.pollUntilMutex.Unlock()
+	fake.PollUntilStub = stub
+}
+
+func (fake *FakePollerI) PollUntilArgsForCall(i int) (context.Context, poller.Options, func() (bool, error)) {
+	fake.pollUntilMutex.RLock()
+	defer fake.pollUntilMutex.RUnlock()
+	argsForCall := fake.pollUntilArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakePollerI) PollUntilReturns(result1 error) {
+	fake.pollUntilMutex.Lock()
+	defer fake.pollUntilMutex.Unlock()
+	fake.PollUntilStub = nil
+	fake.pollUntilReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePollerI) PollUntilReturnsOnCall(i int, result1 error) {
+	fake.pollUntilMutex.Lock()
+	defer fake.pollUntilMutex.Unlock()
+	fake.PollUntilStub = nil
+	if fake.pollUntilReturnsOnCall == nil {
+		fake.pollUntilReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pollUntilReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakePollerI) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.pollMutex.RLock()
 	defer fake.pollMutex.RUnlock()
+	fake.pollUntilMutex.RLock()
+	defer fake.pollUntilMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,191 @@
+package preflight_factory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+
+	"github.com/cloudfoundry/stembuild/filesystem"
+	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients"
+	vcenterclientfactory "github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/factory"
+	"github.com/cloudfoundry/stembuild/iaas_cli/iaas_clients/vcenter_manager"
+	"github.com/cloudfoundry/stembuild/package_stemcell/ovftool"
+	"github.com/cloudfoundry/stembuild/preflight"
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+const (
+	VCenterURLCheck         = "vCenter URL and TLS"
+	VCenterCredentialsCheck = "vCenter credentials"
+	FindVMCheck             = "VM inventory path"
+	GuestOperationsCheck    = "Guest operations login"
+	WinRMReachableCheck     = "WinRM reachability"
+	WinRMLoginCheck         = "WinRM login"
+	LGPOCheck               = "LGPO.zip"
+	OvftoolCheck            = "ovftool"
+	OutputDirSpaceCheck     = "Output directory free space"
+	TempDirSpaceCheck       = "Temp directory free space"
+)
+
+const gigabyte = 1024 * 1024 * 1024
+
+type CheckFactory struct{}
+
+func (f *CheckFactory) Checks(ctx context.Context, c preflight.Config) []preflight.Check {
+	vcenterClient := iaas_clients.NewVcenterClient(ctx, c.VCenterUsername, c.VCenterPassword, c.VCenterUrl, c.CaCertFile)
+
+	winRmClientFactory := remotemanager.NewWinRmClientFactory(c.GuestVmIp, c.GuestVMUsername, c.GuestVMPassword, c.WinRM)
+	remoteManager := remotemanager.NewWinRM(c.GuestVmIp, c.GuestVMUsername, c.GuestVMPassword, c.WinRM, winRmClientFactory)
+
+	// The vCenter manager and VM are shared by the checks that follow the one that finds them
+	var vCenterManager *vcenter_manager.VCenterManager
+	var vm *object.VirtualMachine
+
+	outputDir := c.OutputDir
+	if outputDir == "" {
+		outputDir = "."
+	}
+	minFreeSpace := c.MinFreeSpaceGB * gigabyte
+
+	return []preflight.Check{
+		{
+			Name:        VCenterURLCheck,
+			Remediation: "check that -vcenter-url is reachable from this machine and, for a self-signed certificate, pass its CA with -vcenter-ca-certs",
+			Run:         vcenterClient.ValidateUrl,
+		},
+		{
+			Name:        VCenterCredentialsCheck,
+			Remediation: "check -vcenter-username and -vcenter-password",
+			Requires:    []string{VCenterURLCheck},
+			Run:         vcenterClient.ValidateCredentials,
+		},
+		{
+			Name:        FindVMCheck,
+			Remediation: "use the full inventory path including the 'vm' folder, e.g. /<datacenter>/vm/<vm-folder>/<vm-name>",
+			Requires:    []string{VCenterCredentialsCheck},
+			Run: func() error {
+				if !strings.Contains(c.VmInventoryPath, "/vm/") {
+					return fmt.Errorf("inventory path %s does not include the 'vm' folder", c.VmInventoryPath)
+				}
+
+				managerFactory := &vcenterclientfactory.ManagerFactory{}
+				managerFactory.SetConfig(vcenterclientfactory.FactoryConfig{
+					VCenterServer:  c.VCenterUrl,
+					Username:       c.VCenterUsername,
+					Password:       c.VCenterPassword,
+					ClientCreator:  &vcenterclientfactory.ClientCreator{},
+					FinderCreator:  &vcenterclientfactory.GovmomiFinderCreator{},
+					RootCACertPath: c.CaCertFile,
+				})
+
+				var err error
+				vCenterManager, err = managerFactory.VCenterManager(ctx)
+				if err != nil {
+					return err
+				}
+
+				err = vCenterManager.Login(ctx)
+				if err != nil {
+					return err
+				}
+
+				vm, err = vCenterManager.FindVM(ctx, c.VmInventoryPath)
+				return err
+			},
+		},
+		{
+			Name:        GuestOperationsCheck,
+			Remediation: "check -vm-username and -vm-password, and that VMware Tools is running in the guest",
+			Requires:    []string{FindVMCheck},
+			Run: func() error {
+				opsManager := vCenterManager.OperationsManager(ctx, vm)
+				guestManager, err := vCenterManager.GuestManager(ctx, opsManager, c.GuestVMUsername, c.GuestVMPassword)
+				if err != nil {
+					return err
+				}
+
+				pid, err := guestManager.StartProgramInGuest(ctx, "C:\\Windows\\System32\\cmd.exe", "/c exit 0")
+				if err != nil {
+					return err
+				}
+
+				exitCode, err := guestManager.ExitCodeForProgramInGuest(ctx, pid)
+				if err != nil {
+					return err
+				}
+				if exitCode != 0 {
+					return fmt.Errorf("test program in guest exited with code %d", exitCode)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        WinRMReachableCheck,
+			Remediation: fmt.Sprintf("check -vm-ip and that %s is open between this machine and the VM", c.WinRM.Address(c.GuestVmIp)),
+			Run:         remoteManager.CanReachVM,
+		},
+		{
+			Name:        WinRMLoginCheck,
+			Remediation: winRMLoginRemediation(c.WinRM),
+			Requires:    []string{WinRMReachableCheck},
+			Run:         remoteManager.CanLoginVM,
+		},
+		{
+			Name:        LGPOCheck,
+			Remediation: "download LGPO.zip from Microsoft into the current working directory, or pass -lgpo-path or -lgpo-url with -lgpo-sha256",
+			Run:         c.LGPO.CheckInDefaultCache,
+		},
+		{
+			Name:        OvftoolCheck,
+			Remediation: "install VMware ovftool and add it to your PATH (only needed to package a VMDK)",
+			Optional:    !c.PackageVMDK,
+			Run: func() error {
+				searchPaths, err := ovftool.SearchPaths()
+				if err != nil {
+					return fmt.Errorf("could not get search paths for Ovftool: %s", err)
+				}
+				_, err = ovftool.Ovftool(searchPaths)
+				return err
+			},
+		},
+		{
+			Name:        OutputDirSpaceCheck,
+			Remediation: "free up disk space or choose another output directory",
+			Run: func() error {
+				return hasFreeSpace(&filesystem.OSFileSystem{}, outputDir, minFreeSpace)
+			},
+		},
+		{
+			Name:        TempDirSpaceCheck,
+			Remediation: "free up disk space or point TMPDIR (TMP on Windows) at a larger disk",
+			Run: func() error {
+				return hasFreeSpace(&filesystem.OSFileSystem{}, os.TempDir(), minFreeSpace)
+			},
+		},
+	}
+}
+
+func hasFreeSpace(fs filesystem.FileSystem, path string, minFreeSpace uint64) error {
+	freeSpace, err := fs.GetAvailableDiskSpace(path)
+	if err != nil {
+		return fmt.Errorf("could not check free space on disk: %s", err)
+	}
+	if freeSpace < minFreeSpace {
+		return fmt.Errorf("%d MB free in %s, at least %d MB required", freeSpace/(1024*1024), path, minFreeSpace/(1024*1024))
+	}
+	return nil
+}
+
+func winRMLoginRemediation(options remotemanager.WinRMOptions) string {
+	auth := "basic"
+	if options.Auth == remotemanager.WinRMAuthNTLM {
+		auth = "NTLM"
+	}
+	if options.HTTPS {
+		return fmt.Sprintf("check -vm-username and -vm-password, that WinRM allows %s authentication over HTTPS, and that its certificate is trusted or -winrm-ca-cert is set", auth)
+	}
+	return fmt.Sprintf("check -vm-username and -vm-password, and that WinRM allows %s authentication over HTTP", auth)
+}
@@ -0,0 +1,98 @@
+package preflight_factory_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+	"github.com/cloudfoundry/stembuild/preflight"
+	preflightfactory "github.com/cloudfoundry/stembuild/preflight/factory"
+)
+
+var _ = Describe("CheckFactory", func() {
+	It("returns every construct and package check, dependencies first", func() {
+		factory := &preflightfactory.CheckFactory{}
+
+		checks := factory.Checks(context.Background(), preflight.Config{})
+
+		var names []string
+		for _, check := range checks {
+			names = append(names, check.Name)
+			Expect(check.Remediation).NotTo(BeEmpty())
+		}
+		Expect(names).To(Equal([]string{
+			preflightfactory.VCenterURLCheck,
+			preflightfactory.VCenterCredentialsCheck,
+			preflightfactory.FindVMCheck,
+			preflightfactory.GuestOperationsCheck,
+			preflightfactory.WinRMReachableCheck,
+			preflightfactory.WinRMLoginCheck,
+			preflightfactory.LGPOCheck,
+			preflightfactory.OvftoolCheck,
+			preflightfactory.OutputDirSpaceCheck,
+			preflightfactory.TempDirSpaceCheck,
+		}))
+	})
+
+	It("rejects an inventory path without the vm folder before contacting vCenter", func() {
+		factory := &preflightfactory.CheckFactory{}
+
+		checks := factory.Checks(context.Background(), preflight.Config{VmInventoryPath: "/dc/folder/my-vm"})
+
+		Expect(checks[2].Name).To(Equal(preflightfactory.FindVMCheck))
+		Expect(checks[2].Run()).To(MatchError(ContainSubstring("does not include the 'vm' folder")))
+	})
+
+	It("checks the LGPO.zip given with -lgpo-path", func() {
+		factory := &preflightfactory.CheckFactory{}
+		lgpoPath := filepath.Join(GinkgoT().TempDir(), "LGPO.zip")
+		Expect(os.WriteFile(lgpoPath, []byte("not a zip"), 0600)).To(Succeed())
+
+		checks := factory.Checks(context.Background(), preflight.Config{LGPO: lgpo.Source{Path: lgpoPath}})
+
+		Expect(checks[6].Name).To(Equal(preflightfactory.LGPOCheck))
+		Expect(checks[6].Run()).To(MatchError(ContainSubstring("is not a valid zip archive")))
+	})
+
+	It("only warns about a missing ovftool unless a VMDK is to be packaged", func() {
+		factory := &preflightfactory.CheckFactory{}
+
+		checks := factory.Checks(context.Background(), preflight.Config{})
+		Expect(checks[7].Name).To(Equal(preflightfactory.OvftoolCheck))
+		Expect(checks[7].Optional).To(BeTrue())
+
+		checks = factory.Checks(context.Background(), preflight.Config{PackageVMDK: true})
+		Expect(checks[7].Optional).To(BeFalse())
+	})
+
+	It("does not download LGPO.zip given with -lgpo-url", func() {
+		var methods []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+		}))
+		DeferCleanup(server.Close)
+		GinkgoT().Setenv("XDG_CACHE_HOME", GinkgoT().TempDir())
+		factory := &preflightfactory.CheckFactory{}
+
+		checks := factory.Checks(context.Background(), preflight.Config{LGPO: lgpo.Source{URL: server.URL + "/LGPO.zip", SHA256: strings.Repeat("0", 64)}})
+
+		Expect(checks[6].Run()).To(Succeed())
+		Expect(methods).To(Equal([]string{http.MethodHead}))
+	})
+
+	It("fails the disk space checks when less than the minimum is free", func() {
+		factory := &preflightfactory.CheckFactory{}
+
+		checks := factory.Checks(context.Background(), preflight.Config{OutputDir: GinkgoT().TempDir(), MinFreeSpaceGB: 1 << 30})
+
+		Expect(checks[8].Run()).To(MatchError(ContainSubstring("MB required")))
+		Expect(checks[9].Run()).To(MatchError(ContainSubstring("MB required")))
+	})
+})
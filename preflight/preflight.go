@@ -0,0 +1,120 @@
+package preflight
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudfoundry/stembuild/construct/lgpo"
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+type Status string
+
+const (
+	Passed  Status = "PASS"
+	Failed  Status = "FAIL"
+	Skipped Status = "SKIP"
+	Warning Status = "WARN"
+)
+
+// Check is a read-only verification of a prerequisite for construct or package.
+// A check whose Requires names a check that did not pass is skipped.
+type Check struct {
+	Name        string
+	Remediation string
+	Requires    []string
+	Run         func() error
+	// Optional checks a prerequisite not every user needs, so failing it is only a warning
+	Optional bool
+}
+
+type Result struct {
+	Name        string
+	Status      Status
+	Details     string
+	Remediation string
+}
+
+func RunChecks(checks []Check) []Result {
+	statuses := map[string]Status{}
+	var results []Result
+
+	for _, check := range checks {
+		result := Result{Name: check.Name, Status: Passed, Remediation: check.Remediation}
+
+		for _, required := range check.Requires {
+			if statuses[required] != Passed {
+				result.Status = Skipped
+				result.Details = fmt.Sprintf("requires '%s' to pass", required)
+				break
+			}
+		}
+
+		if result.Status != Skipped {
+			err := check.Run()
+			if err != nil {
+				result.Status = Failed
+				if check.Optional {
+					result.Status = Warning
+				}
+				result.Details = err.Error()
+			}
+		}
+
+		statuses[check.Name] = result.Status
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// AllPassed reports whether every check passed, or only warned
+func AllPassed(results []Result) bool {
+	for _, result := range results {
+		if result.Status != Passed && result.Status != Warning {
+			return false
+		}
+	}
+	return true
+}
+
+func PrintResults(out io.Writer, results []Result) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tDETAILS")
+	for _, result := range results {
+		// keep multi-line errors from breaking the table layout
+		details := strings.Join(strings.Fields(result.Details), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Name, result.Status, details)
+	}
+	w.Flush() //nolint:errcheck
+
+	var hints []string
+	for _, result := range results {
+		if (result.Status == Failed || result.Status == Warning) && result.Remediation != "" {
+			hints = append(hints, fmt.Sprintf("  %s: %s", result.Name, result.Remediation))
+		}
+	}
+	if len(hints) > 0 {
+		fmt.Fprintln(out, "\nRemediation:")
+		fmt.Fprintln(out, strings.Join(hints, "\n"))
+	}
+}
+
+type Config struct {
+	GuestVmIp       string
+	GuestVMUsername string
+	GuestVMPassword string
+	VCenterUrl      string
+	VCenterUsername string
+	VCenterPassword string
+	VmInventoryPath string
+	CaCertFile      string
+	OutputDir       string
+	MinFreeSpaceGB  uint64
+	WinRM           remotemanager.WinRMOptions
+	LGPO            lgpo.Source
+	// PackageVMDK makes the prerequisites of packaging a VMDK, such as ovftool, required
+	PackageVMDK bool
+}
@@ -0,0 +1,85 @@
+package preflight_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry/stembuild/preflight"
+)
+
+var _ = Describe("Preflight", func() {
+	passing := func() error { return nil }
+	failing := func() error { return errors.New("port 5985 is blocked") }
+
+	Describe("RunChecks", func() {
+		It("runs every check and records its result", func() {
+			results := preflight.RunChecks([]preflight.Check{
+				{Name: "first", Run: passing},
+				{Name: "second", Run: failing, Remediation: "open the port"},
+			})
+
+			Expect(results).To(Equal([]preflight.Result{
+				{Name: "first", Status: preflight.Passed},
+				{Name: "second", Status: preflight.Failed, Details: "port 5985 is blocked", Remediation: "open the port"},
+			}))
+			Expect(preflight.AllPassed(results)).To(BeFalse())
+		})
+
+		It("skips checks whose required checks did not pass", func() {
+			dependentRan := false
+			results := preflight.RunChecks([]preflight.Check{
+				{Name: "login", Run: failing},
+				{Name: "find vm", Requires: []string{"login"}, Run: func() error {
+					dependentRan = true
+					return nil
+				}},
+				{Name: "guest login", Requires: []string{"find vm"}, Run: passing},
+			})
+
+			Expect(dependentRan).To(BeFalse())
+			Expect(results[1].Status).To(Equal(preflight.Skipped))
+			Expect(results[1].Details).To(Equal("requires 'login' to pass"))
+			Expect(results[2].Status).To(Equal(preflight.Skipped))
+		})
+
+		It("only warns when an optional check fails", func() {
+			results := preflight.RunChecks([]preflight.Check{
+				{Name: "ovftool", Optional: true, Run: failing},
+				{Name: "package", Requires: []string{"ovftool"}, Run: passing},
+			})
+
+			Expect(results[0].Status).To(Equal(preflight.Warning))
+			Expect(results[0].Details).To(Equal("port 5985 is blocked"))
+			Expect(results[1].Status).To(Equal(preflight.Skipped))
+			Expect(preflight.AllPassed(results[:1])).To(BeTrue())
+		})
+
+		It("reports success when every check passes", func() {
+			results := preflight.RunChecks([]preflight.Check{
+				{Name: "first", Run: passing},
+				{Name: "second", Requires: []string{"first"}, Run: passing},
+			})
+
+			Expect(preflight.AllPassed(results)).To(BeTrue())
+		})
+	})
+
+	Describe("PrintResults", func() {
+		It("prints a table followed by the remediation hints of failed checks", func() {
+			buf := NewBuffer()
+			preflight.PrintResults(buf, []preflight.Result{
+				{Name: "vCenter URL", Status: preflight.Passed, Remediation: "not shown"},
+				{Name: "WinRM reachability", Status: preflight.Failed, Details: "host is\nunreachable", Remediation: "open port 5985"},
+			})
+
+			Expect(buf).To(Say(`CHECK\s+RESULT\s+DETAILS`))
+			Expect(buf).To(Say(`vCenter URL\s+PASS`))
+			Expect(buf).To(Say(`WinRM reachability\s+FAIL\s+host is unreachable`))
+			Expect(buf).To(Say(`Remediation:\n  WinRM reachability: open port 5985`))
+			Expect(buf.Contents()).NotTo(ContainSubstring("not shown"))
+		})
+	})
+})
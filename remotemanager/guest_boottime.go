@@ -0,0 +1,60 @@
+package remotemanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const guestPowershell = `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`
+const lastBootUpTimeFile = guestOpsTempDir + "stembuild-last-boot.txt"
+
+//counterfeiter:generate . BootTimeReader
+type BootTimeReader interface {
+	LastBootUpTime() (time.Time, error)
+}
+
+// GuestBootTime reads the time Windows in the guest last booted through VMware Tools guest operations,
+// which, unlike the boot time vCenter reports, changes when the guest restarts itself
+type GuestBootTime struct {
+	ctx      context.Context
+	guestOps GuestOperations
+}
+
+func NewGuestBootTime(ctx context.Context, guestOps GuestOperations) *GuestBootTime {
+	return &GuestBootTime{ctx: ctx, guestOps: guestOps}
+}
+
+func (g *GuestBootTime) LastBootUpTime() (time.Time, error) {
+	command := fmt.Sprintf("-NoProfile -Command \"(Get-CimInstance Win32_OperatingSystem).LastBootUpTime.ToUniversalTime().ToString('o') | Out-File -Encoding ascii -FilePath '%s'\"", lastBootUpTimeFile)
+	pid, err := g.guestOps.StartProgramInGuest(g.ctx, guestPowershell, command)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = g.guestOps.DeleteFileInGuest(g.ctx, lastBootUpTimeFile) }()
+
+	exitCode, err := g.guestOps.ExitCodeForProgramInGuest(g.ctx, pid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if exitCode != 0 {
+		return time.Time{}, fmt.Errorf("reading the last boot time of the guest exited with code %d", exitCode)
+	}
+
+	reader, _, err := g.guestOps.DownloadFileInGuest(g.ctx, lastBootUpTimeFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	bootTime, err := time.Parse(time.RFC3339, strings.Trim(string(contents), "\ufeff\x00 \r\n\t"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse the last boot time of the guest: %s", err)
+	}
+	return bootTime, nil
+}
@@ -0,0 +1,68 @@
+package remotemanager_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
+)
+
+var _ = Describe("GuestBootTime", func() {
+	var (
+		fakeGuestOps *remotemanagerfakes.FakeGuestOperations
+		bootTime     *remotemanager.GuestBootTime
+	)
+
+	BeforeEach(func() {
+		fakeGuestOps = &remotemanagerfakes.FakeGuestOperations{}
+		fakeGuestOps.StartProgramInGuestReturns(42, nil)
+		fakeGuestOps.DownloadFileInGuestReturns(strings.NewReader("\ufeff2024-05-02T10:00:00.5000000Z\r\n"), 32, nil)
+		bootTime = remotemanager.NewGuestBootTime(context.Background(), fakeGuestOps)
+	})
+
+	It("reads the last boot time Windows reports through guest operations", func() {
+		lastBoot, err := bootTime.LastBootUpTime()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lastBoot).To(BeTemporally("==", time.Date(2024, 5, 2, 10, 0, 0, 500000000, time.UTC)))
+
+		_, command, args := fakeGuestOps.StartProgramInGuestArgsForCall(0)
+		Expect(command).To(HaveSuffix("powershell.exe"))
+		Expect(args).To(ContainSubstring("Win32_OperatingSystem).LastBootUpTime"))
+
+		_, pid := fakeGuestOps.ExitCodeForProgramInGuestArgsForCall(0)
+		Expect(pid).To(Equal(int64(42)))
+
+		_, downloaded := fakeGuestOps.DownloadFileInGuestArgsForCall(0)
+		_, deleted := fakeGuestOps.DeleteFileInGuestArgsForCall(0)
+		Expect(downloaded).To(Equal(`C:\Windows\Temp\stembuild-last-boot.txt`))
+		Expect(deleted).To(Equal(downloaded))
+	})
+
+	It("returns an error when the program cannot be started in the guest", func() {
+		fakeGuestOps.StartProgramInGuestReturns(0, errors.New("guest operations agent could not be contacted"))
+
+		_, err := bootTime.LastBootUpTime()
+		Expect(err).To(MatchError("guest operations agent could not be contacted"))
+	})
+
+	It("returns an error when reading the last boot time fails in the guest", func() {
+		fakeGuestOps.ExitCodeForProgramInGuestReturns(1, nil)
+
+		_, err := bootTime.LastBootUpTime()
+		Expect(err).To(MatchError("reading the last boot time of the guest exited with code 1"))
+		Expect(fakeGuestOps.DownloadFileInGuestCallCount()).To(Equal(0))
+	})
+
+	It("returns an error when the file does not contain a time", func() {
+		fakeGuestOps.DownloadFileInGuestReturns(strings.NewReader("Access is denied."), 17, nil)
+
+		_, err := bootTime.LastBootUpTime()
+		Expect(err).To(MatchError(ContainSubstring("cannot parse the last boot time of the guest")))
+	})
+})
@@ -0,0 +1,173 @@
+package remotemanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const guestOpsShell = `C:\Windows\System32\cmd.exe`
+const guestOpsTempDir = `C:\Windows\Temp\`
+
+// terminateTimeout bounds stopping a command in the guest once its context is done
+const terminateTimeout = 30 * time.Second
+
+//counterfeiter:generate . GuestOperations
+type GuestOperations interface {
+	StartProgramInGuest(ctx context.Context, command, args string) (int64, error)
+	ExitCodeForProgramInGuest(ctx context.Context, pid int64) (int32, error)
+	DownloadFileInGuest(ctx context.Context, path string) (io.Reader, int64, error)
+	UploadFileInGuest(ctx context.Context, path string, src io.Reader, size int64) error
+	DeleteFileInGuest(ctx context.Context, path string) error
+	TerminateProgramInGuest(ctx context.Context, pid int64) error
+}
+
+// GuestOps runs commands and copies files through VMware Tools guest operations
+// via vCenter, so the guest does not need to be reachable over the network.
+// Guest operations do not return program output, so each command has its
+// stdout and stderr redirected to temporary files in the guest which are
+// downloaded once the command exits.
+type GuestOps struct {
+	ctx      context.Context
+	guestOps GuestOperations
+
+	// Stdout and Stderr receive the output of commands run in the guest
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func NewGuestOps(ctx context.Context, guestOps GuestOperations) *GuestOps {
+	return &GuestOps{ctx: ctx, guestOps: guestOps, Stdout: os.Stdout, Stderr: os.Stderr}
+}
+
+func (g *GuestOps) CanReachVM() error {
+	_, err := g.guestOps.StartProgramInGuest(g.ctx, guestOpsShell, "/C exit 0")
+	if err != nil {
+		return fmt.Errorf("guest operations are unavailable; please ensure VMware Tools is running in the VM: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GuestOps) CanLoginVM() error {
+	pid, err := g.guestOps.StartProgramInGuest(g.ctx, guestOpsShell, "/C exit 0")
+	if err != nil {
+		return fmt.Errorf("failed to start a program in the guest: %w", err)
+	}
+
+	exitCode, err := g.guestOps.ExitCodeForProgramInGuest(g.ctx, pid)
+	if err != nil {
+		return fmt.Errorf("failed to run a program in the guest: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("failed to run a program in the guest: exit code %d", exitCode)
+	}
+
+	return nil
+}
+
+func (g *GuestOps) UploadArtifact(sourceFilePath, destinationFilePath string) error {
+	file, err := os.Open(sourceFilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return g.guestOps.UploadFileInGuest(g.ctx, destinationFilePath, file, info.Size())
+}
+
+func (g *GuestOps) ExtractArchive(source, destination string) error {
+	command := fmt.Sprintf("powershell.exe Expand-Archive %s %s -Force", source, destination)
+	_, err := g.ExecuteCommand(command)
+	return err
+}
+
+func (g *GuestOps) ExecuteCommandWithTimeout(command string, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(g.ctx, timeout)
+	defer cancel()
+
+	return g.execute(ctx, command)
+}
+
+func (g *GuestOps) ExecuteCommand(command string) (int, error) {
+	exitCode, err := g.execute(g.ctx, command)
+	if err != nil {
+		return exitCode, fmt.Errorf("error executing '%s': %w", command, err)
+	}
+
+	return exitCode, nil
+}
+
+func (g *GuestOps) execute(ctx context.Context, command string) (int, error) {
+	nonce := make([]byte, 8)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return -1, err
+	}
+	outputFile := guestOpsTempDir + "stembuild-" + hex.EncodeToString(nonce)
+	stdoutFile := outputFile + ".stdout"
+	stderrFile := outputFile + ".stderr"
+
+	args := fmt.Sprintf(`/S /C "%s > "%s" 2> "%s""`, command, stdoutFile, stderrFile)
+	pid, err := g.guestOps.StartProgramInGuest(ctx, guestOpsShell, args)
+	if err != nil {
+		return -1, err
+	}
+
+	defer g.removeGuestFile(stdoutFile)
+	defer g.removeGuestFile(stderrFile)
+
+	exitCode, err := g.guestOps.ExitCodeForProgramInGuest(ctx, pid)
+	if err != nil {
+		if ctx.Err() != nil {
+			g.terminate(ctx, pid)
+		}
+		return -1, err
+	}
+
+	g.copyGuestFile(ctx, stdoutFile, g.Stdout)
+	errBuffer := new(bytes.Buffer)
+	g.copyGuestFile(ctx, stderrFile, io.MultiWriter(errBuffer, g.Stderr))
+
+	if exitCode != 0 {
+		return int(exitCode), fmt.Errorf("%s: %s", PowershellExecutionErrorMessage, errBuffer.String())
+	}
+
+	return int(exitCode), nil
+}
+
+// copyGuestFile is best effort: the command has already run, so output that
+// cannot be retrieved should not turn its result into a failure
+func (g *GuestOps) copyGuestFile(ctx context.Context, path string, dst io.Writer) {
+	reader, _, err := g.guestOps.DownloadFileInGuest(ctx, path)
+	if err != nil {
+		return
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	_, _ = io.Copy(dst, reader)
+}
+
+// terminate stops a command that is still running in the guest after its context is done
+func (g *GuestOps) terminate(ctx context.Context, pid int64) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), terminateTimeout)
+	defer cancel()
+
+	_ = g.guestOps.TerminateProgramInGuest(ctx, pid)
+}
+
+func (g *GuestOps) removeGuestFile(path string) {
+	_ = g.guestOps.DeleteFileInGuest(g.ctx, path)
+}
@@ -0,0 +1,243 @@
+package remotemanager_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
+)
+
+var _ = Describe("GuestOps RemoteManager", func() {
+	var (
+		fakeGuestOps  *remotemanagerfakes.FakeGuestOperations
+		remoteManager remotemanager.RemoteManager
+	)
+
+	BeforeEach(func() {
+		fakeGuestOps = &remotemanagerfakes.FakeGuestOperations{}
+		fakeGuestOps.StartProgramInGuestReturns(42, nil)
+		fakeGuestOps.DownloadFileInGuestReturns(strings.NewReader(""), 0, nil)
+		remoteManager = remotemanager.NewGuestOps(context.Background(), fakeGuestOps)
+	})
+
+	Describe("ExecuteCommand", func() {
+		It("runs the command with its output redirected to files in the guest", func() {
+			exitCode, err := remoteManager.ExecuteCommand("powershell.exe foobar")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exitCode).To(Equal(0))
+
+			Expect(fakeGuestOps.StartProgramInGuestCallCount()).To(Equal(1))
+			_, command, args := fakeGuestOps.StartProgramInGuestArgsForCall(0)
+			Expect(command).To(Equal(`C:\Windows\System32\cmd.exe`))
+			Expect(args).To(MatchRegexp(`^/S /C "powershell.exe foobar > "C:\\Windows\\Temp\\stembuild-\w+\.stdout" 2> "C:\\Windows\\Temp\\stembuild-\w+\.stderr""$`))
+
+			_, pid := fakeGuestOps.ExitCodeForProgramInGuestArgsForCall(0)
+			Expect(pid).To(Equal(int64(42)))
+		})
+
+		It("downloads and then deletes the output files", func() {
+			_, err := remoteManager.ExecuteCommand("foobar")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeGuestOps.DownloadFileInGuestCallCount()).To(Equal(2))
+			_, stdoutFile := fakeGuestOps.DownloadFileInGuestArgsForCall(0)
+			_, stderrFile := fakeGuestOps.DownloadFileInGuestArgsForCall(1)
+			Expect(stdoutFile).To(HaveSuffix(".stdout"))
+			Expect(stderrFile).To(HaveSuffix(".stderr"))
+
+			Expect(fakeGuestOps.DeleteFileInGuestCallCount()).To(Equal(2))
+			_, deleted := fakeGuestOps.DeleteFileInGuestArgsForCall(0)
+			Expect(deleted).To(Equal(stderrFile))
+			_, deleted = fakeGuestOps.DeleteFileInGuestArgsForCall(1)
+			Expect(deleted).To(Equal(stdoutFile))
+		})
+
+		It("uses a different output file for every command", func() {
+			_, _ = remoteManager.ExecuteCommand("foo")
+			_, _ = remoteManager.ExecuteCommand("bar")
+
+			_, _, firstArgs := fakeGuestOps.StartProgramInGuestArgsForCall(0)
+			_, _, secondArgs := fakeGuestOps.StartProgramInGuestArgsForCall(1)
+			Expect(strings.TrimPrefix(firstArgs, `/S /C "foo`)).NotTo(Equal(strings.TrimPrefix(secondArgs, `/S /C "bar`)))
+		})
+
+		It("returns the nonzero exit code and the command's stderr", func() {
+			fakeGuestOps.ExitCodeForProgramInGuestReturns(2, nil)
+			fakeGuestOps.DownloadFileInGuestCalls(func(_ context.Context, path string) (io.Reader, int64, error) {
+				if strings.HasSuffix(path, ".stderr") {
+					return strings.NewReader("access denied"), 13, nil
+				}
+				return strings.NewReader(""), 0, nil
+			})
+
+			exitCode, err := remoteManager.ExecuteCommand("foobar")
+			Expect(exitCode).To(Equal(2))
+			Expect(err).To(MatchError("error executing 'foobar': powershell encountered an issue: access denied"))
+		})
+
+		It("writes the command's output to Stdout and Stderr", func() {
+			fakeGuestOps.DownloadFileInGuestCalls(func(_ context.Context, path string) (io.Reader, int64, error) {
+				if strings.HasSuffix(path, ".stderr") {
+					return strings.NewReader("a warning"), 9, nil
+				}
+				return strings.NewReader("some output"), 11, nil
+			})
+			guestOps := remotemanager.NewGuestOps(context.Background(), fakeGuestOps)
+			stdout, stderr := new(strings.Builder), new(strings.Builder)
+			guestOps.Stdout = stdout
+			guestOps.Stderr = stderr
+
+			_, err := guestOps.ExecuteCommand("foobar")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal("some output"))
+			Expect(stderr.String()).To(Equal("a warning"))
+		})
+
+		It("succeeds even if the output cannot be downloaded", func() {
+			fakeGuestOps.DownloadFileInGuestReturns(nil, 0, errors.New("file not found"))
+
+			exitCode, err := remoteManager.ExecuteCommand("foobar")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exitCode).To(Equal(0))
+		})
+
+		It("returns an error if the command cannot be started", func() {
+			fakeGuestOps.StartProgramInGuestReturns(-1, errors.New("guest is rebooting"))
+
+			exitCode, err := remoteManager.ExecuteCommand("foobar")
+			Expect(exitCode).To(Equal(-1))
+			Expect(err).To(MatchError("error executing 'foobar': guest is rebooting"))
+			Expect(fakeGuestOps.DeleteFileInGuestCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if the exit code cannot be retrieved", func() {
+			fakeGuestOps.ExitCodeForProgramInGuestReturns(-1, errors.New("could not observe program exiting"))
+
+			exitCode, err := remoteManager.ExecuteCommand("foobar")
+			Expect(exitCode).To(Equal(-1))
+			Expect(err).To(MatchError("error executing 'foobar': could not observe program exiting"))
+		})
+	})
+
+	Describe("ExecuteCommandWithTimeout", func() {
+		It("waits for the command no longer than the timeout", func() {
+			fakeGuestOps.ExitCodeForProgramInGuestCalls(func(ctx context.Context, _ int64) (int32, error) {
+				<-ctx.Done()
+				return -1, ctx.Err()
+			})
+
+			_, err := remoteManager.ExecuteCommandWithTimeout("foobar", 10*time.Millisecond)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+
+		It("terminates the command in the guest when it does not finish in time", func() {
+			fakeGuestOps.ExitCodeForProgramInGuestCalls(func(ctx context.Context, _ int64) (int32, error) {
+				<-ctx.Done()
+				return -1, ctx.Err()
+			})
+			var terminateErr error
+			fakeGuestOps.TerminateProgramInGuestCalls(func(ctx context.Context, _ int64) error {
+				terminateErr = ctx.Err()
+				return nil
+			})
+
+			_, err := remoteManager.ExecuteCommandWithTimeout("foobar", 10*time.Millisecond)
+			Expect(err).To(HaveOccurred())
+
+			Expect(fakeGuestOps.TerminateProgramInGuestCallCount()).To(Equal(1))
+			_, pid := fakeGuestOps.TerminateProgramInGuestArgsForCall(0)
+			Expect(pid).To(Equal(int64(42)))
+			Expect(terminateErr).NotTo(HaveOccurred())
+		})
+
+		It("does not terminate a command that could not be observed for another reason", func() {
+			fakeGuestOps.ExitCodeForProgramInGuestReturns(-1, errors.New("could not observe program exiting"))
+
+			_, err := remoteManager.ExecuteCommandWithTimeout("foobar", time.Minute)
+			Expect(err).To(HaveOccurred())
+			Expect(fakeGuestOps.TerminateProgramInGuestCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("UploadArtifact", func() {
+		It("uploads the file to the guest", func() {
+			source := filepath.Join(GinkgoT().TempDir(), "artifact.zip")
+			Expect(os.WriteFile(source, []byte("contents"), 0644)).To(Succeed())
+
+			var uploaded []byte
+			fakeGuestOps.UploadFileInGuestCalls(func(_ context.Context, _ string, src io.Reader, _ int64) error {
+				var err error
+				uploaded, err = io.ReadAll(src)
+				return err
+			})
+
+			err := remoteManager.UploadArtifact(source, `C:\provision\artifact.zip`)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, destination, _, size := fakeGuestOps.UploadFileInGuestArgsForCall(0)
+			Expect(destination).To(Equal(`C:\provision\artifact.zip`))
+			Expect(size).To(Equal(int64(8)))
+			Expect(uploaded).To(Equal([]byte("contents")))
+		})
+
+		It("returns an error if the upload fails", func() {
+			source := filepath.Join(GinkgoT().TempDir(), "artifact.zip")
+			Expect(os.WriteFile(source, []byte("contents"), 0644)).To(Succeed())
+			fakeGuestOps.UploadFileInGuestReturns(errors.New("disk full"))
+
+			err := remoteManager.UploadArtifact(source, `C:\provision\artifact.zip`)
+			Expect(err).To(MatchError("disk full"))
+		})
+
+		It("returns an error if the source does not exist", func() {
+			err := remoteManager.UploadArtifact(filepath.Join(GinkgoT().TempDir(), "missing"), `C:\provision\missing`)
+			Expect(err).To(HaveOccurred())
+			Expect(fakeGuestOps.UploadFileInGuestCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("ExtractArchive", func() {
+		It("expands the archive with powershell", func() {
+			err := remoteManager.ExtractArchive(`C:\provision\a.zip`, `C:\provision\`)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, args := fakeGuestOps.StartProgramInGuestArgsForCall(0)
+			Expect(args).To(HavePrefix(`/S /C "powershell.exe Expand-Archive C:\provision\a.zip C:\provision\ -Force > `))
+		})
+	})
+
+	Describe("CanReachVM", func() {
+		It("returns nil if a program can be started in the guest", func() {
+			Expect(remoteManager.CanReachVM()).To(Succeed())
+		})
+
+		It("returns an error if guest operations are unavailable", func() {
+			fakeGuestOps.StartProgramInGuestReturns(-1, errors.New("tools not running"))
+
+			err := remoteManager.CanReachVM()
+			Expect(err).To(MatchError(ContainSubstring("please ensure VMware Tools is running in the VM: tools not running")))
+		})
+	})
+
+	Describe("CanLoginVM", func() {
+		It("returns nil if a program runs successfully in the guest", func() {
+			Expect(remoteManager.CanLoginVM()).To(Succeed())
+		})
+
+		It("returns an error if the program exits nonzero", func() {
+			fakeGuestOps.ExitCodeForProgramInGuestReturns(1, nil)
+
+			err := remoteManager.CanLoginVM()
+			Expect(err).To(MatchError("failed to run a program in the guest: exit code 1"))
+		})
+	})
+})
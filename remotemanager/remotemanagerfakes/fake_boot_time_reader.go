@@ -0,0 +1,108 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package remotemanagerfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+type FakeBootTimeReader struct {
+	LastBootUpTimeStub        func() (time.Time, error)
+	lastBootUpTimeMutex       sync.RWMutex
+	lastBootUpTimeArgsForCall []struct {
+	}
+	lastBootUpTimeReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	lastBootUpTimeReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBootTimeReader) LastBootUpTime() (time.Time, error) {
+	fake.lastBootUpTimeMutex.Lock()
+	ret, specificReturn := fake.lastBootUpTimeReturnsOnCall[len(fake.lastBootUpTimeArgsForCall)]
+	fake.lastBootUpTimeArgsForCall = append(fake.lastBootUpTimeArgsForCall, struct {
+	}{})
+	stub := fake.LastBootUpTimeStub
+	fakeReturns := fake.lastBootUpTimeReturns
+	fake.recordInvocation("LastBootUpTime", []interface{}{})
+	fake.lastBootUpTimeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBootTimeReader) LastBootUpTimeCallCount() int {
+	fake.lastBootUpTimeMutex.RLock()
+	defer fake.lastBootUpTimeMutex.RUnlock()
+	return len(fake.lastBootUpTimeArgsForCall)
+}
+
+func (fake *FakeBootTimeReader) LastBootUpTimeCalls(stub func() (time.Time, error)) {
+	fake.lastBootUpTimeMutex.Lock()
+	defer fake.lastBootUpTimeMutex.Unlock()
+	fake.LastBootUpTimeStub = stub
+}
+
+func (fake *FakeBootTimeReader) LastBootUpTimeReturns(result1 time.Time, result2 error) {
+	fake.lastBootUpTimeMutex.Lock()
+	defer fake.lastBootUpTimeMutex.Unlock()
+	fake.LastBootUpTimeStub = nil
+	fake.lastBootUpTimeReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBootTimeReader) LastBootUpTimeReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.lastBootUpTimeMutex.Lock()
+	defer fake.lastBootUpTimeMutex.Unlock()
+	fake.LastBootUpTimeStub = nil
+	if fake.lastBootUpTimeReturnsOnCall == nil {
+		fake.lastBootUpTimeReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.lastBootUpTimeReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBootTimeReader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.lastBootUpTimeMutex.RLock()
+	defer fake.lastBootUpTimeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeBootTimeReader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ remotemanager.BootTimeReader = new(FakeBootTimeReader)
@@ -0,0 +1,521 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package remotemanagerfakes
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+type FakeGuestOperations struct {
+	DeleteFileInGuestStub        func(context.Context, string) error
+	deleteFileInGuestMutex       sync.RWMutex
+	deleteFileInGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	deleteFileInGuestReturns struct {
+		result1 error
+	}
+	deleteFileInGuestReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DownloadFileInGuestStub        func(context.Context, string) (io.Reader, int64, error)
+	downloadFileInGuestMutex       sync.RWMutex
+	downloadFileInGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	downloadFileInGuestReturns struct {
+		result1 io.Reader
+		result2 int64
+		result3 error
+	}
+	downloadFileInGuestReturnsOnCall map[int]struct {
+		result1 io.Reader
+		result2 int64
+		result3 error
+	}
+	ExitCodeForProgramInGuestStub        func(context.Context, int64) (int32, error)
+	exitCodeForProgramInGuestMutex       sync.RWMutex
+	exitCodeForProgramInGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+	}
+	exitCodeForProgramInGuestReturns struct {
+		result1 int32
+		result2 error
+	}
+	exitCodeForProgramInGuestReturnsOnCall map[int]struct {
+		result1 int32
+		result2 error
+	}
+	StartProgramInGuestStub        func(context.Context, string, string) (int64, error)
+	startProgramInGuestMutex       sync.RWMutex
+	startProgramInGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	startProgramInGuestReturns struct {
+		result1 int64
+		result2 error
+	}
+	startProgramInGuestReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	TerminateProgramInGuestStub        func(context.Context, int64) error
+	terminateProgramInGuestMutex       sync.RWMutex
+	terminateProgramInGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+	}
+	terminateProgramInGuestReturns struct {
+		result1 error
+	}
+	terminateProgramInGuestReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UploadFileInGuestStub        func(context.Context, string, io.Reader, int64) error
+	uploadFileInGuestMutex       sync.RWMutex
+	uploadFileInGuestArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 io.Reader
+		arg4 int64
+	}
+	uploadFileInGuestReturns struct {
+		result1 error
+	}
+	uploadFileInGuestReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeGuestOperations) DeleteFileInGuest(arg1 context.Context, arg2 string) error {
+	fake.deleteFileInGuestMutex.Lock()
+	ret, specificReturn := fake.deleteFileInGuestReturnsOnCall[len(fake.deleteFileInGuestArgsForCall)]
+	fake.deleteFileInGuestArgsForCall = append(fake.deleteFileInGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteFileInGuestStub
+	fakeReturns := fake.deleteFileInGuestReturns
+	fake.recordInvocation("DeleteFileInGuest", []interface{}{arg1, arg2})
+	fake.deleteFileInGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGuestOperations) DeleteFileInGuestCallCount() int {
+	fake.deleteFileInGuestMutex.RLock()
+	defer fake.deleteFileInGuestMutex.RUnlock()
+	return len(fake.deleteFileInGuestArgsForCall)
+}
+
+func (fake *FakeGuestOperations) DeleteFileInGuestCalls(stub func(context.Context, string) error) {
+	fake.deleteFileInGuestMutex.Lock()
+	defer fake.deleteFileInGuestMutex.Unlock()
+	fake.DeleteFileInGuestStub = stub
+}
+
+func (fake *FakeGuestOperations) DeleteFileInGuestArgsForCall(i int) (context.Context, string) {
+	fake.deleteFileInGuestMutex.RLock()
+	defer fake.deleteFileInGuestMutex.RUnlock()
+	argsForCall := fake.deleteFileInGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGuestOperations) DeleteFileInGuestReturns(result1 error) {
+	fake.deleteFileInGuestMutex.Lock()
+	defer fake.deleteFileInGuestMutex.Unlock()
+	fake.DeleteFileInGuestStub = nil
+	fake.deleteFileInGuestReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGuestOperations) DeleteFileInGuestReturnsOnCall(i int, result1 error) {
+	fake.deleteFileInGuestMutex.Lock()
+	defer fake.deleteFileInGuestMutex.Unlock()
+	fake.DeleteFileInGuestStub = nil
+	if fake.deleteFileInGuestReturnsOnCall == nil {
+		fake.deleteFileInGuestReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFileInGuestReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGuestOperations) DownloadFileInGuest(arg1 context.Context, arg2 string) (io.Reader, int64, error) {
+	fake.downloadFileInGuestMutex.Lock()
+	ret, specificReturn := fake.downloadFileInGuestReturnsOnCall[len(fake.downloadFileInGuestArgsForCall)]
+	fake.downloadFileInGuestArgsForCall = append(fake.downloadFileInGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DownloadFileInGuestStub
+	fakeReturns := fake.downloadFileInGuestReturns
+	fake.recordInvocation("DownloadFileInGuest", []interface{}{arg1, arg2})
+	fake.downloadFileInGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeGuestOperations) DownloadFileInGuestCallCount() int {
+	fake.downloadFileInGuestMutex.RLock()
+	defer fake.downloadFileInGuestMutex.RUnlock()
+	return len(fake.downloadFileInGuestArgsForCall)
+}
+
+func (fake *FakeGuestOperations) DownloadFileInGuestCalls(stub func(context.Context, string) (io.Reader, int64, error)) {
+	fake.downloadFileInGuestMutex.Lock()
+	defer fake.downloadFileInGuestMutex.Unlock()
+	fake.DownloadFileInGuestStub = stub
+}
+
+func (fake *FakeGuestOperations) DownloadFileInGuestArgsForCall(i int) (context.Context, string) {
+	fake.downloadFileInGuestMutex.RLock()
+	defer fake.downloadFileInGuestMutex.RUnlock()
+	argsForCall := fake.downloadFileInGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGuestOperations) DownloadFileInGuestReturns(result1 io.Reader, result2 int64, result3 error) {
+	fake.downloadFileInGuestMutex.Lock()
+	defer fake.downloadFileInGuestMutex.Unlock()
+	fake.DownloadFileInGuestStub = nil
+	fake.downloadFileInGuestReturns = struct {
+		result1 io.Reader
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGuestOperations) DownloadFileInGuestReturnsOnCall(i int, result1 io.Reader, result2 int64, result3 error) {
+	fake.downloadFileInGuestMutex.Lock()
+	defer fake.downloadFileInGuestMutex.Unlock()
+	fake.DownloadFileInGuestStub = nil
+	if fake.downloadFileInGuestReturnsOnCall == nil {
+		fake.downloadFileInGuestReturnsOnCall = make(map[int]struct {
+			result1 io.Reader
+			result2 int64
+			result3 error
+		})
+	}
+	fake.downloadFileInGuestReturnsOnCall[i] = struct {
+		result1 io.Reader
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGuestOperations) ExitCodeForProgramInGuest(arg1 context.Context, arg2 int64) (int32, error) {
+	fake.exitCodeForProgramInGuestMutex.Lock()
+	ret, specificReturn := fake.exitCodeForProgramInGuestReturnsOnCall[len(fake.exitCodeForProgramInGuestArgsForCall)]
+	fake.exitCodeForProgramInGuestArgsForCall = append(fake.exitCodeForProgramInGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+	}{arg1, arg2})
+	stub := fake.ExitCodeForProgramInGuestStub
+	fakeReturns := fake.exitCodeForProgramInGuestReturns
+	fake.recordInvocation("ExitCodeForProgramInGuest", []interface{}{arg1, arg2})
+	fake.exitCodeForProgramInGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGuestOperations) ExitCodeForProgramInGuestCallCount() int {
+	fake.exitCodeForProgramInGuestMutex.RLock()
+	defer fake.exitCodeForProgramInGuestMutex.RUnlock()
+	return len(fake.exitCodeForProgramInGuestArgsForCall)
+}
+
+func (fake *FakeGuestOperations) ExitCodeForProgramInGuestCalls(stub func(context.Context, int64) (int32, error)) {
+	fake.exitCodeForProgramInGuestMutex.Lock()
+	defer fake.exitCodeForProgramInGuestMutex.Unlock()
+	fake.ExitCodeForProgramInGuestStub = stub
+}
+
+func (fake *FakeGuestOperations) ExitCodeForProgramInGuestArgsForCall(i int) (context.Context, int64) {
+	fake.exitCodeForProgramInGuestMutex.RLock()
+	defer fake.exitCodeForProgramInGuestMutex.RUnlock()
+	argsForCall := fake.exitCodeForProgramInGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGuestOperations) ExitCodeForProgramInGuestReturns(result1 int32, result2 error) {
+	fake.exitCodeForProgramInGuestMutex.Lock()
+	defer fake.exitCodeForProgramInGuestMutex.Unlock()
+	fake.ExitCodeForProgramInGuestStub = nil
+	fake.exitCodeForProgramInGuestReturns = struct {
+		result1 int32
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGuestOperations) ExitCodeForProgramInGuestReturnsOnCall(i int, result1 int32, result2 error) {
+	fake.exitCodeForProgramInGuestMutex.Lock()
+	defer fake.exitCodeForProgramInGuestMutex.Unlock()
+	fake.ExitCodeForProgramInGuestStub = nil
+	if fake.exitCodeForProgramInGuestReturnsOnCall == nil {
+		fake.exitCodeForProgramInGuestReturnsOnCall = make(map[int]struct {
+			result1 int32
+			result2 error
+		})
+	}
+	fake.exitCodeForProgramInGuestReturnsOnCall[i] = struct {
+		result1 int32
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGuestOperations) StartProgramInGuest(arg1 context.Context, arg2 string, arg3 string) (int64, error) {
+	fake.startProgramInGuestMutex.Lock()
+	ret, specificReturn := fake.startProgramInGuestReturnsOnCall[len(fake.startProgramInGuestArgsForCall)]
+	fake.startProgramInGuestArgsForCall = append(fake.startProgramInGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.StartProgramInGuestStub
+	fakeReturns := fake.startProgramInGuestReturns
+	fake.recordInvocation("StartProgramInGuest", []interface{}{arg1, arg2, arg3})
+	fake.startProgramInGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGuestOperations) StartProgramInGuestCallCount() int {
+	fake.startProgramInGuestMutex.RLock()
+	defer fake.startProgramInGuestMutex.RUnlock()
+	return len(fake.startProgramInGuestArgsForCall)
+}
+
+func (fake *FakeGuestOperations) StartProgramInGuestCalls(stub func(context.Context, string, string) (int64, error)) {
+	fake.startProgramInGuestMutex.Lock()
+	defer fake.startProgramInGuestMutex.Unlock()
+	fake.StartProgramInGuestStub = stub
+}
+
+func (fake *FakeGuestOperations) StartProgramInGuestArgsForCall(i int) (context.Context, string, string) {
+	fake.startProgramInGuestMutex.RLock()
+	defer fake.startProgramInGuestMutex.RUnlock()
+	argsForCall := fake.startProgramInGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGuestOperations) StartProgramInGuestReturns(result1 int64, result2 error) {
+	fake.startProgramInGuestMutex.Lock()
+	defer fake.startProgramInGuestMutex.Unlock()
+	fake.StartProgramInGuestStub = nil
+	fake.startProgramInGuestReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGuestOperations) StartProgramInGuestReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.startProgramInGuestMutex.Lock()
+	defer fake.startProgramInGuestMutex.Unlock()
+	fake.StartProgramInGuestStub = nil
+	if fake.startProgramInGuestReturnsOnCall == nil {
+		fake.startProgramInGuestReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.startProgramInGuestReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGuestOperations) TerminateProgramInGuest(arg1 context.Context, arg2 int64) error {
+	fake.terminateProgramInGuestMutex.Lock()
+	ret, specificReturn := fake.terminateProgramInGuestReturnsOnCall[len(fake.terminateProgramInGuestArgsForCall)]
+	fake.terminateProgramInGuestArgsForCall = append(fake.terminateProgramInGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+	}{arg1, arg2})
+	stub := fake.TerminateProgramInGuestStub
+	fakeReturns := fake.terminateProgramInGuestReturns
+	fake.recordInvocation("TerminateProgramInGuest", []interface{}{arg1, arg2})
+	fake.terminateProgramInGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGuestOperations) TerminateProgramInGuestCallCount() int {
+	fake.terminateProgramInGuestMutex.RLock()
+	defer fake.terminateProgramInGuestMutex.RUnlock()
+	return len(fake.terminateProgramInGuestArgsForCall)
+}
+
+func (fake *FakeGuestOperations) TerminateProgramInGuestCalls(stub func(context.Context, int64) error) {
+	fake.terminateProgramInGuestMutex.Lock()
+	defer fake.terminateProgramInGuestMutex.Unlock()
+	fake.TerminateProgramInGuestStub = stub
+}
+
+func (fake *FakeGuestOperations) TerminateProgramInGuestArgsForCall(i int) (context.Context, int64) {
+	fake.terminateProgramInGuestMutex.RLock()
+	defer fake.terminateProgramInGuestMutex.RUnlock()
+	argsForCall := fake.terminateProgramInGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGuestOperations) TerminateProgramInGuestReturns(result1 error) {
+	fake.terminateProgramInGuestMutex.Lock()
+	defer fake.terminateProgramInGuestMutex.Unlock()
+	fake.TerminateProgramInGuestStub = nil
+	fake.terminateProgramInGuestReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGuestOperations) TerminateProgramInGuestReturnsOnCall(i int, result1 error) {
+	fake.terminateProgramInGuestMutex.Lock()
+	defer fake.terminateProgramInGuestMutex.Unlock()
+	fake.TerminateProgramInGuestStub = nil
+	if fake.terminateProgramInGuestReturnsOnCall == nil {
+		fake.terminateProgramInGuestReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.terminateProgramInGuestReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGuestOperations) UploadFileInGuest(arg1 context.Context, arg2 string, arg3 io.Reader, arg4 int64) error {
+	fake.uploadFileInGuestMutex.Lock()
+	ret, specificReturn := fake.uploadFileInGuestReturnsOnCall[len(fake.uploadFileInGuestArgsForCall)]
+	fake.uploadFileInGuestArgsForCall = append(fake.uploadFileInGuestArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 io.Reader
+		arg4 int64
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.UploadFileInGuestStub
+	fakeReturns := fake.uploadFileInGuestReturns
+	fake.recordInvocation("UploadFileInGuest", []interface{}{arg1, arg2, arg3, arg4})
+	fake.uploadFileInGuestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGuestOperations) UploadFileInGuestCallCount() int {
+	fake.uploadFileInGuestMutex.RLock()
+	defer fake.uploadFileInGuestMutex.RUnlock()
+	return len(fake.uploadFileInGuestArgsForCall)
+}
+
+func (fake *FakeGuestOperations) UploadFileInGuestCalls(stub func(context.Context, string, io.Reader, int64) error) {
+	fake.uploadFileInGuestMutex.Lock()
+	defer fake.uploadFileInGuestMutex.Unlock()
+	fake.UploadFileInGuestStub = stub
+}
+
+func (fake *FakeGuestOperations) UploadFileInGuestArgsForCall(i int) (context.Context, string, io.Reader, int64) {
+	fake.uploadFileInGuestMutex.RLock()
+	defer fake.uploadFileInGuestMutex.RUnlock()
+	argsForCall := fake.uploadFileInGuestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeGuestOperations) UploadFileInGuestReturns(result1 error) {
+	fake.uploadFileInGuestMutex.Lock()
+	defer fake.uploadFileInGuestMutex.Unlock()
+	fake.UploadFileInGuestStub = nil
+	fake.uploadFileInGuestReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGuestOperations) UploadFileInGuestReturnsOnCall(i int, result1 error) {
+	fake.uploadFileInGuestMutex.Lock()
+	defer fake.uploadFileInGuestMutex.Unlock()
+	fake.UploadFileInGuestStub = nil
+	if fake.uploadFileInGuestReturnsOnCall == nil {
+		fake.uploadFileInGuestReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.uploadFileInGuestReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGuestOperations) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteFileInGuestMutex.RLock()
+	defer fake.deleteFileInGuestMutex.RUnlock()
+	fake.downloadFileInGuestMutex.RLock()
+	defer fake.downloadFileInGuestMutex.RUnlock()
+	fake.exitCodeForProgramInGuestMutex.RLock()
+	defer fake.exitCodeForProgramInGuestMutex.RUnlock()
+	fake.startProgramInGuestMutex.RLock()
+	defer fake.startProgramInGuestMutex.RUnlock()
+	fake.terminateProgramInGuestMutex.RLock()
+	defer fake.terminateProgramInGuestMutex.RUnlock()
+	fake.uploadFileInGuestMutex.RLock()
+	defer fake.uploadFileInGuestMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeGuestOperations) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ remotemanager.GuestOperations = new(FakeGuestOperations)
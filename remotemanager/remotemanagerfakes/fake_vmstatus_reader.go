@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package remotemanagerfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+type FakeVMStatusReader struct {
+	RebootStatusStub        func(context.Context) (mo.VirtualMachine, error)
+	rebootStatusMutex       sync.RWMutex
+	rebootStatusArgsForCall []struct {
+		arg1 context.Context
+	}
+	rebootStatusReturns struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}
+	rebootStatusReturnsOnCall map[int]struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeVMStatusReader) RebootStatus(arg1 context.Context) (mo.VirtualMachine, error) {
+	fake.rebootStatusMutex.Lock()
+	ret, specificReturn := fake.rebootStatusReturnsOnCall[len(fake.rebootStatusArgsForCall)]
+	fake.rebootStatusArgsForCall = append(fake.rebootStatusArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.RebootStatusStub
+	fakeReturns := fake.rebootStatusReturns
+	fake.recordInvocation("RebootStatus", []interface{}{arg1})
+	fake.rebootStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVMStatusReader) RebootStatusCallCount() int {
+	fake.rebootStatusMutex.RLock()
+	defer fake.rebootStatusMutex.RUnlock()
+	return len(fake.rebootStatusArgsForCall)
+}
+
+func (fake *FakeVMStatusReader) RebootStatusCalls(stub func(context.Context) (mo.VirtualMachine, error)) {
+	fake.rebootStatusMutex.Lock()
+	defer fake.rebootStatusMutex.Unlock()
+	fake.RebootStatusStub = stub
+}
+
+func (fake *FakeVMStatusReader) RebootStatusArgsForCall(i int) context.Context {
+	fake.rebootStatusMutex.RLock()
+	defer fake.rebootStatusMutex.RUnlock()
+	argsForCall := fake.rebootStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeVMStatusReader) RebootStatusReturns(result1 mo.VirtualMachine, result2 error) {
+	fake.rebootStatusMutex.Lock()
+	defer fake.rebootStatusMutex.Unlock()
+	fake.RebootStatusStub = nil
+	fake.rebootStatusReturns = struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVMStatusReader) RebootStatusReturnsOnCall(i int, result1 mo.VirtualMachine, result2 error) {
+	fake.rebootStatusMutex.Lock()
+	defer fake.rebootStatusMutex.Unlock()
+	fake.RebootStatusStub = nil
+	if fake.rebootStatusReturnsOnCall == nil {
+		fake.rebootStatusReturnsOnCall = make(map[int]struct {
+			result1 mo.VirtualMachine
+			result2 error
+		})
+	}
+	fake.rebootStatusReturnsOnCall[i] = struct {
+		result1 mo.VirtualMachine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVMStatusReader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.rebootStatusMutex.RLock()
+	defer fake.rebootStatusMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeVMStatusReader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ remotemanager.VMStatusReader = new(FakeVMStatusReader)
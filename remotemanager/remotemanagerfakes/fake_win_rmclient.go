@@ -2,6 +2,7 @@
 package remotemanagerfakes
 
 import (
+	"context"
 	"io"
 	"sync"
 
@@ -22,18 +23,19 @@ type FakeWinRMClient struct {
 		result1 *winrm.Shell
 		result2 error
 	}
-	RunStub        func(string, io.Writer, io.Writer) (int, error)
-	runMutex       sync.RWMutex
-	runArgsForCall []struct {
-		arg1 string
-		arg2 io.Writer
+	RunWithContextStub        func(context.Context, string, io.Writer, io.Writer) (int, error)
+	runWithContextMutex       sync.RWMutex
+	runWithContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
 		arg3 io.Writer
+		arg4 io.Writer
 	}
-	runReturns struct {
+	runWithContextReturns struct {
 		result1 int
 		result2 error
 	}
-	runReturnsOnCall map[int]struct {
+	runWithContextReturnsOnCall map[int]struct {
 		result1 int
 		result2 error
 	}
@@ -97,20 +99,21 @@ func (fake *FakeWinRMClient) CreateShellReturnsOnCall(i int, result1 *winrm.Shel
 	}{result1, result2}
 }
 
-func (fake *FakeWinRMClient) Run(arg1 string, arg2 io.Writer, arg3 io.Writer) (int, error) {
-	fake.runMutex.Lock()
-	ret, specificReturn := fake.runReturnsOnCall[len(fake.runArgsForCall)]
-	fake.runArgsForCall = append(fake.runArgsForCall, struct {
-		arg1 string
-		arg2 io.Writer
+func (fake *FakeWinRMClient) RunWithContext(arg1 context.Context, arg2 string, arg3 io.Writer, arg4 io.Writer) (int, error) {
+	fake.runWithContextMutex.Lock()
+	ret, specificReturn := fake.runWithContextReturnsOnCall[len(fake.runWithContextArgsForCall)]
+	fake.runWithContextArgsForCall = append(fake.runWithContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
 		arg3 io.Writer
-	}{arg1, arg2, arg3})
-	stub := fake.RunStub
-	fakeReturns := fake.runReturns
-	fake.recordInvocation("Run", []interface{}{arg1, arg2, arg3})
-	fake.runMutex.Unlock()
+		arg4 io.Writer
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunWithContextStub
+	fakeReturns := fake.runWithContextReturns
+	fake.recordInvocation("RunWithContext", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runWithContextMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -118,46 +121,46 @@ func (fake *FakeWinRMClient) Run(arg1 string, arg2 io.Writer, arg3 io.Writer) (i
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeWinRMClient) RunCallCount() int {
-	fake.runMutex.RLock()
-	defer fake.runMutex.RUnlock()
-	return len(fake.runArgsForCall)
+func (fake *FakeWinRMClient) RunWithContextCallCount() int {
+	fake.runWithContextMutex.RLock()
+	defer fake.runWithContextMutex.RUnlock()
+	return len(fake.runWithContextArgsForCall)
 }
 
-func (fake *FakeWinRMClient) RunCalls(stub func(string, io.Writer, io.Writer) (int, error)) {
-	fake.runMutex.Lock()
-	defer fake.runMutex.Unlock()
-	fake.RunStub = stub
+func (fake *FakeWinRMClient) RunWithContextCalls(stub func(context.Context, string, io.Writer, io.Writer) (int, error)) {
+	fake.runWithContextMutex.Lock()
+	defer fake.runWithContextMutex.Unlock()
+	fake.RunWithContextStub = stub
 }
 
-func (fake *FakeWinRMClient) RunArgsForCall(i int) (string, io.Writer, io.Writer) {
-	fake.runMutex.RLock()
-	defer fake.runMutex.RUnlock()
-	argsForCall := fake.runArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+func (fake *FakeWinRMClient) RunWithContextArgsForCall(i int) (context.Context, string, io.Writer, io.Writer) {
+	fake.runWithContextMutex.RLock()
+	defer fake.runWithContextMutex.RUnlock()
+	argsForCall := fake.runWithContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
-func (fake *FakeWinRMClient) RunReturns(result1 int, result2 error) {
-	fake.runMutex.Lock()
-	defer fake.runMutex.Unlock()
-	fake.RunStub = nil
-	fake.runReturns = struct {
+func (fake *FakeWinRMClient) RunWithContextReturns(result1 int, result2 error) {
+	fake.runWithContextMutex.Lock()
+	defer fake.runWithContextMutex.Unlock()
+	fake.RunWithContextStub = nil
+	fake.runWithContextReturns = struct {
 		result1 int
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeWinRMClient) RunReturnsOnCall(i int, result1 int, result2 error) {
-	fake.runMutex.Lock()
-	defer fake.runMutex.Unlock()
-	fake.RunStub = nil
-	if fake.runReturnsOnCall == nil {
-		fake.runReturnsOnCall = make(map[int]struct {
+func (fake *FakeWinRMClient) RunWithContextReturnsOnCall(i int, result1 int, result2 error) {
+	fake.runWithContextMutex.Lock()
+	defer fake.runWithContextMutex.Unlock()
+	fake.RunWithContextStub = nil
+	if fake.runWithContextReturnsOnCall == nil {
+		fake.runWithContextReturnsOnCall = make(map[int]struct {
 			result1 int
 			result2 error
 		})
 	}
-	fake.runReturnsOnCall[i] = struct {
+	fake.runWithContextReturnsOnCall[i] = struct {
 		result1 int
 		result2 error
 	}{result1, result2}
@@ -168,8 +171,8 @@ func (fake *FakeWinRMClient) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.createShellMutex.RLock()
 	defer fake.createShellMutex.RUnlock()
-	fake.runMutex.RLock()
-	defer fake.runMutex.RUnlock()
+	fake.runWithContextMutex.RLock()
+	defer fake.runWithContextMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,142 @@
+package remotemanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+//counterfeiter:generate . VMStatusReader
+type VMStatusReader interface {
+	RebootStatus(ctx context.Context) (mo.VirtualMachine, error)
+}
+
+// RebootBaseliner is implemented by reboot checkers that need to see the VM before a reboot is triggered
+type RebootBaseliner interface {
+	RecordBaseline()
+}
+
+// guestBootTimeSkew is how much later than the baseline the last boot time of the guest must be to count as a reboot,
+// since Windows derives it from a clock that may be adjusted while the guest runs
+const guestBootTimeSkew = 5 * time.Second
+
+// VCenterRebootChecker finds out whether a reboot has finished from what vCenter reports about the VM,
+// so unlike RebootChecker it never schedules a reboot in the guest to probe it while vCenter can tell.
+type VCenterRebootChecker struct {
+	ctx context.Context
+	vm  VMStatusReader
+
+	// Fallback decides instead when vCenter cannot tell, because the status of the VM
+	// cannot be read, VMware Tools is not installed in the guest, or the guest is up
+	// without having been seen going down since the baseline
+	Fallback RebootCheckerI
+
+	// Guest reads the last boot time of Windows in the guest, which, unlike the boot time vCenter
+	// reports, changes on a restart from inside the guest. When it was read for the baseline, it
+	// decides for a guest that is up, and the Fallback is not asked.
+	Guest BootTimeReader
+
+	hasBaseline      bool
+	baselineBootTime time.Time
+	wentDown         bool
+
+	hasGuestBaseline      bool
+	baselineGuestBootTime time.Time
+}
+
+func NewVCenterRebootChecker(ctx context.Context, vm VMStatusReader, fallback RebootCheckerI) *VCenterRebootChecker {
+	return &VCenterRebootChecker{ctx: ctx, vm: vm, Fallback: fallback}
+}
+
+// RecordBaseline remembers the boot time of the VM, and the last boot time of the guest, before a reboot is triggered.
+// Without a baseline, only seeing the guest go down and come back up, or the Fallback, finishes a reboot.
+func (rc *VCenterRebootChecker) RecordBaseline() {
+	rc.hasBaseline = false
+	rc.wentDown = false
+	rc.hasGuestBaseline = false
+
+	if rc.Guest != nil {
+		bootTime, err := rc.Guest.LastBootUpTime()
+		if err == nil {
+			rc.hasGuestBaseline = true
+			rc.baselineGuestBootTime = bootTime
+		}
+	}
+
+	status, err := rc.vm.RebootStatus(rc.ctx)
+	if err != nil {
+		return
+	}
+	rc.hasBaseline = true
+	rc.baselineBootTime = time.Time{}
+	if status.Runtime.BootTime != nil {
+		rc.baselineBootTime = *status.Runtime.BootTime
+	}
+}
+
+// RebootHasFinished reports a finished reboot once the VM is powered on and VMware Tools is running
+// in the guest with a green heartbeat, and either the boot time is later than the baseline or the guest
+// has been seen going down since. A restart from inside the guest leaves the boot time unchanged, as
+// vCenter only updates it on power on, and is usually over between two checks, so otherwise the guest
+// decides by reporting a later last boot time than the baseline. Only without a guest baseline is a guest
+// that has not been seen going down left to the Fallback, which refuses while a shutdown is pending.
+func (rc *VCenterRebootChecker) RebootHasFinished() (bool, error) {
+	status, err := rc.vm.RebootStatus(rc.ctx)
+	if err != nil && rc.ctx.Err() != nil {
+		return false, err
+	}
+	if err != nil || !toolsInstalled(status) {
+		if rc.Fallback != nil {
+			return rc.Fallback.RebootHasFinished()
+		}
+		if err != nil {
+			return false, fmt.Errorf("cannot read the status of the VM from vCenter: %s", err)
+		}
+		return false, fmt.Errorf("cannot tell whether the VM has rebooted without VMware Tools in the guest")
+	}
+
+	if !guestIsUp(status) {
+		rc.wentDown = true
+		return false, nil
+	}
+
+	if rc.wentDown || (rc.hasBaseline && status.Runtime.BootTime.After(rc.baselineBootTime)) {
+		rc.hasBaseline = false
+		rc.wentDown = false
+		rc.hasGuestBaseline = false
+		return true, nil
+	}
+	if rc.hasGuestBaseline {
+		// guest operations fail while the guest restarts, which only means the reboot has not finished yet
+		bootTime, err := rc.Guest.LastBootUpTime()
+		if err != nil || !bootTime.After(rc.baselineGuestBootTime.Add(guestBootTimeSkew)) {
+			return false, nil
+		}
+		rc.hasBaseline = false
+		rc.wentDown = false
+		rc.hasGuestBaseline = false
+		return true, nil
+	}
+	if rc.Fallback != nil {
+		return rc.Fallback.RebootHasFinished()
+	}
+	return false, nil
+}
+
+func guestIsUp(status mo.VirtualMachine) bool {
+	if status.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn || status.Runtime.BootTime == nil {
+		return false
+	}
+	if status.Guest.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		return false
+	}
+	return status.GuestHeartbeatStatus == types.ManagedEntityStatusGreen
+}
+
+func toolsInstalled(status mo.VirtualMachine) bool {
+	return status.Guest != nil &&
+		status.Guest.ToolsVersionStatus2 != string(types.VirtualMachineToolsVersionStatusGuestToolsNotInstalled)
+}
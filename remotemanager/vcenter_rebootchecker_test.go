@@ -0,0 +1,302 @@
+package remotemanager_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
+)
+
+var _ = Describe("VCenterRebootChecker", func() {
+
+	var (
+		fakeVM       *remotemanagerfakes.FakeVMStatusReader
+		fakeFallback *remotemanagerfakes.FakeRebootCheckerI
+		status       mo.VirtualMachine
+		rc           *remotemanager.VCenterRebootChecker
+	)
+
+	BeforeEach(func() {
+		fakeVM = &remotemanagerfakes.FakeVMStatusReader{}
+		fakeFallback = &remotemanagerfakes.FakeRebootCheckerI{}
+
+		bootTime := time.Now()
+		status = mo.VirtualMachine{
+			Runtime: types.VirtualMachineRuntimeInfo{
+				PowerState: types.VirtualMachinePowerStatePoweredOn,
+				BootTime:   &bootTime,
+			},
+			Guest: &types.GuestInfo{
+				ToolsRunningStatus:  string(types.VirtualMachineToolsRunningStatusGuestToolsRunning),
+				ToolsVersionStatus2: string(types.VirtualMachineToolsVersionStatusGuestToolsCurrent),
+			},
+		}
+		status.GuestHeartbeatStatus = types.ManagedEntityStatusGreen
+
+		rc = remotemanager.NewVCenterRebootChecker(context.Background(), fakeVM, fakeFallback)
+	})
+
+	JustBeforeEach(func() {
+		fakeVM.RebootStatusReturns(status, nil)
+	})
+
+	Context("with a baseline from before the reboot", func() {
+		var before mo.VirtualMachine
+
+		BeforeEach(func() {
+			bootTime := status.Runtime.BootTime.Add(-time.Hour)
+			before = status
+			before.Runtime.BootTime = &bootTime
+		})
+
+		JustBeforeEach(func() {
+			fakeVM.RebootStatusReturnsOnCall(0, before, nil)
+			rc.RecordBaseline()
+		})
+
+		It("returns true once the VM has booted since and VMware Tools is running with a green heartbeat", func() {
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeTrue())
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(0))
+		})
+
+		Context("when the boot time is unchanged", func() {
+			BeforeEach(func() {
+				before.Runtime.BootTime = status.Runtime.BootTime
+			})
+
+			It("returns false while the guest has not been seen going down", func() {
+				rc.Fallback = nil
+
+				hasFinished, err := rc.RebootHasFinished()
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasFinished).To(BeFalse())
+			})
+
+			It("asks the fallback, which refuses while a shutdown is pending", func() {
+				hasFinished, err := rc.RebootHasFinished()
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasFinished).To(BeFalse())
+				Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(1))
+			})
+
+			It("returns true once the guest has gone down and come back up", func() {
+				down := status
+				down.Guest = &types.GuestInfo{
+					ToolsRunningStatus:  string(types.VirtualMachineToolsRunningStatusGuestToolsNotRunning),
+					ToolsVersionStatus2: string(types.VirtualMachineToolsVersionStatusGuestToolsCurrent),
+				}
+				fakeVM.RebootStatusReturnsOnCall(1, down, nil)
+
+				hasFinished, err := rc.RebootHasFinished()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasFinished).To(BeFalse())
+
+				hasFinished, err = rc.RebootHasFinished()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasFinished).To(BeTrue())
+				Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("with the last boot time of the guest in the baseline", func() {
+		var (
+			fakeGuest     *remotemanagerfakes.FakeBootTimeReader
+			guestBootTime time.Time
+		)
+
+		BeforeEach(func() {
+			fakeGuest = &remotemanagerfakes.FakeBootTimeReader{}
+			rc.Guest = fakeGuest
+			guestBootTime = time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+			fakeGuest.LastBootUpTimeReturnsOnCall(0, guestBootTime, nil)
+		})
+
+		JustBeforeEach(func() {
+			// vCenter keeps the boot time of a guest that restarts itself
+			rc.RecordBaseline()
+		})
+
+		It("returns false without asking the fallback while the guest reports the same last boot time", func() {
+			fakeGuest.LastBootUpTimeReturnsOnCall(1, guestBootTime.Add(300*time.Millisecond), nil)
+
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeFalse())
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(0))
+		})
+
+		It("returns true without asking the fallback once the guest reports a later last boot time", func() {
+			fakeGuest.LastBootUpTimeReturnsOnCall(1, guestBootTime.Add(20*time.Minute), nil)
+
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeTrue())
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(0))
+		})
+
+		It("returns false while the last boot time of the guest cannot be read", func() {
+			fakeGuest.LastBootUpTimeReturnsOnCall(1, time.Time{}, errors.New("guest operations agent could not be contacted"))
+			fakeGuest.LastBootUpTimeReturnsOnCall(2, guestBootTime.Add(20*time.Minute), nil)
+
+			hasFinished, err := rc.RebootHasFinished()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeFalse())
+
+			hasFinished, err = rc.RebootHasFinished()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeTrue())
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(0))
+		})
+
+		Context("when the last boot time of the guest cannot be read before the reboot", func() {
+			BeforeEach(func() {
+				fakeGuest.LastBootUpTimeReturnsOnCall(0, time.Time{}, errors.New("guest operations agent could not be contacted"))
+			})
+
+			It("asks the fallback", func() {
+				fakeFallback.RebootHasFinishedReturns(true, nil)
+
+				hasFinished, err := rc.RebootHasFinished()
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasFinished).To(BeTrue())
+				Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(1))
+				Expect(fakeGuest.LastBootUpTimeCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	It("asks the fallback when the VM is up without a baseline", func() {
+		fakeFallback.RebootHasFinishedReturns(true, nil)
+
+		hasFinished, err := rc.RebootHasFinished()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hasFinished).To(BeTrue())
+		Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(1))
+	})
+
+	It("reads the status with the context of the checker", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rc = remotemanager.NewVCenterRebootChecker(ctx, fakeVM, fakeFallback)
+
+		_, _ = rc.RebootHasFinished()
+
+		Expect(fakeVM.RebootStatusArgsForCall(0)).To(Equal(ctx))
+	})
+
+	Context("when the VM is powered off", func() {
+		BeforeEach(func() {
+			status.Runtime.PowerState = types.VirtualMachinePowerStatePoweredOff
+			status.Runtime.BootTime = nil
+		})
+
+		It("returns false", func() {
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeFalse())
+		})
+	})
+
+	Context("when VMware Tools is not running", func() {
+		BeforeEach(func() {
+			status.Guest.ToolsRunningStatus = string(types.VirtualMachineToolsRunningStatusGuestToolsNotRunning)
+		})
+
+		It("returns false", func() {
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeFalse())
+		})
+	})
+
+	Context("when the guest heartbeat is not green", func() {
+		BeforeEach(func() {
+			status.GuestHeartbeatStatus = types.ManagedEntityStatusGray
+		})
+
+		It("returns false", func() {
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeFalse())
+		})
+	})
+
+	Context("when VMware Tools is not installed in the guest", func() {
+		BeforeEach(func() {
+			status.Guest.ToolsVersionStatus2 = string(types.VirtualMachineToolsVersionStatusGuestToolsNotInstalled)
+		})
+
+		It("asks the fallback", func() {
+			fakeFallback.RebootHasFinishedReturns(true, nil)
+
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasFinished).To(BeTrue())
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(1))
+		})
+
+		It("returns an error without a fallback", func() {
+			rc.Fallback = nil
+
+			_, err := rc.RebootHasFinished()
+
+			Expect(err).To(MatchError("cannot tell whether the VM has rebooted without VMware Tools in the guest"))
+		})
+	})
+
+	Context("when the status of the VM cannot be read", func() {
+		JustBeforeEach(func() {
+			fakeVM.RebootStatusReturns(mo.VirtualMachine{}, errors.New("no permission"))
+		})
+
+		It("asks the fallback", func() {
+			fakeFallback.RebootHasFinishedReturns(false, errors.New("unable to abort reboot."))
+
+			hasFinished, err := rc.RebootHasFinished()
+
+			Expect(err).To(MatchError("unable to abort reboot."))
+			Expect(hasFinished).To(BeFalse())
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(1))
+		})
+
+		It("returns the error without a fallback", func() {
+			rc.Fallback = nil
+
+			_, err := rc.RebootHasFinished()
+
+			Expect(err).To(MatchError("cannot read the status of the VM from vCenter: no permission"))
+		})
+
+		It("returns the error without asking the fallback once the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			rc = remotemanager.NewVCenterRebootChecker(ctx, fakeVM, fakeFallback)
+
+			_, err := rc.RebootHasFinished()
+
+			Expect(err).To(MatchError("no permission"))
+			Expect(fakeFallback.RebootHasFinishedCallCount()).To(Equal(0))
+		})
+	})
+})
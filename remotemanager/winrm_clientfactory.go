@@ -10,20 +10,25 @@ type WinRMClientFactory struct {
 	host     string
 	username string
 	password string
+	options  WinRMOptions
 }
 
-func NewWinRmClientFactory(host, username, password string) *WinRMClientFactory {
-	return &WinRMClientFactory{host: host, username: username, password: password}
+func NewWinRmClientFactory(host, username, password string, options WinRMOptions) *WinRMClientFactory {
+	return &WinRMClientFactory{host: host, username: username, password: password, options: options}
 }
 
 func (f *WinRMClientFactory) Build(timeout time.Duration) (WinRMClient, error) {
-	endpoint := winrm.NewEndpoint(f.host, WinRmPort, false, true, nil, nil, nil, timeout)
+	endpoint, err := f.options.endpoint(f.host, timeout)
+	if err != nil {
+		return nil, err
+	}
 	params := winrm.NewParameters(
 		winrm.DefaultParameters.Timeout,
 		winrm.DefaultParameters.Locale,
 		winrm.DefaultParameters.EnvelopeSize,
 	)
 	params.AllowTimeout = true
+	params.TransportDecorator = f.options.transportDecorator()
 	client, err := winrm.NewClientWithParameters(endpoint, f.username, f.password, params)
 	return client, err
 }
@@ -0,0 +1,143 @@
+package remotemanager
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masterzen/winrm"
+)
+
+const WinRmHTTPSPort = 5986
+
+const (
+	WinRMAuthBasic = "basic"
+	WinRMAuthNTLM  = "ntlm"
+)
+
+// WinRMOptions describes how the WinRM service of the VM is reached. The zero value is
+// basic authentication over HTTP on port 5985.
+type WinRMOptions struct {
+	HTTPS bool
+	// Port defaults to 5985, or 5986 with HTTPS
+	Port int
+	// CACertFile is a PEM file with the CAs trusted to sign the WinRM HTTPS certificate, in addition to the system roots
+	CACertFile         string
+	InsecureSkipVerify bool
+	// Auth is WinRMAuthBasic or WinRMAuthNTLM, defaulting to basic
+	Auth string
+	// Timeout bounds connecting and every command run without its own timeout, defaulting to WinRmTimeout
+	Timeout time.Duration
+	// DialTimeout bounds checking that the WinRM port is reachable, defaulting to WinRmDialTimeout
+	DialTimeout time.Duration
+}
+
+func (o WinRMOptions) Validate() error {
+	if o.Port < 0 || o.Port > 65535 {
+		return fmt.Errorf("port %d is out of range", o.Port)
+	}
+
+	if o.Timeout < 0 || o.DialTimeout < 0 {
+		return errors.New("timeouts cannot be negative")
+	}
+
+	switch o.Auth {
+	case "", WinRMAuthBasic, WinRMAuthNTLM:
+	default:
+		return fmt.Errorf("auth must be one of %s, %s", WinRMAuthBasic, WinRMAuthNTLM)
+	}
+
+	if !o.HTTPS && (o.CACertFile != "" || o.InsecureSkipVerify) {
+		return errors.New("a CA certificate and skipping certificate verification only apply to HTTPS")
+	}
+
+	if o.CACertFile != "" && o.InsecureSkipVerify {
+		return errors.New("a CA certificate cannot be combined with skipping certificate verification")
+	}
+
+	if o.CACertFile != "" {
+		_, err := o.caCert()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o WinRMOptions) port() int {
+	if o.Port != 0 {
+		return o.Port
+	}
+	if o.HTTPS {
+		return WinRmHTTPSPort
+	}
+	return WinRmPort
+}
+
+func (o WinRMOptions) timeout() time.Duration {
+	if o.Timeout != 0 {
+		return o.Timeout
+	}
+	return WinRmTimeout
+}
+
+func (o WinRMOptions) dialTimeout() time.Duration {
+	if o.DialTimeout != 0 {
+		return o.DialTimeout
+	}
+	return WinRmDialTimeout
+}
+
+// Address joins host and the WinRM port, bracketing IPv6 literals
+func (o WinRMOptions) Address(host string) string {
+	return net.JoinHostPort(unbracket(host), strconv.Itoa(o.port()))
+}
+
+func (o WinRMOptions) endpoint(host string, timeout time.Duration) (*winrm.Endpoint, error) {
+	caCert, err := o.caCert()
+	if err != nil {
+		return nil, err
+	}
+
+	// the endpoint builds its URL with host:port, so IPv6 literals must already be bracketed
+	endpointHost := unbracket(host)
+	if ip := net.ParseIP(endpointHost); ip != nil && ip.To4() == nil {
+		endpointHost = "[" + endpointHost + "]"
+	}
+
+	return winrm.NewEndpoint(endpointHost, o.port(), o.HTTPS, o.InsecureSkipVerify, caCert, nil, nil, timeout), nil
+}
+
+func (o WinRMOptions) transportDecorator() func() winrm.Transporter {
+	if o.Auth == WinRMAuthNTLM {
+		return func() winrm.Transporter { return &winrm.ClientNTLM{} }
+	}
+	return nil
+}
+
+func (o WinRMOptions) caCert() ([]byte, error) {
+	if o.CACertFile == "" {
+		return nil, nil
+	}
+
+	caCert, err := os.ReadFile(o.CACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read WinRM CA certificate: %s", err)
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", o.CACertFile)
+	}
+
+	return caCert, nil
+}
+
+func unbracket(host string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
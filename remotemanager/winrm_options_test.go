@@ -0,0 +1,173 @@
+package remotemanager_test
+
+import (
+	"encoding/pem"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	"github.com/cloudfoundry/stembuild/remotemanager"
+)
+
+var _ = Describe("WinRMOptions", func() {
+	Describe("Address", func() {
+		It("uses port 5985 by default", func() {
+			Expect(remotemanager.WinRMOptions{}.Address("10.0.0.5")).To(Equal("10.0.0.5:5985"))
+		})
+
+		It("uses port 5986 for HTTPS", func() {
+			Expect(remotemanager.WinRMOptions{HTTPS: true}.Address("10.0.0.5")).To(Equal("10.0.0.5:5986"))
+		})
+
+		It("uses the configured port", func() {
+			Expect(remotemanager.WinRMOptions{HTTPS: true, Port: 8443}.Address("vm.example.com")).To(Equal("vm.example.com:8443"))
+		})
+
+		It("brackets IPv6 literals", func() {
+			Expect(remotemanager.WinRMOptions{}.Address("fd00::5")).To(Equal("[fd00::5]:5985"))
+			Expect(remotemanager.WinRMOptions{}.Address("[fd00::5]")).To(Equal("[fd00::5]:5985"))
+		})
+	})
+
+	Describe("Validate", func() {
+		var caCertFile string
+
+		BeforeEach(func() {
+			server := NewTLSServer()
+			defer server.Close()
+			caCertFile = writeCACert(server)
+		})
+
+		It("accepts the defaults", func() {
+			Expect(remotemanager.WinRMOptions{}.Validate()).To(Succeed())
+		})
+
+		It("accepts HTTPS with a CA certificate and NTLM", func() {
+			options := remotemanager.WinRMOptions{HTTPS: true, CACertFile: caCertFile, Auth: remotemanager.WinRMAuthNTLM}
+			Expect(options.Validate()).To(Succeed())
+		})
+
+		It("rejects an unknown auth", func() {
+			err := remotemanager.WinRMOptions{Auth: "kerberos"}.Validate()
+			Expect(err).To(MatchError("auth must be one of basic, ntlm"))
+		})
+
+		It("rejects a port out of range", func() {
+			Expect(remotemanager.WinRMOptions{Port: 70000}.Validate()).To(MatchError("port 70000 is out of range"))
+		})
+
+		It("rejects negative timeouts", func() {
+			Expect(remotemanager.WinRMOptions{Timeout: -time.Second}.Validate()).To(MatchError("timeouts cannot be negative"))
+			Expect(remotemanager.WinRMOptions{DialTimeout: -time.Second}.Validate()).To(MatchError("timeouts cannot be negative"))
+		})
+
+		It("rejects certificate settings without HTTPS", func() {
+			Expect(remotemanager.WinRMOptions{CACertFile: caCertFile}.Validate()).To(MatchError(ContainSubstring("only apply to HTTPS")))
+			Expect(remotemanager.WinRMOptions{InsecureSkipVerify: true}.Validate()).To(MatchError(ContainSubstring("only apply to HTTPS")))
+		})
+
+		It("rejects a CA certificate together with skipping verification", func() {
+			options := remotemanager.WinRMOptions{HTTPS: true, CACertFile: caCertFile, InsecureSkipVerify: true}
+			Expect(options.Validate()).To(MatchError(ContainSubstring("cannot be combined")))
+		})
+
+		It("rejects a CA certificate file that does not exist", func() {
+			options := remotemanager.WinRMOptions{HTTPS: true, CACertFile: filepath.Join(GinkgoT().TempDir(), "missing.pem")}
+			Expect(options.Validate()).To(MatchError(ContainSubstring("cannot read WinRM CA certificate")))
+		})
+
+		It("rejects a CA certificate file without certificates", func() {
+			notACert := filepath.Join(GinkgoT().TempDir(), "ca.pem")
+			Expect(os.WriteFile(notACert, []byte("not a certificate"), 0644)).To(Succeed())
+
+			options := remotemanager.WinRMOptions{HTTPS: true, CACertFile: notACert}
+			Expect(options.Validate()).To(MatchError(ContainSubstring("no PEM certificates found")))
+		})
+	})
+
+	Describe("WinRMClientFactory over HTTPS", func() {
+		var (
+			testServer *Server
+			host       string
+			port       int
+		)
+
+		BeforeEach(func() {
+			testServer = NewTLSServer()
+
+			testServerURL, err := url.Parse(testServer.URL())
+			Expect(err).NotTo(HaveOccurred())
+			host = testServerURL.Hostname()
+			port, err = strconv.Atoi(testServerURL.Port())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			testServer.Close()
+		})
+
+		It("connects to a server signed by the given CA", func() {
+			appendShellHandlers(testServer)
+			options := remotemanager.WinRMOptions{HTTPS: true, Port: port, CACertFile: writeCACert(testServer)}
+
+			client, err := remotemanager.NewWinRmClientFactory(host, "user", "pass", options).Build(time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			shell, err := client.CreateShell()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shell.Close()).To(Succeed())
+		})
+
+		It("connects without verifying the certificate when asked to", func() {
+			appendShellHandlers(testServer)
+			options := remotemanager.WinRMOptions{HTTPS: true, Port: port, InsecureSkipVerify: true}
+
+			client, err := remotemanager.NewWinRmClientFactory(host, "user", "pass", options).Build(time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			shell, err := client.CreateShell()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shell.Close()).To(Succeed())
+		})
+
+		It("refuses a certificate signed by an unknown CA", func() {
+			options := remotemanager.WinRMOptions{HTTPS: true, Port: port}
+
+			client, err := remotemanager.NewWinRmClientFactory(host, "user", "pass", options).Build(time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.CreateShell()
+			Expect(err).To(MatchError(ContainSubstring("certificate")))
+		})
+	})
+
+	Describe("CanReachVM", func() {
+		It("reaches an IPv6 host on the configured port", func() {
+			listener, err := net.Listen("tcp", "[::1]:0")
+			if err != nil {
+				Skip("IPv6 loopback is not available")
+			}
+			defer listener.Close()
+
+			port := listener.Addr().(*net.TCPAddr).Port
+			remoteManager := remotemanager.NewWinRM("::1", "user", "pass", remotemanager.WinRMOptions{Port: port}, nil)
+
+			Expect(remoteManager.CanReachVM()).To(Succeed())
+		})
+	})
+})
+
+func writeCACert(server *Server) string {
+	certificate := server.HTTPTestServer.Certificate()
+	caCertFile := filepath.Join(GinkgoT().TempDir(), "ca.pem")
+	err := os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}), 0644)
+	Expect(err).NotTo(HaveOccurred())
+	return caCertFile
+}
@@ -1,6 +1,7 @@
 package remotemanager
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -8,24 +9,60 @@ import (
 	"github.com/cloudfoundry/stembuild/poller"
 )
 
+const (
+	DefaultRebootPollInterval    = 10 * time.Second
+	DefaultRebootMaxPollInterval = time.Minute
+	DefaultRebootTimeout         = time.Hour
+)
+
 var tryCheckReboot = `shutdown /r /f /t 60 /c "stembuild reboot test"`
 var abortReboot = `shutdown /a`
 
 type RebootWaiter struct {
+	ctx           context.Context
 	poller        poller.PollerI
 	rebootChecker RebootCheckerI
+	// Interval is the time before the first check whether the reboot has finished
+	Interval time.Duration
+	// MaxInterval caps the time between checks as it doubles after every check
+	MaxInterval time.Duration
+	// Timeout bounds the wait for the reboot to finish
+	Timeout time.Duration
+	// Progress is called before every wait between checks, when set
+	Progress func(poller.Progress)
 }
 
-func NewRebootWaiter(poller poller.PollerI, rebootChecker RebootCheckerI) *RebootWaiter {
+func NewRebootWaiter(ctx context.Context, poller poller.PollerI, rebootChecker RebootCheckerI) *RebootWaiter {
 	return &RebootWaiter{
-		poller,
-		rebootChecker,
+		ctx:           ctx,
+		poller:        poller,
+		rebootChecker: rebootChecker,
+		Interval:      DefaultRebootPollInterval,
+		MaxInterval:   DefaultRebootMaxPollInterval,
+		Timeout:       DefaultRebootTimeout,
 	}
 }
 
-func (rw *RebootWaiter) WaitForRebootFinished() error {
-	err := rw.poller.Poll(10*time.Second, rw.rebootChecker.RebootHasFinished)
+// BeforeReboot lets the reboot checker see the VM before a reboot is triggered,
+// so that WaitForRebootFinished cannot mistake a guest that has not gone down yet for a rebooted one
+func (rw *RebootWaiter) BeforeReboot() {
+	if baseliner, ok := rw.rebootChecker.(RebootBaseliner); ok {
+		baseliner.RecordBaseline()
+	}
+}
 
+func (rw *RebootWaiter) WaitForRebootFinished() error {
+	err := rw.poller.PollUntil(rw.ctx, poller.Options{
+		Interval:    rw.Interval,
+		MaxInterval: rw.MaxInterval,
+		Jitter:      poller.DefaultJitter,
+		Timeout:     rw.Timeout,
+		Progress:    rw.Progress,
+	}, rw.rebootChecker.RebootHasFinished)
+
+	if errors.Is(err, poller.ErrTimedOut) {
+		return fmt.Errorf("the VM did not finish rebooting within %s, increase -reboot-timeout if it needs longer", rw.Timeout)
+	}
 	if err != nil {
 		return fmt.Errorf("error polling for reboot: %s", err)
 	}
@@ -1,6 +1,8 @@
 package remotemanager_test
 
 import (
+	"context"
+	"fmt"
 	_ "reflect"
 	"time"
 
@@ -8,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 
+	"github.com/cloudfoundry/stembuild/poller"
 	"github.com/cloudfoundry/stembuild/poller/pollerfakes"
 	"github.com/cloudfoundry/stembuild/remotemanager"
 	"github.com/cloudfoundry/stembuild/remotemanager/remotemanagerfakes"
@@ -34,7 +37,7 @@ var _ = Describe("WinRM RebootChecker", func() {
 	Describe("WaitForRebootFinished", func() {
 		It("calls the hasFinished func using the Poller", func() {
 			numberOfPollCalls := 8
-			fakePoller.PollStub = func(duration time.Duration, pollFunc func() (bool, error)) error {
+			fakePoller.PollUntilStub = func(ctx context.Context, opts poller.Options, pollFunc func() (bool, error)) error {
 				for call := 0; call < numberOfPollCalls; call++ {
 					pollFunc() //nolint:errcheck
 				}
@@ -43,23 +46,23 @@ var _ = Describe("WinRM RebootChecker", func() {
 
 			rc := &remotemanagerfakes.FakeRebootCheckerI{}
 			rc.RebootHasFinishedReturns(false, nil)
-			waiter := remotemanager.NewRebootWaiter(fakePoller, rc)
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, rc)
 
 			_ = waiter.WaitForRebootFinished()
 
-			Expect(fakePoller.PollCallCount()).To(Equal(1))
+			Expect(fakePoller.PollUntilCallCount()).To(Equal(1))
 			Expect(rc.RebootHasFinishedCallCount()).To(Equal(numberOfPollCalls))
 		})
 
 		It("returns nil if a reboot has finished successfully", func() {
-			fakePoller.PollStub = func(duration time.Duration, pollFunc func() (bool, error)) error {
+			fakePoller.PollUntilStub = func(ctx context.Context, opts poller.Options, pollFunc func() (bool, error)) error {
 				pollFunc() //nolint:errcheck
 				return nil
 			}
 
 			rc := &remotemanagerfakes.FakeRebootCheckerI{}
 			rc.RebootHasFinishedReturns(false, nil)
-			waiter := remotemanager.NewRebootWaiter(fakePoller, rc)
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, rc)
 
 			err := waiter.WaitForRebootFinished()
 			Expect(err).ToNot(HaveOccurred())
@@ -67,13 +70,76 @@ var _ = Describe("WinRM RebootChecker", func() {
 
 		It("returns error if a reboot cannot finish successfully", func() {
 			errorMessage := "unable to abort reboot."
-			fakePoller.PollReturns(errors.New(errorMessage))
+			fakePoller.PollUntilReturns(errors.New(errorMessage))
 
-			waiter := remotemanager.NewRebootWaiter(fakePoller, rc)
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, rc)
 
 			err := waiter.WaitForRebootFinished()
 			Expect(err.Error()).To(ContainSubstring(errorMessage))
 		})
+
+		It("polls with the context, intervals and timeout of the waiter", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			waiter := remotemanager.NewRebootWaiter(ctx, fakePoller, rc)
+			Expect(waiter.Interval).To(Equal(remotemanager.DefaultRebootPollInterval))
+			Expect(waiter.MaxInterval).To(Equal(remotemanager.DefaultRebootMaxPollInterval))
+			Expect(waiter.Timeout).To(Equal(remotemanager.DefaultRebootTimeout))
+
+			waiter.Interval = 5 * time.Second
+			waiter.MaxInterval = 20 * time.Second
+			waiter.Timeout = 30 * time.Minute
+			_ = waiter.WaitForRebootFinished()
+
+			pollCtx, opts, _ := fakePoller.PollUntilArgsForCall(0)
+			Expect(pollCtx).To(Equal(ctx))
+			Expect(opts.Interval).To(Equal(5 * time.Second))
+			Expect(opts.MaxInterval).To(Equal(20 * time.Second))
+			Expect(opts.Jitter).To(Equal(poller.DefaultJitter))
+			Expect(opts.Timeout).To(Equal(30 * time.Minute))
+		})
+
+		It("reports progress between checks", func() {
+			var reported []poller.Progress
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, rc)
+			waiter.Progress = func(p poller.Progress) {
+				reported = append(reported, p)
+			}
+
+			_ = waiter.WaitForRebootFinished()
+
+			_, opts, _ := fakePoller.PollUntilArgsForCall(0)
+			opts.Progress(poller.Progress{Attempt: 1, Elapsed: time.Minute})
+			Expect(reported).To(Equal([]poller.Progress{{Attempt: 1, Elapsed: time.Minute}}))
+		})
+
+		It("returns a timeout error when the reboot does not finish in time", func() {
+			fakePoller.PollUntilReturns(fmt.Errorf("%w after 1h0m0s", poller.ErrTimedOut))
+
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, rc)
+
+			err := waiter.WaitForRebootFinished()
+			Expect(err).To(MatchError("the VM did not finish rebooting within 1h0m0s, increase -reboot-timeout if it needs longer"))
+		})
+	})
+
+	Describe("BeforeReboot", func() {
+		It("records a baseline with a checker that takes one", func() {
+			fakeVM := &remotemanagerfakes.FakeVMStatusReader{}
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, remotemanager.NewVCenterRebootChecker(context.Background(), fakeVM, rc))
+
+			waiter.BeforeReboot()
+
+			Expect(fakeVM.RebootStatusCallCount()).To(Equal(1))
+		})
+
+		It("does nothing with a checker that takes no baseline", func() {
+			waiter := remotemanager.NewRebootWaiter(context.Background(), fakePoller, rc)
+
+			waiter.BeforeReboot()
+
+			Expect(fakeRemoteManager.ExecuteCommandCallCount()).To(Equal(0))
+		})
 	})
 
 	Describe("RebootHasFinished", func() {
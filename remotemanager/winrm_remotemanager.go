@@ -2,10 +2,12 @@ package remotemanager
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/masterzen/winrm"
@@ -14,17 +16,27 @@ import (
 
 const WinRmPort = 5985
 const WinRmTimeout = 120 * time.Second
+const WinRmDialTimeout = 60 * time.Second
+
+var stderrSwap sync.Mutex
 
 type WinRM struct {
 	host          string
 	username      string
 	password      string
+	options       WinRMOptions
 	clientFactory WinRMClientFactoryI
+
+	// Stdout and Stderr receive the output of commands run in the guest
+	Stdout io.Writer
+	Stderr io.Writer
+	// Context stops commands running in the guest once it is done, default is context.Background()
+	Context context.Context
 }
 
 //counterfeiter:generate . WinRMClient
 type WinRMClient interface {
-	Run(command string, stdout io.Writer, stderr io.Writer) (int, error)
+	RunWithContext(ctx context.Context, command string, stdout io.Writer, stderr io.Writer) (int, error)
 	CreateShell() (*winrm.Shell, error)
 }
 
@@ -33,12 +45,21 @@ type WinRMClientFactoryI interface {
 	Build(timeout time.Duration) (WinRMClient, error)
 }
 
-func NewWinRM(host string, username string, password string, clientFactory WinRMClientFactoryI) RemoteManager {
-	return &WinRM{host, username, password, clientFactory}
+func NewWinRM(host string, username string, password string, options WinRMOptions, clientFactory WinRMClientFactoryI) *WinRM {
+	return &WinRM{
+		host:          host,
+		username:      username,
+		password:      password,
+		options:       options,
+		clientFactory: clientFactory,
+		Stdout:        os.Stdout,
+		Stderr:        os.Stderr,
+		Context:       context.Background(),
+	}
 }
 
 func (w *WinRM) CanReachVM() error {
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", w.host, WinRmPort), time.Second*60)
+	conn, err := net.DialTimeout("tcp", w.options.Address(w.host), w.options.dialTimeout())
 	if err != nil {
 		return fmt.Errorf("host %s is unreachable; lease ensure WinRM is enabled and the IP is correct: %w", w.host, err)
 	}
@@ -52,7 +73,7 @@ func (w *WinRM) CanReachVM() error {
 }
 
 func (w *WinRM) CanLoginVM() error {
-	winrmClient, err := w.clientFactory.Build(WinRmTimeout)
+	winrmClient, err := w.clientFactory.Build(w.options.timeout())
 
 	if err != nil {
 		return fmt.Errorf("failed to create winrm client: %w", err)
@@ -72,13 +93,20 @@ func (w *WinRM) CanLoginVM() error {
 }
 
 func (w *WinRM) UploadArtifact(sourceFilePath, destinationFilePath string) error {
-	client, err := winrmcp.New(w.host, &winrmcp.Config{
+	caCert, err := w.options.caCert()
+	if err != nil {
+		return err
+	}
+
+	client, err := winrmcp.New(w.options.Address(w.host), &winrmcp.Config{
 		Auth:                  winrmcp.Auth{User: w.username, Password: w.password},
-		Https:                 false,
-		Insecure:              true,
-		ConnectTimeout:        WinRmTimeout,
-		OperationTimeout:      WinRmTimeout,
+		Https:                 w.options.HTTPS,
+		Insecure:              w.options.InsecureSkipVerify,
+		CACertBytes:           caCert,
+		ConnectTimeout:        w.options.timeout(),
+		OperationTimeout:      w.options.timeout(),
 		MaxOperationsPerShell: 15,
+		TransportDecorator:    w.options.transportDecorator(),
 		AllowTimeout:          true,
 	})
 
@@ -88,7 +116,11 @@ func (w *WinRM) UploadArtifact(sourceFilePath, destinationFilePath string) error
 
 	// We override Stderr because WinRM Copy output a lot of XML status messages to StdErr
 	// even though they are not errors. In addition, these status messages are difficult to read
-	// and add little customer value. WinRM does not have an output override for Copy yet
+	// and add little customer value. WinRM does not have an output override for Copy yet.
+	// os.Stderr is process-wide, so uploads running concurrently must not swap it at the same time
+	stderrSwap.Lock()
+	defer stderrSwap.Unlock()
+
 	reader, tmpStdOut, _ := os.Pipe()
 	oldStdErr := os.Stderr
 	os.Stderr = tmpStdOut
@@ -114,15 +146,22 @@ func (w *WinRM) ExecuteCommandWithTimeout(command string, timeout time.Duration)
 		return -1, err
 	}
 	errBuffer := new(bytes.Buffer)
-	exitCode, err := client.Run(command, os.Stdout, io.MultiWriter(errBuffer, os.Stderr))
+	exitCode, err := client.RunWithContext(w.context(), command, w.Stdout, io.MultiWriter(errBuffer, w.Stderr))
 	if err == nil && exitCode != 0 {
 		err = fmt.Errorf("%s: %s", PowershellExecutionErrorMessage, errBuffer.String())
 	}
 	return exitCode, err
 }
 
+func (w *WinRM) context() context.Context {
+	if w.Context == nil {
+		return context.Background()
+	}
+	return w.Context
+}
+
 func (w *WinRM) ExecuteCommand(command string) (int, error) {
-	exitCode, err := w.ExecuteCommandWithTimeout(command, WinRmTimeout)
+	exitCode, err := w.ExecuteCommandWithTimeout(command, w.options.timeout())
 	if err != nil {
 		return exitCode, fmt.Errorf("error executing '%s': %w", command, err)
 	}
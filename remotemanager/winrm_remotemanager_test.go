@@ -1,6 +1,8 @@
 package remotemanager_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -18,7 +20,10 @@ import (
 )
 
 func setupTestServer() *Server {
-	server := NewServer()
+	return appendShellHandlers(NewServer())
+}
+
+func appendShellHandlers(server *Server) *Server {
 
 	// winRMClient expects: `//w:Selector[@Name='ShellId']`
 	createShellResponse := `<s:Envelope xmlns:s="https://www.w3.org/2003/05/soap-envelope"
@@ -79,19 +84,43 @@ var _ = Describe("WinRM RemoteManager", func() {
 		Context("when a command runs successfully", func() {
 			BeforeEach(func() {
 				fakeClient = &remotemanagerfakes.FakeWinRMClient{}
-				fakeClient.RunReturns(0, nil)
+				fakeClient.RunWithContextReturns(0, nil)
 				fakeClientFactory = &remotemanagerfakes.FakeWinRMClientFactoryI{}
 				fakeClientFactory.BuildReturns(fakeClient, nil)
 			})
 
 			It("returns an exit code of 0 and no error", func() {
-				remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", fakeClientFactory)
+				remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", remotemanager.WinRMOptions{}, fakeClientFactory)
 				exitCode, err := remoteManager.ExecuteCommand("foobar")
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(exitCode).To(Equal(0))
 			})
 
+			It("passes Stdout and Stderr to the command", func() {
+				remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", remotemanager.WinRMOptions{}, fakeClientFactory)
+				stdout := &bytes.Buffer{}
+				remoteManager.Stdout = stdout
+				_, err := remoteManager.ExecuteCommand("foobar")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, commandStdout, _ := fakeClient.RunWithContextArgsForCall(0)
+				Expect(commandStdout).To(BeIdenticalTo(stdout))
+			})
+
+			It("runs the command with its Context, so that the command stops once the context is done", func() {
+				remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", remotemanager.WinRMOptions{}, fakeClientFactory)
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				remoteManager.Context = ctx
+
+				_, err := remoteManager.ExecuteCommand("foobar")
+				Expect(err).NotTo(HaveOccurred())
+
+				commandCtx, _, _, _ := fakeClient.RunWithContextArgsForCall(0)
+				Expect(commandCtx).To(BeIdenticalTo(ctx))
+			})
+
 		})
 
 		Context("when a command does not run successfully", func() {
@@ -103,11 +132,11 @@ var _ = Describe("WinRM RemoteManager", func() {
 
 			Context("when a command returns a nonzero exit code and an error", func() {
 				BeforeEach(func() {
-					fakeClient.RunReturns(2, errors.New("command error"))
+					fakeClient.RunWithContextReturns(2, errors.New("command error"))
 				})
 
 				It("returns the command's nonzero exit code and errors", func() {
-					remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", fakeClientFactory)
+					remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", remotemanager.WinRMOptions{}, fakeClientFactory)
 					exitCode, err := remoteManager.ExecuteCommand("foobar")
 
 					Expect(err).To(HaveOccurred())
@@ -117,11 +146,11 @@ var _ = Describe("WinRM RemoteManager", func() {
 
 			Context("when a command returns a nonzero exit code but does not error", func() {
 				BeforeEach(func() {
-					fakeClient.RunReturns(2, nil)
+					fakeClient.RunWithContextReturns(2, nil)
 				})
 
 				It("returns the command's nonzero exit code and errors", func() {
-					remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", fakeClientFactory)
+					remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", remotemanager.WinRMOptions{}, fakeClientFactory)
 					exitCode, err := remoteManager.ExecuteCommand("foobar")
 
 					Expect(err).To(HaveOccurred())
@@ -131,11 +160,11 @@ var _ = Describe("WinRM RemoteManager", func() {
 
 			Context("when a command exits 0 but errors", func() {
 				BeforeEach(func() {
-					fakeClient.RunReturns(0, errors.New("command error"))
+					fakeClient.RunWithContextReturns(0, errors.New("command error"))
 				})
 
 				It("returns the command's exit code and errors", func() {
-					remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", fakeClientFactory)
+					remoteManager := remotemanager.NewWinRM("foo", "bar", "baz", remotemanager.WinRMOptions{}, fakeClientFactory)
 					exitCode, err := remoteManager.ExecuteCommand("foobar")
 
 					Expect(err).To(HaveOccurred())
@@ -175,7 +204,7 @@ var _ = Describe("WinRM RemoteManager", func() {
 			winRMClientFactory := &remotemanagerfakes.FakeWinRMClientFactoryI{}
 			winRMClientFactory.BuildReturns(winRMClient, nil)
 
-			remotemanager := remotemanager.NewWinRM("some-host", "some-user", "some-pass", winRMClientFactory)
+			remotemanager := remotemanager.NewWinRM("some-host", "some-user", "some-pass", remotemanager.WinRMOptions{}, winRMClientFactory)
 
 			err := remotemanager.CanLoginVM()
 			Expect(err).NotTo(HaveOccurred())
@@ -186,7 +215,7 @@ var _ = Describe("WinRM RemoteManager", func() {
 			buildErr := errors.New("unable to build a client")
 			winRMClientFactory.BuildReturns(nil, buildErr)
 
-			remotemanager := remotemanager.NewWinRM("some-host", "some-user", "some-pass", winRMClientFactory)
+			remotemanager := remotemanager.NewWinRM("some-host", "some-user", "some-pass", remotemanager.WinRMOptions{}, winRMClientFactory)
 
 			err := remotemanager.CanLoginVM()
 			Expect(err).To(HaveOccurred())
@@ -201,7 +230,7 @@ var _ = Describe("WinRM RemoteManager", func() {
 			shellErr := errors.New("some shell creation error")
 			winRMClient.CreateShellReturns(nil, shellErr)
 
-			remotemanager := remotemanager.NewWinRM("some-host", "some-user", "some-pass", winRMClientFactory)
+			remotemanager := remotemanager.NewWinRM("some-host", "some-user", "some-pass", remotemanager.WinRMOptions{}, winRMClientFactory)
 
 			err := remotemanager.CanLoginVM()
 			Expect(err).To(HaveOccurred())
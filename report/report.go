@@ -0,0 +1,124 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Step records when a step of construct or package ran and how long it took
+type Step struct {
+	Name     string    `json:"name"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration float64   `json:"duration_seconds"`
+	Failed   bool      `json:"failed,omitempty"`
+}
+
+// Recorder records the timing of steps. It is safe for concurrent use, and a nil Recorder records nothing.
+type Recorder struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Time runs a step and records its timing
+func (r *Recorder) Time(name string, run func() error) error {
+	start := time.Now()
+	err := run()
+	if r == nil {
+		return err
+	}
+
+	end := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, Step{
+		Name:     name,
+		Start:    start.UTC(),
+		End:      end.UTC(),
+		Duration: end.Sub(start).Seconds(),
+		Failed:   err != nil,
+	})
+	return err
+}
+
+// Steps returns the recorded steps in the order they finished
+func (r *Recorder) Steps() []Step {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Step(nil), r.steps...)
+}
+
+// Merge keeps the earlier steps that were not run again, followed by the later steps
+func Merge(earlier, later []Step) []Step {
+	rerun := map[string]bool{}
+	for _, step := range later {
+		rerun[step.Name] = true
+	}
+
+	var merged []Step
+	for _, step := range earlier {
+		if !rerun[step.Name] {
+			merged = append(merged, step)
+		}
+	}
+	return append(merged, later...)
+}
+
+// Report is the build report written next to a stemcell
+type Report struct {
+	Stemcell string `json:"stemcell"`
+	OS       string `json:"os"`
+	Version  string `json:"version"`
+	// Construct holds the steps of the construct that provisioned the packaged VM, when they were recorded on this machine
+	Construct []Step `json:"construct,omitempty"`
+	Package   []Step `json:"package"`
+}
+
+// Path is where the report of a stemcell is written
+func Path(stemcellPath string) string {
+	return stemcellPath + ".report.json"
+}
+
+func (r Report) Write(path string) error {
+	contents, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode build report: %s", err)
+	}
+
+	err = os.WriteFile(path, append(contents, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("could not write build report: %s", err)
+	}
+	return nil
+}
+
+// PrintSummary writes a table of the duration of every step and their total
+func PrintSummary(w io.Writer, title string, steps []Step) {
+	fmt.Fprintf(w, "\n%s:\n", title)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	var total time.Duration
+	for _, step := range steps {
+		duration := time.Duration(step.Duration * float64(time.Second))
+		total += duration
+		if step.Failed {
+			fmt.Fprintf(tw, "  %s\t%s (failed)\n", step.Name, duration.Round(time.Second))
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", step.Name, duration.Round(time.Second))
+	}
+	fmt.Fprintf(tw, "  total\t%s\n", total.Round(time.Second))
+	tw.Flush() //nolint:errcheck
+}
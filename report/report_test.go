@@ -0,0 +1,109 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/report"
+)
+
+var _ = Describe("Recorder", func() {
+	It("records the timing of every step in the order they finished", func() {
+		recorder := report.NewRecorder()
+
+		Expect(recorder.Time("export", func() error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})).To(Succeed())
+		Expect(recorder.Time("stemcell-tar", func() error { return errors.New("disk full") })).To(MatchError("disk full"))
+
+		steps := recorder.Steps()
+		Expect(steps).To(HaveLen(2))
+		Expect(steps[0].Name).To(Equal("export"))
+		Expect(steps[0].End).To(BeTemporally(">=", steps[0].Start.Add(10*time.Millisecond)))
+		Expect(steps[0].Duration).To(BeNumerically(">=", 0.01))
+		Expect(steps[0].Failed).To(BeFalse())
+		Expect(steps[1].Name).To(Equal("stemcell-tar"))
+		Expect(steps[1].Failed).To(BeTrue())
+	})
+
+	It("only runs steps when nil", func() {
+		var recorder *report.Recorder
+		ran := false
+
+		Expect(recorder.Time("export", func() error {
+			ran = true
+			return nil
+		})).To(Succeed())
+
+		Expect(ran).To(BeTrue())
+		Expect(recorder.Steps()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Merge", func() {
+	It("keeps the earlier steps that were not run again", func() {
+		earlier := []report.Step{{Name: "enable-winrm", Duration: 1}, {Name: "upload-artifacts", Duration: 2, Failed: true}}
+		later := []report.Step{{Name: "upload-artifacts", Duration: 3}, {Name: "reboot", Duration: 4}}
+
+		Expect(report.Merge(earlier, later)).To(Equal([]report.Step{
+			{Name: "enable-winrm", Duration: 1},
+			{Name: "upload-artifacts", Duration: 3},
+			{Name: "reboot", Duration: 4},
+		}))
+	})
+})
+
+var _ = Describe("Report", func() {
+	It("is written next to the stemcell as JSON", func() {
+		stemcellPath := filepath.Join(GinkgoT().TempDir(), "bosh-stemcell-2019.2-vsphere-esxi-windows2019-go_agent.tgz")
+		r := report.Report{
+			Stemcell: stemcellPath,
+			OS:       "2019",
+			Version:  "2019.2",
+			Package:  []report.Step{{Name: "export", Duration: 90}},
+		}
+
+		Expect(report.Path(stemcellPath)).To(Equal(stemcellPath + ".report.json"))
+		Expect(r.Write(report.Path(stemcellPath))).To(Succeed())
+
+		contents, err := os.ReadFile(report.Path(stemcellPath))
+		Expect(err).NotTo(HaveOccurred())
+		var written map[string]interface{}
+		Expect(json.Unmarshal(contents, &written)).To(Succeed())
+		Expect(written).To(HaveKeyWithValue("stemcell", stemcellPath))
+		Expect(written).To(HaveKeyWithValue("os", "2019"))
+		Expect(written).To(HaveKeyWithValue("version", "2019.2"))
+		Expect(written).NotTo(HaveKey("construct"))
+		Expect(written["package"]).To(HaveLen(1))
+		Expect(written["package"].([]interface{})[0]).To(HaveKeyWithValue("duration_seconds", 90.0))
+	})
+
+	It("returns an error when the report cannot be written", func() {
+		err := report.Report{}.Write(filepath.Join(GinkgoT().TempDir(), "missing", "report.json"))
+		Expect(err).To(MatchError(HavePrefix("could not write build report: ")))
+	})
+})
+
+var _ = Describe("PrintSummary", func() {
+	It("prints the duration of every step and the total", func() {
+		output := &bytes.Buffer{}
+
+		report.PrintSummary(output, "Package step timings", []report.Step{
+			{Name: "export", Duration: 725.4},
+			{Name: "image-gzip", Duration: 61, Failed: true},
+		})
+
+		Expect(output.String()).To(Equal("\nPackage step timings:\n" +
+			"  export      12m5s\n" +
+			"  image-gzip  1m1s (failed)\n" +
+			"  total       13m6s\n"))
+	})
+})
@@ -0,0 +1,114 @@
+package secret
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Provider returns a secret such as a password. Implement it to read secrets from another store.
+type Provider interface {
+	Secret() (string, error)
+}
+
+// File reads the secret from a file, ignoring a trailing newline
+type File struct {
+	Path string
+}
+
+func (f File) Secret() (string, error) {
+	contents, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("could not read secret file: %s", err)
+	}
+
+	secret := strings.TrimRight(string(contents), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", f.Path)
+	}
+	return secret, nil
+}
+
+// Env reads the secret from an environment variable
+type Env struct {
+	Name string
+}
+
+func (e Env) Secret() (string, error) {
+	secret := os.Getenv(e.Name)
+	if secret == "" {
+		return "", fmt.Errorf("environment variable %s is not set", e.Name)
+	}
+	return secret, nil
+}
+
+// Reader reads the secret from the first line of Input, e.g. stdin
+type Reader struct {
+	Input io.Reader
+}
+
+func (r Reader) Secret() (string, error) {
+	line, err := bufio.NewReader(r.Input).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("could not read secret from stdin: %s", err)
+	}
+
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		return "", errors.New("no secret was given on stdin")
+	}
+	return secret, nil
+}
+
+// Prompt asks for the secret on a terminal without echoing it
+type Prompt struct {
+	// Label names the secret in the prompt, e.g. "vCenter password"
+	Label    string
+	Terminal *os.File
+	Output   io.Writer
+}
+
+// CanPrompt tells whether input is a terminal that a Prompt can read from
+func CanPrompt(input *os.File) bool {
+	return input != nil && term.IsTerminal(int(input.Fd()))
+}
+
+func (p Prompt) Secret() (string, error) {
+	if !CanPrompt(p.Terminal) {
+		return "", fmt.Errorf("cannot prompt for the %s without a terminal", p.Label)
+	}
+
+	fmt.Fprintf(p.Output, "%s: ", p.Label)
+	secret, err := term.ReadPassword(int(p.Terminal.Fd()))
+	fmt.Fprintln(p.Output)
+	if err != nil {
+		return "", fmt.Errorf("could not read the %s: %s", p.Label, err)
+	}
+	if len(secret) == 0 {
+		return "", fmt.Errorf("no %s was given", p.Label)
+	}
+	return string(secret), nil
+}
+
+// Parse returns the provider for a source of the form file:<path>, env:<name> or stdin.
+// Stdin is read from stdin, which may be nil when it is not available.
+func Parse(source string, stdin io.Reader) (Provider, error) {
+	scheme, arg, _ := strings.Cut(source, ":")
+	switch {
+	case scheme == "file" && arg != "":
+		return File{Path: arg}, nil
+	case scheme == "env" && arg != "":
+		return Env{Name: arg}, nil
+	case source == "stdin":
+		if stdin == nil {
+			return nil, errors.New("stdin is not available")
+		}
+		return Reader{Input: stdin}, nil
+	}
+	return nil, fmt.Errorf("%q must be file:<path>, env:<name> or stdin", source)
+}
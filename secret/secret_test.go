@@ -0,0 +1,109 @@
+package secret_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/stembuild/secret"
+)
+
+var _ = Describe("Secret", func() {
+	Describe("File", func() {
+		var secretFile string
+
+		BeforeEach(func() {
+			secretFile = filepath.Join(GinkgoT().TempDir(), "password")
+		})
+
+		It("returns the contents of the file without a trailing newline", func() {
+			Expect(os.WriteFile(secretFile, []byte("p@ss word\r\n"), 0600)).To(Succeed())
+
+			Expect(secret.File{Path: secretFile}.Secret()).To(Equal("p@ss word"))
+		})
+
+		It("returns an error when the file is empty", func() {
+			Expect(os.WriteFile(secretFile, []byte("\n"), 0600)).To(Succeed())
+
+			_, err := secret.File{Path: secretFile}.Secret()
+			Expect(err).To(MatchError("secret file " + secretFile + " is empty"))
+		})
+
+		It("returns an error when the file cannot be read", func() {
+			_, err := secret.File{Path: secretFile}.Secret()
+			Expect(err).To(MatchError(HavePrefix("could not read secret file: ")))
+		})
+	})
+
+	Describe("Env", func() {
+		It("returns the value of the environment variable", func() {
+			GinkgoT().Setenv("SECRET_TEST_PASSWORD", "from-env")
+
+			Expect(secret.Env{Name: "SECRET_TEST_PASSWORD"}.Secret()).To(Equal("from-env"))
+		})
+
+		It("returns an error when the environment variable is not set", func() {
+			_, err := secret.Env{Name: "SECRET_TEST_UNSET"}.Secret()
+			Expect(err).To(MatchError("environment variable SECRET_TEST_UNSET is not set"))
+		})
+	})
+
+	Describe("Reader", func() {
+		It("returns the first line", func() {
+			Expect(secret.Reader{Input: strings.NewReader("first\nsecond\n")}.Secret()).To(Equal("first"))
+		})
+
+		It("accepts a line without a newline", func() {
+			Expect(secret.Reader{Input: strings.NewReader("only")}.Secret()).To(Equal("only"))
+		})
+
+		It("returns an error when nothing is given", func() {
+			_, err := secret.Reader{Input: strings.NewReader("")}.Secret()
+			Expect(err).To(MatchError("no secret was given on stdin"))
+		})
+	})
+
+	Describe("Prompt", func() {
+		It("returns an error without a terminal", func() {
+			notATerminal, err := os.CreateTemp(GinkgoT().TempDir(), "stdin")
+			Expect(err).NotTo(HaveOccurred())
+			defer notATerminal.Close()
+
+			Expect(secret.CanPrompt(notATerminal)).To(BeFalse())
+			Expect(secret.CanPrompt(nil)).To(BeFalse())
+
+			_, err = secret.Prompt{Label: "VM password", Terminal: notATerminal, Output: io.Discard}.Secret()
+			Expect(err).To(MatchError("cannot prompt for the VM password without a terminal"))
+		})
+	})
+
+	Describe("Parse", func() {
+		It("returns the provider for the source", func() {
+			stdin := strings.NewReader("")
+
+			Expect(secret.Parse("file:/run/secrets/password", stdin)).To(Equal(secret.File{Path: "/run/secrets/password"}))
+			Expect(secret.Parse("env:VM_PASSWORD", stdin)).To(Equal(secret.Env{Name: "VM_PASSWORD"}))
+			Expect(secret.Parse("stdin", stdin)).To(Equal(secret.Reader{Input: stdin}))
+		})
+
+		It("returns an error for stdin when it is not available", func() {
+			_, err := secret.Parse("stdin", nil)
+			Expect(err).To(MatchError("stdin is not available"))
+		})
+
+		DescribeTable("rejects invalid sources",
+			func(source string) {
+				_, err := secret.Parse(source, nil)
+				Expect(err).To(MatchError(`"` + source + `" must be file:<path>, env:<name> or stdin`))
+			},
+			Entry("without a scheme", "password"),
+			Entry("with an unknown scheme", "vault:secret/vm"),
+			Entry("with an empty path", "file:"),
+			Entry("with an empty name", "env:"),
+		)
+	})
+})